@@ -0,0 +1,9 @@
+--SCHEMA VERSIONING
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO schema_migrations(version)
+VALUES (1), (2), (3)
+ON CONFLICT (version) DO NOTHING;
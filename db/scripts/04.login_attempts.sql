@@ -0,0 +1,27 @@
+--LOGIN ATTEMPTS AND ACCOUNT LOCKOUT
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    login VARCHAR(256) NOT NULL,
+    player_id UUID,
+    ip VARCHAR(45) NOT NULL,
+    succeeded BOOLEAN NOT NULL,
+    attempted_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT login_attempts_fk_player FOREIGN KEY (player_id) REFERENCES players(id)
+);
+
+CREATE INDEX IF NOT EXISTS login_attempts_ip_attempted_at ON login_attempts(ip, attempted_at);
+
+CREATE TABLE IF NOT EXISTS account_lockouts (
+    player_id UUID PRIMARY KEY,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    lockout_count INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMPTZ,
+    last_failed_at TIMESTAMPTZ,
+
+    CONSTRAINT account_lockouts_fk_player FOREIGN KEY (player_id) REFERENCES players(id)
+);
+
+INSERT INTO schema_migrations(version)
+VALUES (4)
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,15 @@
+--ROLES AND MODERATION
+ALTER TABLE players ADD COLUMN IF NOT EXISTS role VARCHAR(16) NOT NULL DEFAULT 'player';
+ALTER TABLE players ADD COLUMN IF NOT EXISTS disabled BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE players DROP CONSTRAINT IF EXISTS players_role_check;
+ALTER TABLE players ADD CONSTRAINT players_role_check CHECK (role IN ('player', 'moderator', 'admin'));
+
+ALTER TABLE games ADD COLUMN IF NOT EXISTS voided BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE players SET role = 'admin' WHERE login = 'player_1';
+UPDATE players SET role = 'moderator' WHERE login = 'player_2';
+
+INSERT INTO schema_migrations(version)
+VALUES (5)
+ON CONFLICT (version) DO NOTHING;
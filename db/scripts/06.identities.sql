@@ -0,0 +1,19 @@
+--EXTERNAL IDENTITIES
+ALTER TABLE players ALTER COLUMN password DROP NOT NULL;
+
+CREATE TABLE IF NOT EXISTS player_identities (
+    issuer VARCHAR(256) NOT NULL,
+    subject VARCHAR(256) NOT NULL,
+    player_id UUID NOT NULL,
+    email VARCHAR(256),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (issuer, subject),
+    CONSTRAINT player_identities_fk_player FOREIGN KEY (player_id) REFERENCES players(id)
+);
+
+CREATE INDEX IF NOT EXISTS player_identities_player_id ON player_identities(player_id);
+
+INSERT INTO schema_migrations(version)
+VALUES (6)
+ON CONFLICT (version) DO NOTHING;
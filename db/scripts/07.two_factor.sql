@@ -0,0 +1,24 @@
+--TWO-FACTOR AUTHENTICATION
+CREATE TABLE IF NOT EXISTS player_two_factor (
+    player_id UUID PRIMARY KEY,
+    secret VARCHAR(64) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    confirmed_at TIMESTAMPTZ,
+
+    CONSTRAINT player_two_factor_fk_player FOREIGN KEY (player_id) REFERENCES players(id)
+);
+
+CREATE TABLE IF NOT EXISTS player_recovery_codes (
+    player_id UUID NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ,
+
+    PRIMARY KEY (player_id, code_hash),
+    CONSTRAINT player_recovery_codes_fk_player FOREIGN KEY (player_id) REFERENCES players(id)
+);
+
+INSERT INTO schema_migrations(version)
+VALUES (7)
+ON CONFLICT (version) DO NOTHING;
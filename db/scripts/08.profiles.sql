@@ -0,0 +1,17 @@
+--PLAYER PROFILES AND RATINGS
+ALTER TABLE players ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE players ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+ALTER TABLE players_stats ADD COLUMN IF NOT EXISTS rating INTEGER NOT NULL DEFAULT 1200;
+
+ALTER TABLE games ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE games ADD COLUMN IF NOT EXISTS finished_at TIMESTAMPTZ;
+ALTER TABLE games ADD COLUMN IF NOT EXISTS host_rating_delta INTEGER;
+ALTER TABLE games ADD COLUMN IF NOT EXISTS guest_rating_delta INTEGER;
+
+CREATE INDEX IF NOT EXISTS games_host_id_finished_at ON games(host_id, finished_at);
+CREATE INDEX IF NOT EXISTS games_guest_id_finished_at ON games(guest_id, finished_at);
+
+INSERT INTO schema_migrations(version)
+VALUES (8)
+ON CONFLICT (version) DO NOTHING;
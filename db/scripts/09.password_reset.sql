@@ -0,0 +1,19 @@
+--PASSWORD RESET
+ALTER TABLE players ADD COLUMN IF NOT EXISTS email VARCHAR(256);
+CREATE UNIQUE INDEX IF NOT EXISTS players_email_idx ON players (lower(email));
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    token_hash CHAR(64) PRIMARY KEY,
+    player_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+
+    CONSTRAINT password_reset_tokens_fk_player FOREIGN KEY (player_id) REFERENCES players(id)
+);
+
+CREATE INDEX IF NOT EXISTS password_reset_tokens_player_idx ON password_reset_tokens (player_id);
+
+INSERT INTO schema_migrations(version)
+VALUES (9)
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,46 @@
+--FRIENDS, BLOCKS AND INVITATIONS
+CREATE TABLE IF NOT EXISTS friendships (
+    requester_id UUID NOT NULL,
+    addressee_id UUID NOT NULL,
+    accepted BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    accepted_at TIMESTAMPTZ,
+
+    PRIMARY KEY (requester_id, addressee_id),
+    CONSTRAINT friendships_fk_requester FOREIGN KEY (requester_id) REFERENCES players(id),
+    CONSTRAINT friendships_fk_addressee FOREIGN KEY (addressee_id) REFERENCES players(id),
+    CONSTRAINT friendships_not_self CHECK (requester_id <> addressee_id)
+);
+
+-- One friendship per pair of players, whoever asked first.
+CREATE UNIQUE INDEX IF NOT EXISTS friendships_pair_idx ON friendships (LEAST(requester_id, addressee_id), GREATEST(requester_id, addressee_id));
+CREATE INDEX IF NOT EXISTS friendships_addressee_idx ON friendships (addressee_id);
+
+CREATE TABLE IF NOT EXISTS player_blocks (
+    player_id UUID NOT NULL,
+    blocked_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (player_id, blocked_id),
+    CONSTRAINT player_blocks_fk_player FOREIGN KEY (player_id) REFERENCES players(id),
+    CONSTRAINT player_blocks_fk_blocked FOREIGN KEY (blocked_id) REFERENCES players(id),
+    CONSTRAINT player_blocks_not_self CHECK (player_id <> blocked_id)
+);
+
+CREATE INDEX IF NOT EXISTS player_blocks_blocked_idx ON player_blocks (blocked_id);
+
+-- A room with an invitation is private: only the invited player can join it.
+CREATE TABLE IF NOT EXISTS room_invitations (
+    room_id UUID PRIMARY KEY,
+    player_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT room_invitations_fk_room FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE,
+    CONSTRAINT room_invitations_fk_player FOREIGN KEY (player_id) REFERENCES players(id)
+);
+
+CREATE INDEX IF NOT EXISTS room_invitations_player_idx ON room_invitations (player_id);
+
+INSERT INTO schema_migrations(version)
+VALUES (10)
+ON CONFLICT (version) DO NOTHING;
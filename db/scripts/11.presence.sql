@@ -0,0 +1,6 @@
+--PRESENCE
+ALTER TABLE players ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ;
+
+INSERT INTO schema_migrations(version)
+VALUES (11)
+ON CONFLICT (version) DO NOTHING;
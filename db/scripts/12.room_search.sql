@@ -0,0 +1,7 @@
+--ROOM SEARCH
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+CREATE INDEX IF NOT EXISTS rooms_phase_created_at_id_idx ON rooms (phase, created_at DESC, id DESC);
+
+INSERT INTO schema_migrations(version)
+VALUES (12)
+ON CONFLICT (version) DO NOTHING;
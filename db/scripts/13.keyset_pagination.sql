@@ -0,0 +1,6 @@
+--KEYSET PAGINATION
+CREATE INDEX IF NOT EXISTS players_stats_ranking_idx ON players_stats (wins DESC, draws DESC, losses ASC);
+
+INSERT INTO schema_migrations(version)
+VALUES (13)
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,54 @@
+--SEASONS
+CREATE TABLE IF NOT EXISTS seasons (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(50) NOT NULL,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    archived_at TIMESTAMPTZ,
+
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS seasons_starts_at_idx ON seasons (starts_at);
+
+CREATE TABLE IF NOT EXISTS season_stats (
+    season_id UUID NOT NULL,
+    player_id UUID NOT NULL,
+    wins INTEGER NOT NULL DEFAULT 0,
+    losses INTEGER NOT NULL DEFAULT 0,
+    draws INTEGER NOT NULL DEFAULT 0,
+    rating INTEGER NOT NULL,
+
+    PRIMARY KEY (season_id, player_id),
+    CONSTRAINT season_stats_fk_season FOREIGN KEY (season_id) REFERENCES seasons(id),
+    CONSTRAINT season_stats_fk_player FOREIGN KEY (player_id) REFERENCES players(id)
+);
+
+CREATE INDEX IF NOT EXISTS season_stats_player_idx ON season_stats (player_id);
+CREATE INDEX IF NOT EXISTS season_stats_ranking_idx ON season_stats (season_id, rating DESC, wins DESC);
+
+CREATE TABLE IF NOT EXISTS season_standings (
+    season_id UUID NOT NULL,
+    player_id UUID NOT NULL,
+    rank INTEGER NOT NULL,
+    wins INTEGER NOT NULL,
+    losses INTEGER NOT NULL,
+    draws INTEGER NOT NULL,
+    rating INTEGER NOT NULL,
+
+    PRIMARY KEY (season_id, player_id),
+    CONSTRAINT season_standings_fk_season FOREIGN KEY (season_id) REFERENCES seasons(id),
+    CONSTRAINT season_standings_fk_player FOREIGN KEY (player_id) REFERENCES players(id)
+);
+
+CREATE INDEX IF NOT EXISTS season_standings_rank_idx ON season_standings (season_id, rank);
+
+ALTER TABLE games ADD COLUMN IF NOT EXISTS season_id UUID REFERENCES seasons(id);
+ALTER TABLE games ADD COLUMN IF NOT EXISTS host_season_rating_delta INTEGER;
+ALTER TABLE games ADD COLUMN IF NOT EXISTS guest_season_rating_delta INTEGER;
+
+CREATE INDEX IF NOT EXISTS games_finished_at_idx ON games (finished_at);
+
+INSERT INTO schema_migrations(version)
+VALUES (14)
+ON CONFLICT (version) DO NOTHING;
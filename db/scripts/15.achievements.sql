@@ -0,0 +1,16 @@
+--ACHIEVEMENTS
+ALTER TABLE players_stats ADD COLUMN IF NOT EXISTS current_streak INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE players_stats ADD COLUMN IF NOT EXISTS longest_streak INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS player_achievements (
+    player_id UUID NOT NULL,
+    achievement VARCHAR(30) NOT NULL,
+    unlocked_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (player_id, achievement),
+    CONSTRAINT player_achievements_fk_player FOREIGN KEY (player_id) REFERENCES players(id)
+);
+
+INSERT INTO schema_migrations(version)
+VALUES (15)
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,17 @@
+--GAME MOVES
+CREATE TABLE IF NOT EXISTS game_moves (
+    game_id UUID NOT NULL,
+    seq INTEGER NOT NULL,
+    player_id UUID NOT NULL,
+    position SMALLINT NOT NULL,
+    mark CHAR(1) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (game_id, seq),
+    CONSTRAINT game_moves_fk_game FOREIGN KEY (game_id) REFERENCES games(id),
+    CONSTRAINT game_moves_fk_player FOREIGN KEY (player_id) REFERENCES players(id)
+);
+
+INSERT INTO schema_migrations(version)
+VALUES (16)
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,9 @@
+--UNRANKED ROOMS
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS ranked BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE games ADD COLUMN IF NOT EXISTS hints_used INTEGER NOT NULL DEFAULT 0;
+-- Every game started before rooms could be unranked was ranked.
+ALTER TABLE games ADD COLUMN IF NOT EXISTS ranked BOOLEAN NOT NULL DEFAULT true;
+
+INSERT INTO schema_migrations(version)
+VALUES (17)
+ON CONFLICT (version) DO NOTHING;
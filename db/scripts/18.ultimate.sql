@@ -0,0 +1,10 @@
+--ULTIMATE VARIANT
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS variant VARCHAR(20) NOT NULL DEFAULT 'classic';
+ALTER TABLE games ADD COLUMN IF NOT EXISTS variant VARCHAR(20) NOT NULL DEFAULT 'classic';
+ALTER TABLE games ADD COLUMN IF NOT EXISTS next_board SMALLINT;
+
+CREATE INDEX IF NOT EXISTS rooms_phase_variant_idx ON rooms (phase, variant);
+
+INSERT INTO schema_migrations(version)
+VALUES (18)
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,7 @@
+--RULE SETS
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS rule_set VARCHAR(20) NOT NULL DEFAULT 'standard';
+ALTER TABLE games ADD COLUMN IF NOT EXISTS rule_set VARCHAR(20) NOT NULL DEFAULT 'standard';
+
+INSERT INTO schema_migrations(version)
+VALUES (19)
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,35 @@
+--CONNECT FOUR
+CREATE TABLE IF NOT EXISTS players_game_stats (
+    player_id UUID NOT NULL,
+    variant VARCHAR(20) NOT NULL,
+    wins INTEGER NOT NULL DEFAULT 0,
+    losses INTEGER NOT NULL DEFAULT 0,
+    draws INTEGER NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (player_id, variant),
+    CONSTRAINT players_game_stats_fk_player FOREIGN KEY (player_id) REFERENCES players(id)
+);
+
+CREATE INDEX IF NOT EXISTS players_game_stats_ranking_idx ON players_game_stats (variant, wins DESC, draws DESC, losses ASC);
+
+-- Completed games (phase 1) of ranked rooms.
+INSERT INTO players_game_stats (player_id, variant, wins, losses, draws)
+SELECT r.player_id, r.variant,
+    COUNT(*) FILTER (WHERE r.winner_id = r.player_id),
+    COUNT(*) FILTER (WHERE r.winner_id <> r.player_id),
+    COUNT(*) FILTER (WHERE r.winner_id IS NULL)
+FROM (
+    SELECT g.host_id AS player_id, g.variant, g.winner_id
+    FROM games AS g
+    WHERE g.phase = 1 AND NOT g.voided AND g.ranked
+    UNION ALL
+    SELECT g.guest_id AS player_id, g.variant, g.winner_id
+    FROM games AS g
+    WHERE g.phase = 1 AND NOT g.voided AND g.ranked
+) AS r
+GROUP BY r.player_id, r.variant
+ON CONFLICT (player_id, variant) DO NOTHING;
+
+INSERT INTO schema_migrations(version)
+VALUES (20)
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,18 @@
+--START POLICIES
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS mark_policy VARCHAR(20) NOT NULL DEFAULT 'random';
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS first_move_policy VARCHAR(20) NOT NULL DEFAULT 'alternate';
+ALTER TABLE games ADD COLUMN IF NOT EXISTS mark_policy VARCHAR(20) NOT NULL DEFAULT 'random';
+ALTER TABLE games ADD COLUMN IF NOT EXISTS first_move_policy VARCHAR(20) NOT NULL DEFAULT 'alternate';
+ALTER TABLE games ADD COLUMN IF NOT EXISTS first_player_id UUID;
+
+-- The first player of earlier games is only known from their recorded moves.
+UPDATE games g
+SET first_player_id = m.player_id
+FROM game_moves m
+WHERE m.game_id = g.id
+    AND m.seq = 1
+    AND g.first_player_id IS NULL;
+
+INSERT INTO schema_migrations(version)
+VALUES (21)
+ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,6 @@
+--LOGIN ATTEMPTS RETENTION
+CREATE INDEX IF NOT EXISTS login_attempts_attempted_at_idx ON login_attempts (attempted_at);
+
+INSERT INTO schema_migrations(version)
+VALUES (22)
+ON CONFLICT (version) DO NOTHING;
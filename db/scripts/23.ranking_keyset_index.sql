@@ -0,0 +1,9 @@
+--RANKING KEYSET INDEX
+-- A row comparison can only use an index whose columns all sort the same
+-- way, so the descending keys are negated. The player id breaks ties.
+DROP INDEX IF EXISTS players_stats_ranking_idx;
+CREATE INDEX IF NOT EXISTS players_stats_ranking_idx ON players_stats ((-wins), (-draws), losses, player_id);
+
+INSERT INTO schema_migrations(version)
+VALUES (23)
+ON CONFLICT (version) DO NOTHING;
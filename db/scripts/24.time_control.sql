@@ -0,0 +1,8 @@
+--TIME CONTROL
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS time_control VARCHAR(20) NOT NULL DEFAULT 'untimed';
+
+CREATE INDEX IF NOT EXISTS rooms_phase_time_control_idx ON rooms (phase, time_control);
+
+INSERT INTO schema_migrations(version)
+VALUES (24)
+ON CONFLICT (version) DO NOTHING;
@@ -2,13 +2,27 @@ package app
 
 import (
 	"context"
+	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/plamen-v/tic-tac-toe/src/app/server"
 	"github.com/plamen-v/tic-tac-toe/src/config"
+	"github.com/plamen-v/tic-tac-toe/src/services/admin"
+	"github.com/plamen-v/tic-tac-toe/src/services/analysis"
 	"github.com/plamen-v/tic-tac-toe/src/services/auth"
 	"github.com/plamen-v/tic-tac-toe/src/services/engine"
+	"github.com/plamen-v/tic-tac-toe/src/services/health"
+	"github.com/plamen-v/tic-tac-toe/src/services/lockout"
 	"github.com/plamen-v/tic-tac-toe/src/services/logger"
+	"github.com/plamen-v/tic-tac-toe/src/services/metrics"
+	"github.com/plamen-v/tic-tac-toe/src/services/oidc"
+	"github.com/plamen-v/tic-tac-toe/src/services/passwordreset"
+	"github.com/plamen-v/tic-tac-toe/src/services/presence"
+	"github.com/plamen-v/tic-tac-toe/src/services/profile"
+	"github.com/plamen-v/tic-tac-toe/src/services/ratelimit"
+	"github.com/plamen-v/tic-tac-toe/src/services/season"
+	"github.com/plamen-v/tic-tac-toe/src/services/social"
+	"github.com/plamen-v/tic-tac-toe/src/services/twofactor"
 )
 
 type Application interface {
@@ -20,19 +34,58 @@ type applicationImpl struct {
 	config                *config.AppConfiguration
 	logger                logger.LoggerService
 	server                server.APIServer
+	metricsService        metrics.MetricsService
+	healthService         health.HealthService
+	rateLimitService      ratelimit.RateLimitService
+	presenceService       presence.PresenceService
 	authenticationService auth.AuthenticationService
+	lockoutService        lockout.LockoutService
+	oidcService           oidc.OIDCService
+	twoFactorService      twofactor.TwoFactorService
+	adminService          admin.AdminService
+	profileService        profile.ProfileService
+	passwordResetService  passwordreset.PasswordResetService
+	socialService         social.SocialService
+	seasonService         season.SeasonService
+	analysisService       analysis.AnalysisService
 	gameEngineService     engine.GameEngineService
 }
 
 func NewApplication(
 	configuration *config.AppConfiguration,
 	logger logger.LoggerService,
+	metricsService metrics.MetricsService,
+	healthService health.HealthService,
+	rateLimitService ratelimit.RateLimitService,
+	presenceService presence.PresenceService,
 	authenticationService auth.AuthenticationService,
+	lockoutService lockout.LockoutService,
+	oidcService oidc.OIDCService,
+	twoFactorService twofactor.TwoFactorService,
+	adminService admin.AdminService,
+	profileService profile.ProfileService,
+	passwordResetService passwordreset.PasswordResetService,
+	socialService social.SocialService,
+	seasonService season.SeasonService,
+	analysisService analysis.AnalysisService,
 	gameEngineService engine.GameEngineService) Application {
 	return &applicationImpl{
 		config:                configuration,
 		logger:                logger,
+		metricsService:        metricsService,
+		healthService:         healthService,
+		rateLimitService:      rateLimitService,
+		presenceService:       presenceService,
 		authenticationService: authenticationService,
+		lockoutService:        lockoutService,
+		oidcService:           oidcService,
+		twoFactorService:      twoFactorService,
+		adminService:          adminService,
+		profileService:        profileService,
+		passwordResetService:  passwordResetService,
+		socialService:         socialService,
+		seasonService:         seasonService,
+		analysisService:       analysisService,
 		gameEngineService:     gameEngineService,
 	}
 }
@@ -51,10 +104,17 @@ func (a *applicationImpl) Stop(ctx context.Context) error {
 }
 
 func (a *applicationImpl) initialize() error {
-	a.server = server.NewAPI(a.config, a.logger, a.authenticationService, a.gameEngineService)
+	a.server = server.NewAPI(a.config, a.logger, a.metricsService, a.healthService, a.rateLimitService, a.presenceService, a.authenticationService, a.lockoutService, a.oidcService, a.twoFactorService, a.adminService, a.profileService, a.passwordResetService, a.socialService, a.seasonService, a.analysisService, a.gameEngineService)
 	return nil
 }
 
 func (a *applicationImpl) finalize(ctx context.Context) error {
+	// Fail readiness first and give load balancers time to stop routing
+	// new traffic before in-flight requests are drained.
+	a.healthService.SetShuttingDown()
+	select {
+	case <-time.After(a.config.Server.ShutdownDelay):
+	case <-ctx.Done():
+	}
 	return a.server.Stop(ctx)
 }
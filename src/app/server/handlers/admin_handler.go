@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/admin"
+	"github.com/plamen-v/tic-tac-toe/src/services/engine"
+	"github.com/plamen-v/tic-tac-toe/src/services/lockout"
+)
+
+func GetPlayersHandler(adminService admin.AdminService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		pageStr := c.Query("page")
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			page = 1
+		}
+
+		pageSizeStr := c.Query("pageSize")
+		pageSize, err := strconv.Atoi(pageSizeStr)
+		if err != nil {
+			pageSize = engine.DefaultPageSize
+		}
+
+		players, pageSize, page, total, err := adminService.GetPlayers(c.Request.Context(), page, pageSize)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		response := domain.AccountListResponse{
+			Players: players,
+			PageInfo: models.PageInfo{
+				Page:     page,
+				PageSize: pageSize,
+				TotalCnt: total,
+			},
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func UpdatePlayerRoleHandler(adminService admin.AdminService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var request domain.UpdateRoleRequest
+		if err := c.BindJSON(&request); err != nil {
+			_ = c.Error(models.NewValidationError("bad request"))
+			return
+		}
+
+		pPlayerID := c.Param("playerId")
+		playerID, err := uuid.FromString(pPlayerID)
+		if err != nil {
+			_ = c.Error(models.NewValidationErrorf("Invalid player id '%s'", pPlayerID))
+			return
+		}
+
+		actorID, ok := getPlayerIDFromContext(c, middleware.KEY_PLAYER_ID)
+		if !ok {
+			_ = c.Error(models.NewValidationError("Missing player_id claim"))
+			return
+		}
+
+		err = adminService.SetPlayerRole(c.Request.Context(), actorID, playerID, request.Role)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.Status(http.StatusOK)
+	}
+}
+
+func UpdatePlayerDisabledHandler(adminService admin.AdminService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var request domain.UpdateDisabledRequest
+		if err := c.BindJSON(&request); err != nil {
+			_ = c.Error(models.NewValidationError("bad request"))
+			return
+		}
+
+		pPlayerID := c.Param("playerId")
+		playerID, err := uuid.FromString(pPlayerID)
+		if err != nil {
+			_ = c.Error(models.NewValidationErrorf("Invalid player id '%s'", pPlayerID))
+			return
+		}
+
+		actorID, ok := getPlayerIDFromContext(c, middleware.KEY_PLAYER_ID)
+		if !ok {
+			_ = c.Error(models.NewValidationError("Missing player_id claim"))
+			return
+		}
+
+		err = adminService.SetPlayerDisabled(c.Request.Context(), actorID, playerID, request.Disabled)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.Status(http.StatusOK)
+	}
+}
+
+func UpdatePlayerStatsHandler(adminService admin.AdminService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var request models.PlayerStats
+		if err := c.BindJSON(&request); err != nil {
+			_ = c.Error(models.NewValidationError("bad request"))
+			return
+		}
+
+		pPlayerID := c.Param("playerId")
+		playerID, err := uuid.FromString(pPlayerID)
+		if err != nil {
+			_ = c.Error(models.NewValidationErrorf("Invalid player id '%s'", pPlayerID))
+			return
+		}
+
+		err = adminService.UpdatePlayerStats(c.Request.Context(), playerID, request)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.Status(http.StatusOK)
+	}
+}
+
+func CloseRoomHandler(adminService admin.AdminService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		pRoomID := c.Param("roomId")
+		roomID, err := uuid.FromString(pRoomID)
+		if err != nil {
+			_ = c.Error(models.NewValidationErrorf("Invalid room id '%s'", pRoomID))
+			return
+		}
+
+		err = adminService.CloseRoom(c.Request.Context(), roomID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.Status(http.StatusOK)
+	}
+}
+
+func VoidGameHandler(adminService admin.AdminService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		pGameID := c.Param("gameId")
+		gameID, err := uuid.FromString(pGameID)
+		if err != nil {
+			_ = c.Error(models.NewValidationErrorf("Invalid game id '%s'", pGameID))
+			return
+		}
+
+		err = adminService.VoidGame(c.Request.Context(), gameID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.Status(http.StatusOK)
+	}
+}
+
+func GetLockoutsHandler(lockoutService lockout.LockoutService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		lockouts, err := lockoutService.GetLockouts(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		response := domain.LockoutListResponse{
+			Lockouts: lockouts,
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func GetLockoutHandler(lockoutService lockout.LockoutService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		pPlayerID := c.Param("playerId")
+		playerID, err := uuid.FromString(pPlayerID)
+		if err != nil {
+			_ = c.Error(models.NewValidationErrorf("Invalid player id '%s'", pPlayerID))
+			return
+		}
+
+		lockout, err := lockoutService.GetLockout(c.Request.Context(), playerID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		response := domain.LockoutResponse{
+			Lockout: lockout,
+			Locked:  lockout.IsLocked(time.Now()),
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func UnlockHandler(lockoutService lockout.LockoutService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		pPlayerID := c.Param("playerId")
+		playerID, err := uuid.FromString(pPlayerID)
+		if err != nil {
+			_ = c.Error(models.NewValidationErrorf("Invalid player id '%s'", pPlayerID))
+			return
+		}
+
+		err = lockoutService.Unlock(c.Request.Context(), playerID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.Status(http.StatusOK)
+	}
+}
@@ -0,0 +1,249 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/handlers"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	adminmocks "github.com/plamen-v/tic-tac-toe/src/services/admin/mocks"
+	"github.com/plamen-v/tic-tac-toe/src/services/lockout/mocks"
+	"github.com/stretchr/testify/mock"
+
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AdminHandler", func() {
+	var (
+		mockLockoutService *mocks.MockLockoutService
+		mockAdminService   *adminmocks.MockAdminService
+		router             *gin.Engine
+	)
+
+	jsonRequest := func(method string, target string, body any) *http.Request {
+		requestBody, err := json.Marshal(body)
+		Expect(err).To(BeNil())
+		request := httptest.NewRequest(method, target, bytes.NewBuffer(requestBody))
+		request.Header.Set("Content-Type", "application/json")
+		return request
+	}
+
+	BeforeEach(func() {
+		mockLockoutService = new(mocks.MockLockoutService)
+		mockAdminService = new(adminmocks.MockAdminService)
+		gin.SetMode(gin.TestMode)
+		router = gin.Default()
+		router.Use(middleware.ErrorHandler())
+	})
+
+	Context("GetLockouts", func() {
+		It("should return 200 with the lockouts", func() {
+			lockouts := []*domain.Lockout{{PlayerID: uuid.Must(uuid.NewV4()), Login: "player_1", LockoutCount: 1}}
+			mockLockoutService.On("GetLockouts", mock.Anything).Return(lockouts, nil)
+			router.GET("/lockouts", handlers.GetLockoutsHandler(mockLockoutService))
+
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/lockouts", nil))
+
+			Expect(response.Code).To(Equal(http.StatusOK))
+			var body domain.LockoutListResponse
+			Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+			Expect(body.Lockouts).To(HaveLen(1))
+			Expect(body.Lockouts[0].Login).To(Equal("player_1"))
+		})
+
+		It("should return 500 if server error occurs", func() {
+			mockLockoutService.On("GetLockouts", mock.Anything).Return(nil, models.NewGenericError("server error"))
+			router.GET("/lockouts", handlers.GetLockoutsHandler(mockLockoutService))
+
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/lockouts", nil))
+
+			Expect(response.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	Context("GetLockout", func() {
+		It("should return 400 if player id is invalid", func() {
+			router.GET("/lockouts/:playerId", handlers.GetLockoutHandler(mockLockoutService))
+
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/lockouts/invalid", nil))
+
+			Expect(response.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should report whether the account is locked", func() {
+			playerID := uuid.Must(uuid.NewV4())
+			lockedUntil := time.Now().Add(time.Minute)
+			mockLockoutService.On("GetLockout", mock.Anything, playerID).Return(&domain.Lockout{PlayerID: playerID, LockedUntil: &lockedUntil}, nil)
+			router.GET("/lockouts/:playerId", handlers.GetLockoutHandler(mockLockoutService))
+
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/lockouts/%s", playerID), nil))
+
+			Expect(response.Code).To(Equal(http.StatusOK))
+			var body domain.LockoutResponse
+			Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+			Expect(body.Locked).To(BeTrue())
+		})
+
+		It("should return 404 if there is no lockout", func() {
+			mockLockoutService.On("GetLockout", mock.Anything, mock.Anything).Return(nil, models.NewNotFoundError("not found"))
+			router.GET("/lockouts/:playerId", handlers.GetLockoutHandler(mockLockoutService))
+
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/lockouts/%s", uuid.Must(uuid.NewV4())), nil))
+
+			Expect(response.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("Unlock", func() {
+		It("should return 200 when the account is unlocked", func() {
+			playerID := uuid.Must(uuid.NewV4())
+			mockLockoutService.On("Unlock", mock.Anything, playerID).Return(nil)
+			router.DELETE("/lockouts/:playerId", handlers.UnlockHandler(mockLockoutService))
+
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/lockouts/%s", playerID), nil))
+
+			Expect(response.Code).To(Equal(http.StatusOK))
+			mockLockoutService.AssertExpectations(GinkgoT())
+		})
+
+		It("should return 400 if player id is invalid", func() {
+			router.DELETE("/lockouts/:playerId", handlers.UnlockHandler(mockLockoutService))
+
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, httptest.NewRequest(http.MethodDelete, "/lockouts/invalid", nil))
+
+			Expect(response.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("GetPlayers", func() {
+		It("should return 200 with the page of players", func() {
+			players := []*domain.Account{{Player: models.Player{Login: "player_1"}, Role: domain.RoleAdmin}}
+			mockAdminService.On("GetPlayers", mock.Anything, 2, 5).Return(players, 5, 2, 6, nil)
+			router.GET("/players", handlers.GetPlayersHandler(mockAdminService))
+
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/players?page=2&pageSize=5", nil))
+
+			Expect(response.Code).To(Equal(http.StatusOK))
+			var body domain.AccountListResponse
+			Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+			Expect(body.Players).To(HaveLen(1))
+			Expect(body.Players[0].Role).To(Equal(domain.RoleAdmin))
+			Expect(body.PageInfo.TotalCnt).To(Equal(6))
+		})
+	})
+
+	Context("UpdatePlayerRole", func() {
+		It("should pass the acting player to the service", func() {
+			actorID := uuid.Must(uuid.NewV4())
+			playerID := uuid.Must(uuid.NewV4())
+			mockAdminService.On("SetPlayerRole", mock.Anything, actorID, playerID, domain.RoleModerator).Return(nil)
+			router.Use(insertPlayerIDInContextMiddleware(actorID))
+			router.PUT("/players/:playerId/role", handlers.UpdatePlayerRoleHandler(mockAdminService))
+
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, jsonRequest(http.MethodPut, fmt.Sprintf("/players/%s/role", playerID), domain.UpdateRoleRequest{Role: domain.RoleModerator}))
+
+			Expect(response.Code).To(Equal(http.StatusOK))
+			mockAdminService.AssertExpectations(GinkgoT())
+		})
+
+		It("should return 400 if request is invalid", func() {
+			router.PUT("/players/:playerId/role", handlers.UpdatePlayerRoleHandler(mockAdminService))
+
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, httptest.NewRequest(http.MethodPut, fmt.Sprintf("/players/%s/role", uuid.Must(uuid.NewV4())), nil))
+
+			Expect(response.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("UpdatePlayerDisabled", func() {
+		It("should return 200 when the player is disabled", func() {
+			actorID := uuid.Must(uuid.NewV4())
+			playerID := uuid.Must(uuid.NewV4())
+			mockAdminService.On("SetPlayerDisabled", mock.Anything, actorID, playerID, true).Return(nil)
+			router.Use(insertPlayerIDInContextMiddleware(actorID))
+			router.PUT("/players/:playerId/disabled", handlers.UpdatePlayerDisabledHandler(mockAdminService))
+
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, jsonRequest(http.MethodPut, fmt.Sprintf("/players/%s/disabled", playerID), domain.UpdateDisabledRequest{Disabled: true}))
+
+			Expect(response.Code).To(Equal(http.StatusOK))
+			mockAdminService.AssertExpectations(GinkgoT())
+		})
+	})
+
+	Context("UpdatePlayerStats", func() {
+		It("should return 400 if the service rejects the stats", func() {
+			mockAdminService.On("UpdatePlayerStats", mock.Anything, mock.Anything, mock.Anything).Return(models.NewValidationError("stats can not be negative"))
+			router.PUT("/players/:playerId/stats", handlers.UpdatePlayerStatsHandler(mockAdminService))
+
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, jsonRequest(http.MethodPut, fmt.Sprintf("/players/%s/stats", uuid.Must(uuid.NewV4())), models.PlayerStats{Wins: -1}))
+
+			Expect(response.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("CloseRoom", func() {
+		It("should return 200 when the room is closed", func() {
+			roomID := uuid.Must(uuid.NewV4())
+			mockAdminService.On("CloseRoom", mock.Anything, roomID).Return(nil)
+			router.DELETE("/rooms/:roomId", handlers.CloseRoomHandler(mockAdminService))
+
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/rooms/%s", roomID), nil))
+
+			Expect(response.Code).To(Equal(http.StatusOK))
+		})
+
+		It("should return 404 if the room does not exist", func() {
+			mockAdminService.On("CloseRoom", mock.Anything, mock.Anything).Return(models.NewNotFoundError("not found"))
+			router.DELETE("/rooms/:roomId", handlers.CloseRoomHandler(mockAdminService))
+
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/rooms/%s", uuid.Must(uuid.NewV4())), nil))
+
+			Expect(response.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("VoidGame", func() {
+		It("should return 400 if game id is invalid", func() {
+			router.POST("/games/:gameId/void", handlers.VoidGameHandler(mockAdminService))
+
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/games/invalid/void", nil))
+
+			Expect(response.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should return 200 when the game is voided", func() {
+			gameID := uuid.Must(uuid.NewV4())
+			mockAdminService.On("VoidGame", mock.Anything, gameID).Return(nil)
+			router.POST("/games/:gameId/void", handlers.VoidGameHandler(mockAdminService))
+
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/games/%s/void", gameID), nil))
+
+			Expect(response.Code).To(Equal(http.StatusOK))
+		})
+	})
+})
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/services/analysis"
+)
+
+func GetGameAnalysisHandler(analysisService analysis.AnalysisService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		pGameID := c.Param("gameId")
+		gameID, err := uuid.FromString(pGameID)
+		if err != nil {
+			_ = c.Error(models.NewValidationErrorf("Invalid game id '%s'", pGameID))
+			return
+		}
+
+		result, err := analysisService.AnalyzeGame(c.Request.Context(), gameID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+func GetHintHandler(analysisService analysis.AnalysisService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		pRoomID := c.Param("roomId")
+		roomID, err := uuid.FromString(pRoomID)
+		if err != nil {
+			_ = c.Error(models.NewValidationErrorf("Invalid room id '%s'", pRoomID))
+			return
+		}
+
+		playerID, ok := getPlayerIDFromContext(c, middleware.KEY_PLAYER_ID)
+		if !ok {
+			_ = c.Error(models.NewValidationError("Missing player_id claim"))
+			return
+		}
+
+		hint, err := analysisService.GetHint(c.Request.Context(), roomID, playerID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, hint)
+	}
+}
@@ -0,0 +1,108 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/handlers"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/analysis"
+	"github.com/plamen-v/tic-tac-toe/src/services/analysis/mocks"
+	"github.com/stretchr/testify/mock"
+
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AnalysisHandler", func() {
+	var (
+		mockAnalysisService *mocks.MockAnalysisService
+		router              *gin.Engine
+		gameID              uuid.UUID
+		roomID              uuid.UUID
+		playerID            uuid.UUID
+	)
+
+	BeforeEach(func() {
+		mockAnalysisService = new(mocks.MockAnalysisService)
+		gameID = uuid.Must(uuid.NewV4())
+		roomID = uuid.Must(uuid.NewV4())
+		playerID = uuid.Must(uuid.NewV4())
+		gin.SetMode(gin.TestMode)
+		router = gin.Default()
+		router.Use(middleware.ErrorHandler())
+		router.Use(func(c *gin.Context) {
+			c.Set(middleware.KEY_PLAYER_ID, uuid.NullUUID{UUID: playerID, Valid: true})
+		})
+		router.GET("/games/:gameId/analysis", handlers.GetGameAnalysisHandler(mockAnalysisService))
+		router.GET("/rooms/:roomId/game/hint", handlers.GetHintHandler(mockAnalysisService))
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		request, err := http.NewRequest(http.MethodGet, path, nil)
+		Expect(err).To(BeNil())
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		return response
+	}
+
+	It("should return the analysis of the game", func() {
+		mockAnalysisService.On("AnalyzeGame", mock.Anything, gameID).
+			Return(&domain.GameAnalysis{
+				GameID: gameID,
+				Moves:  []*domain.AnalyzedMove{{Move: domain.Move{Seq: 1, Position: 5, Mark: "X"}, BestMoves: []int{5}}},
+			}, nil)
+
+		response := serve("/games/" + gameID.String() + "/analysis")
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var body domain.GameAnalysis
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Moves).To(HaveLen(1))
+		Expect(body.Moves[0].Position).To(Equal(5))
+	})
+
+	It("should return bad request if the game is in progress", func() {
+		mockAnalysisService.On("AnalyzeGame", mock.Anything, gameID).
+			Return(nil, models.NewValidationError(analysis.GameNotCompletedErrorMessage))
+
+		response := serve("/games/" + gameID.String() + "/analysis")
+
+		Expect(response.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should return bad request for an invalid game id", func() {
+		response := serve("/games/abc/analysis")
+
+		Expect(response.Code).To(Equal(http.StatusBadRequest))
+		mockAnalysisService.AssertNotCalled(GinkgoT(), "AnalyzeGame", mock.Anything, mock.Anything)
+	})
+
+	It("should return the hint for the player", func() {
+		mockAnalysisService.On("GetHint", mock.Anything, roomID, playerID).
+			Return(&domain.Hint{Position: 5, Evaluation: domain.EvaluationDraw, HintsUsed: 1}, nil)
+
+		response := serve("/rooms/" + roomID.String() + "/game/hint")
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var body domain.Hint
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Position).To(Equal(5))
+		Expect(body.HintsUsed).To(Equal(1))
+	})
+
+	It("should return bad request in a ranked room", func() {
+		mockAnalysisService.On("GetHint", mock.Anything, roomID, playerID).
+			Return(nil, models.NewValidationError(analysis.RankedRoomHintErrorMessage))
+
+		response := serve("/rooms/" + roomID.String() + "/game/hint")
+
+		Expect(response.Code).To(Equal(http.StatusBadRequest))
+	})
+})
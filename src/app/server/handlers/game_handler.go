@@ -9,7 +9,9 @@ import (
 
 	"github.com/plamen-v/tic-tac-toe-models/models"
 	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
 	"github.com/plamen-v/tic-tac-toe/src/services/engine"
+	"github.com/plamen-v/tic-tac-toe/src/services/presence"
 )
 
 func GetRoomHandler(gameEngineService engine.GameEngineService) func(*gin.Context) {
@@ -34,7 +36,7 @@ func GetRoomHandler(gameEngineService engine.GameEngineService) func(*gin.Contex
 	}
 }
 
-func GetOpenRoomsHandler(gameEngineService engine.GameEngineService) func(*gin.Context) {
+func GetOpenRoomsHandler(gameEngineService engine.GameEngineService, presenceService presence.PresenceService) func(*gin.Context) {
 	return func(c *gin.Context) {
 		pageStr := c.Query("page")
 		page, err := strconv.Atoi(pageStr)
@@ -48,19 +50,55 @@ func GetOpenRoomsHandler(gameEngineService engine.GameEngineService) func(*gin.C
 			pageSize = engine.DefaultPageSize
 		}
 
-		rooms, pageSize, page, total, err := gameEngineService.GetOpenRooms(c.Request.Context(), page, pageSize)
+		filter := &domain.RoomFilter{
+			Title:        c.Query("title"),
+			HostNickname: c.Query("host"),
+			Variant:      domain.GameVariant(c.Query("variant")),
+			TimeControl:  domain.TimeControl(c.Query("timeControl")),
+			Sort:         domain.RoomSort(c.Query("sort")),
+		}
+		if filter.MinRating, err = intQuery(c, "minRating"); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if filter.MaxRating, err = intQuery(c, "maxRating"); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		var rooms []*models.Room
+		pageInfo := domain.PageInfo{}
+		// A cursor parameter, even an empty one for the first page, selects
+		// cursor pagination.
+		if cursor, ok := c.GetQuery("cursor"); ok {
+			rooms, pageInfo.NextCursor, err = gameEngineService.GetOpenRoomsAfter(c.Request.Context(), filter, cursor, pageSize)
+			pageInfo.PageSize = pageSize
+		} else {
+			rooms, pageInfo.PageSize, pageInfo.Page, pageInfo.TotalCnt, err = gameEngineService.GetOpenRooms(c.Request.Context(), filter, page, pageSize)
+		}
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		playerIDs := make([]uuid.UUID, 0, len(rooms))
+		for _, room := range rooms {
+			playerIDs = append(playerIDs, room.Host.ID)
+			if room.Guest != nil {
+				playerIDs = append(playerIDs, room.Guest.ID)
+			}
+		}
+
+		presences, err := presenceService.GetPresence(c.Request.Context(), playerIDs...)
 		if err != nil {
 			_ = c.Error(err)
 			return
 		}
 
-		response := models.RoomListResponse{
-			Rooms: rooms,
-			PageInfo: models.PageInfo{
-				Page:     page,
-				PageSize: pageSize,
-				TotalCnt: total,
-			},
+		response := domain.RoomListResponse{
+			Rooms:    rooms,
+			PageInfo: pageInfo,
+			Presence: presences,
 		}
 
 		c.JSON(http.StatusOK, response)
@@ -69,7 +107,7 @@ func GetOpenRoomsHandler(gameEngineService engine.GameEngineService) func(*gin.C
 
 func CreateRoomHandler(gameEngineService engine.GameEngineService) func(*gin.Context) {
 	return func(c *gin.Context) {
-		var request models.CreateRoomRequest
+		var request domain.CreateRoomRequest
 		var err error
 		if err = c.BindJSON(&request); err != nil {
 			_ = c.Error(models.NewValidationError("bad request"))
@@ -82,7 +120,27 @@ func CreateRoomHandler(gameEngineService engine.GameEngineService) func(*gin.Con
 			return
 		}
 
-		roomID, err := gameEngineService.CreateRoom(c.Request.Context(), playerID, request.Title, request.Description)
+		settings := domain.DefaultRoomSettings()
+		if request.Ranked != nil {
+			settings.Ranked = *request.Ranked
+		}
+		if request.Variant != nil {
+			settings.Variant = *request.Variant
+		}
+		if request.RuleSet != nil {
+			settings.RuleSet = *request.RuleSet
+		}
+		if request.MarkPolicy != nil {
+			settings.MarkPolicy = *request.MarkPolicy
+		}
+		if request.FirstMovePolicy != nil {
+			settings.FirstMovePolicy = *request.FirstMovePolicy
+		}
+		if request.TimeControl != nil {
+			settings.TimeControl = *request.TimeControl
+		}
+
+		roomID, err := gameEngineService.CreateRoom(c.Request.Context(), playerID, request.Title, request.Description, settings)
 		if err != nil {
 			_ = c.Error(err)
 			return
@@ -199,7 +257,7 @@ func GetGameStateHandler(gameEngineService engine.GameEngineService) func(*gin.C
 			return
 		}
 
-		response := models.GameResponse{
+		response := domain.GameResponse{
 			Game: game,
 		}
 
@@ -230,7 +288,40 @@ func MakeMoveHandler(gameEngineService engine.GameEngineService) func(*gin.Conte
 			return
 		}
 
-		err = gameEngineService.PlayerMakeMove(c.Request.Context(), roomID, playerID, position)
+		err = gameEngineService.PlayerMakeMove(c.Request.Context(), roomID, playerID, engine.MoveKindCell, position, c.Query("mark"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.Status(http.StatusOK)
+	}
+}
+
+// MakeColumnMoveHandler plays a move by column, for games where the marks drop
+// down the columns.
+func MakeColumnMoveHandler(gameEngineService engine.GameEngineService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		pRoomID := c.Param("roomId")
+		roomID, err := uuid.FromString(pRoomID)
+		if err != nil {
+			_ = c.Error(models.NewValidationErrorf("Invalid id '%s'", pRoomID))
+			return
+		}
+
+		pColumn := c.Param("column")
+		column, err := strconv.Atoi(pColumn)
+		if err != nil {
+			_ = c.Error(models.NewValidationErrorf("Invalid column '%s'", pColumn))
+			return
+		}
+
+		playerID, ok := getPlayerIDFromContext(c, middleware.KEY_PLAYER_ID)
+		if !ok {
+			_ = c.Error(models.NewValidationError("Missing player_id claim"))
+			return
+		}
+
+		err = gameEngineService.PlayerMakeMove(c.Request.Context(), roomID, playerID, engine.MoveKindColumn, column, c.Query("mark"))
 		if err != nil {
 			_ = c.Error(err)
 			return
@@ -253,25 +344,53 @@ func GetRankingHandler(gameEngineService engine.GameEngineService) func(*gin.Con
 			pageSize = engine.DefaultPageSize
 		}
 
-		players, pageSize, page, total, err := gameEngineService.GetRanking(c.Request.Context(), page, pageSize)
+		var players []*models.Player
+		pageInfo := domain.PageInfo{}
+		cursor, cursorOk := c.GetQuery("cursor")
+		// A variant parameter ranks the players by the games of the variant
+		// only. A cursor parameter, even an empty one for the first page,
+		// selects cursor pagination.
+		if variant, ok := c.GetQuery("variant"); ok {
+			if cursorOk {
+				_ = c.Error(models.NewValidationError(engine.GameRankingCursorErrorMessage))
+				return
+			}
+			players, pageInfo.PageSize, pageInfo.Page, pageInfo.TotalCnt, err = gameEngineService.GetGameRanking(c.Request.Context(), domain.GameVariant(variant), page, pageSize)
+		} else if cursorOk {
+			players, pageInfo.NextCursor, err = gameEngineService.GetRankingAfter(c.Request.Context(), cursor, pageSize)
+			pageInfo.PageSize = pageSize
+		} else {
+			players, pageInfo.PageSize, pageInfo.Page, pageInfo.TotalCnt, err = gameEngineService.GetRanking(c.Request.Context(), page, pageSize)
+		}
 		if err != nil {
 			_ = c.Error(err)
 			return
 		}
 
-		response := models.RankingResponse{
-			Players: players,
-			PageInfo: models.PageInfo{
-				Page:     page,
-				PageSize: pageSize,
-				TotalCnt: total,
-			},
+		response := domain.RankingResponse{
+			Players:  players,
+			PageInfo: pageInfo,
 		}
 
 		c.JSON(http.StatusOK, response)
 	}
 }
 
+// intQuery returns the value of an optional integer query parameter.
+func intQuery(c *gin.Context, key string) (*int, error) {
+	valueStr, ok := c.GetQuery(key)
+	if !ok || len(valueStr) == 0 {
+		return nil, nil
+	}
+
+	value, err := strconv.Atoi(valueStr)
+	if err != nil {
+		return nil, models.NewValidationErrorf("Invalid %s '%s'", key, valueStr)
+	}
+
+	return &value, nil
+}
+
 func getPlayerIDFromContext(c *gin.Context, key string) (uuid.UUID, bool) {
 	val, exists := c.Get(key)
 	if !exists {
@@ -13,7 +13,10 @@ import (
 	"github.com/plamen-v/tic-tac-toe-models/models"
 	"github.com/plamen-v/tic-tac-toe/src/app/server/handlers"
 	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/engine"
 	"github.com/plamen-v/tic-tac-toe/src/services/engine/mocks"
+	presencemocks "github.com/plamen-v/tic-tac-toe/src/services/presence/mocks"
 	"github.com/stretchr/testify/mock"
 
 	. "github.com/onsi/gomega"
@@ -22,11 +25,13 @@ import (
 var _ = Describe("GameHandler", func() {
 	var (
 		mockGameEngineService *mocks.MockGameEngineService
+		mockPresenceService   *presencemocks.MockPresenceService
 		router                *gin.Engine
 	)
 
 	BeforeEach(func() {
 		mockGameEngineService = new(mocks.MockGameEngineService)
+		mockPresenceService = new(presencemocks.MockPresenceService)
 		gin.SetMode(gin.TestMode)
 		router = gin.Default()
 		router.Use(middleware.ErrorHandler())
@@ -82,17 +87,83 @@ var _ = Describe("GameHandler", func() {
 			Expect(err).To(BeNil())
 			response := httptest.NewRecorder()
 			mockGameEngineService.On("GetOpenRooms", mock.Anything, mock.Anything, mock.Anything).Return([]*models.Room{}, 1, 1, 1, nil)
-			handler := handlers.GetOpenRoomsHandler(mockGameEngineService)
+			mockPresenceService.On("GetPresence", mock.Anything, []uuid.UUID{}).Return(map[uuid.UUID]*domain.Presence{}, nil)
+			handler := handlers.GetOpenRoomsHandler(mockGameEngineService, mockPresenceService)
 			router.GET("/rooms", handler)
 			router.ServeHTTP(response, request)
 
 			Expect(response.Code).To(Equal(http.StatusOK))
 		})
 
+		It("should return the presence of the players in the rooms", func() {
+			hostID := uuid.Must(uuid.NewV4())
+			guestID := uuid.Must(uuid.NewV4())
+			rooms := []*models.Room{{ID: uuid.Must(uuid.NewV4()), Host: models.RoomPlayer{ID: hostID}, Guest: &models.RoomPlayer{ID: guestID}}}
+			request, err := http.NewRequest("GET", "/rooms", nil)
+			Expect(err).To(BeNil())
+			response := httptest.NewRecorder()
+			mockGameEngineService.On("GetOpenRooms", mock.Anything, mock.Anything, mock.Anything).Return(rooms, 1, 1, 1, nil)
+			mockPresenceService.On("GetPresence", mock.Anything, []uuid.UUID{hostID, guestID}).Return(map[uuid.UUID]*domain.Presence{
+				hostID:  {Status: domain.PresenceInLobby},
+				guestID: {Status: domain.PresenceAway},
+			}, nil)
+			router.GET("/rooms", handlers.GetOpenRoomsHandler(mockGameEngineService, mockPresenceService))
+			router.ServeHTTP(response, request)
+
+			Expect(response.Code).To(Equal(http.StatusOK))
+			var body domain.RoomListResponse
+			Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+			Expect(body.Rooms).To(HaveLen(1))
+			Expect(body.Presence[hostID].Status).To(Equal(domain.PresenceInLobby))
+			Expect(body.Presence[guestID].Status).To(Equal(domain.PresenceAway))
+		})
+
+		It("should pass the filter from the query", func() {
+			minRating := 1300
+			filter := &domain.RoomFilter{Title: "fast", HostNickname: "nick", MinRating: &minRating, TimeControl: domain.TimeControlBlitz, Sort: domain.RoomSortRating}
+			request, err := http.NewRequest("GET", "/rooms?title=fast&host=nick&minRating=1300&timeControl=blitz&sort=rating", nil)
+			Expect(err).To(BeNil())
+			response := httptest.NewRecorder()
+			mockGameEngineService.On("GetOpenRooms", mock.Anything, filter).Return([]*models.Room{}, 1, 1, 0, nil)
+			mockPresenceService.On("GetPresence", mock.Anything, []uuid.UUID{}).Return(map[uuid.UUID]*domain.Presence{}, nil)
+			router.GET("/rooms", handlers.GetOpenRoomsHandler(mockGameEngineService, mockPresenceService))
+			router.ServeHTTP(response, request)
+
+			Expect(response.Code).To(Equal(http.StatusOK))
+			mockGameEngineService.AssertExpectations(GinkgoT())
+		})
+
+		It("should read the first page by cursor for an empty cursor", func() {
+			request, err := http.NewRequest("GET", "/rooms?cursor=", nil)
+			Expect(err).To(BeNil())
+			response := httptest.NewRecorder()
+			mockGameEngineService.On("GetOpenRoomsAfter", mock.Anything, mock.Anything, "", engine.DefaultPageSize).Return([]*models.Room{}, "next", nil)
+			mockPresenceService.On("GetPresence", mock.Anything, []uuid.UUID{}).Return(map[uuid.UUID]*domain.Presence{}, nil)
+			router.GET("/rooms", handlers.GetOpenRoomsHandler(mockGameEngineService, mockPresenceService))
+			router.ServeHTTP(response, request)
+
+			Expect(response.Code).To(Equal(http.StatusOK))
+			var body domain.RoomListResponse
+			Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+			Expect(body.PageInfo.NextCursor).To(Equal("next"))
+			Expect(body.PageInfo.Page).To(BeZero())
+		})
+
+		It("should return 400 for an invalid rating", func() {
+			request, err := http.NewRequest("GET", "/rooms?maxRating=high", nil)
+			Expect(err).To(BeNil())
+			response := httptest.NewRecorder()
+			router.GET("/rooms", handlers.GetOpenRoomsHandler(mockGameEngineService, mockPresenceService))
+			router.ServeHTTP(response, request)
+
+			Expect(response.Code).To(Equal(http.StatusBadRequest))
+			mockGameEngineService.AssertNotCalled(GinkgoT(), "GetOpenRooms", mock.Anything, mock.Anything)
+		})
+
 		It("should return 500 if internal error occurs", func() {
 			request, err := http.NewRequest("GET", "/rooms", nil)
 			Expect(err).To(BeNil())
-			handler := handlers.GetOpenRoomsHandler(mockGameEngineService)
+			handler := handlers.GetOpenRoomsHandler(mockGameEngineService, mockPresenceService)
 			router.GET("/rooms", handler)
 			mockGameEngineService.On("GetOpenRooms", mock.Anything, mock.Anything, mock.Anything).Return(nil, 0, 0, 0, models.NewGenericError("server error"))
 			response := httptest.NewRecorder()
@@ -147,11 +218,58 @@ var _ = Describe("GameHandler", func() {
 			router.Use(insertPlayerIDInContextMiddleware(playerID))
 			handler := handlers.CreateRoomHandler(mockGameEngineService)
 			router.POST("/rooms", handler)
-			mockGameEngineService.On("CreateRoom", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(uuid.Nil, nil)
+			mockGameEngineService.On("CreateRoom", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(uuid.Nil, nil)
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+
+			Expect(response.Code).To(Equal(http.StatusCreated))
+		})
+
+		It("should pass the start policies to the room settings", func() {
+			markPolicy := domain.MarkPolicyHostX
+			firstMovePolicy := domain.FirstMovePolicyLoserStarts
+			createRoomRequest := domain.CreateRoomRequest{
+				CreateRoomRequest: models.CreateRoomRequest{Title: "title"},
+				MarkPolicy:        &markPolicy,
+				FirstMovePolicy:   &firstMovePolicy,
+			}
+			requestBody, err := json.Marshal(createRoomRequest)
+			Expect(err).To(BeNil())
+			request, err := http.NewRequest("POST", "/rooms", bytes.NewBuffer(requestBody))
+			Expect(err).To(BeNil())
+			router.Use(insertPlayerIDInContextMiddleware(uuid.Must(uuid.NewV4())))
+			router.POST("/rooms", handlers.CreateRoomHandler(mockGameEngineService))
+			expectedSettings := domain.DefaultRoomSettings()
+			expectedSettings.MarkPolicy = markPolicy
+			expectedSettings.FirstMovePolicy = firstMovePolicy
+			mockGameEngineService.On("CreateRoom", mock.Anything, mock.Anything, "title", "", expectedSettings).Return(uuid.Nil, nil)
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+
+			Expect(response.Code).To(Equal(http.StatusCreated))
+			mockGameEngineService.AssertExpectations(GinkgoT())
+		})
+
+		It("should pass the time control to the room settings", func() {
+			timeControl := domain.TimeControlRapid
+			createRoomRequest := domain.CreateRoomRequest{
+				CreateRoomRequest: models.CreateRoomRequest{Title: "title"},
+				TimeControl:       &timeControl,
+			}
+			requestBody, err := json.Marshal(createRoomRequest)
+			Expect(err).To(BeNil())
+			request, err := http.NewRequest("POST", "/rooms", bytes.NewBuffer(requestBody))
+			Expect(err).To(BeNil())
+			router.Use(insertPlayerIDInContextMiddleware(uuid.Must(uuid.NewV4())))
+			router.POST("/rooms", handlers.CreateRoomHandler(mockGameEngineService))
+			expectedSettings := domain.DefaultRoomSettings()
+			expectedSettings.TimeControl = timeControl
+			mockGameEngineService.On("CreateRoom", mock.Anything, mock.Anything, "title", "", expectedSettings).Return(uuid.Nil, nil)
 			response := httptest.NewRecorder()
 			router.ServeHTTP(response, request)
 
 			Expect(response.Code).To(Equal(http.StatusCreated))
+			mockGameEngineService.AssertExpectations(GinkgoT())
 		})
 
 		It("should return 500 if server error occurs", func() {
@@ -168,7 +286,7 @@ var _ = Describe("GameHandler", func() {
 			router.Use(insertPlayerIDInContextMiddleware(playerID))
 			handler := handlers.CreateRoomHandler(mockGameEngineService)
 			router.POST("/rooms", handler)
-			mockGameEngineService.On("CreateRoom", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, models.NewGenericError("server error"))
+			mockGameEngineService.On("CreateRoom", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, models.NewGenericError("server error"))
 			response := httptest.NewRecorder()
 			router.ServeHTTP(response, request)
 
@@ -427,7 +545,7 @@ var _ = Describe("GameHandler", func() {
 			Expect(err).To(BeNil())
 			router.Use(insertPlayerIDInContextMiddleware(playerID))
 			router.GET("/rooms/:roomId/game", handler)
-			mockGameEngineService.On("GetGameState", mock.Anything, mock.Anything, mock.Anything).Return(&models.Game{}, nil)
+			mockGameEngineService.On("GetGameState", mock.Anything, mock.Anything, mock.Anything).Return(&domain.GameState{Game: &models.Game{}}, nil)
 			router.ServeHTTP(response, request)
 			Expect(response.Code).To(Equal(http.StatusOK))
 		})
@@ -505,7 +623,7 @@ var _ = Describe("GameHandler", func() {
 			Expect(err).To(BeNil())
 			router.Use(insertPlayerIDInContextMiddleware(validPlayerID))
 			router.POST("/test/:roomId/game/board/:position", handler)
-			mockGameEngineService.On("PlayerMakeMove", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			mockGameEngineService.On("PlayerMakeMove", mock.Anything, mock.Anything, mock.Anything, engine.MoveKindCell, mock.Anything, mock.Anything).Return(nil)
 			router.ServeHTTP(response, request)
 			Expect(response.Code).To(Equal(http.StatusOK))
 		})
@@ -523,12 +641,37 @@ var _ = Describe("GameHandler", func() {
 			Expect(err).To(BeNil())
 			router.Use(insertPlayerIDInContextMiddleware(validPlayerID))
 			router.POST("/test/:roomId/game/board/:position", handler)
-			mockGameEngineService.On("PlayerMakeMove", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(models.NewGenericError("server error"))
+			mockGameEngineService.On("PlayerMakeMove", mock.Anything, mock.Anything, mock.Anything, engine.MoveKindCell, mock.Anything, mock.Anything).Return(models.NewGenericError("server error"))
 			router.ServeHTTP(response, request)
 			Expect(response.Code).To(Equal(http.StatusInternalServerError))
 		})
 	})
 
+	Context("MakeColumnMoveHandler", func() {
+		It("should return 400 if column param is invalid", func() {
+			request, err := http.NewRequest("POST", fmt.Sprintf("/test/%s/game/columns/left", uuid.Must(uuid.NewV4())), nil)
+			Expect(err).To(BeNil())
+			router.POST("/test/:roomId/game/columns/:column", handlers.MakeColumnMoveHandler(mockGameEngineService))
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+			Expect(response.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should play the move in the column", func() {
+			roomID := uuid.Must(uuid.NewV4())
+			playerID := uuid.Must(uuid.NewV4())
+			request, err := http.NewRequest("POST", fmt.Sprintf("/test/%s/game/columns/%d", roomID, 4), nil)
+			Expect(err).To(BeNil())
+			router.Use(insertPlayerIDInContextMiddleware(playerID))
+			router.POST("/test/:roomId/game/columns/:column", handlers.MakeColumnMoveHandler(mockGameEngineService))
+			mockGameEngineService.On("PlayerMakeMove", mock.Anything, roomID, playerID, engine.MoveKindColumn, 4, "").Return(nil)
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+			Expect(response.Code).To(Equal(http.StatusOK))
+			mockGameEngineService.AssertExpectations(GinkgoT())
+		})
+	})
+
 	Context("GetRankingHandler", func() {
 
 		It("should return 200 if request is OK", func() {
@@ -543,6 +686,47 @@ var _ = Describe("GameHandler", func() {
 			Expect(response.Code).To(Equal(http.StatusOK))
 		})
 
+		It("should read the ranking by cursor", func() {
+			request, err := http.NewRequest("GET", "/ranking?cursor=abc&pageSize=5", nil)
+			Expect(err).To(BeNil())
+			router.GET("/ranking", handlers.GetRankingHandler(mockGameEngineService))
+			mockGameEngineService.On("GetRankingAfter", mock.Anything, "abc", 5).Return([]*models.Player{}, "def", nil)
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+
+			Expect(response.Code).To(Equal(http.StatusOK))
+			var body domain.RankingResponse
+			Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+			Expect(body.PageInfo.NextCursor).To(Equal("def"))
+			Expect(body.PageInfo.PageSize).To(Equal(5))
+			mockGameEngineService.AssertNotCalled(GinkgoT(), "GetRanking", mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		It("should rank the players by the games of the variant", func() {
+			request, err := http.NewRequest("GET", "/ranking?variant=connect_four&page=2&pageSize=5", nil)
+			Expect(err).To(BeNil())
+			router.GET("/ranking", handlers.GetRankingHandler(mockGameEngineService))
+			mockGameEngineService.On("GetGameRanking", mock.Anything, domain.GameVariantConnectFour, 2, 5).Return([]*models.Player{}, 5, 2, 7, nil)
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+
+			Expect(response.Code).To(Equal(http.StatusOK))
+			var body domain.RankingResponse
+			Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+			Expect(body.PageInfo.TotalCnt).To(Equal(7))
+		})
+
+		It("should return 400 for the ranking of a variant by cursor", func() {
+			request, err := http.NewRequest("GET", "/ranking?variant=connect_four&cursor=", nil)
+			Expect(err).To(BeNil())
+			router.GET("/ranking", handlers.GetRankingHandler(mockGameEngineService))
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+
+			Expect(response.Code).To(Equal(http.StatusBadRequest))
+			mockGameEngineService.AssertNotCalled(GinkgoT(), "GetGameRanking", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+
 		It("should return 500 if server error occurs", func() {
 			request, err := http.NewRequest("GET", "/ranking", nil)
 			Expect(err).To(BeNil())
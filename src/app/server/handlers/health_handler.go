@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/plamen-v/tic-tac-toe/src/services/health"
+)
+
+func LivenessHandler(healthService health.HealthService) func(*gin.Context) {
+	return reportHandler(healthService.Liveness)
+}
+
+func ReadinessHandler(healthService health.HealthService) func(*gin.Context) {
+	return reportHandler(healthService.Readiness)
+}
+
+func HealthHandler(healthService health.HealthService) func(*gin.Context) {
+	return reportHandler(healthService.Health)
+}
+
+func reportHandler(check func(context.Context) *health.Report) func(*gin.Context) {
+	return func(c *gin.Context) {
+		report := check(c.Request.Context())
+
+		status := http.StatusOK
+		if !report.OK() {
+			status = http.StatusServiceUnavailable
+		}
+
+		c.JSON(status, report)
+	}
+}
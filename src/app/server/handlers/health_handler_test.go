@@ -0,0 +1,90 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/handlers"
+	"github.com/plamen-v/tic-tac-toe/src/services/health"
+	"github.com/plamen-v/tic-tac-toe/src/services/health/mocks"
+	"github.com/stretchr/testify/mock"
+
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HealthHandler", func() {
+	var (
+		mockHealthService *mocks.MockHealthService
+		router            *gin.Engine
+	)
+
+	BeforeEach(func() {
+		mockHealthService = new(mocks.MockHealthService)
+		gin.SetMode(gin.TestMode)
+		router = gin.Default()
+	})
+
+	It("should return 200 if the service is alive", func() {
+		mockHealthService.On("Liveness", mock.Anything).Return(&health.Report{Status: health.StatusOK})
+		router.GET("/livez", handlers.LivenessHandler(mockHealthService))
+
+		request, err := http.NewRequest("GET", "/livez", nil)
+		Expect(err).To(BeNil())
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+	})
+
+	It("should return 200 with the checks if the service is ready", func() {
+		mockHealthService.On("Readiness", mock.Anything).Return(&health.Report{
+			Status: health.StatusOK,
+			Checks: map[string]string{health.DatabaseCheckName: string(health.StatusOK)},
+		})
+		router.GET("/readyz", handlers.ReadinessHandler(mockHealthService))
+
+		request, err := http.NewRequest("GET", "/readyz", nil)
+		Expect(err).To(BeNil())
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var report health.Report
+		err = json.Unmarshal(response.Body.Bytes(), &report)
+		Expect(err).To(BeNil())
+		Expect(report.Checks).To(HaveKeyWithValue(health.DatabaseCheckName, string(health.StatusOK)))
+	})
+
+	It("should return 503 if the service is not ready", func() {
+		mockHealthService.On("Readiness", mock.Anything).Return(&health.Report{
+			Status: health.StatusFail,
+			Checks: map[string]string{health.ShutdownCheckName: health.ShuttingDownMessage},
+		})
+		router.GET("/readyz", handlers.ReadinessHandler(mockHealthService))
+
+		request, err := http.NewRequest("GET", "/readyz", nil)
+		Expect(err).To(BeNil())
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+
+		Expect(response.Code).To(Equal(http.StatusServiceUnavailable))
+	})
+
+	It("should return 503 if a dependency is unhealthy", func() {
+		mockHealthService.On("Health", mock.Anything).Return(&health.Report{
+			Status: health.StatusFail,
+			Checks: map[string]string{health.DatabaseCheckName: health.UnreachableMessage},
+		})
+		router.GET("/healthz", handlers.HealthHandler(mockHealthService))
+
+		request, err := http.NewRequest("GET", "/healthz", nil)
+		Expect(err).To(BeNil())
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+
+		Expect(response.Code).To(Equal(http.StatusServiceUnavailable))
+	})
+})
@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/plamen-v/tic-tac-toe/src/services/auth"
+)
+
+const JWKSCacheControl string = "public, max-age=300"
+
+func JWKSHandler(authService auth.AuthenticationService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", JWKSCacheControl)
+		c.JSON(http.StatusOK, authService.JWKS())
+	}
+}
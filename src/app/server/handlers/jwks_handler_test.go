@@ -0,0 +1,43 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/handlers"
+	"github.com/plamen-v/tic-tac-toe/src/services/auth"
+	"github.com/plamen-v/tic-tac-toe/src/services/auth/mocks"
+
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JWKSHandler", func() {
+	var (
+		mockAuthenticationService *mocks.MockAuthenticationService
+		router                    *gin.Engine
+	)
+
+	BeforeEach(func() {
+		mockAuthenticationService = new(mocks.MockAuthenticationService)
+		gin.SetMode(gin.TestMode)
+		router = gin.Default()
+	})
+
+	It("should return the public keys with a cache header", func() {
+		jwks := &auth.JWKSet{Keys: []auth.JWK{{KeyType: "OKP", KeyID: "2025-01", Use: "sig", Algorithm: "EdDSA", Curve: "Ed25519", X: "abc"}}}
+		mockAuthenticationService.On("JWKS").Return(jwks)
+		router.GET(auth.JWKSPath, handlers.JWKSHandler(mockAuthenticationService))
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, auth.JWKSPath, nil))
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		Expect(response.Header().Get("Cache-Control")).To(Equal(handlers.JWKSCacheControl))
+		var body auth.JWKSet
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body).To(Equal(*jwks))
+	})
+})
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
 	"github.com/plamen-v/tic-tac-toe/src/services/auth"
 )
 
@@ -17,7 +18,25 @@ func LoginHandler(authService auth.AuthenticationService) func(*gin.Context) {
 			return
 		}
 
-		player, tokenStr, err := authService.Authenticate(c.Request.Context(), loginRequest.Login, loginRequest.Password)
+		response, err := authService.Authenticate(c.Request.Context(), loginRequest.Login, loginRequest.Password, c.ClientIP())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func TwoFactorLoginHandler(authService auth.AuthenticationService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var request domain.TwoFactorLoginRequest
+		if err := c.BindJSON(&request); err != nil {
+			_ = c.Error(models.NewValidationError("bad request"))
+			return
+		}
+
+		player, tokenStr, err := authService.VerifyTwoFactor(c.Request.Context(), request.ChallengeToken, request.Code, c.ClientIP())
 		if err != nil {
 			_ = c.Error(err)
 			return
@@ -9,8 +9,11 @@ import (
 	"github.com/gin-gonic/gin"
 	. "github.com/onsi/ginkgo/v2"
 	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/app/server"
 	"github.com/plamen-v/tic-tac-toe/src/app/server/handlers"
 	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/config"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
 	"github.com/plamen-v/tic-tac-toe/src/services/auth/mocks"
 	"github.com/stretchr/testify/mock"
 
@@ -45,7 +48,7 @@ var _ = Describe("LoginHandler", func() {
 
 	It("should return 401 if player is invalid", func() {
 		loginHandler := handlers.LoginHandler(mockAuthenticationService)
-		mockAuthenticationService.On("Authenticate", mock.Anything, mock.Anything, mock.Anything).Return(nil, "", models.NewAuthorizationErrorf("invalid password"))
+		mockAuthenticationService.On("Authenticate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, models.NewAuthorizationErrorf("invalid password"))
 
 		loginRequest := models.LoginRequest{
 			Login:    "login",
@@ -64,6 +67,33 @@ var _ = Describe("LoginHandler", func() {
 		Expect(response.Code).To(Equal(http.StatusUnauthorized))
 	})
 
+	It("should authenticate with the peer address rather than X-Forwarded-For", func() {
+		engine, err := server.NewEngine(config.ServerConfiguration{})
+		Expect(err).To(BeNil())
+		engine.Use(middleware.ErrorHandler())
+		loginHandler := handlers.LoginHandler(mockAuthenticationService)
+		mockAuthenticationService.On("Authenticate", mock.Anything, "login", "password", "192.0.2.1").Return(nil, models.NewAuthorizationErrorf("invalid login or password"))
+
+		loginRequest := models.LoginRequest{
+			Login:    "login",
+			Password: "password",
+		}
+		requestBody, err := json.Marshal(loginRequest)
+		Expect(err).To(BeNil())
+		request, err := http.NewRequest("POST", "/test", bytes.NewBuffer(requestBody))
+		Expect(err).To(BeNil())
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("X-Forwarded-For", "198.51.100.7")
+		request.RemoteAddr = "192.0.2.1:1234"
+
+		response := httptest.NewRecorder()
+		engine.POST("/test", loginHandler)
+		engine.ServeHTTP(response, request)
+
+		Expect(response.Code).To(Equal(http.StatusUnauthorized))
+		mockAuthenticationService.AssertExpectations(GinkgoT())
+	})
+
 	It("should return 200 if player is valid", func() {
 		loginHandler := handlers.LoginHandler(mockAuthenticationService)
 
@@ -80,9 +110,77 @@ var _ = Describe("LoginHandler", func() {
 
 		response := httptest.NewRecorder()
 		router.POST("/test", loginHandler)
-		mockAuthenticationService.On("Authenticate", mock.Anything, mock.Anything, mock.Anything).Return(&models.Player{}, "valid-token", nil)
+		mockAuthenticationService.On("Authenticate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&domain.LoginResponse{Player: &models.Player{}, Token: "valid-token"}, nil)
+
+		router.ServeHTTP(response, request)
+		Expect(response.Code).To(Equal(http.StatusOK))
+	})
+
+	It("should return a challenge if two-factor authentication is enabled", func() {
+		loginHandler := handlers.LoginHandler(mockAuthenticationService)
+		mockAuthenticationService.On("Authenticate", mock.Anything, "login", "password", mock.Anything).Return(&domain.LoginResponse{TwoFactorRequired: true, ChallengeToken: "challenge"}, nil)
 
+		requestBody, err := json.Marshal(models.LoginRequest{Login: "login", Password: "password"})
+		Expect(err).To(BeNil())
+		request, err := http.NewRequest("POST", "/test", bytes.NewBuffer(requestBody))
+		Expect(err).To(BeNil())
+		request.Header.Set("Content-Type", "application/json")
+
+		response := httptest.NewRecorder()
+		router.POST("/test", loginHandler)
 		router.ServeHTTP(response, request)
+
 		Expect(response.Code).To(Equal(http.StatusOK))
+		var body domain.LoginResponse
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.TwoFactorRequired).To(BeTrue())
+		Expect(body.ChallengeToken).To(Equal("challenge"))
+		Expect(body.Token).To(BeEmpty())
+	})
+})
+
+var _ = Describe("TwoFactorLoginHandler", func() {
+	var (
+		mockAuthenticationService *mocks.MockAuthenticationService
+		router                    *gin.Engine
+	)
+
+	BeforeEach(func() {
+		mockAuthenticationService = new(mocks.MockAuthenticationService)
+		gin.SetMode(gin.TestMode)
+		router = gin.Default()
+		router.Use(middleware.ErrorHandler())
+		router.POST("/test", handlers.TwoFactorLoginHandler(mockAuthenticationService))
+	})
+
+	post := func(body any) *httptest.ResponseRecorder {
+		requestBody, err := json.Marshal(body)
+		Expect(err).To(BeNil())
+		request, err := http.NewRequest("POST", "/test", bytes.NewBuffer(requestBody))
+		Expect(err).To(BeNil())
+		request.Header.Set("Content-Type", "application/json")
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		return response
+	}
+
+	It("should return the access token for a valid code", func() {
+		mockAuthenticationService.On("VerifyTwoFactor", mock.Anything, "challenge", "123456", mock.Anything).Return(&models.Player{}, "valid-token", nil)
+
+		response := post(domain.TwoFactorLoginRequest{ChallengeToken: "challenge", Code: "123456"})
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var body models.LoginResponse
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Token).To(Equal("valid-token"))
+	})
+
+	It("should return 401 for an invalid code", func() {
+		mockAuthenticationService.On("VerifyTwoFactor", mock.Anything, "challenge", "000000", mock.Anything).Return(nil, "", models.NewAuthorizationError("two-factor code is invalid"))
+
+		response := post(domain.TwoFactorLoginRequest{ChallengeToken: "challenge", Code: "000000"})
+
+		Expect(response.Code).To(Equal(http.StatusUnauthorized))
 	})
 })
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/auth"
+	"github.com/plamen-v/tic-tac-toe/src/services/oidc"
+)
+
+func OIDCProvidersHandler(oidcService oidc.OIDCService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, domain.OIDCProvidersResponse{Providers: oidcService.Providers()})
+	}
+}
+
+// OIDCLoginHandler redirects to the provider and keeps the login session in a
+// cookie scoped to the provider's callback.
+func OIDCLoginHandler(oidcService oidc.OIDCService, stateTTL time.Duration) func(*gin.Context) {
+	return func(c *gin.Context) {
+		provider := c.Param("provider")
+		url, session, err := oidcService.Begin(c.Request.Context(), provider)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookie(oidc.SessionCookieName, session.Encode(), int(stateTTL.Seconds()), sessionCookiePath(c), "", c.Request.TLS != nil, true)
+		c.Redirect(http.StatusFound, url)
+	}
+}
+
+func OIDCCallbackHandler(oidcService oidc.OIDCService, authService auth.AuthenticationService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		provider := c.Param("provider")
+		cookie, _ := c.Cookie(oidc.SessionCookieName)
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookie(oidc.SessionCookieName, "", -1, sessionCookiePath(c), "", c.Request.TLS != nil, true)
+
+		if errorCode := c.Query("error"); len(errorCode) > 0 {
+			_ = c.Error(models.NewAuthorizationErrorf("oidc provider returned '%s': %s", errorCode, c.Query("error_description")))
+			return
+		}
+
+		session, err := oidc.DecodeSession(cookie)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		externalPlayer, err := oidcService.Complete(c.Request.Context(), provider, session, c.Query("state"), c.Query("code"))
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		response, err := authService.AuthenticatePlayer(c.Request.Context(), externalPlayer.ID, c.ClientIP())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func sessionCookiePath(c *gin.Context) string {
+	return "/api/oidc/" + c.Param("provider")
+}
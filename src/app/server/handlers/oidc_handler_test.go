@@ -0,0 +1,149 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/handlers"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/apperrors"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/auth/mocks"
+	"github.com/plamen-v/tic-tac-toe/src/services/oidc"
+	oidcmocks "github.com/plamen-v/tic-tac-toe/src/services/oidc/mocks"
+	"github.com/stretchr/testify/mock"
+
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OIDCHandler", func() {
+	var (
+		mockOIDCService           *oidcmocks.MockOIDCService
+		mockAuthenticationService *mocks.MockAuthenticationService
+		router                    *gin.Engine
+		session                   *oidc.Session
+		playerID                  uuid.UUID
+	)
+
+	BeforeEach(func() {
+		mockOIDCService = new(oidcmocks.MockOIDCService)
+		mockAuthenticationService = new(mocks.MockAuthenticationService)
+		gin.SetMode(gin.TestMode)
+		router = gin.Default()
+		router.Use(middleware.ErrorHandler())
+		router.GET("/api/oidc", handlers.OIDCProvidersHandler(mockOIDCService))
+		router.GET("/api/oidc/:provider/login", handlers.OIDCLoginHandler(mockOIDCService, time.Minute))
+		router.GET("/api/oidc/:provider/callback", handlers.OIDCCallbackHandler(mockOIDCService, mockAuthenticationService))
+		session = &oidc.Session{State: "state", Nonce: "nonce", Verifier: "verifier"}
+		playerID = uuid.Must(uuid.NewV4())
+	})
+
+	callback := func(query string, withSession bool) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/api/oidc/corp/callback?"+query, nil)
+		if withSession {
+			request.AddCookie(&http.Cookie{Name: oidc.SessionCookieName, Value: session.Encode()})
+		}
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		return response
+	}
+
+	It("should list the configured providers", func() {
+		mockOIDCService.On("Providers").Return([]string{"corp"})
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/oidc", nil))
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var body domain.OIDCProvidersResponse
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Providers).To(Equal([]string{"corp"}))
+	})
+
+	It("should redirect to the provider and store the session", func() {
+		mockOIDCService.On("Begin", mock.Anything, "corp").Return("https://idp.example.com/authorize?state=state", session, nil)
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/oidc/corp/login", nil))
+
+		Expect(response.Code).To(Equal(http.StatusFound))
+		Expect(response.Header().Get("Location")).To(Equal("https://idp.example.com/authorize?state=state"))
+		cookies := response.Result().Cookies()
+		Expect(cookies).To(HaveLen(1))
+		Expect(cookies[0].Name).To(Equal(oidc.SessionCookieName))
+		Expect(cookies[0].Value).To(Equal(session.Encode()))
+		Expect(cookies[0].Path).To(Equal("/api/oidc/corp"))
+		Expect(cookies[0].HttpOnly).To(BeTrue())
+		Expect(cookies[0].MaxAge).To(Equal(60))
+	})
+
+	It("should return 404 for unknown providers", func() {
+		mockOIDCService.On("Begin", mock.Anything, "other").Return("", nil, models.NewNotFoundError("not found"))
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/oidc/other/login", nil))
+
+		Expect(response.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should log the linked player in", func() {
+		player := &models.Player{ID: playerID, Nickname: "jane.doe"}
+		mockOIDCService.On("Complete", mock.Anything, "corp", session, "state", "code").Return(player, nil)
+		mockAuthenticationService.On("AuthenticatePlayer", mock.Anything, playerID, mock.Anything).Return(&domain.LoginResponse{Player: player, Token: "token"}, nil)
+
+		response := callback("state=state&code=code", true)
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var body models.LoginResponse
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Token).To(Equal("token"))
+		Expect(body.Player.ID).To(Equal(playerID))
+		cookies := response.Result().Cookies()
+		Expect(cookies).To(HaveLen(1))
+		Expect(cookies[0].MaxAge).To(BeNumerically("<", 0))
+	})
+
+	It("should return the challenge for players with two-factor authentication", func() {
+		player := &models.Player{ID: playerID}
+		mockOIDCService.On("Complete", mock.Anything, "corp", session, "state", "code").Return(player, nil)
+		mockAuthenticationService.On("AuthenticatePlayer", mock.Anything, playerID, mock.Anything).Return(&domain.LoginResponse{TwoFactorRequired: true, ChallengeToken: "challenge"}, nil)
+
+		response := callback("state=state&code=code", true)
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var body domain.LoginResponse
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.TwoFactorRequired).To(BeTrue())
+		Expect(body.ChallengeToken).To(Equal("challenge"))
+		Expect(body.Token).To(BeEmpty())
+	})
+
+	It("should return 401 without a session", func() {
+		response := callback("state=state&code=code", false)
+
+		Expect(response.Code).To(Equal(http.StatusUnauthorized))
+		mockOIDCService.AssertNotCalled(GinkgoT(), "Complete", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	It("should return 401 when the provider reports an error", func() {
+		response := callback("error=access_denied&error_description=denied", true)
+
+		Expect(response.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should return 403 for disabled players", func() {
+		player := &models.Player{ID: playerID}
+		mockOIDCService.On("Complete", mock.Anything, "corp", session, "state", "code").Return(player, nil)
+		mockAuthenticationService.On("AuthenticatePlayer", mock.Anything, playerID, mock.Anything).Return(nil, apperrors.NewForbiddenError("account is disabled"))
+
+		response := callback("state=state&code=code", true)
+
+		Expect(response.Code).To(Equal(http.StatusForbidden))
+	})
+})
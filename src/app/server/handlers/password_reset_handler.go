@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/passwordreset"
+)
+
+func RequestPasswordResetHandler(passwordResetService passwordreset.PasswordResetService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var request domain.PasswordResetRequest
+		if err := c.BindJSON(&request); err != nil {
+			_ = c.Error(models.NewValidationError("bad request"))
+			return
+		}
+
+		if err := passwordResetService.RequestReset(c.Request.Context(), request.Login); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.Status(http.StatusAccepted)
+	}
+}
+
+func ConfirmPasswordResetHandler(passwordResetService passwordreset.PasswordResetService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var request domain.PasswordResetConfirmRequest
+		if err := c.BindJSON(&request); err != nil {
+			_ = c.Error(models.NewValidationError("bad request"))
+			return
+		}
+
+		if err := passwordResetService.ConfirmReset(c.Request.Context(), request.Token, request.NewPassword); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
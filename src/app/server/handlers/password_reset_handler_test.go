@@ -0,0 +1,73 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/handlers"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/passwordreset/mocks"
+	"github.com/stretchr/testify/mock"
+
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PasswordResetHandler", func() {
+	var (
+		mockPasswordResetService *mocks.MockPasswordResetService
+		router                   *gin.Engine
+	)
+
+	BeforeEach(func() {
+		mockPasswordResetService = new(mocks.MockPasswordResetService)
+		gin.SetMode(gin.TestMode)
+		router = gin.Default()
+		router.Use(middleware.ErrorHandler())
+		router.POST("/password-reset", handlers.RequestPasswordResetHandler(mockPasswordResetService))
+		router.POST("/password-reset/confirm", handlers.ConfirmPasswordResetHandler(mockPasswordResetService))
+	})
+
+	serve := func(path string, body any) *httptest.ResponseRecorder {
+		requestBody, err := json.Marshal(body)
+		Expect(err).To(BeNil())
+		request, err := http.NewRequest(http.MethodPost, path, bytes.NewBuffer(requestBody))
+		Expect(err).To(BeNil())
+		request.Header.Set("Content-Type", "application/json")
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		return response
+	}
+
+	It("should accept a reset request", func() {
+		mockPasswordResetService.On("RequestReset", mock.Anything, "player").Return(nil)
+
+		response := serve("/password-reset", domain.PasswordResetRequest{Login: "player"})
+
+		Expect(response.Code).To(Equal(http.StatusAccepted))
+		mockPasswordResetService.AssertExpectations(GinkgoT())
+	})
+
+	It("should set the new password", func() {
+		mockPasswordResetService.On("ConfirmReset", mock.Anything, "token", "new-password").Return(nil)
+
+		response := serve("/password-reset/confirm", domain.PasswordResetConfirmRequest{Token: "token", NewPassword: "new-password"})
+
+		Expect(response.Code).To(Equal(http.StatusNoContent))
+	})
+
+	It("should return 401 for an invalid token", func() {
+		mockPasswordResetService.On("ConfirmReset", mock.Anything, "token", "new-password").
+			Return(models.NewAuthorizationError("password reset token is invalid or expired"))
+
+		response := serve("/password-reset/confirm", domain.PasswordResetConfirmRequest{Token: "token", NewPassword: "new-password"})
+
+		Expect(response.Code).To(Equal(http.StatusUnauthorized))
+	})
+})
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/profile"
+)
+
+func GetPlayerProfileHandler(profileService profile.ProfileService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		pPlayerID := c.Param("playerId")
+		playerID, err := uuid.FromString(pPlayerID)
+		if err != nil {
+			_ = c.Error(models.NewValidationErrorf("Invalid player id '%s'", pPlayerID))
+			return
+		}
+
+		playerProfile, err := profileService.GetProfile(c.Request.Context(), playerID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, playerProfile)
+	}
+}
+
+func GetMeHandler(profileService profile.ProfileService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		playerID, ok := getPlayerIDFromContext(c, middleware.KEY_PLAYER_ID)
+		if !ok {
+			_ = c.Error(models.NewValidationError("Missing player_id claim"))
+			return
+		}
+
+		ownProfile, err := profileService.GetOwnProfile(c.Request.Context(), playerID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, ownProfile)
+	}
+}
+
+func UpdateMeHandler(profileService profile.ProfileService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var request domain.UpdateProfileRequest
+		if err := c.BindJSON(&request); err != nil {
+			_ = c.Error(models.NewValidationError("bad request"))
+			return
+		}
+
+		playerID, ok := getPlayerIDFromContext(c, middleware.KEY_PLAYER_ID)
+		if !ok {
+			_ = c.Error(models.NewValidationError("Missing player_id claim"))
+			return
+		}
+
+		ownProfile, err := profileService.UpdateProfile(c.Request.Context(), playerID, &request)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, ownProfile)
+	}
+}
+
+func DeleteMeHandler(profileService profile.ProfileService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var request domain.DeleteAccountRequest
+		if err := c.BindJSON(&request); err != nil {
+			_ = c.Error(models.NewValidationError("bad request"))
+			return
+		}
+
+		playerID, ok := getPlayerIDFromContext(c, middleware.KEY_PLAYER_ID)
+		if !ok {
+			_ = c.Error(models.NewValidationError("Missing player_id claim"))
+			return
+		}
+
+		if err := profileService.DeleteAccount(c.Request.Context(), playerID, request.Password); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,133 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/handlers"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/profile/mocks"
+	"github.com/stretchr/testify/mock"
+
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProfileHandler", func() {
+	var (
+		mockProfileService *mocks.MockProfileService
+		router             *gin.Engine
+		playerID           uuid.UUID
+	)
+
+	BeforeEach(func() {
+		mockProfileService = new(mocks.MockProfileService)
+		playerID = uuid.Must(uuid.NewV4())
+		gin.SetMode(gin.TestMode)
+		router = gin.Default()
+		router.Use(middleware.ErrorHandler())
+		router.Use(func(c *gin.Context) {
+			c.Set(middleware.KEY_PLAYER_ID, uuid.NullUUID{UUID: playerID, Valid: true})
+		})
+		router.GET("/me", handlers.GetMeHandler(mockProfileService))
+		router.PATCH("/me", handlers.UpdateMeHandler(mockProfileService))
+		router.DELETE("/me", handlers.DeleteMeHandler(mockProfileService))
+		router.GET("/players/:playerId", handlers.GetPlayerProfileHandler(mockProfileService))
+	})
+
+	serve := func(method string, path string, body any) *httptest.ResponseRecorder {
+		var requestBody []byte
+		if body != nil {
+			var err error
+			requestBody, err = json.Marshal(body)
+			Expect(err).To(BeNil())
+		}
+		request, err := http.NewRequest(method, path, bytes.NewBuffer(requestBody))
+		Expect(err).To(BeNil())
+		request.Header.Set("Content-Type", "application/json")
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		return response
+	}
+
+	It("should return the own profile", func() {
+		mockProfileService.On("GetOwnProfile", mock.Anything, playerID).
+			Return(&domain.OwnProfile{Profile: domain.Profile{ID: playerID, Nickname: "nick"}, Login: "login"}, nil)
+
+		response := serve(http.MethodGet, "/me", nil)
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var body domain.OwnProfile
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Login).To(Equal("login"))
+		Expect(body.Nickname).To(Equal("nick"))
+	})
+
+	It("should update the own profile", func() {
+		nickname := "new-nick"
+		mockProfileService.On("UpdateProfile", mock.Anything, playerID, &domain.UpdateProfileRequest{Nickname: &nickname}).
+			Return(&domain.OwnProfile{Profile: domain.Profile{ID: playerID, Nickname: nickname}}, nil)
+
+		response := serve(http.MethodPatch, "/me", domain.UpdateProfileRequest{Nickname: &nickname})
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var body domain.OwnProfile
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Nickname).To(Equal(nickname))
+	})
+
+	It("should return 401 for a wrong current password", func() {
+		newPassword := "another-password"
+		mockProfileService.On("UpdateProfile", mock.Anything, playerID, mock.Anything).
+			Return(nil, models.NewAuthorizationError("current password is invalid"))
+
+		response := serve(http.MethodPatch, "/me", domain.UpdateProfileRequest{CurrentPassword: "wrong", NewPassword: &newPassword})
+
+		Expect(response.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should delete the account", func() {
+		mockProfileService.On("DeleteAccount", mock.Anything, playerID, "secret").Return(nil)
+
+		response := serve(http.MethodDelete, "/me", domain.DeleteAccountRequest{Password: "secret"})
+
+		Expect(response.Code).To(Equal(http.StatusNoContent))
+	})
+
+	It("should return a public profile", func() {
+		otherID := uuid.Must(uuid.NewV4())
+		mockProfileService.On("GetProfile", mock.Anything, otherID).
+			Return(&domain.Profile{ID: otherID, Nickname: "other", Rating: 1216}, nil)
+
+		response := serve(http.MethodGet, "/players/"+otherID.String(), nil)
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var body map[string]any
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body["rating"]).To(BeEquivalentTo(1216))
+		Expect(body).ToNot(HaveKey("login"))
+	})
+
+	It("should return 404 for an unknown player", func() {
+		otherID := uuid.Must(uuid.NewV4())
+		mockProfileService.On("GetProfile", mock.Anything, otherID).
+			Return(nil, models.NewNotFoundErrorf("player '%s' not exist", otherID.String()))
+
+		response := serve(http.MethodGet, "/players/"+otherID.String(), nil)
+
+		Expect(response.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should return 400 for an invalid player id", func() {
+		response := serve(http.MethodGet, "/players/invalid", nil)
+
+		Expect(response.Code).To(Equal(http.StatusBadRequest))
+	})
+})
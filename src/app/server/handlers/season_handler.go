@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/season"
+)
+
+func GetSeasonsHandler(seasonService season.SeasonService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		seasons, err := seasonService.GetSeasons(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, domain.SeasonsResponse{Seasons: seasons})
+	}
+}
+
+func GetCurrentSeasonHandler(seasonService season.SeasonService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		current, err := seasonService.GetCurrentSeason(c.Request.Context())
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, current)
+	}
+}
+
+func GetSeasonStandingsHandler(seasonService season.SeasonService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		pSeasonID := c.Param("seasonId")
+		seasonID, err := uuid.FromString(pSeasonID)
+		if err != nil {
+			_ = c.Error(models.NewValidationErrorf("Invalid season id '%s'", pSeasonID))
+			return
+		}
+
+		page, pageSize := pageQuery(c)
+		response, err := seasonService.GetStandings(c.Request.Context(), seasonID, page, pageSize)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func GetLeaderboardHandler(seasonService season.SeasonService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		period := domain.LeaderboardPeriod(c.Param("period"))
+		page, pageSize := pageQuery(c)
+		response, err := seasonService.GetLeaderboard(c.Request.Context(), period, page, pageSize)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func CreateSeasonHandler(seasonService season.SeasonService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var request domain.CreateSeasonRequest
+		if err := c.BindJSON(&request); err != nil {
+			_ = c.Error(models.NewValidationError("bad request"))
+			return
+		}
+
+		created, err := seasonService.CreateSeason(c.Request.Context(), &request)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, created)
+	}
+}
+
+func ArchiveSeasonHandler(seasonService season.SeasonService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		pSeasonID := c.Param("seasonId")
+		seasonID, err := uuid.FromString(pSeasonID)
+		if err != nil {
+			_ = c.Error(models.NewValidationErrorf("Invalid season id '%s'", pSeasonID))
+			return
+		}
+
+		archived, err := seasonService.ArchiveSeason(c.Request.Context(), seasonID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, archived)
+	}
+}
+
+// pageQuery returns the page and pageSize query parameters. Missing or
+// invalid values fall back to the first page of the default size.
+func pageQuery(c *gin.Context) (int, int) {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.Query("pageSize"))
+	if err != nil {
+		pageSize = season.DefaultPageSize
+	}
+
+	return page, pageSize
+}
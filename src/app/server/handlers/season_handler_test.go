@@ -0,0 +1,122 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/handlers"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/season"
+	"github.com/plamen-v/tic-tac-toe/src/services/season/mocks"
+	"github.com/stretchr/testify/mock"
+
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SeasonHandler", func() {
+	var (
+		mockSeasonService *mocks.MockSeasonService
+		router            *gin.Engine
+		seasonID          uuid.UUID
+	)
+
+	BeforeEach(func() {
+		mockSeasonService = new(mocks.MockSeasonService)
+		seasonID = uuid.Must(uuid.NewV4())
+		gin.SetMode(gin.TestMode)
+		router = gin.Default()
+		router.Use(middleware.ErrorHandler())
+		router.GET("/seasons/current", handlers.GetCurrentSeasonHandler(mockSeasonService))
+		router.GET("/seasons/:seasonId/standings", handlers.GetSeasonStandingsHandler(mockSeasonService))
+		router.GET("/leaderboards/:period", handlers.GetLeaderboardHandler(mockSeasonService))
+		router.POST("/admin/seasons", handlers.CreateSeasonHandler(mockSeasonService))
+		router.POST("/admin/seasons/:seasonId/archive", handlers.ArchiveSeasonHandler(mockSeasonService))
+	})
+
+	serve := func(method string, path string, body any) *httptest.ResponseRecorder {
+		var requestBody []byte
+		if body != nil {
+			var err error
+			requestBody, err = json.Marshal(body)
+			Expect(err).To(BeNil())
+		}
+		request, err := http.NewRequest(method, path, bytes.NewBuffer(requestBody))
+		Expect(err).To(BeNil())
+		request.Header.Set("Content-Type", "application/json")
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		return response
+	}
+
+	It("should return not found if no season is running", func() {
+		mockSeasonService.On("GetCurrentSeason", mock.Anything).
+			Return(nil, models.NewNotFoundError("no season is running"))
+
+		response := serve(http.MethodGet, "/seasons/current", nil)
+
+		Expect(response.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should return the standings of the season", func() {
+		mockSeasonService.On("GetStandings", mock.Anything, seasonID, 2, 5).
+			Return(&domain.StandingsResponse{
+				Season:    &domain.Season{ID: seasonID},
+				Standings: []*domain.Standing{{Rank: 6, Rating: 1210}},
+				PageInfo:  models.PageInfo{Page: 2, PageSize: 5, TotalCnt: 7},
+			}, nil)
+
+		response := serve(http.MethodGet, "/seasons/"+seasonID.String()+"/standings?page=2&pageSize=5", nil)
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var body domain.StandingsResponse
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Standings).To(HaveLen(1))
+		Expect(body.Standings[0].Rank).To(Equal(6))
+	})
+
+	It("should return bad request for an invalid season id", func() {
+		response := serve(http.MethodGet, "/seasons/abc/standings", nil)
+
+		Expect(response.Code).To(Equal(http.StatusBadRequest))
+		mockSeasonService.AssertNotCalled(GinkgoT(), "GetStandings", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	It("should return the leaderboard of the period with the default page", func() {
+		mockSeasonService.On("GetLeaderboard", mock.Anything, domain.LeaderboardWeekly, 1, season.DefaultPageSize).
+			Return(&domain.LeaderboardResponse{Period: domain.LeaderboardWeekly}, nil)
+
+		response := serve(http.MethodGet, "/leaderboards/weekly", nil)
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		mockSeasonService.AssertExpectations(GinkgoT())
+	})
+
+	It("should create the season", func() {
+		startsAt := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+		request := domain.CreateSeasonRequest{Name: "Summer", StartsAt: startsAt, EndsAt: startsAt.AddDate(0, 3, 0)}
+		mockSeasonService.On("CreateSeason", mock.Anything, &request).
+			Return(&domain.Season{ID: seasonID, Name: "Summer"}, nil)
+
+		response := serve(http.MethodPost, "/admin/seasons", request)
+
+		Expect(response.Code).To(Equal(http.StatusCreated))
+	})
+
+	It("should return bad request if the season cannot be archived yet", func() {
+		mockSeasonService.On("ArchiveSeason", mock.Anything, seasonID).
+			Return(nil, models.NewValidationError(season.SeasonNotEndedErrorMessage))
+
+		response := serve(http.MethodPost, "/admin/seasons/"+seasonID.String()+"/archive", nil)
+
+		Expect(response.Code).To(Equal(http.StatusBadRequest))
+	})
+})
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/engine"
+	"github.com/plamen-v/tic-tac-toe/src/services/social"
+)
+
+func GetFriendsHandler(socialService social.SocialService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		playerID, ok := getPlayerIDFromContext(c, middleware.KEY_PLAYER_ID)
+		if !ok {
+			_ = c.Error(models.NewValidationError("Missing player_id claim"))
+			return
+		}
+
+		friends, err := socialService.GetFriends(c.Request.Context(), playerID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		response := domain.FriendsResponse{
+			Friends: friends,
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func RequestFriendHandler(socialService social.SocialService) func(*gin.Context) {
+	return playerActionHandler(socialService.RequestFriend)
+}
+
+func AcceptFriendHandler(socialService social.SocialService) func(*gin.Context) {
+	return playerActionHandler(socialService.AcceptFriend)
+}
+
+func RemoveFriendHandler(socialService social.SocialService) func(*gin.Context) {
+	return playerActionHandler(socialService.RemoveFriend)
+}
+
+func GetBlockedPlayersHandler(socialService social.SocialService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		playerID, ok := getPlayerIDFromContext(c, middleware.KEY_PLAYER_ID)
+		if !ok {
+			_ = c.Error(models.NewValidationError("Missing player_id claim"))
+			return
+		}
+
+		players, err := socialService.GetBlocked(c.Request.Context(), playerID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		response := domain.BlockedPlayersResponse{
+			Players: players,
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func BlockPlayerHandler(socialService social.SocialService) func(*gin.Context) {
+	return playerActionHandler(socialService.Block)
+}
+
+func UnblockPlayerHandler(socialService social.SocialService) func(*gin.Context) {
+	return playerActionHandler(socialService.Unblock)
+}
+
+func InvitePlayerHandler(socialService social.SocialService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var request domain.InviteRequest
+		if err := c.BindJSON(&request); err != nil {
+			_ = c.Error(models.NewValidationError("bad request"))
+			return
+		}
+
+		playerID, ok := getPlayerIDFromContext(c, middleware.KEY_PLAYER_ID)
+		if !ok {
+			_ = c.Error(models.NewValidationError("Missing player_id claim"))
+			return
+		}
+
+		roomID, err := socialService.Invite(c.Request.Context(), playerID, request.PlayerID, request.Title, request.Description)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		response := models.CreateRoomResponse{
+			RoomID: roomID,
+		}
+
+		c.JSON(http.StatusCreated, response)
+	}
+}
+
+func GetInvitationsHandler(gameEngineService engine.GameEngineService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		playerID, ok := getPlayerIDFromContext(c, middleware.KEY_PLAYER_ID)
+		if !ok {
+			_ = c.Error(models.NewValidationError("Missing player_id claim"))
+			return
+		}
+
+		invitations, err := gameEngineService.GetInvitations(c.Request.Context(), playerID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		response := domain.InvitationsResponse{
+			Invitations: invitations,
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func DeclineInvitationHandler(gameEngineService engine.GameEngineService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		pRoomID := c.Param("roomId")
+		roomID, err := uuid.FromString(pRoomID)
+		if err != nil {
+			_ = c.Error(models.NewValidationErrorf("Invalid room id '%s'", pRoomID))
+			return
+		}
+
+		playerID, ok := getPlayerIDFromContext(c, middleware.KEY_PLAYER_ID)
+		if !ok {
+			_ = c.Error(models.NewValidationError("Missing player_id claim"))
+			return
+		}
+
+		if err = gameEngineService.DeclineInvitation(c.Request.Context(), roomID, playerID); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// playerActionHandler applies an action of the current player on the player
+// from the path.
+func playerActionHandler(action func(context.Context, uuid.UUID, uuid.UUID) error) func(*gin.Context) {
+	return func(c *gin.Context) {
+		pOtherID := c.Param("playerId")
+		otherID, err := uuid.FromString(pOtherID)
+		if err != nil {
+			_ = c.Error(models.NewValidationErrorf("Invalid player id '%s'", pOtherID))
+			return
+		}
+
+		playerID, ok := getPlayerIDFromContext(c, middleware.KEY_PLAYER_ID)
+		if !ok {
+			_ = c.Error(models.NewValidationError("Missing player_id claim"))
+			return
+		}
+
+		if err = action(c.Request.Context(), playerID, otherID); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,171 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/handlers"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/apperrors"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	enginemocks "github.com/plamen-v/tic-tac-toe/src/services/engine/mocks"
+	"github.com/plamen-v/tic-tac-toe/src/services/social/mocks"
+	"github.com/stretchr/testify/mock"
+
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SocialHandler", func() {
+	var (
+		mockSocialService     *mocks.MockSocialService
+		mockGameEngineService *enginemocks.MockGameEngineService
+		router                *gin.Engine
+		playerID              uuid.UUID
+		otherID               uuid.UUID
+	)
+
+	BeforeEach(func() {
+		mockSocialService = new(mocks.MockSocialService)
+		mockGameEngineService = new(enginemocks.MockGameEngineService)
+		playerID = uuid.Must(uuid.NewV4())
+		otherID = uuid.Must(uuid.NewV4())
+		gin.SetMode(gin.TestMode)
+		router = gin.Default()
+		router.Use(middleware.ErrorHandler())
+		router.Use(func(c *gin.Context) {
+			c.Set(middleware.KEY_PLAYER_ID, uuid.NullUUID{UUID: playerID, Valid: true})
+		})
+		router.GET("/me/friends", handlers.GetFriendsHandler(mockSocialService))
+		router.POST("/me/friends/:playerId", handlers.RequestFriendHandler(mockSocialService))
+		router.POST("/me/friends/:playerId/accept", handlers.AcceptFriendHandler(mockSocialService))
+		router.DELETE("/me/friends/:playerId", handlers.RemoveFriendHandler(mockSocialService))
+		router.PUT("/me/blocks/:playerId", handlers.BlockPlayerHandler(mockSocialService))
+		router.POST("/invitations", handlers.InvitePlayerHandler(mockSocialService))
+		router.GET("/me/invitations", handlers.GetInvitationsHandler(mockGameEngineService))
+		router.DELETE("/me/invitations/:roomId", handlers.DeclineInvitationHandler(mockGameEngineService))
+	})
+
+	serve := func(method string, path string, body any) *httptest.ResponseRecorder {
+		var requestBody []byte
+		if body != nil {
+			var err error
+			requestBody, err = json.Marshal(body)
+			Expect(err).To(BeNil())
+		}
+		request, err := http.NewRequest(method, path, bytes.NewBuffer(requestBody))
+		Expect(err).To(BeNil())
+		request.Header.Set("Content-Type", "application/json")
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		return response
+	}
+
+	It("should return the friends", func() {
+		mockSocialService.On("GetFriends", mock.Anything, playerID).
+			Return([]*domain.Friend{{ID: otherID, Nickname: "friend", Status: domain.FriendStatusAccepted}}, nil)
+
+		response := serve(http.MethodGet, "/me/friends", nil)
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var body domain.FriendsResponse
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Friends).To(HaveLen(1))
+		Expect(body.Friends[0].ID).To(Equal(otherID))
+	})
+
+	It("should send a friend request", func() {
+		mockSocialService.On("RequestFriend", mock.Anything, playerID, otherID).Return(nil)
+
+		response := serve(http.MethodPost, "/me/friends/"+otherID.String(), nil)
+
+		Expect(response.Code).To(Equal(http.StatusNoContent))
+		mockSocialService.AssertExpectations(GinkgoT())
+	})
+
+	It("should accept a friend request", func() {
+		mockSocialService.On("AcceptFriend", mock.Anything, playerID, otherID).Return(nil)
+
+		response := serve(http.MethodPost, "/me/friends/"+otherID.String()+"/accept", nil)
+
+		Expect(response.Code).To(Equal(http.StatusNoContent))
+		mockSocialService.AssertExpectations(GinkgoT())
+	})
+
+	It("should return 404 when removing a player who is not a friend", func() {
+		mockSocialService.On("RemoveFriend", mock.Anything, playerID, otherID).
+			Return(models.NewNotFoundError("friendship not found"))
+
+		response := serve(http.MethodDelete, "/me/friends/"+otherID.String(), nil)
+
+		Expect(response.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should return 400 for an invalid player id", func() {
+		response := serve(http.MethodPut, "/me/blocks/invalid", nil)
+
+		Expect(response.Code).To(Equal(http.StatusBadRequest))
+		mockSocialService.AssertNotCalled(GinkgoT(), "Block", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	It("should block a player", func() {
+		mockSocialService.On("Block", mock.Anything, playerID, otherID).Return(nil)
+
+		response := serve(http.MethodPut, "/me/blocks/"+otherID.String(), nil)
+
+		Expect(response.Code).To(Equal(http.StatusNoContent))
+		mockSocialService.AssertExpectations(GinkgoT())
+	})
+
+	It("should invite a player", func() {
+		roomID := uuid.Must(uuid.NewV4())
+		mockSocialService.On("Invite", mock.Anything, playerID, otherID, "title", "description").Return(roomID, nil)
+
+		response := serve(http.MethodPost, "/invitations",
+			domain.InviteRequest{PlayerID: otherID, Title: "title", Description: "description"})
+
+		Expect(response.Code).To(Equal(http.StatusCreated))
+		var body models.CreateRoomResponse
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.RoomID).To(Equal(roomID))
+	})
+
+	It("should return 403 when inviting a blocked player", func() {
+		mockSocialService.On("Invite", mock.Anything, playerID, otherID, "title", "").
+			Return(nil, apperrors.NewForbiddenError("player is blocked"))
+
+		response := serve(http.MethodPost, "/invitations", domain.InviteRequest{PlayerID: otherID, Title: "title"})
+
+		Expect(response.Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("should return the invitations", func() {
+		roomID := uuid.Must(uuid.NewV4())
+		mockGameEngineService.On("GetInvitations", mock.Anything, playerID).
+			Return([]*domain.Invitation{{RoomID: roomID, PlayerID: playerID, HostID: otherID}}, nil)
+
+		response := serve(http.MethodGet, "/me/invitations", nil)
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var body domain.InvitationsResponse
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Invitations).To(HaveLen(1))
+		Expect(body.Invitations[0].RoomID).To(Equal(roomID))
+	})
+
+	It("should decline an invitation", func() {
+		roomID := uuid.Must(uuid.NewV4())
+		mockGameEngineService.On("DeclineInvitation", mock.Anything, roomID, playerID).Return(nil)
+
+		response := serve(http.MethodDelete, "/me/invitations/"+roomID.String(), nil)
+
+		Expect(response.Code).To(Equal(http.StatusNoContent))
+		mockGameEngineService.AssertExpectations(GinkgoT())
+	})
+})
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/twofactor"
+)
+
+func GetTwoFactorStatusHandler(twoFactorService twofactor.TwoFactorService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		playerID, ok := getPlayerIDFromContext(c, middleware.KEY_PLAYER_ID)
+		if !ok {
+			_ = c.Error(models.NewValidationError("Missing player_id claim"))
+			return
+		}
+
+		status, err := twoFactorService.Status(c.Request.Context(), playerID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, status)
+	}
+}
+
+func EnrollTwoFactorHandler(twoFactorService twofactor.TwoFactorService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		playerID, ok := getPlayerIDFromContext(c, middleware.KEY_PLAYER_ID)
+		if !ok {
+			_ = c.Error(models.NewValidationError("Missing player_id claim"))
+			return
+		}
+
+		enrollment, err := twoFactorService.Enroll(c.Request.Context(), playerID)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, enrollment)
+	}
+}
+
+func ConfirmTwoFactorHandler(twoFactorService twofactor.TwoFactorService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var request domain.TwoFactorCodeRequest
+		if err := c.BindJSON(&request); err != nil {
+			_ = c.Error(models.NewValidationError("bad request"))
+			return
+		}
+
+		playerID, ok := getPlayerIDFromContext(c, middleware.KEY_PLAYER_ID)
+		if !ok {
+			_ = c.Error(models.NewValidationError("Missing player_id claim"))
+			return
+		}
+
+		codes, err := twoFactorService.Confirm(c.Request.Context(), playerID, request.Code)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, domain.RecoveryCodesResponse{RecoveryCodes: codes})
+	}
+}
+
+func DisableTwoFactorHandler(twoFactorService twofactor.TwoFactorService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var request domain.TwoFactorCodeRequest
+		if err := c.BindJSON(&request); err != nil {
+			_ = c.Error(models.NewValidationError("bad request"))
+			return
+		}
+
+		playerID, ok := getPlayerIDFromContext(c, middleware.KEY_PLAYER_ID)
+		if !ok {
+			_ = c.Error(models.NewValidationError("Missing player_id claim"))
+			return
+		}
+
+		if err := twoFactorService.Disable(c.Request.Context(), playerID, request.Code); err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func RegenerateRecoveryCodesHandler(twoFactorService twofactor.TwoFactorService) func(*gin.Context) {
+	return func(c *gin.Context) {
+		var request domain.TwoFactorCodeRequest
+		if err := c.BindJSON(&request); err != nil {
+			_ = c.Error(models.NewValidationError("bad request"))
+			return
+		}
+
+		playerID, ok := getPlayerIDFromContext(c, middleware.KEY_PLAYER_ID)
+		if !ok {
+			_ = c.Error(models.NewValidationError("Missing player_id claim"))
+			return
+		}
+
+		codes, err := twoFactorService.RegenerateRecoveryCodes(c.Request.Context(), playerID, request.Code)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, domain.RecoveryCodesResponse{RecoveryCodes: codes})
+	}
+}
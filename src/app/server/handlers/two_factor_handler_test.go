@@ -0,0 +1,108 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/handlers"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/twofactor/mocks"
+	"github.com/stretchr/testify/mock"
+
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TwoFactorHandler", func() {
+	var (
+		mockTwoFactorService *mocks.MockTwoFactorService
+		router               *gin.Engine
+		playerID             uuid.UUID
+	)
+
+	BeforeEach(func() {
+		mockTwoFactorService = new(mocks.MockTwoFactorService)
+		playerID = uuid.Must(uuid.NewV4())
+		gin.SetMode(gin.TestMode)
+		router = gin.Default()
+		router.Use(middleware.ErrorHandler())
+		router.Use(func(c *gin.Context) {
+			c.Set(middleware.KEY_PLAYER_ID, uuid.NullUUID{UUID: playerID, Valid: true})
+		})
+		router.GET("/me/2fa", handlers.GetTwoFactorStatusHandler(mockTwoFactorService))
+		router.POST("/me/2fa", handlers.EnrollTwoFactorHandler(mockTwoFactorService))
+		router.POST("/me/2fa/confirm", handlers.ConfirmTwoFactorHandler(mockTwoFactorService))
+		router.DELETE("/me/2fa", handlers.DisableTwoFactorHandler(mockTwoFactorService))
+	})
+
+	serve := func(method string, path string, body any) *httptest.ResponseRecorder {
+		var requestBody []byte
+		if body != nil {
+			var err error
+			requestBody, err = json.Marshal(body)
+			Expect(err).To(BeNil())
+		}
+		request, err := http.NewRequest(method, path, bytes.NewBuffer(requestBody))
+		Expect(err).To(BeNil())
+		request.Header.Set("Content-Type", "application/json")
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		return response
+	}
+
+	It("should return the status", func() {
+		mockTwoFactorService.On("Status", mock.Anything, playerID).Return(&domain.TwoFactorStatus{Enabled: true, RecoveryCodesLeft: 5}, nil)
+
+		response := serve(http.MethodGet, "/me/2fa", nil)
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var body domain.TwoFactorStatus
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.RecoveryCodesLeft).To(Equal(5))
+	})
+
+	It("should start an enrolment", func() {
+		mockTwoFactorService.On("Enroll", mock.Anything, playerID).Return(&domain.TwoFactorEnrollment{Secret: "SECRET", ProvisioningURI: "otpauth://totp/x"}, nil)
+
+		response := serve(http.MethodPost, "/me/2fa", nil)
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var body domain.TwoFactorEnrollment
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.Secret).To(Equal("SECRET"))
+	})
+
+	It("should return the recovery codes on confirmation", func() {
+		mockTwoFactorService.On("Confirm", mock.Anything, playerID, "123456").Return([]string{"abcde-fghij"}, nil)
+
+		response := serve(http.MethodPost, "/me/2fa/confirm", domain.TwoFactorCodeRequest{Code: "123456"})
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		var body domain.RecoveryCodesResponse
+		Expect(json.Unmarshal(response.Body.Bytes(), &body)).To(Succeed())
+		Expect(body.RecoveryCodes).To(Equal([]string{"abcde-fghij"}))
+	})
+
+	It("should return 400 for a wrong confirmation code", func() {
+		mockTwoFactorService.On("Confirm", mock.Anything, playerID, "000000").Return(nil, models.NewValidationError("two-factor code is invalid"))
+
+		response := serve(http.MethodPost, "/me/2fa/confirm", domain.TwoFactorCodeRequest{Code: "000000"})
+
+		Expect(response.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should disable two-factor authentication", func() {
+		mockTwoFactorService.On("Disable", mock.Anything, playerID, "123456").Return(nil)
+
+		response := serve(http.MethodDelete, "/me/2fa", domain.TwoFactorCodeRequest{Code: "123456"})
+
+		Expect(response.Code).To(Equal(http.StatusNoContent))
+	})
+})
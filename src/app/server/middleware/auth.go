@@ -6,10 +6,16 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/plamen-v/tic-tac-toe-models/models"
 	"github.com/plamen-v/tic-tac-toe/src/services/auth"
+	"github.com/plamen-v/tic-tac-toe/src/services/logger"
 )
 
-const KEY_PLAYER_ID string = "KEY_PLAYER_ID"
+const (
+	KEY_PLAYER_ID string = "KEY_PLAYER_ID"
+	KEY_ROLE      string = "KEY_ROLE"
+)
 
+// Authentication accepts access tokens of accounts that are still enabled.
+// The role is taken from the stored account rather than from the claims.
 func Authentication(authService auth.AuthenticationService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader(auth.AUTHORIZATION_HEADER)
@@ -21,13 +27,27 @@ func Authentication(authService auth.AuthenticationService) gin.HandlerFunc {
 			return
 		}
 
-		if claims, ok := jwtToken.Claims.(*auth.ExtendedClaims); ok {
-			c.Set(KEY_PLAYER_ID, claims.PlayerID)
-		} else {
+		claims, ok := jwtToken.Claims.(*auth.ExtendedClaims)
+		if !ok {
 			_ = c.Error(models.NewAuthorizationError("Invalid token"))
 			c.Abort()
+			return
 		}
 
+		ctx := c.Request.Context()
+		role, err := authService.AuthorizeAccount(ctx, claims.PlayerID.UUID)
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Set(KEY_PLAYER_ID, claims.PlayerID)
+		c.Set(KEY_ROLE, role)
+
+		playerLogger := logger.FromContext(ctx).With(logger.String("player_id", claims.PlayerID.UUID.String()))
+		c.Request = c.Request.WithContext(logger.NewContext(ctx, playerLogger))
+
 		c.Next()
 	}
 }
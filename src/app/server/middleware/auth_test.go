@@ -10,6 +10,8 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/plamen-v/tic-tac-toe-models/models"
 	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/apperrors"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
 	"github.com/plamen-v/tic-tac-toe/src/services/auth"
 	"github.com/plamen-v/tic-tac-toe/src/services/auth/mocks"
 
@@ -57,11 +59,16 @@ var _ = Describe("Authentication", func() {
 			Valid: true,
 		}
 
+		called := false
 		mockAuthenticationService.On("ValidateToken", mock.Anything).Return(invalidTokenType, nil)
-		router.GET("/test", authz, testHandler)
+		router.GET("/test", authz, func(c *gin.Context) {
+			called = true
+			testHandler(c)
+		})
 		w := httptest.NewRecorder()
 		router.ServeHTTP(w, request)
 		Expect(w.Code).To(Equal(http.StatusUnauthorized))
+		Expect(called).To(BeFalse())
 	})
 
 	It("should call next handler if authorization is successful", func() {
@@ -75,9 +82,58 @@ var _ = Describe("Authentication", func() {
 		}
 
 		mockAuthenticationService.On("ValidateToken", mock.Anything).Return(mockToken, nil)
+		mockAuthenticationService.On("AuthorizeAccount", mock.Anything, mockToken.Claims.(*auth.ExtendedClaims).PlayerID.UUID).Return(domain.RolePlayer, nil)
 		router.GET("/test", authz, testHandler)
 		w := httptest.NewRecorder()
 		router.ServeHTTP(w, request)
 		Expect(w.Code).To(Equal(http.StatusOK))
 	})
+
+	It("should expose the stored role rather than the one in the claims", func() {
+		authz := middleware.Authentication(mockAuthenticationService)
+
+		mockToken := &jwt.Token{
+			Claims: &auth.ExtendedClaims{
+				PlayerID: uuid.NullUUID{UUID: uuid.Must(uuid.NewV4()), Valid: true},
+				Role:     domain.RoleAdmin,
+			},
+			Valid: true,
+		}
+
+		var role any
+		mockAuthenticationService.On("ValidateToken", mock.Anything).Return(mockToken, nil)
+		mockAuthenticationService.On("AuthorizeAccount", mock.Anything, mock.Anything).Return(domain.RoleModerator, nil)
+		router.GET("/test", authz, func(c *gin.Context) {
+			role, _ = c.Get(middleware.KEY_ROLE)
+			testHandler(c)
+		})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, request)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(role).To(Equal(domain.RoleModerator))
+	})
+
+	It("should return 403 for a token of a disabled account", func() {
+		authz := middleware.Authentication(mockAuthenticationService)
+
+		mockToken := &jwt.Token{
+			Claims: &auth.ExtendedClaims{
+				PlayerID: uuid.NullUUID{UUID: uuid.Must(uuid.NewV4()), Valid: true},
+				Role:     domain.RoleAdmin,
+			},
+			Valid: true,
+		}
+
+		called := false
+		mockAuthenticationService.On("ValidateToken", mock.Anything).Return(mockToken, nil)
+		mockAuthenticationService.On("AuthorizeAccount", mock.Anything, mock.Anything).Return(domain.Role(""), apperrors.NewForbiddenError(auth.AccountDisabledErrorMessage))
+		router.GET("/test", authz, func(c *gin.Context) {
+			called = true
+			testHandler(c)
+		})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, request)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		Expect(called).To(BeFalse())
+	})
 })
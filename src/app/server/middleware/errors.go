@@ -2,17 +2,23 @@ package middleware
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/apperrors"
 )
 
+const RETRY_AFTER_HEADER string = "Retry-After"
+
 func ErrorHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
 
 		var validationError *models.ValidationError
+		var tooManyRequestsError *apperrors.TooManyRequestsError
 		if len(c.Errors) > 0 {
 			err := c.Errors.Last().Err
 			var statusCode int
@@ -31,6 +37,16 @@ func ErrorHandler() gin.HandlerFunc {
 				statusCode = http.StatusUnauthorized
 				errorCode = models.UnauthorizedErrorCode
 				errorMessage = models.AuthorizationErrorMessage
+			case errors.As(err, new(*apperrors.ForbiddenError)):
+				statusCode = http.StatusForbidden
+				errorCode = apperrors.ForbiddenErrorCode
+				errorMessage = err.Error()
+			case errors.As(err, &tooManyRequestsError):
+				statusCode = http.StatusTooManyRequests
+				errorCode = apperrors.TooManyRequestsErrorCode
+				errorMessage = err.Error()
+				retryAfter := int(math.Ceil(tooManyRequestsError.RetryAfter.Seconds()))
+				c.Header(RETRY_AFTER_HEADER, strconv.Itoa(max(retryAfter, 1)))
 			case errors.As(err, new(*models.GenericError)):
 				statusCode = http.StatusInternalServerError
 				errorCode = models.InternalServerErrorErrorCode
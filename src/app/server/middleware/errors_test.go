@@ -4,12 +4,14 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"time"
 
 	"net/http/httptest"
 
 	"github.com/gin-gonic/gin"
 	"github.com/plamen-v/tic-tac-toe-models/models"
 	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/apperrors"
 
 	. "github.com/onsi/ginkgo/v2"
 
@@ -35,6 +37,14 @@ var _ = Describe("ErrorHandler", func() {
 		_ = c.Error(models.NewAuthorizationError(errorMsg))
 		c.Abort()
 	}
+	forbiddenErrorHandler := func(c *gin.Context) {
+		_ = c.Error(apperrors.NewForbiddenError(errorMsg))
+		c.Abort()
+	}
+	tooManyRequestsErrorHandler := func(c *gin.Context) {
+		_ = c.Error(apperrors.NewTooManyRequestsError(1500*time.Millisecond, errorMsg))
+		c.Abort()
+	}
 	genericErrorHandler := func(c *gin.Context) {
 		_ = c.Error(models.NewGenericError(errorMsg))
 		c.Abort()
@@ -101,6 +111,37 @@ var _ = Describe("ErrorHandler", func() {
 		Expect(resp.Code).To(Equal(string(models.UnauthorizedErrorCode)))
 	})
 
+	It("should return ForbiddenError error", func() {
+		errorz := middleware.ErrorHandler()
+
+		router.GET("/test", errorz, forbiddenErrorHandler)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, request)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+
+		var resp models.ErrorResponse
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		Expect(err).To(BeNil())
+
+		Expect(resp.Code).To(Equal(string(apperrors.ForbiddenErrorCode)))
+	})
+
+	It("should return TooManyRequestsError error", func() {
+		errorz := middleware.ErrorHandler()
+
+		router.GET("/test", errorz, tooManyRequestsErrorHandler)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, request)
+		Expect(w.Code).To(Equal(http.StatusTooManyRequests))
+		Expect(w.Header().Get(middleware.RETRY_AFTER_HEADER)).To(Equal("2"))
+
+		var resp models.ErrorResponse
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		Expect(err).To(BeNil())
+
+		Expect(resp.Code).To(Equal(string(apperrors.TooManyRequestsErrorCode)))
+	})
+
 	It("should return GenericError error", func() {
 		errorz := middleware.ErrorHandler()
 
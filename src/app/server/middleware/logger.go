@@ -7,11 +7,22 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/plamen-v/tic-tac-toe/src/services/logger"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func Logger(l logger.LoggerService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
+
+		requestLogger := l
+		if requestID := c.GetString(KEY_REQUEST_ID); len(requestID) > 0 {
+			requestLogger = requestLogger.With(logger.String("request_id", requestID))
+		}
+		if spanContext := trace.SpanContextFromContext(c.Request.Context()); spanContext.HasTraceID() {
+			requestLogger = requestLogger.With(logger.String("trace_id", spanContext.TraceID().String()))
+		}
+		c.Request = c.Request.WithContext(logger.NewContext(c.Request.Context(), requestLogger))
+
 		c.Next()
 		duration := time.Since(start)
 
@@ -32,9 +43,9 @@ func Logger(l logger.LoggerService) gin.HandlerFunc {
 				})
 			}
 			fields = append(fields, logger.Any("errors", allErrors))
-			l.Error("request", fields...)
+			requestLogger.Error("request", fields...)
 		} else {
-			l.Info("request", fields...)
+			requestLogger.Info("request", fields...)
 		}
 	}
 }
@@ -71,4 +71,27 @@ var _ = Describe("Logger", func() {
 		Expect(w.Code).To(Equal(http.StatusOK))
 		mockLoggerService.AssertCalled(GinkgoT(), "Info", "request", mock.Anything)
 	})
+
+	It("should store a request-scoped logger in the request context", func() {
+		scopedLoggerService := new(mocks.MockLoggerService)
+		scopedLoggerService.On("Info", "request", mock.Anything).Return()
+		scopedLoggerService.On("Debug", "handled", mock.Anything).Return()
+		mockLoggerService.On("With", mock.Anything).Run(func(args mock.Arguments) {
+			fields := args.Get(0).([]logger.Field)
+			Expect(len(fields)).To(Equal(1))
+		}).Return(scopedLoggerService)
+
+		scopedHandler := func(c *gin.Context) {
+			logger.FromContext(c.Request.Context()).Debug("handled")
+			c.String(http.StatusOK, "test")
+		}
+		router.GET("/test", middleware.RequestID(), middleware.Logger(mockLoggerService), scopedHandler)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, request)
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		mockLoggerService.AssertNotCalled(GinkgoT(), "Info", "request", mock.Anything)
+		scopedLoggerService.AssertCalled(GinkgoT(), "Debug", "handled", mock.Anything)
+		scopedLoggerService.AssertCalled(GinkgoT(), "Info", "request", mock.Anything)
+	})
 })
@@ -0,0 +1,103 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/services/metrics"
+	"github.com/plamen-v/tic-tac-toe/src/services/metrics/mocks"
+	"github.com/stretchr/testify/mock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Metrics", func() {
+	var (
+		mockMetricsService *mocks.MockMetricsService
+		router             *gin.Engine
+	)
+
+	okHandler := func(c *gin.Context) {
+		c.String(http.StatusOK, "test")
+	}
+
+	errorHandler := func(c *gin.Context) {
+		_ = c.Error(models.NewValidationError("test error"))
+		c.Abort()
+	}
+
+	BeforeEach(func() {
+		mockMetricsService = new(mocks.MockMetricsService)
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+	})
+
+	It("should observe the route template and status of a matched request", func() {
+		mockMetricsService.On("ObserveRequest", http.MethodGet, "/rooms/:roomId", http.StatusOK, mock.Anything).Return()
+		router.Use(middleware.Metrics(mockMetricsService))
+		router.GET("/rooms/:roomId", okHandler)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/rooms/123", nil))
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		mockMetricsService.AssertExpectations(GinkgoT())
+	})
+
+	It("should observe the status written by the error handler", func() {
+		mockMetricsService.On("ObserveRequest", http.MethodGet, "/test", http.StatusBadRequest, mock.Anything).Return()
+		router.Use(middleware.Metrics(mockMetricsService), middleware.ErrorHandler())
+		router.GET("/test", errorHandler)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		mockMetricsService.AssertExpectations(GinkgoT())
+	})
+
+	It("should observe unmatched routes with an empty route", func() {
+		mockMetricsService.On("ObserveRequest", http.MethodGet, "", http.StatusNotFound, mock.Anything).Return()
+		router.Use(middleware.Metrics(mockMetricsService))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+		mockMetricsService.AssertExpectations(GinkgoT())
+	})
+
+	It("should expose the registered collectors", func() {
+		metricsService := metrics.NewMetricsService(nil)
+		metricsService.GameStarted()
+		router.GET("/metrics", gin.WrapH(metricsService.Handler()))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(ContainSubstring("tictactoe_game_games_started_total 1"))
+	})
+
+	It("should count the active rooms in the database", func() {
+		db, mock, err := sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		defer db.Close()
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM rooms").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+		metricsService := metrics.NewMetricsService(db)
+		router.GET("/metrics", gin.WrapH(metricsService.Handler()))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(ContainSubstring("tictactoe_game_active_rooms 3"))
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+})
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe/src/services/logger"
+	"github.com/plamen-v/tic-tac-toe/src/services/presence"
+)
+
+// Presence records the activity of the authenticated player. It must run
+// after Authentication. A failure is only logged; presence is not worth
+// failing the request for.
+func Presence(presenceService presence.PresenceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if value, ok := c.Get(KEY_PLAYER_ID); ok {
+			if playerID, ok := value.(uuid.NullUUID); ok && playerID.Valid {
+				ctx := c.Request.Context()
+				if err := presenceService.Touch(ctx, playerID.UUID); err != nil {
+					logger.FromContext(ctx).Error("presence update failed", logger.Err(err))
+				}
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,74 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/services/presence/mocks"
+	"github.com/stretchr/testify/mock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Presence", func() {
+	var (
+		mockPresenceService *mocks.MockPresenceService
+		router              *gin.Engine
+		playerID            uuid.UUID
+	)
+
+	okHandler := func(c *gin.Context) {
+		c.String(http.StatusOK, "test")
+	}
+
+	setPlayer := func(c *gin.Context) {
+		c.Set(middleware.KEY_PLAYER_ID, uuid.NullUUID{UUID: playerID, Valid: true})
+	}
+
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+		return w
+	}
+
+	BeforeEach(func() {
+		mockPresenceService = new(mocks.MockPresenceService)
+		playerID = uuid.Must(uuid.NewV4())
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		router.Use(middleware.ErrorHandler())
+	})
+
+	It("should record the activity of the player", func() {
+		mockPresenceService.On("Touch", mock.Anything, playerID).Return(nil)
+		router.GET("/test", setPlayer, middleware.Presence(mockPresenceService), okHandler)
+
+		w := serve()
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		mockPresenceService.AssertExpectations(GinkgoT())
+	})
+
+	It("should pass the request when the update fails", func() {
+		mockPresenceService.On("Touch", mock.Anything, playerID).Return(errors.New("db error"))
+		router.GET("/test", setPlayer, middleware.Presence(mockPresenceService), okHandler)
+
+		w := serve()
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+	})
+
+	It("should skip anonymous requests", func() {
+		router.GET("/test", middleware.Presence(mockPresenceService), okHandler)
+
+		w := serve()
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		mockPresenceService.AssertNotCalled(GinkgoT(), "Touch", mock.Anything, mock.Anything)
+	})
+})
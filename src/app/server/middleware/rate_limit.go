@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe/src/apperrors"
+	"github.com/plamen-v/tic-tac-toe/src/services/logger"
+	"github.com/plamen-v/tic-tac-toe/src/services/ratelimit"
+)
+
+const (
+	RATE_LIMIT_LIMIT_HEADER     string = "X-RateLimit-Limit"
+	RATE_LIMIT_REMAINING_HEADER string = "X-RateLimit-Remaining"
+)
+
+// RateLimitKeyFunc selects the bucket a request is charged to.
+type RateLimitKeyFunc func(c *gin.Context) string
+
+func ByClientIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByPlayerID charges authenticated requests to the player, so that players
+// behind a shared address do not throttle each other. It must run after
+// Authentication and falls back to the client address otherwise.
+func ByPlayerID(c *gin.Context) string {
+	if playerID, ok := c.Get(KEY_PLAYER_ID); ok {
+		if id, ok := playerID.(uuid.NullUUID); ok && id.Valid {
+			return "player:" + id.UUID.String()
+		}
+	}
+
+	return ByClientIP(c)
+}
+
+// RateLimit rejects requests over policy with a TooManyRequestsError. A store
+// failure lets the request through rather than taking the API down with it.
+func RateLimit(rateLimitService ratelimit.RateLimitService, policy ratelimit.Policy, keyFunc RateLimitKeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		decision, err := rateLimitService.Allow(ctx, policy, keyFunc(c))
+		if err != nil {
+			logger.FromContext(ctx).Error("rate limit check failed", logger.String("policy", policy.Name), logger.Err(err))
+			c.Next()
+			return
+		}
+
+		c.Header(RATE_LIMIT_LIMIT_HEADER, strconv.Itoa(decision.Limit))
+		c.Header(RATE_LIMIT_REMAINING_HEADER, strconv.Itoa(decision.Remaining))
+
+		if !decision.Allowed {
+			logger.FromContext(ctx).Warn("rate limit exceeded", logger.String("policy", policy.Name))
+			_ = c.Error(apperrors.NewTooManyRequestsError(decision.RetryAfter, apperrors.TooManyRequestsErrorMessage))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,106 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/services/ratelimit"
+	"github.com/plamen-v/tic-tac-toe/src/services/ratelimit/mocks"
+	"github.com/stretchr/testify/mock"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RateLimit", func() {
+	var (
+		mockRateLimitService *mocks.MockRateLimitService
+		router               *gin.Engine
+		playerID             uuid.UUID
+	)
+
+	policy := ratelimit.Policy{Name: "test", Rate: 1, Burst: 2}
+
+	okHandler := func(c *gin.Context) {
+		c.String(http.StatusOK, "test")
+	}
+
+	setPlayer := func(c *gin.Context) {
+		c.Set(middleware.KEY_PLAYER_ID, uuid.NullUUID{UUID: playerID, Valid: true})
+	}
+
+	BeforeEach(func() {
+		mockRateLimitService = new(mocks.MockRateLimitService)
+		playerID = uuid.Must(uuid.NewV4())
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		router.Use(middleware.ErrorHandler())
+	})
+
+	It("should pass allowed requests and report the remaining quota", func() {
+		mockRateLimitService.On("Allow", mock.Anything, policy, "ip:192.0.2.1").Return(&ratelimit.Decision{Allowed: true, Limit: 2, Remaining: 1}, nil)
+		router.GET("/test", middleware.RateLimit(mockRateLimitService, policy, middleware.ByClientIP), okHandler)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/test", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		router.ServeHTTP(w, r)
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get(middleware.RATE_LIMIT_LIMIT_HEADER)).To(Equal("2"))
+		Expect(w.Header().Get(middleware.RATE_LIMIT_REMAINING_HEADER)).To(Equal("1"))
+		mockRateLimitService.AssertExpectations(GinkgoT())
+	})
+
+	It("should reject denied requests with 429 and Retry-After", func() {
+		mockRateLimitService.On("Allow", mock.Anything, policy, mock.Anything).Return(&ratelimit.Decision{Allowed: false, Limit: 2, RetryAfter: 2500 * time.Millisecond}, nil)
+		router.GET("/test", middleware.RateLimit(mockRateLimitService, policy, middleware.ByClientIP), okHandler)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+		Expect(w.Code).To(Equal(http.StatusTooManyRequests))
+		Expect(w.Header().Get(middleware.RETRY_AFTER_HEADER)).To(Equal("3"))
+		Expect(w.Header().Get(middleware.RATE_LIMIT_REMAINING_HEADER)).To(Equal("0"))
+	})
+
+	It("should key authenticated requests by player", func() {
+		mockRateLimitService.On("Allow", mock.Anything, policy, "player:"+playerID.String()).Return(&ratelimit.Decision{Allowed: true}, nil)
+		router.GET("/test", setPlayer, middleware.RateLimit(mockRateLimitService, policy, middleware.ByPlayerID), okHandler)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		mockRateLimitService.AssertExpectations(GinkgoT())
+	})
+
+	It("should fall back to the client address without a player", func() {
+		mockRateLimitService.On("Allow", mock.Anything, policy, "ip:192.0.2.1").Return(&ratelimit.Decision{Allowed: true}, nil)
+		router.GET("/test", middleware.RateLimit(mockRateLimitService, policy, middleware.ByPlayerID), okHandler)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/test", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		router.ServeHTTP(w, r)
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		mockRateLimitService.AssertExpectations(GinkgoT())
+	})
+
+	It("should let requests through when the store fails", func() {
+		mockRateLimitService.On("Allow", mock.Anything, policy, mock.Anything).Return(nil, errors.New("store down"))
+		router.GET("/test", middleware.RateLimit(mockRateLimitService, policy, middleware.ByClientIP), okHandler)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil).WithContext(context.Background()))
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+	})
+})
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	KEY_REQUEST_ID       string = "KEY_REQUEST_ID"
+	REQUEST_ID_HEADER    string = "X-Request-ID"
+	MaxRequestIDLength   int    = 128
+	requestIDPunctuation string = "-_.:"
+)
+
+// RequestID reuses a well-formed X-Request-ID from the client or generates a
+// new one, and echoes it back in the response.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(REQUEST_ID_HEADER)
+		if !isValidRequestID(requestID) {
+			requestID = uuid.Must(uuid.NewV4()).String()
+		}
+
+		c.Set(KEY_REQUEST_ID, requestID)
+		c.Header(REQUEST_ID_HEADER, requestID)
+
+		c.Next()
+	}
+}
+
+func isValidRequestID(requestID string) bool {
+	if len(requestID) == 0 || len(requestID) > MaxRequestIDLength {
+		return false
+	}
+
+	for _, r := range requestID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune(requestIDPunctuation, r):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,77 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RequestID", func() {
+	var (
+		router    *gin.Engine
+		request   *http.Request
+		requestID string
+	)
+
+	testHandler := func(c *gin.Context) {
+		requestID = c.GetString(middleware.KEY_REQUEST_ID)
+		c.String(http.StatusOK, "test")
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.Default()
+		router.Use(middleware.RequestID())
+		router.GET("/test", testHandler)
+		request = httptest.NewRequest(http.MethodGet, "/test", nil)
+		requestID = ""
+	})
+
+	It("should generate a request id if the header is missing", func() {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, request)
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		_, err := uuid.FromString(requestID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(w.Header().Get(middleware.REQUEST_ID_HEADER)).To(Equal(requestID))
+	})
+
+	It("should propagate a valid request id from the client", func() {
+		request.Header.Set(middleware.REQUEST_ID_HEADER, "client-id_1.2:3")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, request)
+
+		Expect(requestID).To(Equal("client-id_1.2:3"))
+		Expect(w.Header().Get(middleware.REQUEST_ID_HEADER)).To(Equal("client-id_1.2:3"))
+	})
+
+	It("should replace a request id with invalid characters", func() {
+		request.Header.Set(middleware.REQUEST_ID_HEADER, "bad id\n")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, request)
+
+		Expect(requestID).ToNot(Equal("bad id\n"))
+		_, err := uuid.FromString(requestID)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should replace a request id that is too long", func() {
+		request.Header.Set(middleware.REQUEST_ID_HEADER, strings.Repeat("a", middleware.MaxRequestIDLength+1))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, request)
+
+		_, err := uuid.FromString(requestID)
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
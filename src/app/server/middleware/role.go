@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/plamen-v/tic-tac-toe/src/apperrors"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+)
+
+// RequireRole only lets through players whose role includes role. It must run
+// after Authentication.
+func RequireRole(role domain.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get(KEY_ROLE)
+		playerRole, ok := value.(domain.Role)
+		if !ok || !playerRole.Includes(role) {
+			_ = c.Error(apperrors.NewForbiddenError(apperrors.ForbiddenErrorMessage))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,59 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RequireRole", func() {
+	var router *gin.Engine
+
+	okHandler := func(c *gin.Context) {
+		c.String(http.StatusOK, "test")
+	}
+
+	withRole := func(role domain.Role) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set(middleware.KEY_ROLE, role)
+		}
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		router.Use(middleware.ErrorHandler())
+	})
+
+	DescribeTable("should enforce the role hierarchy",
+		func(role domain.Role, required domain.Role, expectedStatus int) {
+			router.GET("/test", withRole(role), middleware.RequireRole(required), okHandler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+			Expect(w.Code).To(Equal(expectedStatus))
+		},
+		Entry("admin on admin route", domain.RoleAdmin, domain.RoleAdmin, http.StatusOK),
+		Entry("admin on moderator route", domain.RoleAdmin, domain.RoleModerator, http.StatusOK),
+		Entry("moderator on moderator route", domain.RoleModerator, domain.RoleModerator, http.StatusOK),
+		Entry("moderator on admin route", domain.RoleModerator, domain.RoleAdmin, http.StatusForbidden),
+		Entry("player on moderator route", domain.RolePlayer, domain.RoleModerator, http.StatusForbidden),
+		Entry("unknown role", domain.Role("root"), domain.RolePlayer, http.StatusForbidden),
+	)
+
+	It("should return 403 without a role", func() {
+		router.GET("/test", middleware.RequireRole(domain.RolePlayer), okHandler)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+	})
+})
@@ -11,9 +11,31 @@ import (
 	"github.com/plamen-v/tic-tac-toe/src/app/server/handlers"
 	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
 	"github.com/plamen-v/tic-tac-toe/src/config"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/admin"
+	"github.com/plamen-v/tic-tac-toe/src/services/analysis"
 	"github.com/plamen-v/tic-tac-toe/src/services/auth"
 	"github.com/plamen-v/tic-tac-toe/src/services/engine"
+	"github.com/plamen-v/tic-tac-toe/src/services/health"
+	"github.com/plamen-v/tic-tac-toe/src/services/lockout"
 	"github.com/plamen-v/tic-tac-toe/src/services/logger"
+	"github.com/plamen-v/tic-tac-toe/src/services/metrics"
+	"github.com/plamen-v/tic-tac-toe/src/services/oidc"
+	"github.com/plamen-v/tic-tac-toe/src/services/passwordreset"
+	"github.com/plamen-v/tic-tac-toe/src/services/presence"
+	"github.com/plamen-v/tic-tac-toe/src/services/profile"
+	"github.com/plamen-v/tic-tac-toe/src/services/ratelimit"
+	"github.com/plamen-v/tic-tac-toe/src/services/season"
+	"github.com/plamen-v/tic-tac-toe/src/services/social"
+	"github.com/plamen-v/tic-tac-toe/src/services/twofactor"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+const (
+	metricsPath   string = "/metrics"
+	livenessPath  string = "/livez"
+	readinessPath string = "/readyz"
+	healthPath    string = "/healthz"
 )
 
 type APIServer interface {
@@ -26,21 +48,49 @@ type apiServerImpl struct {
 	logger logger.LoggerService
 	//ginEngine   *gin.Engine
 	server                *http.Server
+	metricsService        metrics.MetricsService
+	healthService         health.HealthService
+	rateLimitService      ratelimit.RateLimitService
+	presenceService       presence.PresenceService
 	authenticationService auth.AuthenticationService
+	lockoutService        lockout.LockoutService
+	oidcService           oidc.OIDCService
+	twoFactorService      twofactor.TwoFactorService
+	adminService          admin.AdminService
+	profileService        profile.ProfileService
+	passwordResetService  passwordreset.PasswordResetService
+	socialService         social.SocialService
+	seasonService         season.SeasonService
+	analysisService       analysis.AnalysisService
 	gameEngineService     engine.GameEngineService
 }
 
-func NewAPI(config *config.AppConfiguration, logger logger.LoggerService, authenticationService auth.AuthenticationService, gameEngineService engine.GameEngineService) APIServer {
+func NewAPI(config *config.AppConfiguration, logger logger.LoggerService, metricsService metrics.MetricsService, healthService health.HealthService, rateLimitService ratelimit.RateLimitService, presenceService presence.PresenceService, authenticationService auth.AuthenticationService, lockoutService lockout.LockoutService, oidcService oidc.OIDCService, twoFactorService twofactor.TwoFactorService, adminService admin.AdminService, profileService profile.ProfileService, passwordResetService passwordreset.PasswordResetService, socialService social.SocialService, seasonService season.SeasonService, analysisService analysis.AnalysisService, gameEngineService engine.GameEngineService) APIServer {
 	return &apiServerImpl{
 		config:                config,
 		logger:                logger,
+		metricsService:        metricsService,
+		healthService:         healthService,
+		rateLimitService:      rateLimitService,
+		presenceService:       presenceService,
 		authenticationService: authenticationService,
+		lockoutService:        lockoutService,
+		oidcService:           oidcService,
+		twoFactorService:      twoFactorService,
+		adminService:          adminService,
+		profileService:        profileService,
+		passwordResetService:  passwordResetService,
+		socialService:         socialService,
+		seasonService:         seasonService,
+		analysisService:       analysisService,
 		gameEngineService:     gameEngineService,
 	}
 }
 
 func (s *apiServerImpl) Start() error {
-	s.initialize()
+	if err := s.initialize(); err != nil {
+		return err
+	}
 	return s.server.ListenAndServe()
 }
 
@@ -48,9 +98,12 @@ func (s *apiServerImpl) Stop(ctx context.Context) error {
 	return s.server.Shutdown(ctx)
 }
 
-func (s *apiServerImpl) initialize() {
+func (s *apiServerImpl) initialize() error {
 	setServerMode(s.config.AppMode)
-	engine := gin.Default()
+	engine, err := NewEngine(s.config.Server)
+	if err != nil {
+		return err
+	}
 	s.setEndpoints(engine)
 
 	address := fmt.Sprintf(":%d", s.config.Server.Port)
@@ -58,11 +111,36 @@ func (s *apiServerImpl) initialize() {
 		Addr:    address,
 		Handler: engine.Handler(),
 	}
+
+	return nil
+}
+
+// NewEngine returns the gin engine that takes the client address from
+// X-Forwarded-For only for requests of the trusted proxies.
+func NewEngine(configuration config.ServerConfiguration) (*gin.Engine, error) {
+	engine := gin.Default()
+	if err := engine.SetTrustedProxies(configuration.TrustedProxies); err != nil {
+		return nil, err
+	}
+
+	return engine, nil
 }
 
 func (s *apiServerImpl) setEndpoints(engine *gin.Engine) {
 	engine.Use(
+		middleware.RequestID(),
+		otelgin.Middleware(s.config.AppName,
+			otelgin.WithGinFilter(func(c *gin.Context) bool {
+				switch c.FullPath() {
+				case metricsPath, livenessPath, readinessPath, healthPath:
+					return false
+				default:
+					return true
+				}
+			}),
+		),
 		middleware.Logger(s.logger),
+		middleware.Metrics(s.metricsService),
 		middleware.ErrorHandler(),
 		gin.RecoveryWithWriter(gin.DefaultErrorWriter,
 			func(c *gin.Context, err any) {
@@ -72,21 +150,104 @@ func (s *apiServerImpl) setEndpoints(engine *gin.Engine) {
 		),
 	)
 
+	engine.GET(metricsPath, gin.WrapH(s.metricsService.Handler()))
+	engine.GET(livenessPath, handlers.LivenessHandler(s.healthService))
+	engine.GET(readinessPath, handlers.ReadinessHandler(s.healthService))
+	engine.GET(healthPath, handlers.HealthHandler(s.healthService))
+	engine.GET(auth.JWKSPath, handlers.JWKSHandler(s.authenticationService))
+
+	loginPolicy := ratelimit.NewPolicy(ratelimit.LoginPolicyName, s.config.RateLimit.Login)
+	movePolicy := ratelimit.NewPolicy(ratelimit.MovePolicyName, s.config.RateLimit.Move)
+	apiPolicy := ratelimit.NewPolicy(ratelimit.APIPolicyName, s.config.RateLimit.API)
+
 	api := engine.Group("/api")
-	api.POST("/login", handlers.LoginHandler(s.authenticationService))
+	api.POST("/login",
+		middleware.RateLimit(s.rateLimitService, loginPolicy, middleware.ByClientIP),
+		handlers.LoginHandler(s.authenticationService))
+	api.POST("/login/2fa",
+		middleware.RateLimit(s.rateLimitService, loginPolicy, middleware.ByClientIP),
+		handlers.TwoFactorLoginHandler(s.authenticationService))
+	api.POST("/password-reset",
+		middleware.RateLimit(s.rateLimitService, loginPolicy, middleware.ByClientIP),
+		handlers.RequestPasswordResetHandler(s.passwordResetService))
+	api.POST("/password-reset/confirm",
+		middleware.RateLimit(s.rateLimitService, loginPolicy, middleware.ByClientIP),
+		handlers.ConfirmPasswordResetHandler(s.passwordResetService))
+
+	sso := api.Group("/oidc")
+	sso.GET("", handlers.OIDCProvidersHandler(s.oidcService))
+	sso.Use(middleware.RateLimit(s.rateLimitService, loginPolicy, middleware.ByClientIP))
+	sso.GET("/:provider/login", handlers.OIDCLoginHandler(s.oidcService, s.config.OIDC.StateTTL))
+	sso.GET("/:provider/callback", handlers.OIDCCallbackHandler(s.oidcService, s.authenticationService))
 
 	game := api.Group("/")
-	game.Use(middleware.Authentication(s.authenticationService))
+	game.Use(
+		middleware.Authentication(s.authenticationService),
+		middleware.RateLimit(s.rateLimitService, apiPolicy, middleware.ByPlayerID),
+		middleware.Presence(s.presenceService),
+	)
 
 	game.GET("/room", handlers.GetRoomHandler(s.gameEngineService))
-	game.GET("/rooms", handlers.GetOpenRoomsHandler(s.gameEngineService))
+	game.GET("/rooms", handlers.GetOpenRoomsHandler(s.gameEngineService, s.presenceService))
 	game.POST("/rooms", handlers.CreateRoomHandler(s.gameEngineService))
 	game.POST("rooms/:roomId/player", handlers.PlayerJoinRoomHandler(s.gameEngineService))
 	game.DELETE("rooms/:roomId/player", handlers.PlayerLeaveRoomHandler(s.gameEngineService))
 	game.POST("rooms/:roomId/game", handlers.CreateGameHandler(s.gameEngineService))
 	game.GET("rooms/:roomId/game/", handlers.GetGameStateHandler(s.gameEngineService))
-	game.POST("rooms/:roomId/game/board/:position", handlers.MakeMoveHandler(s.gameEngineService))
+	game.GET("rooms/:roomId/game/hint", handlers.GetHintHandler(s.analysisService))
+	game.POST("rooms/:roomId/game/board/:position",
+		middleware.RateLimit(s.rateLimitService, movePolicy, middleware.ByPlayerID),
+		handlers.MakeMoveHandler(s.gameEngineService))
+	game.POST("rooms/:roomId/game/columns/:column",
+		middleware.RateLimit(s.rateLimitService, movePolicy, middleware.ByPlayerID),
+		handlers.MakeColumnMoveHandler(s.gameEngineService))
 	game.GET("ranking", handlers.GetRankingHandler(s.gameEngineService))
+	game.GET("/games/:gameId/analysis", handlers.GetGameAnalysisHandler(s.analysisService))
+	game.GET("/seasons", handlers.GetSeasonsHandler(s.seasonService))
+	game.GET("/seasons/current", handlers.GetCurrentSeasonHandler(s.seasonService))
+	game.GET("/seasons/:seasonId/standings", handlers.GetSeasonStandingsHandler(s.seasonService))
+	game.GET("/leaderboards/:period", handlers.GetLeaderboardHandler(s.seasonService))
+
+	game.GET("/me", handlers.GetMeHandler(s.profileService))
+	game.PATCH("/me", handlers.UpdateMeHandler(s.profileService))
+	game.DELETE("/me", handlers.DeleteMeHandler(s.profileService))
+	game.GET("/players/:playerId", handlers.GetPlayerProfileHandler(s.profileService))
+
+	game.GET("/me/friends", handlers.GetFriendsHandler(s.socialService))
+	game.POST("/me/friends/:playerId", handlers.RequestFriendHandler(s.socialService))
+	game.POST("/me/friends/:playerId/accept", handlers.AcceptFriendHandler(s.socialService))
+	game.DELETE("/me/friends/:playerId", handlers.RemoveFriendHandler(s.socialService))
+	game.GET("/me/blocks", handlers.GetBlockedPlayersHandler(s.socialService))
+	game.PUT("/me/blocks/:playerId", handlers.BlockPlayerHandler(s.socialService))
+	game.DELETE("/me/blocks/:playerId", handlers.UnblockPlayerHandler(s.socialService))
+	game.GET("/me/invitations", handlers.GetInvitationsHandler(s.gameEngineService))
+	game.DELETE("/me/invitations/:roomId", handlers.DeclineInvitationHandler(s.gameEngineService))
+	game.POST("/invitations", handlers.InvitePlayerHandler(s.socialService))
+
+	game.GET("/me/2fa", handlers.GetTwoFactorStatusHandler(s.twoFactorService))
+	game.POST("/me/2fa", handlers.EnrollTwoFactorHandler(s.twoFactorService))
+	game.POST("/me/2fa/confirm", handlers.ConfirmTwoFactorHandler(s.twoFactorService))
+	game.DELETE("/me/2fa", handlers.DisableTwoFactorHandler(s.twoFactorService))
+	game.POST("/me/2fa/recovery-codes", handlers.RegenerateRecoveryCodesHandler(s.twoFactorService))
+
+	moderation := game.Group("/admin")
+	moderation.Use(middleware.RequireRole(domain.RoleModerator))
+
+	moderation.DELETE("/rooms/:roomId", handlers.CloseRoomHandler(s.adminService))
+	moderation.POST("/games/:gameId/void", handlers.VoidGameHandler(s.adminService))
+
+	administration := game.Group("/admin")
+	administration.Use(middleware.RequireRole(domain.RoleAdmin))
+
+	administration.GET("/players", handlers.GetPlayersHandler(s.adminService))
+	administration.PUT("/players/:playerId/role", handlers.UpdatePlayerRoleHandler(s.adminService))
+	administration.PUT("/players/:playerId/disabled", handlers.UpdatePlayerDisabledHandler(s.adminService))
+	administration.PUT("/players/:playerId/stats", handlers.UpdatePlayerStatsHandler(s.adminService))
+	administration.GET("/lockouts", handlers.GetLockoutsHandler(s.lockoutService))
+	administration.GET("/lockouts/:playerId", handlers.GetLockoutHandler(s.lockoutService))
+	administration.DELETE("/lockouts/:playerId", handlers.UnlockHandler(s.lockoutService))
+	administration.POST("/seasons", handlers.CreateSeasonHandler(s.seasonService))
+	administration.POST("/seasons/:seasonId/archive", handlers.ArchiveSeasonHandler(s.seasonService))
 }
 
 func setServerMode(mode config.AppMode) {
@@ -0,0 +1,62 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/plamen-v/tic-tac-toe/src/app/server"
+	"github.com/plamen-v/tic-tac-toe/src/app/server/middleware"
+	"github.com/plamen-v/tic-tac-toe/src/config"
+	"github.com/plamen-v/tic-tac-toe/src/services/ratelimit"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewEngine", func() {
+	policy := ratelimit.NewPolicy("test", config.RateLimitPolicyConfiguration{Requests: config.RateLimitRequests(1), Period: time.Minute, Burst: 1})
+
+	login := func(configuration config.ServerConfiguration) func(forwardedFor string) int {
+		gin.SetMode(gin.TestMode)
+		engine, err := server.NewEngine(configuration)
+		Expect(err).To(BeNil())
+		rateLimitService, err := ratelimit.NewRateLimitService(config.RateLimitConfiguration{Store: config.MemoryRateLimitStore})
+		Expect(err).To(BeNil())
+		engine.Use(middleware.ErrorHandler())
+		engine.POST("/login", middleware.RateLimit(rateLimitService, policy, middleware.ByClientIP), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		return func(forwardedFor string) int {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/login", nil)
+			r.RemoteAddr = "192.0.2.1:1234"
+			r.Header.Set("X-Forwarded-For", forwardedFor)
+			engine.ServeHTTP(w, r)
+			return w.Code
+		}
+	}
+
+	It("should not let a spoofed X-Forwarded-For reset the rate limit", func() {
+		request := login(config.ServerConfiguration{})
+
+		Expect(request("198.51.100.1")).To(Equal(http.StatusOK))
+		Expect(request("198.51.100.2")).To(Equal(http.StatusTooManyRequests))
+	})
+
+	It("should take the client address from a trusted proxy", func() {
+		request := login(config.ServerConfiguration{TrustedProxies: []string{"192.0.2.0/24"}})
+
+		Expect(request("198.51.100.1")).To(Equal(http.StatusOK))
+		Expect(request("198.51.100.2")).To(Equal(http.StatusOK))
+		Expect(request("198.51.100.2")).To(Equal(http.StatusTooManyRequests))
+	})
+
+	It("should reject an invalid trusted proxy", func() {
+		_, err := server.NewEngine(config.ServerConfiguration{TrustedProxies: []string{"proxy"}})
+
+		Expect(err).NotTo(BeNil())
+	})
+})
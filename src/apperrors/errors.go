@@ -0,0 +1,60 @@
+// Package apperrors holds the error kinds the API reports that the shared
+// tic-tac-toe-models module does not define. They are mapped to HTTP
+// statuses by middleware.ErrorHandler next to the models errors.
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/plamen-v/tic-tac-toe-models/models"
+)
+
+const (
+	TooManyRequestsErrorCode    models.ErrorCode = "TOO_MANY_REQUESTS"
+	TooManyRequestsErrorMessage string           = "too many requests"
+	ForbiddenErrorCode          models.ErrorCode = "FORBIDDEN"
+	ForbiddenErrorMessage       string           = "forbidden"
+)
+
+type TooManyRequestsError struct {
+	message    string
+	RetryAfter time.Duration
+}
+
+func (e *TooManyRequestsError) Error() string {
+	return e.message
+}
+
+func NewTooManyRequestsError(retryAfter time.Duration, message string) error {
+	return &TooManyRequestsError{message: message, RetryAfter: retryAfter}
+}
+
+func NewTooManyRequestsErrorf(retryAfter time.Duration, format string, args ...any) error {
+	return &TooManyRequestsError{message: fmt.Sprintf(format, args...), RetryAfter: retryAfter}
+}
+
+func IsTooManyRequestsError(err error) bool {
+	return errors.As(err, new(*TooManyRequestsError))
+}
+
+type ForbiddenError struct {
+	message string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.message
+}
+
+func NewForbiddenError(message string) error {
+	return &ForbiddenError{message: message}
+}
+
+func NewForbiddenErrorf(format string, args ...any) error {
+	return &ForbiddenError{message: fmt.Sprintf(format, args...)}
+}
+
+func IsForbiddenError(err error) bool {
+	return errors.As(err, new(*ForbiddenError))
+}
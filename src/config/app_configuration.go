@@ -37,12 +37,21 @@ func init() {
 }
 
 type AppConfiguration struct {
-	AppName  string                `yaml:"appName,omitempty"`
-	AppMode  AppMode               `yaml:"appMode,omitempty"`
-	LogLevel LogLevel              `yaml:"logLevel,omitempty"`
-	Secret   string                `yaml:"secret,omitempty"`
-	Server   ServerConfiguration   `yaml:"server"`
-	Database DatabaseConfiguration `yaml:"database"`
+	AppName       string                     `yaml:"appName,omitempty"`
+	AppMode       AppMode                    `yaml:"appMode,omitempty"`
+	LogLevel      LogLevel                   `yaml:"logLevel,omitempty"`
+	Secret        string                     `yaml:"secret,omitempty"`
+	Server        ServerConfiguration        `yaml:"server"`
+	Database      DatabaseConfiguration      `yaml:"database"`
+	Tracing       TracingConfiguration       `yaml:"tracing"`
+	RateLimit     RateLimitConfiguration     `yaml:"rateLimit"`
+	Lockout       LockoutConfiguration       `yaml:"lockout"`
+	Signing       SigningConfiguration       `yaml:"signing"`
+	OIDC          OIDCConfiguration          `yaml:"oidc"`
+	TwoFactor     TwoFactorConfiguration     `yaml:"twoFactor"`
+	Notifier      NotifierConfiguration      `yaml:"notifier"`
+	PasswordReset PasswordResetConfiguration `yaml:"passwordReset"`
+	Presence      PresenceConfiguration      `yaml:"presence"`
 }
 
 func (c *AppConfiguration) SetDefaults() {
@@ -59,6 +68,15 @@ func (c *AppConfiguration) SetDefaults() {
 
 	c.Server.SetDefaults()
 	c.Database.SetDefaults()
+	c.Tracing.SetDefaults()
+	c.RateLimit.SetDefaults()
+	c.Lockout.SetDefaults()
+	c.Signing.SetDefaults()
+	c.OIDC.SetDefaults()
+	c.TwoFactor.SetDefaults(c.AppName)
+	c.Notifier.SetDefaults()
+	c.PasswordReset.SetDefaults()
+	c.Presence.SetDefaults()
 }
 
 func (c *AppConfiguration) Validate() error {
@@ -66,8 +84,8 @@ func (c *AppConfiguration) Validate() error {
 		return errors.New("application name is required")
 	}
 
-	if len(c.Secret) == 0 {
-		return errors.New("application secret is required")
+	if len(c.Secret) == 0 && len(c.Signing.Keys) == 0 {
+		return errors.New("application secret or signing keys are required")
 	}
 
 	if err := c.Server.Validate(); err != nil {
@@ -78,6 +96,42 @@ func (c *AppConfiguration) Validate() error {
 		return err
 	}
 
+	if err := c.Tracing.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.RateLimit.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Lockout.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Signing.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.OIDC.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.TwoFactor.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Notifier.Validate(c.AppMode); err != nil {
+		return err
+	}
+
+	if err := c.PasswordReset.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Presence.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	DefaultLockoutThreshold        int           = 5
+	DefaultLockoutDuration         time.Duration = time.Minute
+	DefaultLockoutMaxDuration      time.Duration = time.Hour
+	DefaultLockoutAnomalyWindow    time.Duration = 10 * time.Minute
+	DefaultLockoutAnomalyThreshold int           = 5
+	DefaultLoginAttemptRetention   time.Duration = 30 * 24 * time.Hour
+)
+
+// LockoutConfiguration controls account lockout after failed logins. An
+// account is locked after Threshold consecutive failures; each further
+// lockout doubles Duration up to MaxDuration. An address that fails to log in
+// to AnomalyThreshold distinct accounts within AnomalyWindow is reported.
+// Login attempts are kept for AttemptRetention.
+type LockoutConfiguration struct {
+	Threshold        int           `yaml:"threshold,omitempty"`
+	Duration         time.Duration `yaml:"duration,omitempty"`
+	MaxDuration      time.Duration `yaml:"maxDuration,omitempty"`
+	AnomalyWindow    time.Duration `yaml:"anomalyWindow,omitempty"`
+	AnomalyThreshold int           `yaml:"anomalyThreshold,omitempty"`
+	AttemptRetention time.Duration `yaml:"attemptRetention,omitempty"`
+}
+
+func (c *LockoutConfiguration) SetDefaults() {
+	if c.Threshold == 0 {
+		c.Threshold = DefaultLockoutThreshold
+	}
+	if c.Duration == 0 {
+		c.Duration = DefaultLockoutDuration
+	}
+	if c.MaxDuration == 0 {
+		c.MaxDuration = DefaultLockoutMaxDuration
+	}
+	if c.AnomalyWindow == 0 {
+		c.AnomalyWindow = DefaultLockoutAnomalyWindow
+	}
+	if c.AnomalyThreshold == 0 {
+		c.AnomalyThreshold = DefaultLockoutAnomalyThreshold
+	}
+	if c.AttemptRetention == 0 {
+		c.AttemptRetention = DefaultLoginAttemptRetention
+	}
+}
+
+func (c *LockoutConfiguration) Validate() error {
+	if c.Threshold < 0 {
+		return errors.New("lockout threshold is invalid")
+	}
+
+	if c.Duration < 0 || c.MaxDuration < c.Duration {
+		return errors.New("lockout duration is invalid")
+	}
+
+	if c.AnomalyWindow < 0 || c.AnomalyThreshold < 0 {
+		return errors.New("lockout anomaly detection is invalid")
+	}
+
+	if c.AttemptRetention < c.AnomalyWindow {
+		return errors.New("login attempt retention is shorter than the anomaly window")
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+type NotifierType string
+
+const (
+	LogNotifierType  NotifierType = "log"
+	SMTPNotifierType NotifierType = "smtp"
+	DefaultSMTPPort  int          = 587
+)
+
+// SMTPConfiguration describes the mail server messages are sent through.
+// Credentials are optional; when set the server has to offer STARTTLS unless
+// it runs on localhost.
+type SMTPConfiguration struct {
+	Host     string `yaml:"host,omitempty"`
+	Port     int    `yaml:"port,omitempty"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	From     string `yaml:"from,omitempty"`
+}
+
+// NotifierConfiguration selects how messages such as password reset links
+// reach players. It has no default type. The log notifier only writes them,
+// reset tokens included, to the application log and is rejected in production.
+type NotifierConfiguration struct {
+	Type NotifierType      `yaml:"type,omitempty"`
+	SMTP SMTPConfiguration `yaml:"smtp,omitempty"`
+}
+
+func (c *NotifierConfiguration) SetDefaults() {
+	if c.SMTP.Port == 0 {
+		c.SMTP.Port = DefaultSMTPPort
+	}
+}
+
+func (c *NotifierConfiguration) Validate(mode AppMode) error {
+	switch c.Type {
+	case "":
+		return errors.New("notifier type is required")
+	case LogNotifierType:
+		if mode == ProductionAppMode {
+			return errors.New("the log notifier is not allowed in production")
+		}
+	case SMTPNotifierType:
+		if len(c.SMTP.Host) == 0 {
+			return errors.New("smtp host is required for the smtp notifier")
+		}
+		if len(c.SMTP.From) == 0 {
+			return errors.New("smtp from address is required for the smtp notifier")
+		}
+		if c.SMTP.Port < 1 || c.SMTP.Port > 65535 {
+			return errors.New("smtp port is invalid")
+		}
+	default:
+		return fmt.Errorf("unknown notifier type '%s'", c.Type)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+)
+
+const (
+	DefaultOIDCStateTTL time.Duration = 10 * time.Minute
+	OpenIDScope         string        = "openid"
+)
+
+var (
+	DefaultOIDCScopes = []string{OpenIDScope, "profile", "email"}
+	oidcProviderName  = regexp.MustCompile(`^[a-z0-9_-]+$`)
+)
+
+// OIDCProviderConfiguration describes an OpenID Connect provider players can
+// log in with. Name appears in the login and callback paths; RedirectURL must
+// point at the callback path of this provider and be registered with it.
+type OIDCProviderConfiguration struct {
+	Name         string   `yaml:"name"`
+	Issuer       string   `yaml:"issuer"`
+	ClientID     string   `yaml:"clientId"`
+	ClientSecret string   `yaml:"clientSecret,omitempty"`
+	RedirectURL  string   `yaml:"redirectUrl"`
+	Scopes       []string `yaml:"scopes,omitempty"`
+}
+
+// OIDCConfiguration lists the providers available for single sign-on.
+// StateTTL bounds the time between starting a login and its callback.
+type OIDCConfiguration struct {
+	StateTTL  time.Duration               `yaml:"stateTtl,omitempty"`
+	Providers []OIDCProviderConfiguration `yaml:"providers,omitempty"`
+}
+
+func (c *OIDCConfiguration) SetDefaults() {
+	if c.StateTTL == 0 {
+		c.StateTTL = DefaultOIDCStateTTL
+	}
+
+	for i := range c.Providers {
+		if len(c.Providers[i].Scopes) == 0 {
+			c.Providers[i].Scopes = DefaultOIDCScopes
+		}
+	}
+}
+
+func (c *OIDCConfiguration) Validate() error {
+	if c.StateTTL < 0 {
+		return errors.New("oidc state ttl is invalid")
+	}
+
+	names := map[string]bool{}
+	for _, provider := range c.Providers {
+		if !oidcProviderName.MatchString(provider.Name) {
+			return fmt.Errorf("oidc provider name '%s' is invalid", provider.Name)
+		}
+		if names[provider.Name] {
+			return fmt.Errorf("oidc provider '%s' is duplicated", provider.Name)
+		}
+		names[provider.Name] = true
+
+		if len(provider.Issuer) == 0 || len(provider.ClientID) == 0 || len(provider.RedirectURL) == 0 {
+			return fmt.Errorf("oidc provider '%s' requires issuer, client id and redirect url", provider.Name)
+		}
+		if !slices.Contains(provider.Scopes, OpenIDScope) {
+			return fmt.Errorf("oidc provider '%s' scopes must include '%s'", provider.Name, OpenIDScope)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+const DefaultPasswordResetTokenTTL time.Duration = 30 * time.Minute
+
+// PasswordResetConfiguration controls the password reset flow. URL is the
+// page of the client that completes the reset; the token is appended to it,
+// so it usually ends with a query parameter such as "?token=". Without a URL
+// the message carries the bare token.
+type PasswordResetConfiguration struct {
+	TokenTTL time.Duration `yaml:"tokenTtl,omitempty"`
+	URL      string        `yaml:"url,omitempty"`
+}
+
+func (c *PasswordResetConfiguration) SetDefaults() {
+	if c.TokenTTL == 0 {
+		c.TokenTTL = DefaultPasswordResetTokenTTL
+	}
+}
+
+func (c *PasswordResetConfiguration) Validate() error {
+	if c.TokenTTL < 0 {
+		return errors.New("password reset token ttl is invalid")
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	DefaultPresenceAwayAfter     time.Duration = 2 * time.Minute
+	DefaultPresenceOfflineAfter  time.Duration = 5 * time.Minute
+	DefaultPresenceTouchInterval time.Duration = 30 * time.Second
+)
+
+// PresenceConfiguration controls online presence. A player without activity
+// for AwayAfter is away and for OfflineAfter is offline. Activity is written
+// at most once per TouchInterval for each player, so TouchInterval should be
+// well below AwayAfter.
+type PresenceConfiguration struct {
+	AwayAfter     time.Duration `yaml:"awayAfter,omitempty"`
+	OfflineAfter  time.Duration `yaml:"offlineAfter,omitempty"`
+	TouchInterval time.Duration `yaml:"touchInterval,omitempty"`
+}
+
+func (c *PresenceConfiguration) SetDefaults() {
+	if c.AwayAfter == 0 {
+		c.AwayAfter = DefaultPresenceAwayAfter
+	}
+	if c.OfflineAfter == 0 {
+		c.OfflineAfter = DefaultPresenceOfflineAfter
+	}
+	if c.TouchInterval == 0 {
+		c.TouchInterval = DefaultPresenceTouchInterval
+	}
+}
+
+func (c *PresenceConfiguration) Validate() error {
+	if c.AwayAfter < 0 || c.OfflineAfter < c.AwayAfter {
+		return errors.New("presence timeouts are invalid")
+	}
+
+	if c.TouchInterval < 0 || c.TouchInterval >= c.AwayAfter {
+		return errors.New("presence touch interval is invalid")
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type RateLimitStore string
+
+const (
+	NoneRateLimitStore   RateLimitStore = "none"
+	MemoryRateLimitStore RateLimitStore = "memory"
+)
+
+var (
+	DefaultLoginRateLimit = RateLimitPolicyConfiguration{Requests: RateLimitRequests(5), Period: time.Minute, Burst: 5}
+	DefaultMoveRateLimit  = RateLimitPolicyConfiguration{Requests: RateLimitRequests(2), Period: time.Second, Burst: 5}
+	DefaultAPIRateLimit   = RateLimitPolicyConfiguration{Requests: RateLimitRequests(10), Period: time.Second, Burst: 20}
+)
+
+// RateLimitPolicyConfiguration allows Requests per Period. Requests set to 0
+// disables the policy; left out, it takes the default of the policy.
+type RateLimitPolicyConfiguration struct {
+	Requests *int          `yaml:"requests,omitempty"`
+	Period   time.Duration `yaml:"period,omitempty"`
+	Burst    int           `yaml:"burst,omitempty"`
+}
+
+func RateLimitRequests(requests int) *int {
+	return &requests
+}
+
+func (c *RateLimitPolicyConfiguration) SetDefaults(defaults RateLimitPolicyConfiguration) {
+	if c.Requests == nil {
+		c.Requests = RateLimitRequests(*defaults.Requests)
+	}
+	if c.Period == 0 {
+		c.Period = defaults.Period
+	}
+	if c.Burst == 0 {
+		c.Burst = *c.Requests
+	}
+}
+
+func (c *RateLimitPolicyConfiguration) Validate(name string) error {
+	if (c.Requests != nil && *c.Requests < 0) || c.Period < 0 || c.Burst < 0 {
+		return fmt.Errorf("%s rate limit is invalid", name)
+	}
+
+	return nil
+}
+
+type RateLimitConfiguration struct {
+	Store RateLimitStore               `yaml:"store,omitempty"`
+	Login RateLimitPolicyConfiguration `yaml:"login"`
+	Move  RateLimitPolicyConfiguration `yaml:"move"`
+	API   RateLimitPolicyConfiguration `yaml:"api"`
+}
+
+func (c *RateLimitConfiguration) SetDefaults() {
+	if len(c.Store) == 0 {
+		c.Store = MemoryRateLimitStore
+	}
+
+	c.Login.SetDefaults(DefaultLoginRateLimit)
+	c.Move.SetDefaults(DefaultMoveRateLimit)
+	c.API.SetDefaults(DefaultAPIRateLimit)
+}
+
+func (c *RateLimitConfiguration) Validate() error {
+	switch c.Store {
+	case NoneRateLimitStore, MemoryRateLimitStore:
+	default:
+		return fmt.Errorf("unknown rate limit store '%s'", c.Store)
+	}
+
+	if err := c.Login.Validate("login"); err != nil {
+		return err
+	}
+
+	if err := c.Move.Validate("move"); err != nil {
+		return err
+	}
+
+	if err := c.API.Validate("api"); err != nil {
+		return err
+	}
+
+	return nil
+}
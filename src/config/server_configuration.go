@@ -1,12 +1,30 @@
 package config
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
 
+const (
+	DefaultShutdownDelay time.Duration = 5 * time.Second
+)
+
+// ServerConfiguration lists the TrustedProxies, addresses or CIDR ranges,
+// whose X-Forwarded-For header is taken as the client address. No proxy is
+// trusted by default, so clients can not choose the address the rate limits
+// and the lockout are keyed by.
 type ServerConfiguration struct {
-	Port int `yaml:"port,omitempty"`
+	Port           int           `yaml:"port,omitempty"`
+	ShutdownDelay  time.Duration `yaml:"shutdownDelay,omitempty"`
+	TrustedProxies []string      `yaml:"trustedProxies,omitempty"`
 }
 
 func (c *ServerConfiguration) SetDefaults() {
+	if c.ShutdownDelay == 0 {
+		c.ShutdownDelay = DefaultShutdownDelay
+	}
 }
 
 func (c *ServerConfiguration) Validate() error {
@@ -14,5 +32,17 @@ func (c *ServerConfiguration) Validate() error {
 		return errors.New("application port is invalid")
 	}
 
+	if c.ShutdownDelay < 0 {
+		return errors.New("shutdown delay is invalid")
+	}
+
+	for _, proxy := range c.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return fmt.Errorf("trusted proxy '%s' is invalid", proxy)
+			}
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+type SigningAlgorithm string
+
+const (
+	HS256SigningAlgorithm SigningAlgorithm = "HS256"
+	RS256SigningAlgorithm SigningAlgorithm = "RS256"
+	EdDSASigningAlgorithm SigningAlgorithm = "EdDSA"
+)
+
+// SigningKeyConfiguration describes a token signing key. A key with a private
+// key file can sign and verify; a key with only a public key file is kept to
+// verify tokens issued before it was rotated out. With both files the public
+// key has to match the private key.
+type SigningKeyConfiguration struct {
+	ID             string           `yaml:"id"`
+	Algorithm      SigningAlgorithm `yaml:"algorithm"`
+	PrivateKeyFile string           `yaml:"privateKeyFile,omitempty"`
+	PublicKeyFile  string           `yaml:"publicKeyFile,omitempty"`
+}
+
+// SigningConfiguration lists the keys used for access tokens. When no keys
+// are configured tokens are signed with HS256 and the application secret.
+type SigningConfiguration struct {
+	ActiveKey string                    `yaml:"activeKey,omitempty"`
+	Keys      []SigningKeyConfiguration `yaml:"keys,omitempty"`
+}
+
+func (c *SigningConfiguration) SetDefaults() {
+	if len(c.ActiveKey) == 0 && len(c.Keys) == 1 {
+		c.ActiveKey = c.Keys[0].ID
+	}
+}
+
+func (c *SigningConfiguration) Validate() error {
+	if len(c.Keys) == 0 {
+		return nil
+	}
+
+	ids := map[string]bool{}
+	for _, key := range c.Keys {
+		if len(key.ID) == 0 {
+			return errors.New("signing key id is required")
+		}
+		if ids[key.ID] {
+			return fmt.Errorf("signing key '%s' is duplicated", key.ID)
+		}
+		ids[key.ID] = true
+
+		switch key.Algorithm {
+		case RS256SigningAlgorithm, EdDSASigningAlgorithm:
+		default:
+			return fmt.Errorf("signing key '%s' has unsupported algorithm '%s'", key.ID, key.Algorithm)
+		}
+
+		if len(key.PrivateKeyFile) == 0 && len(key.PublicKeyFile) == 0 {
+			return fmt.Errorf("signing key '%s' requires a private or public key file", key.ID)
+		}
+
+		if key.ID == c.ActiveKey && len(key.PrivateKeyFile) == 0 {
+			return fmt.Errorf("active signing key '%s' requires a private key file", key.ID)
+		}
+	}
+
+	if !ids[c.ActiveKey] {
+		return fmt.Errorf("active signing key '%s' is not configured", c.ActiveKey)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+type TracingExporter string
+
+const (
+	NoneTracingExporter   TracingExporter = "none"
+	StdoutTracingExporter TracingExporter = "stdout"
+	OTLPTracingExporter   TracingExporter = "otlp"
+	DefaultSampleRatio    float64         = 1
+)
+
+// TracingConfiguration selects where spans are exported. SampleRatio is the
+// share of new traces that are sampled; 0 samples none and, left out, all are
+// sampled.
+type TracingConfiguration struct {
+	Exporter    TracingExporter `yaml:"exporter,omitempty"`
+	Endpoint    string          `yaml:"endpoint,omitempty"`
+	Insecure    bool            `yaml:"insecure,omitempty"`
+	SampleRatio *float64        `yaml:"sampleRatio,omitempty"`
+}
+
+func TracingSampleRatio(ratio float64) *float64 {
+	return &ratio
+}
+
+func (c *TracingConfiguration) SetDefaults() {
+	if len(c.Exporter) == 0 {
+		c.Exporter = NoneTracingExporter
+	}
+	if c.SampleRatio == nil {
+		c.SampleRatio = TracingSampleRatio(DefaultSampleRatio)
+	}
+}
+
+func (c *TracingConfiguration) Validate() error {
+	switch c.Exporter {
+	case NoneTracingExporter, StdoutTracingExporter:
+	case OTLPTracingExporter:
+		if len(c.Endpoint) == 0 {
+			return errors.New("tracing endpoint is required for the otlp exporter")
+		}
+	default:
+		return fmt.Errorf("unknown tracing exporter '%s'", c.Exporter)
+	}
+
+	if c.SampleRatio == nil || *c.SampleRatio < 0 || *c.SampleRatio > 1 {
+		return errors.New("tracing sample ratio must be between 0 and 1")
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	DefaultTwoFactorChallengeTTL  time.Duration = 5 * time.Minute
+	DefaultTwoFactorRecoveryCodes int           = 10
+)
+
+// TwoFactorConfiguration controls TOTP two-factor authentication. Issuer is
+// shown next to the account in authenticator apps and defaults to the
+// application name. ChallengeTTL is how long a player has to enter the code
+// after the password was accepted.
+type TwoFactorConfiguration struct {
+	Issuer        string        `yaml:"issuer,omitempty"`
+	ChallengeTTL  time.Duration `yaml:"challengeTtl,omitempty"`
+	RecoveryCodes int           `yaml:"recoveryCodes,omitempty"`
+}
+
+func (c *TwoFactorConfiguration) SetDefaults(appName string) {
+	if len(c.Issuer) == 0 {
+		c.Issuer = appName
+	}
+	if c.ChallengeTTL == 0 {
+		c.ChallengeTTL = DefaultTwoFactorChallengeTTL
+	}
+	if c.RecoveryCodes == 0 {
+		c.RecoveryCodes = DefaultTwoFactorRecoveryCodes
+	}
+}
+
+func (c *TwoFactorConfiguration) Validate() error {
+	if c.ChallengeTTL < 0 {
+		return errors.New("two-factor challenge ttl is invalid")
+	}
+
+	if c.RecoveryCodes < 0 {
+		return errors.New("two-factor recovery codes count is invalid")
+	}
+
+	return nil
+}
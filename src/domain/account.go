@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"github.com/plamen-v/tic-tac-toe-models/models"
+)
+
+type Role string
+
+const (
+	RolePlayer    Role = "player"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+var roleRanks = map[Role]int{
+	RolePlayer:    0,
+	RoleModerator: 1,
+	RoleAdmin:     2,
+}
+
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Includes reports whether r grants at least the rights of other. Roles are
+// ordered player < moderator < admin.
+func (r Role) Includes(other Role) bool {
+	rank, ok := roleRanks[r]
+	return ok && rank >= roleRanks[other]
+}
+
+// Account is a player together with the access control state that the
+// shared models do not carry.
+type Account struct {
+	models.Player
+	Role     Role `json:"role"`
+	Disabled bool `json:"disabled"`
+}
+
+type AccountListResponse struct {
+	Players  []*Account      `json:"players"`
+	PageInfo models.PageInfo `json:"pageInfo"`
+}
+
+type UpdateRoleRequest struct {
+	Role Role `json:"role"`
+}
+
+type UpdateDisabledRequest struct {
+	Disabled bool `json:"disabled"`
+}
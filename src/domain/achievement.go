@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type AchievementCode string
+
+const (
+	AchievementFirstWin          AchievementCode = "first_win"
+	AchievementWinStreak         AchievementCode = "win_streak"
+	AchievementSecondMoverWin    AchievementCode = "second_mover_win"
+	AchievementQuickestWin       AchievementCode = "quickest_win"
+	AchievementPerfectRoundRobin AchievementCode = "perfect_round_robin"
+)
+
+type Achievement struct {
+	PlayerID   uuid.UUID       `json:"-"`
+	Code       AchievementCode `json:"code"`
+	UnlockedAt time.Time       `json:"unlockedAt"`
+}
+
+// Streak counts consecutive wins. A loss or a draw ends the current streak.
+type Streak struct {
+	Current int `json:"current"`
+	Longest int `json:"longest"`
+}
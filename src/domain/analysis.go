@@ -0,0 +1,45 @@
+package domain
+
+import "github.com/gofrs/uuid"
+
+// Move is a mark placed on the board. Position is 1-based like in the move
+// endpoint.
+type Move struct {
+	Seq      int       `json:"seq"`
+	PlayerID uuid.UUID `json:"playerId"`
+	Position int       `json:"position"`
+	Mark     string    `json:"mark"`
+}
+
+// Evaluation is the result of a position under perfect play, seen from the
+// player to move.
+type Evaluation int
+
+const (
+	EvaluationLoss Evaluation = -1
+	EvaluationDraw Evaluation = 0
+	EvaluationWin  Evaluation = 1
+)
+
+// AnalyzedMove compares a move with perfect play. Both evaluations are seen
+// from the player who made the move. A blunder turns a position that was won
+// or drawn into a lost one.
+type AnalyzedMove struct {
+	Move
+	EvaluationBefore Evaluation `json:"evaluationBefore"`
+	EvaluationAfter  Evaluation `json:"evaluationAfter"`
+	BestMoves        []int      `json:"bestMoves"`
+	Blunder          bool       `json:"blunder"`
+}
+
+type GameAnalysis struct {
+	GameID uuid.UUID       `json:"gameId"`
+	Moves  []*AnalyzedMove `json:"moves"`
+}
+
+// Hint is the recommended move for the player in turn.
+type Hint struct {
+	Position   int        `json:"position"`
+	Evaluation Evaluation `json:"evaluation"`
+	HintsUsed  int        `json:"hintsUsed"`
+}
@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Identity links an account at an external OpenID Connect provider to a
+// player.
+type Identity struct {
+	Issuer    string
+	Subject   string
+	PlayerID  uuid.UUID
+	Email     string
+	CreatedAt time.Time
+}
+
+type OIDCProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
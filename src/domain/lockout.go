@@ -0,0 +1,39 @@
+// Package domain holds the entities and API payloads that the shared
+// tic-tac-toe-models module does not define.
+package domain
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type LoginAttempt struct {
+	Login       string
+	PlayerID    *uuid.UUID
+	IP          string
+	Succeeded   bool
+	AttemptedAt time.Time
+}
+
+type Lockout struct {
+	PlayerID       uuid.UUID  `json:"playerId"`
+	Login          string     `json:"login"`
+	FailedAttempts int        `json:"failedAttempts"`
+	LockoutCount   int        `json:"lockoutCount"`
+	LockedUntil    *time.Time `json:"lockedUntil"`
+	LastFailedAt   *time.Time `json:"lastFailedAt"`
+}
+
+func (l *Lockout) IsLocked(now time.Time) bool {
+	return l.LockedUntil != nil && now.Before(*l.LockedUntil)
+}
+
+type LockoutResponse struct {
+	Lockout *Lockout `json:"lockout"`
+	Locked  bool     `json:"locked"`
+}
+
+type LockoutListResponse struct {
+	Lockouts []*Lockout `json:"lockouts"`
+}
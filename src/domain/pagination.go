@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"github.com/plamen-v/tic-tac-toe-models/models"
+)
+
+// PageInfo adds the cursor of the next page to models.PageInfo. Lists read
+// with a cursor leave Page and TotalCnt zero; NextCursor is empty on the last
+// page.
+type PageInfo struct {
+	models.PageInfo
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+type RankingResponse struct {
+	Players  []*models.Player `json:"players"`
+	PageInfo PageInfo         `json:"pageInfo"`
+}
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// PasswordResetToken is a single-use token sent to the player to set a new
+// password. Only the SHA-256 hash of the token is stored.
+type PasswordResetToken struct {
+	TokenHash string
+	PlayerID  uuid.UUID
+	ExpiresAt time.Time
+}
+
+type PasswordResetRequest struct {
+	Login string `json:"login"`
+}
+
+type PasswordResetConfirmRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"newPassword"`
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type PresenceStatus string
+
+const (
+	// PresenceOnline is an active player outside of any room.
+	PresenceOnline PresenceStatus = "online"
+	// PresenceInLobby is an active player waiting in a room.
+	PresenceInLobby PresenceStatus = "in_lobby"
+	// PresenceInGame is a player with a game in progress.
+	PresenceInGame  PresenceStatus = "in_game"
+	PresenceAway    PresenceStatus = "away"
+	PresenceOffline PresenceStatus = "offline"
+)
+
+type Presence struct {
+	Status     PresenceStatus `json:"status"`
+	LastSeenAt *time.Time     `json:"lastSeenAt,omitempty"`
+}
+
+// PlayerActivity is what the presence of a player is derived from.
+type PlayerActivity struct {
+	PlayerID   uuid.UUID
+	LastSeenAt *time.Time
+	InRoom     bool
+	InGame     bool
+}
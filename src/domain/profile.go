@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+)
+
+const DefaultRating int = 1200
+
+type GameResult string
+
+const (
+	GameResultWin  GameResult = "win"
+	GameResultLoss GameResult = "loss"
+	GameResultDraw GameResult = "draw"
+)
+
+// GameSummary is a finished game seen from one of its players.
+type GameSummary struct {
+	GameID           uuid.UUID  `json:"gameId"`
+	OpponentID       uuid.UUID  `json:"opponentId"`
+	OpponentNickname string     `json:"opponentNickname"`
+	Result           GameResult `json:"result"`
+	RatingChange     int        `json:"ratingChange"`
+	FinishedAt       time.Time  `json:"finishedAt"`
+}
+
+// Profile is the public view of a player.
+type Profile struct {
+	ID           uuid.UUID          `json:"id"`
+	Nickname     string             `json:"nickname"`
+	Stats        models.PlayerStats `json:"stats"`
+	Rating       int                `json:"rating"`
+	JoinedAt     time.Time          `json:"joinedAt"`
+	Presence     *Presence          `json:"presence,omitempty"`
+	Streak       Streak             `json:"streak"`
+	Achievements []*Achievement     `json:"achievements"`
+	RecentGames  []*GameSummary     `json:"recentGames"`
+}
+
+// OwnProfile is the profile as seen by the player it belongs to.
+type OwnProfile struct {
+	Profile
+	Login string `json:"login"`
+	Email string `json:"email,omitempty"`
+	Role  Role   `json:"role"`
+}
+
+// UpdateProfileRequest changes the fields that are set. Changing the
+// password or the email requires the current password; an empty email
+// removes it.
+type UpdateProfileRequest struct {
+	Nickname        *string `json:"nickname,omitempty"`
+	Email           *string `json:"email,omitempty"`
+	CurrentPassword string  `json:"currentPassword,omitempty"`
+	NewPassword     *string `json:"newPassword,omitempty"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
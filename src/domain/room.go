@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+)
+
+// MarkPolicy decides which mark each player of a game gets.
+type MarkPolicy string
+
+const (
+	// MarkPolicyRandom assigns the marks at random when a pairing starts and
+	// keeps them for its rematches.
+	MarkPolicyRandom MarkPolicy = "random"
+	// MarkPolicyHostX always gives X to the host.
+	MarkPolicyHostX MarkPolicy = "host_x"
+)
+
+// FirstMovePolicy decides which player of a game moves first.
+type FirstMovePolicy string
+
+const (
+	// FirstMovePolicyAlternate picks the first player of a pairing at random
+	// and alternates the first player in its rematches.
+	FirstMovePolicyAlternate FirstMovePolicy = "alternate"
+	// FirstMovePolicyCoinFlip picks the first player of every game at random.
+	FirstMovePolicyCoinFlip FirstMovePolicy = "coin_flip"
+	// FirstMovePolicyXFirst lets the player with X move first.
+	FirstMovePolicyXFirst FirstMovePolicy = "x_first"
+	// FirstMovePolicyLoserStarts lets the loser of the previous game of the
+	// pairing move first. It alternates after a draw.
+	FirstMovePolicyLoserStarts FirstMovePolicy = "loser_starts"
+)
+
+// TimeControl is the pace the host sets for the games of a room.
+type TimeControl string
+
+const (
+	// TimeControlUntimed sets no pace.
+	TimeControlUntimed TimeControl = "untimed"
+	// TimeControlBlitz is for games of about ten seconds per move.
+	TimeControlBlitz TimeControl = "blitz"
+	// TimeControlRapid is for games of about a minute per move.
+	TimeControlRapid TimeControl = "rapid"
+)
+
+// RoomSettings are chosen by the host when the room is created. Games in
+// unranked rooms do not change the stats or the ratings of the players and
+// allow hints.
+type RoomSettings struct {
+	Ranked          bool            `json:"ranked"`
+	Variant         GameVariant     `json:"variant"`
+	RuleSet         RuleSet         `json:"ruleSet"`
+	MarkPolicy      MarkPolicy      `json:"markPolicy"`
+	FirstMovePolicy FirstMovePolicy `json:"firstMovePolicy"`
+	TimeControl     TimeControl     `json:"timeControl"`
+}
+
+func DefaultRoomSettings() *RoomSettings {
+	return &RoomSettings{
+		Ranked:          true,
+		Variant:         GameVariantClassic,
+		RuleSet:         RuleSetStandard,
+		MarkPolicy:      MarkPolicyRandom,
+		FirstMovePolicy: FirstMovePolicyAlternate,
+		TimeControl:     TimeControlUntimed,
+	}
+}
+
+// CreateRoomRequest extends the room request with the room settings. Omitted
+// settings keep their defaults.
+type CreateRoomRequest struct {
+	models.CreateRoomRequest
+	Ranked          *bool            `json:"ranked,omitempty"`
+	Variant         *GameVariant     `json:"variant,omitempty"`
+	RuleSet         *RuleSet         `json:"ruleSet,omitempty"`
+	MarkPolicy      *MarkPolicy      `json:"markPolicy,omitempty"`
+	FirstMovePolicy *FirstMovePolicy `json:"firstMovePolicy,omitempty"`
+	TimeControl     *TimeControl     `json:"timeControl,omitempty"`
+}
+
+type RoomSort string
+
+const (
+	RoomSortNewest RoomSort = "newest"
+	// RoomSortRating lists the rooms of the strongest hosts first.
+	RoomSortRating RoomSort = "rating"
+)
+
+// RoomFilter narrows the list of open rooms. Title and HostNickname match
+// any part of the value regardless of case; empty fields do not filter.
+type RoomFilter struct {
+	Title        string
+	HostNickname string
+	MinRating    *int
+	MaxRating    *int
+	Variant      GameVariant
+	TimeControl  TimeControl
+	Sort         RoomSort
+}
+
+// RoomListResponse is the list of open rooms with the presence of the players
+// in them.
+type RoomListResponse struct {
+	Rooms    []*models.Room          `json:"rooms"`
+	PageInfo PageInfo                `json:"pageInfo"`
+	Presence map[uuid.UUID]*Presence `json:"presence"`
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+)
+
+// SeasonRatingCarryOver is the part of the distance from DefaultRating that
+// the rating of a player keeps from one season into the next.
+const SeasonRatingCarryOver float64 = 0.5
+
+// Season is a period with its own stats and ratings. Seasons do not overlap;
+// an archived season has its final standings stored.
+type Season struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	StartsAt   time.Time  `json:"startsAt"`
+	EndsAt     time.Time  `json:"endsAt"`
+	ArchivedAt *time.Time `json:"archivedAt,omitempty"`
+}
+
+type CreateSeasonRequest struct {
+	Name     string    `json:"name"`
+	StartsAt time.Time `json:"startsAt"`
+	EndsAt   time.Time `json:"endsAt"`
+}
+
+type SeasonsResponse struct {
+	Seasons []*Season `json:"seasons"`
+}
+
+// Standing is the place of a player in a season or a leaderboard. Rating is
+// the season rating; in a leaderboard it is the rating change in the period.
+type Standing struct {
+	Rank     int                `json:"rank"`
+	PlayerID uuid.UUID          `json:"playerId"`
+	Nickname string             `json:"nickname"`
+	Stats    models.PlayerStats `json:"stats"`
+	Rating   int                `json:"rating"`
+}
+
+type StandingsResponse struct {
+	Season    *Season         `json:"season"`
+	Standings []*Standing     `json:"standings"`
+	PageInfo  models.PageInfo `json:"pageInfo"`
+}
+
+type LeaderboardPeriod string
+
+const (
+	LeaderboardDaily   LeaderboardPeriod = "daily"
+	LeaderboardWeekly  LeaderboardPeriod = "weekly"
+	LeaderboardMonthly LeaderboardPeriod = "monthly"
+)
+
+type LeaderboardResponse struct {
+	Period    LeaderboardPeriod `json:"period"`
+	From      time.Time         `json:"from"`
+	Standings []*Standing       `json:"standings"`
+	PageInfo  models.PageInfo   `json:"pageInfo"`
+}
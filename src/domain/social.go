@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type FriendStatus string
+
+const (
+	FriendStatusAccepted FriendStatus = "accepted"
+	// FriendStatusIncoming is a request the player received and can accept.
+	FriendStatusIncoming FriendStatus = "incoming"
+	// FriendStatusOutgoing is a request the player sent.
+	FriendStatusOutgoing FriendStatus = "outgoing"
+)
+
+type Friendship struct {
+	RequesterID uuid.UUID
+	AddresseeID uuid.UUID
+	Accepted    bool
+}
+
+// Friend is another player seen from the friends list of a player.
+type Friend struct {
+	ID       uuid.UUID    `json:"id"`
+	Nickname string       `json:"nickname"`
+	Status   FriendStatus `json:"status"`
+	Since    time.Time    `json:"since"`
+	Presence *Presence    `json:"presence,omitempty"`
+}
+
+type FriendsResponse struct {
+	Friends []*Friend `json:"friends"`
+}
+
+type BlockedPlayer struct {
+	ID        uuid.UUID `json:"id"`
+	Nickname  string    `json:"nickname"`
+	BlockedAt time.Time `json:"blockedAt"`
+}
+
+type BlockedPlayersResponse struct {
+	Players []*BlockedPlayer `json:"players"`
+}
+
+// Invitation makes a room private to the invited player.
+type Invitation struct {
+	RoomID       uuid.UUID `json:"roomId"`
+	PlayerID     uuid.UUID `json:"playerId"`
+	HostID       uuid.UUID `json:"hostId"`
+	HostNickname string    `json:"hostNickname"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type InvitationsResponse struct {
+	Invitations []*Invitation `json:"invitations"`
+}
+
+type InviteRequest struct {
+	PlayerID    uuid.UUID `json:"playerId"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+}
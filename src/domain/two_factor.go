@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+)
+
+// TwoFactor is the TOTP enrolment of a player. It is pending until the
+// player confirms it with a first valid code. LastUsedStep is the most recent
+// time step a code was accepted for; codes are never accepted twice.
+type TwoFactor struct {
+	PlayerID     uuid.UUID
+	Secret       string
+	Enabled      bool
+	LastUsedStep int64
+	ConfirmedAt  *time.Time
+}
+
+type TwoFactorStatus struct {
+	Enabled           bool `json:"enabled"`
+	Pending           bool `json:"pending"`
+	RecoveryCodesLeft int  `json:"recoveryCodesLeft"`
+}
+
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`
+}
+
+// LoginResponse is models.LoginResponse extended with the second login step.
+// When TwoFactorRequired is set only ChallengeToken is filled in and has to
+// be exchanged together with a code for the access token.
+type LoginResponse struct {
+	Player            *models.Player `json:"player,omitempty"`
+	Token             string         `json:"token,omitempty"`
+	TwoFactorRequired bool           `json:"twoFactorRequired,omitempty"`
+	ChallengeToken    string         `json:"challengeToken,omitempty"`
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+)
+
+type GameVariant string
+
+const (
+	GameVariantClassic GameVariant = "classic"
+	// GameVariantUltimate is played on a meta-board of nine classic boards.
+	// The cell a player marks sends the opponent to the sub-board at the same
+	// place of the meta-board; winning three sub-boards in a line wins.
+	GameVariantUltimate GameVariant = "ultimate"
+	// GameVariantConnectFour is Connect Four on a board of seven columns and
+	// six rows: the marks drop to the lowest free cell of the column and four
+	// in a row win.
+	GameVariantConnectFour GameVariant = "connect_four"
+)
+
+// RuleSet decides which moves are legal and who wins a game on the classic
+// board.
+type RuleSet string
+
+const (
+	RuleSetStandard RuleSet = "standard"
+	// RuleSetMisere loses the game for the player who completes a line.
+	RuleSetMisere RuleSet = "misere"
+	// RuleSetWild lets the players place either mark on each move; a line of
+	// either mark wins for the player who completes it.
+	RuleSetWild RuleSet = "wild"
+	// RuleSetNotakto has both players place X; completing a line loses.
+	RuleSetNotakto RuleSet = "notakto"
+)
+
+// VariantState is the state of a game that is kept besides its board. It
+// records the policies the marks and the first player were chosen by.
+type VariantState struct {
+	Variant GameVariant `json:"variant"`
+	RuleSet RuleSet     `json:"ruleSet"`
+	// NextBoard is the 1-based sub-board of an Ultimate game that the player
+	// in turn has to play in; nil allows any sub-board that is still open.
+	NextBoard       *int            `json:"nextBoard,omitempty"`
+	MarkPolicy      MarkPolicy      `json:"markPolicy"`
+	FirstMovePolicy FirstMovePolicy `json:"firstMovePolicy"`
+	// FirstPlayerID is nil for games that were started before it was
+	// recorded.
+	FirstPlayerID *uuid.UUID `json:"firstPlayerId,omitempty"`
+	// Ranked is whether the game counts for the stats and the ratings, as
+	// the room was set when the game started.
+	Ranked bool `json:"ranked"`
+}
+
+// GameState is a game with its variant state and the positions the player in
+// turn may play. MetaBoard holds the result of each sub-board of an Ultimate
+// game: the mark of its winner, a draw mark or an empty tile while it is
+// open.
+type GameState struct {
+	*models.Game
+	VariantState
+	LegalMoves []int  `json:"legalMoves,omitempty"`
+	MetaBoard  string `json:"metaBoard,omitempty"`
+}
+
+type GameResponse struct {
+	Game *GameState `json:"game"`
+}
@@ -14,9 +14,24 @@ import (
 	"github.com/plamen-v/tic-tac-toe/src/app"
 	"github.com/plamen-v/tic-tac-toe/src/config"
 	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/services/admin"
+	"github.com/plamen-v/tic-tac-toe/src/services/analysis"
 	"github.com/plamen-v/tic-tac-toe/src/services/auth"
 	"github.com/plamen-v/tic-tac-toe/src/services/engine"
+	"github.com/plamen-v/tic-tac-toe/src/services/health"
+	"github.com/plamen-v/tic-tac-toe/src/services/lockout"
 	"github.com/plamen-v/tic-tac-toe/src/services/logger"
+	"github.com/plamen-v/tic-tac-toe/src/services/metrics"
+	"github.com/plamen-v/tic-tac-toe/src/services/notifier"
+	"github.com/plamen-v/tic-tac-toe/src/services/oidc"
+	"github.com/plamen-v/tic-tac-toe/src/services/passwordreset"
+	"github.com/plamen-v/tic-tac-toe/src/services/presence"
+	"github.com/plamen-v/tic-tac-toe/src/services/profile"
+	"github.com/plamen-v/tic-tac-toe/src/services/ratelimit"
+	"github.com/plamen-v/tic-tac-toe/src/services/season"
+	"github.com/plamen-v/tic-tac-toe/src/services/social"
+	"github.com/plamen-v/tic-tac-toe/src/services/tracing"
+	"github.com/plamen-v/tic-tac-toe/src/services/twofactor"
 )
 
 func main() {
@@ -38,6 +53,19 @@ func main() {
 		}
 	}()
 
+	tracingService, err := tracing.NewTracingService(config.AppName, config.Tracing)
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := tracingService.Shutdown(ctx)
+		if err != nil {
+			logger.Error(err.Error())
+		}
+	}()
+
 	source := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
 		config.Database.Host, config.Database.Port, config.Database.User, config.Database.Password, config.Database.Database)
 	db, err := sql.Open("postgres", source)
@@ -55,15 +83,105 @@ func main() {
 		}
 	}()
 
+	metricsService := metrics.NewMetricsService(db)
+
+	rateLimitService, err := ratelimit.NewRateLimitService(config.RateLimit)
+	if err != nil {
+		panic(err)
+	}
+
+	keys, err := auth.NewKeySet(config.Signing, config.Secret)
+	if err != nil {
+		panic(err)
+	}
+
+	lockoutService := lockout.NewLockoutService(config.Lockout,
+		db,
+		repository.NewLoginAttemptRepository,
+		repository.NewLockoutRepository,
+	)
+
+	twoFactorService := twofactor.NewTwoFactorService(config.TwoFactor,
+		db,
+		repository.NewPlayerRepository,
+		repository.NewTwoFactorRepository,
+	)
+
+	notifierService, err := notifier.NewNotifier(config.Notifier, logger)
+	if err != nil {
+		panic(err)
+	}
+
+	presenceService := presence.NewPresenceService(config.Presence,
+		db,
+		time.Now,
+		repository.NewPresenceRepository,
+	)
+
+	gameEngineService := engine.NewTracedGameEngineService(
+		engine.NewGameEngineService(db,
+			metricsService,
+			repository.NewPlayerRepository,
+			repository.NewGameRepository,
+			repository.NewRoomRepository,
+			repository.NewBlockRepository,
+			repository.NewInvitationRepository,
+			repository.NewSeasonRepository,
+			repository.NewAchievementRepository,
+		))
+
 	app := app.NewApplication(
 		config,
 		logger,
-		auth.NewAuthenticationService(config, db),
-		engine.NewGameEngineService(db,
+		metricsService,
+		health.NewHealthService(db, repository.NewSchemaRepository),
+		rateLimitService,
+		presenceService,
+		auth.NewAuthenticationService(config, keys, db, metricsService, lockoutService, twoFactorService),
+		lockoutService,
+		oidc.NewOIDCService(config.OIDC,
+			db,
+			repository.NewPlayerRepository,
+			repository.NewIdentityRepository,
+		),
+		twoFactorService,
+		admin.NewAdminService(db,
+			metricsService,
 			repository.NewPlayerRepository,
 			repository.NewGameRepository,
 			repository.NewRoomRepository,
-		))
+		),
+		profile.NewProfileService(db,
+			presenceService,
+			repository.NewPlayerRepository,
+			repository.NewGameRepository,
+			repository.NewRoomRepository,
+			repository.NewAchievementRepository,
+		),
+		passwordreset.NewPasswordResetService(config.PasswordReset,
+			db,
+			notifierService,
+			repository.NewPlayerRepository,
+			repository.NewPasswordResetRepository,
+		),
+		social.NewSocialService(db,
+			gameEngineService,
+			presenceService,
+			notifierService,
+			repository.NewPlayerRepository,
+			repository.NewFriendshipRepository,
+			repository.NewBlockRepository,
+		),
+		season.NewSeasonService(db,
+			time.Now,
+			repository.NewSeasonRepository,
+			repository.NewGameRepository,
+		),
+		analysis.NewAnalysisService(db,
+			repository.NewGameRepository,
+			repository.NewRoomRepository,
+		),
+		gameEngineService)
 
 	go func() {
 		if err = app.Start(); err != nil {
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+)
+
+type AchievementRepository interface {
+	RecordStreak(context.Context, uuid.UUID, bool) (*domain.Streak, error)
+	Unlock(context.Context, uuid.UUID, domain.AchievementCode) (bool, error)
+	GetByPlayer(context.Context, uuid.UUID) ([]*domain.Achievement, error)
+}
+
+func NewAchievementRepository(db Querier) AchievementRepository {
+	return &achievementRepositoryImpl{
+		db: newInstrumentedQuerier(db),
+	}
+}
+
+type achievementRepositoryImpl struct {
+	db Querier
+}
+
+// RecordStreak extends the win streak of the player after a win and ends it
+// otherwise.
+func (r *achievementRepositoryImpl) RecordStreak(ctx context.Context, playerID uuid.UUID, won bool) (*domain.Streak, error) {
+	sqlStr := `
+		UPDATE players_stats
+		SET current_streak = CASE WHEN $2 THEN current_streak + 1 ELSE 0 END,
+			longest_streak = CASE WHEN $2 THEN GREATEST(longest_streak, current_streak + 1) ELSE longest_streak END
+		WHERE player_id = $1
+		RETURNING current_streak, longest_streak`
+
+	streak := &domain.Streak{}
+	err := r.db.QueryRowContext(ctx, sqlStr, playerID, won).Scan(&streak.Current, &streak.Longest)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.NewNotFoundErrorf("player '%s' not exist", playerID.String())
+		}
+		return nil, models.NewGenericError(err.Error())
+	}
+
+	return streak, nil
+}
+
+// Unlock grants the achievement to the player and reports whether the player
+// did not have it yet.
+func (r *achievementRepositoryImpl) Unlock(ctx context.Context, playerID uuid.UUID, code domain.AchievementCode) (bool, error) {
+	sqlStr := `
+		INSERT INTO player_achievements(player_id, achievement)
+		VALUES($1, $2)
+		ON CONFLICT (player_id, achievement) DO NOTHING`
+
+	result, err := r.db.ExecContext(ctx, sqlStr, playerID, code)
+	if err != nil {
+		return false, models.NewGenericError(err.Error())
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// GetByPlayer returns the achievements of the player in the order they were
+// unlocked.
+func (r *achievementRepositoryImpl) GetByPlayer(ctx context.Context, playerID uuid.UUID) ([]*domain.Achievement, error) {
+	sqlStr := `
+		SELECT player_id, achievement, unlocked_at
+		FROM player_achievements
+		WHERE player_id = $1
+		ORDER BY unlocked_at, achievement`
+
+	rows, err := r.db.QueryContext(ctx, sqlStr, playerID)
+	if err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+	defer rows.Close()
+
+	achievements := []*domain.Achievement{}
+	for rows.Next() {
+		achievement := &domain.Achievement{}
+		err = rows.Scan(&achievement.PlayerID, &achievement.Code, &achievement.UnlockedAt)
+		if err != nil {
+			return nil, models.NewGenericError(err.Error())
+		}
+		achievements = append(achievements, achievement)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+
+	return achievements, nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+)
+
+type BlockRepository interface {
+	Create(context.Context, uuid.UUID, uuid.UUID) error
+	Delete(context.Context, uuid.UUID, uuid.UUID) error
+	GetByPlayer(context.Context, uuid.UUID) ([]*domain.BlockedPlayer, error)
+	Exists(context.Context, uuid.UUID, uuid.UUID) (bool, error)
+}
+
+func NewBlockRepository(db Querier) BlockRepository {
+	return &blockRepositoryImpl{
+		db: newInstrumentedQuerier(db),
+	}
+}
+
+type blockRepositoryImpl struct {
+	db Querier
+}
+
+func (r *blockRepositoryImpl) Create(ctx context.Context, playerID uuid.UUID, blockedID uuid.UUID) error {
+	sqlStr := `
+		INSERT INTO player_blocks(player_id, blocked_id)
+		VALUES($1, $2)
+		ON CONFLICT (player_id, blocked_id) DO NOTHING`
+
+	_, err := r.db.ExecContext(ctx, sqlStr, playerID, blockedID)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	return nil
+}
+
+func (r *blockRepositoryImpl) Delete(ctx context.Context, playerID uuid.UUID, blockedID uuid.UUID) error {
+	sqlStr := `
+		DELETE FROM player_blocks
+		WHERE player_id = $1 AND blocked_id = $2`
+
+	result, err := r.db.ExecContext(ctx, sqlStr, playerID, blockedID)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return models.NewNotFoundErrorf("player '%s' is not blocked", blockedID.String())
+	}
+
+	return nil
+}
+
+func (r *blockRepositoryImpl) GetByPlayer(ctx context.Context, playerID uuid.UUID) ([]*domain.BlockedPlayer, error) {
+	sqlStr := `
+		SELECT p.id, p.nickname, b.created_at
+		FROM player_blocks AS b
+		INNER JOIN players AS p ON p.id = b.blocked_id
+		WHERE b.player_id = $1
+		ORDER BY b.created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, sqlStr, playerID)
+	if err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+	defer rows.Close()
+
+	players := []*domain.BlockedPlayer{}
+	for rows.Next() {
+		player := &domain.BlockedPlayer{}
+		if err = rows.Scan(&player.ID, &player.Nickname, &player.BlockedAt); err != nil {
+			return nil, models.NewGenericError(err.Error())
+		}
+		players = append(players, player)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+
+	return players, nil
+}
+
+// Exists reports whether either of the two players blocked the other.
+func (r *blockRepositoryImpl) Exists(ctx context.Context, playerID uuid.UUID, otherID uuid.UUID) (bool, error) {
+	sqlStr := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM player_blocks
+			WHERE (player_id = $1 AND blocked_id = $2)
+				OR (player_id = $2 AND blocked_id = $1)
+		)`
+
+	exists := false
+	if err := r.db.QueryRowContext(ctx, sqlStr, playerID, otherID).Scan(&exists); err != nil {
+		return false, models.NewGenericError(err.Error())
+	}
+
+	return exists, nil
+}
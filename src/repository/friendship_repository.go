@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+)
+
+type FriendshipRepository interface {
+	Get(context.Context, uuid.UUID, uuid.UUID) (*domain.Friendship, error)
+	Create(context.Context, uuid.UUID, uuid.UUID) error
+	Accept(context.Context, uuid.UUID, uuid.UUID) error
+	Delete(context.Context, uuid.UUID, uuid.UUID) error
+	GetByPlayer(context.Context, uuid.UUID) ([]*domain.Friend, error)
+}
+
+func NewFriendshipRepository(db Querier) FriendshipRepository {
+	return &friendshipRepositoryImpl{
+		db: newInstrumentedQuerier(db),
+	}
+}
+
+type friendshipRepositoryImpl struct {
+	db Querier
+}
+
+// Get returns the friendship or friend request between the two players,
+// whichever of them sent it.
+func (r *friendshipRepositoryImpl) Get(ctx context.Context, playerID uuid.UUID, otherID uuid.UUID) (*domain.Friendship, error) {
+	sqlStr := `
+		SELECT f.requester_id, f.addressee_id, f.accepted
+		FROM friendships AS f
+		WHERE (f.requester_id = $1 AND f.addressee_id = $2)
+			OR (f.requester_id = $2 AND f.addressee_id = $1)
+		`
+
+	friendship := &domain.Friendship{}
+	err := r.db.QueryRowContext(ctx, sqlStr, playerID, otherID).Scan(&friendship.RequesterID, &friendship.AddresseeID, &friendship.Accepted)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.NewNotFoundErrorf("friendship with player '%s' not exist", otherID.String())
+		} else {
+			return nil, models.NewGenericError(err.Error())
+		}
+	}
+
+	return friendship, nil
+}
+
+func (r *friendshipRepositoryImpl) Create(ctx context.Context, requesterID uuid.UUID, addresseeID uuid.UUID) error {
+	sqlStr := `
+		INSERT INTO friendships(requester_id, addressee_id)
+		VALUES($1, $2)`
+
+	_, err := r.db.ExecContext(ctx, sqlStr, requesterID, addresseeID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.NewValidationError("friend request already exists")
+		}
+		return models.NewGenericError(err.Error())
+	}
+
+	return nil
+}
+
+func (r *friendshipRepositoryImpl) Accept(ctx context.Context, requesterID uuid.UUID, addresseeID uuid.UUID) error {
+	sqlStr := `
+		UPDATE friendships
+		SET accepted    = true,
+			accepted_at = now()
+		WHERE requester_id = $1 AND addressee_id = $2 AND accepted = false`
+
+	result, err := r.db.ExecContext(ctx, sqlStr, requesterID, addresseeID)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return models.NewNotFoundErrorf("friend request of player '%s' not exist", requesterID.String())
+	}
+
+	return nil
+}
+
+// Delete removes the friendship or friend request between the two players.
+func (r *friendshipRepositoryImpl) Delete(ctx context.Context, playerID uuid.UUID, otherID uuid.UUID) error {
+	sqlStr := `
+		DELETE FROM friendships
+		WHERE (requester_id = $1 AND addressee_id = $2)
+			OR (requester_id = $2 AND addressee_id = $1)`
+
+	result, err := r.db.ExecContext(ctx, sqlStr, playerID, otherID)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return models.NewNotFoundErrorf("friendship with player '%s' not exist", otherID.String())
+	}
+
+	return nil
+}
+
+// GetByPlayer returns the friends and the pending requests of the player,
+// friends first.
+func (r *friendshipRepositoryImpl) GetByPlayer(ctx context.Context, playerID uuid.UUID) ([]*domain.Friend, error) {
+	sqlStr := `
+		SELECT
+			p.id,
+			p.nickname,
+			CASE
+				WHEN f.accepted THEN 'accepted'
+				WHEN f.requester_id = $1 THEN 'outgoing'
+				ELSE 'incoming'
+			END,
+			COALESCE(f.accepted_at, f.created_at)
+		FROM friendships AS f
+		INNER JOIN players AS p ON p.id = CASE WHEN f.requester_id = $1 THEN f.addressee_id ELSE f.requester_id END
+		WHERE f.requester_id = $1 OR f.addressee_id = $1
+		ORDER BY f.accepted DESC, p.nickname`
+
+	rows, err := r.db.QueryContext(ctx, sqlStr, playerID)
+	if err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+	defer rows.Close()
+
+	friends := []*domain.Friend{}
+	for rows.Next() {
+		friend := &domain.Friend{}
+		if err = rows.Scan(&friend.ID, &friend.Nickname, &friend.Status, &friend.Since); err != nil {
+			return nil, models.NewGenericError(err.Error())
+		}
+		friends = append(friends, friend)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+
+	return friends, nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+)
+
+type IdentityRepository interface {
+	Get(context.Context, string, string) (*domain.Identity, error)
+	Create(context.Context, *domain.Identity) error
+}
+
+func NewIdentityRepository(db Querier) IdentityRepository {
+	return &identityRepositoryImpl{
+		db: newInstrumentedQuerier(db),
+	}
+}
+
+type identityRepositoryImpl struct {
+	db Querier
+}
+
+func (r *identityRepositoryImpl) Get(ctx context.Context, issuer string, subject string) (*domain.Identity, error) {
+	sqlStr := `
+		SELECT pi.issuer, pi.subject, pi.player_id, COALESCE(pi.email, ''), pi.created_at
+		FROM player_identities AS pi
+		WHERE pi.issuer = $1 AND pi.subject = $2
+		`
+
+	identity := &domain.Identity{}
+	err := r.db.QueryRowContext(ctx, sqlStr, issuer, subject).Scan(
+		&identity.Issuer, &identity.Subject, &identity.PlayerID, &identity.Email, &identity.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.NewNotFoundErrorf("identity '%s' of '%s' not exist", subject, issuer)
+		} else {
+			return nil, models.NewGenericError(err.Error())
+		}
+	}
+
+	return identity, nil
+}
+
+func (r *identityRepositoryImpl) Create(ctx context.Context, identity *domain.Identity) error {
+	sqlStr := `
+		INSERT INTO player_identities(issuer, subject, player_id, email)
+		VALUES($1, $2, $3, NULLIF($4, ''))`
+
+	_, err := r.db.ExecContext(ctx, sqlStr, identity.Issuer, identity.Subject, identity.PlayerID, identity.Email)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	return nil
+}
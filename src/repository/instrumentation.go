@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/plamen-v/tic-tac-toe/src/services/logger"
+	"github.com/plamen-v/tic-tac-toe/src/services/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentedQuerier wraps a Querier and starts a client span for every
+// statement, so row lock waits (SELECT ... FOR UPDATE) show up as their own
+// spans. Statements are logged with the request-scoped logger from ctx.
+type instrumentedQuerier struct {
+	db Querier
+}
+
+func newInstrumentedQuerier(db Querier) Querier {
+	if _, ok := db.(*instrumentedQuerier); ok {
+		return db
+	}
+	return &instrumentedQuerier{db: db}
+}
+
+func (q *instrumentedQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, done := startQuery(ctx, query)
+	result, err := q.db.ExecContext(ctx, query, args...)
+	done(err)
+	return result, err
+}
+
+func (q *instrumentedQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, done := startQuery(ctx, query)
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	done(err)
+	return rows, err
+}
+
+func (q *instrumentedQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, done := startQuery(ctx, query)
+	row := q.db.QueryRowContext(ctx, query, args...)
+	done(row.Err())
+	return row
+}
+
+func startQuery(ctx context.Context, query string) (context.Context, func(error)) {
+	query = strings.Join(strings.Fields(query), " ")
+	operation, _, _ := strings.Cut(query, " ")
+	operation = strings.ToUpper(operation)
+
+	start := time.Now()
+	ctx, span := startQuerySpan(ctx, query, operation)
+	return ctx, func(err error) {
+		tracing.End(span, err)
+
+		fields := []logger.Field{
+			logger.String("operation", operation),
+			logger.String("query", query),
+			logger.Duration("duration", time.Since(start)),
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("query failed", append(fields, logger.Err(err))...)
+		} else {
+			logger.FromContext(ctx).Debug("query", fields...)
+		}
+	}
+}
+
+func startQuerySpan(ctx context.Context, query string, operation string) (context.Context, trace.Span) {
+	return tracing.Tracer("repository").Start(ctx, "db "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(query),
+		),
+	)
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+)
+
+type InvitationRepository interface {
+	Create(context.Context, uuid.UUID, uuid.UUID) error
+	Get(context.Context, uuid.UUID) (*domain.Invitation, error)
+	GetByPlayer(context.Context, uuid.UUID) ([]*domain.Invitation, error)
+	Delete(context.Context, uuid.UUID) error
+}
+
+func NewInvitationRepository(db Querier) InvitationRepository {
+	return &invitationRepositoryImpl{
+		db: newInstrumentedQuerier(db),
+	}
+}
+
+type invitationRepositoryImpl struct {
+	db Querier
+}
+
+const invitationSelect = `
+		SELECT ri.room_id, ri.player_id, ph.id, ph.nickname, r.title, COALESCE(r.description, ''), ri.created_at
+		FROM room_invitations AS ri
+		INNER JOIN rooms AS r ON r.id = ri.room_id
+		INNER JOIN players AS ph ON ph.id = r.host_id
+		`
+
+func (r *invitationRepositoryImpl) Create(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) error {
+	sqlStr := `
+		INSERT INTO room_invitations(room_id, player_id)
+		VALUES($1, $2)`
+
+	_, err := r.db.ExecContext(ctx, sqlStr, roomID, playerID)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	return nil
+}
+
+// Get returns the invitation of the room. Rooms without one are public.
+func (r *invitationRepositoryImpl) Get(ctx context.Context, roomID uuid.UUID) (*domain.Invitation, error) {
+	sqlStr := invitationSelect + `WHERE ri.room_id = $1`
+
+	invitation, err := scanInvitation(r.db.QueryRowContext(ctx, sqlStr, roomID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.NewNotFoundErrorf("invitation to room '%s' not exist", roomID.String())
+		} else {
+			return nil, models.NewGenericError(err.Error())
+		}
+	}
+
+	return invitation, nil
+}
+
+// GetByPlayer returns the invitations of the player to rooms that are still
+// waiting for a guest, newest first.
+func (r *invitationRepositoryImpl) GetByPlayer(ctx context.Context, playerID uuid.UUID) ([]*domain.Invitation, error) {
+	sqlStr := invitationSelect + `
+		WHERE ri.player_id = $1 AND r.phase = $2
+		ORDER BY ri.created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, sqlStr, playerID, models.RoomPhaseOpen)
+	if err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+	defer rows.Close()
+
+	invitations := []*domain.Invitation{}
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, models.NewGenericError(err.Error())
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+
+	return invitations, nil
+}
+
+func (r *invitationRepositoryImpl) Delete(ctx context.Context, roomID uuid.UUID) error {
+	sqlStr := `
+		DELETE FROM room_invitations
+		WHERE room_id = $1`
+
+	_, err := r.db.ExecContext(ctx, sqlStr, roomID)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	return nil
+}
+
+func scanInvitation(row rowScanner) (*domain.Invitation, error) {
+	invitation := &domain.Invitation{}
+	err := row.Scan(&invitation.RoomID, &invitation.PlayerID, &invitation.HostID, &invitation.HostNickname,
+		&invitation.Title, &invitation.Description, &invitation.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
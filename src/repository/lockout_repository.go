@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+)
+
+type LoginAttemptRepository interface {
+	Create(context.Context, *domain.LoginAttempt) error
+	CountFailedLoginsByIP(context.Context, string, time.Time) (int, error)
+	DeleteBefore(context.Context, time.Time) (int, error)
+}
+
+func NewLoginAttemptRepository(db Querier) LoginAttemptRepository {
+	return &loginAttemptRepositoryImpl{
+		db: newInstrumentedQuerier(db),
+	}
+}
+
+type loginAttemptRepositoryImpl struct {
+	db Querier
+}
+
+func (r *loginAttemptRepositoryImpl) Create(ctx context.Context, attempt *domain.LoginAttempt) error {
+	sqlStr := `
+		INSERT INTO login_attempts(login, player_id, ip, succeeded, attempted_at)
+		VALUES($1, $2, $3, $4, $5)`
+
+	_, err := r.db.ExecContext(ctx, sqlStr, attempt.Login, attempt.PlayerID, attempt.IP, attempt.Succeeded, attempt.AttemptedAt)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	return nil
+}
+
+// CountFailedLoginsByIP returns the number of distinct logins that failed to
+// authenticate from ip since the given time.
+func (r *loginAttemptRepositoryImpl) CountFailedLoginsByIP(ctx context.Context, ip string, since time.Time) (int, error) {
+	sqlStr := `
+		SELECT COUNT(DISTINCT la.login)
+		FROM login_attempts AS la
+		WHERE la.ip = $1 AND la.succeeded = false AND la.attempted_at >= $2
+		`
+
+	count := 0
+	err := r.db.QueryRowContext(ctx, sqlStr, ip, since).Scan(&count)
+	if err != nil {
+		return 0, models.NewGenericError(err.Error())
+	}
+
+	return count, nil
+}
+
+// DeleteBefore deletes the attempts made before the given time and returns
+// their number.
+func (r *loginAttemptRepositoryImpl) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	sqlStr := `
+		DELETE FROM login_attempts
+		WHERE attempted_at < $1`
+
+	result, err := r.db.ExecContext(ctx, sqlStr, before)
+	if err != nil {
+		return 0, models.NewGenericError(err.Error())
+	}
+
+	deleted, _ := result.RowsAffected()
+	return int(deleted), nil
+}
+
+type LockoutRepository interface {
+	Get(context.Context, uuid.UUID, bool) (*domain.Lockout, error)
+	GetList(context.Context) ([]*domain.Lockout, error)
+	Save(context.Context, *domain.Lockout) error
+	Delete(context.Context, uuid.UUID) error
+}
+
+func NewLockoutRepository(db Querier) LockoutRepository {
+	return &lockoutRepositoryImpl{
+		db: newInstrumentedQuerier(db),
+	}
+}
+
+type lockoutRepositoryImpl struct {
+	db Querier
+}
+
+func (r *lockoutRepositoryImpl) Get(ctx context.Context, playerID uuid.UUID, lock bool) (*domain.Lockout, error) {
+	sqlStr := `
+		SELECT al.player_id, p.login, al.failed_attempts, al.lockout_count, al.locked_until, al.last_failed_at
+		FROM account_lockouts AS al
+		JOIN players p ON p.id = al.player_id
+		WHERE al.player_id = $1
+		`
+	if lock {
+		sqlStr += ` FOR UPDATE OF al`
+	}
+
+	lockout, err := scanLockout(r.db.QueryRowContext(ctx, sqlStr, playerID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.NewNotFoundErrorf("lockout for player '%s' not exist", playerID.String())
+		} else {
+			return nil, models.NewGenericError(err.Error())
+		}
+	}
+
+	return lockout, nil
+}
+
+func (r *lockoutRepositoryImpl) GetList(ctx context.Context) ([]*domain.Lockout, error) {
+	sqlStr := `
+		SELECT al.player_id, p.login, al.failed_attempts, al.lockout_count, al.locked_until, al.last_failed_at
+		FROM account_lockouts AS al
+		JOIN players p ON p.id = al.player_id
+		ORDER BY al.locked_until DESC NULLS LAST, al.last_failed_at DESC
+		`
+
+	rows, err := r.db.QueryContext(ctx, sqlStr)
+	if err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+	defer rows.Close()
+
+	lockouts := []*domain.Lockout{}
+	for rows.Next() {
+		lockout, err := scanLockout(rows)
+		if err != nil {
+			return nil, models.NewGenericError(err.Error())
+		}
+		lockouts = append(lockouts, lockout)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+
+	return lockouts, nil
+}
+
+func (r *lockoutRepositoryImpl) Save(ctx context.Context, lockout *domain.Lockout) error {
+	sqlStr := `
+		INSERT INTO account_lockouts(player_id, failed_attempts, lockout_count, locked_until, last_failed_at)
+		VALUES($1, $2, $3, $4, $5)
+		ON CONFLICT (player_id) DO UPDATE
+		SET failed_attempts = EXCLUDED.failed_attempts,
+			lockout_count   = EXCLUDED.lockout_count,
+			locked_until    = EXCLUDED.locked_until,
+			last_failed_at  = EXCLUDED.last_failed_at`
+
+	_, err := r.db.ExecContext(ctx, sqlStr, lockout.PlayerID, lockout.FailedAttempts, lockout.LockoutCount, lockout.LockedUntil, lockout.LastFailedAt)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	return nil
+}
+
+func (r *lockoutRepositoryImpl) Delete(ctx context.Context, playerID uuid.UUID) error {
+	sqlStr := `
+		DELETE FROM account_lockouts
+		WHERE player_id = $1`
+
+	_, err := r.db.ExecContext(ctx, sqlStr, playerID)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	return nil
+}
+
+func scanLockout(row rowScanner) (*domain.Lockout, error) {
+	var lockedUntil, lastFailedAt sql.NullTime
+	lockout := &domain.Lockout{}
+	err := row.Scan(&lockout.PlayerID, &lockout.Login, &lockout.FailedAttempts, &lockout.LockoutCount, &lockedUntil, &lastFailedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if lockedUntil.Valid {
+		lockout.LockedUntil = &lockedUntil.Time
+	}
+	if lastFailedAt.Valid {
+		lockout.LastFailedAt = &lastFailedAt.Time
+	}
+
+	return lockout, nil
+}
@@ -2,9 +2,11 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -48,6 +50,113 @@ func (m *MockPlayerRepository) GetRanking(ctx context.Context, page int, pageSiz
 	return players, pageSize, page, total, args.Error(4)
 }
 
+func (m *MockPlayerRepository) GetRankingAfter(ctx context.Context, cursor string, limit int) ([]*models.Player, string, error) {
+	args := m.Called(ctx, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).([]*models.Player), args.String(1), args.Error(2)
+}
+
+func (m *MockPlayerRepository) UpdateGameStats(ctx context.Context, id uuid.UUID, variant domain.GameVariant, result domain.GameResult) error {
+	args := m.Called(ctx, id, variant, result)
+	return args.Error(0)
+}
+
+func (m *MockPlayerRepository) GetGameRanking(ctx context.Context, variant domain.GameVariant, page int, pageSize int) ([]*models.Player, int, int, int, error) {
+	args := m.Called(ctx, variant, page, pageSize)
+	if args.Get(0) == nil {
+		return nil, 0, 0, 0, args.Error(4)
+	}
+	return args.Get(0).([]*models.Player), args.Int(1), args.Int(2), args.Int(3), args.Error(4)
+}
+
+func (m *MockPlayerRepository) GetAccount(ctx context.Context, id uuid.UUID) (*domain.Account, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Account), args.Error(1)
+}
+
+func (m *MockPlayerRepository) GetAccounts(ctx context.Context, page int, pageSize int) ([]*domain.Account, int, int, int, error) {
+	args := m.Called(ctx, page, pageSize)
+
+	accounts, okAccounts := args.Get(0).([]*domain.Account)
+	pageSize, okPageSize := args.Get(1).(int)
+	page, okPage := args.Get(2).(int)
+	total, okTotal := args.Get(3).(int)
+
+	if accounts == nil || !okAccounts || !okPageSize || !okPage || !okTotal {
+		return nil, 0, 0, 0, args.Error(4)
+	}
+
+	return accounts, pageSize, page, total, args.Error(4)
+}
+
+func (m *MockPlayerRepository) UpdateRole(ctx context.Context, id uuid.UUID, role domain.Role) error {
+	args := m.Called(ctx, id, role)
+	return args.Error(0)
+}
+
+func (m *MockPlayerRepository) UpdateDisabled(ctx context.Context, id uuid.UUID, disabled bool) error {
+	args := m.Called(ctx, id, disabled)
+	return args.Error(0)
+}
+
+func (m *MockPlayerRepository) Create(ctx context.Context, player *models.Player) (uuid.UUID, error) {
+	args := m.Called(ctx, player)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockPlayerRepository) NicknameExists(ctx context.Context, nickname string) (bool, error) {
+	args := m.Called(ctx, nickname)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPlayerRepository) GetRating(ctx context.Context, id uuid.UUID) (int, error) {
+	args := m.Called(ctx, id)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockPlayerRepository) AdjustRating(ctx context.Context, id uuid.UUID, delta int) error {
+	args := m.Called(ctx, id, delta)
+	return args.Error(0)
+}
+
+func (m *MockPlayerRepository) GetProfile(ctx context.Context, id uuid.UUID) (*domain.Profile, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Profile), args.Error(1)
+}
+
+func (m *MockPlayerRepository) UpdateNickname(ctx context.Context, id uuid.UUID, nickname string) error {
+	args := m.Called(ctx, id, nickname)
+	return args.Error(0)
+}
+
+func (m *MockPlayerRepository) UpdatePassword(ctx context.Context, id uuid.UUID, password string) error {
+	args := m.Called(ctx, id, password)
+	return args.Error(0)
+}
+
+func (m *MockPlayerRepository) GetEmail(ctx context.Context, id uuid.UUID) (string, error) {
+	args := m.Called(ctx, id)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockPlayerRepository) UpdateEmail(ctx context.Context, id uuid.UUID, email string) error {
+	args := m.Called(ctx, id, email)
+	return args.Error(0)
+}
+
+func (m *MockPlayerRepository) Anonymize(ctx context.Context, id uuid.UUID, nickname string) error {
+	args := m.Called(ctx, id, nickname)
+	return args.Error(0)
+}
+
 type MockGameRepository struct {
 	mock.Mock
 }
@@ -60,8 +169,8 @@ func (m *MockGameRepository) Get(ctx context.Context, id uuid.UUID) (*models.Gam
 	return args.Get(0).(*models.Game), args.Error(1)
 }
 
-func (m *MockGameRepository) Create(ctx context.Context, game *models.Game) (uuid.UUID, error) {
-	args := m.Called(ctx, game)
+func (m *MockGameRepository) Create(ctx context.Context, game *models.Game, state *domain.VariantState) (uuid.UUID, error) {
+	args := m.Called(ctx, game, state)
 	if args.Get(0) == nil {
 		return uuid.Nil, args.Error(1)
 	}
@@ -73,6 +182,78 @@ func (m *MockGameRepository) Update(ctx context.Context, game *models.Game) erro
 	return args.Error(0)
 }
 
+func (m *MockGameRepository) GetVariantState(ctx context.Context, id uuid.UUID) (*domain.VariantState, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.VariantState), args.Error(1)
+}
+
+func (m *MockGameRepository) SetNextBoard(ctx context.Context, id uuid.UUID, nextBoard *int) error {
+	args := m.Called(ctx, id, nextBoard)
+	return args.Error(0)
+}
+
+func (m *MockGameRepository) Lock(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockGameRepository) IsRanked(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockGameRepository) Void(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockGameRepository) SetRatingDeltas(ctx context.Context, id uuid.UUID, hostDelta int, guestDelta int) error {
+	args := m.Called(ctx, id, hostDelta, guestDelta)
+	return args.Error(0)
+}
+
+func (m *MockGameRepository) SetSeasonRatingDeltas(ctx context.Context, id uuid.UUID, seasonID uuid.UUID, hostDelta int, guestDelta int) error {
+	args := m.Called(ctx, id, seasonID, hostDelta, guestDelta)
+	return args.Error(0)
+}
+
+func (m *MockGameRepository) GetLeaderboard(ctx context.Context, from time.Time, page int, pageSize int) ([]*domain.Standing, int, int, int, error) {
+	args := m.Called(ctx, from, page, pageSize)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Int(2), args.Int(3), args.Error(4)
+	}
+	return args.Get(0).([]*domain.Standing), args.Int(1), args.Int(2), args.Int(3), args.Error(4)
+}
+
+func (m *MockGameRepository) GetRecentByPlayer(ctx context.Context, playerID uuid.UUID, limit int) ([]*domain.GameSummary, error) {
+	args := m.Called(ctx, playerID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.GameSummary), args.Error(1)
+}
+
+func (m *MockGameRepository) AddMove(ctx context.Context, gameID uuid.UUID, move *domain.Move) error {
+	args := m.Called(ctx, gameID, move)
+	return args.Error(0)
+}
+
+func (m *MockGameRepository) AddHint(ctx context.Context, id uuid.UUID) (int, error) {
+	args := m.Called(ctx, id)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockGameRepository) GetMoves(ctx context.Context, gameID uuid.UUID) ([]*domain.Move, error) {
+	args := m.Called(ctx, gameID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Move), args.Error(1)
+}
+
 type MockRoomRepository struct {
 	mock.Mock
 }
@@ -93,8 +274,8 @@ func (m *MockRoomRepository) GetByPlayerID(ctx context.Context, playerID uuid.UU
 	return args.Get(0).(*models.Room), args.Error(1)
 }
 
-func (m *MockRoomRepository) GetList(ctx context.Context, phase models.RoomPhase, pPageSize, pPage int) ([]*models.Room, int, int, int, error) {
-	args := m.Called(ctx, phase)
+func (m *MockRoomRepository) GetList(ctx context.Context, phase models.RoomPhase, filter *domain.RoomFilter, pPageSize, pPage int) ([]*models.Room, int, int, int, error) {
+	args := m.Called(ctx, phase, filter)
 	rooms, okPlayers := args.Get(0).([]*models.Room)
 	pageSize, okPageSize := args.Get(1).(int)
 	page, okPage := args.Get(2).(int)
@@ -107,14 +288,30 @@ func (m *MockRoomRepository) GetList(ctx context.Context, phase models.RoomPhase
 	return rooms, pageSize, page, total, args.Error(4)
 }
 
-func (m *MockRoomRepository) Create(ctx context.Context, room *models.Room) (uuid.UUID, error) {
-	args := m.Called(ctx, room)
+func (m *MockRoomRepository) GetListAfter(ctx context.Context, phase models.RoomPhase, filter *domain.RoomFilter, cursor string, limit int) ([]*models.Room, string, error) {
+	args := m.Called(ctx, phase, filter, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).([]*models.Room), args.String(1), args.Error(2)
+}
+
+func (m *MockRoomRepository) Create(ctx context.Context, room *models.Room, settings *domain.RoomSettings) (uuid.UUID, error) {
+	args := m.Called(ctx, room, settings)
 	if args.Get(0) == nil {
 		return uuid.Nil, args.Error(1)
 	}
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
+func (m *MockRoomRepository) GetSettings(ctx context.Context, id uuid.UUID) (*domain.RoomSettings, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RoomSettings), args.Error(1)
+}
+
 func (m *MockRoomRepository) Update(ctx context.Context, room *models.Room) error {
 	args := m.Called(ctx, room)
 	return args.Error(0)
@@ -124,3 +321,325 @@ func (m *MockRoomRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
+
+type MockSchemaRepository struct {
+	mock.Mock
+}
+
+func (m *MockSchemaRepository) GetVersion(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+type MockLoginAttemptRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoginAttemptRepository) Create(ctx context.Context, attempt *domain.LoginAttempt) error {
+	args := m.Called(ctx, attempt)
+	return args.Error(0)
+}
+
+func (m *MockLoginAttemptRepository) CountFailedLoginsByIP(ctx context.Context, ip string, since time.Time) (int, error) {
+	args := m.Called(ctx, ip, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockLoginAttemptRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	args := m.Called(ctx, before)
+	return args.Int(0), args.Error(1)
+}
+
+type MockLockoutRepository struct {
+	mock.Mock
+}
+
+func (m *MockLockoutRepository) Get(ctx context.Context, playerID uuid.UUID, lock bool) (*domain.Lockout, error) {
+	args := m.Called(ctx, playerID, lock)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Lockout), args.Error(1)
+}
+
+func (m *MockLockoutRepository) GetList(ctx context.Context) ([]*domain.Lockout, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Lockout), args.Error(1)
+}
+
+func (m *MockLockoutRepository) Save(ctx context.Context, lockout *domain.Lockout) error {
+	args := m.Called(ctx, lockout)
+	return args.Error(0)
+}
+
+func (m *MockLockoutRepository) Delete(ctx context.Context, playerID uuid.UUID) error {
+	args := m.Called(ctx, playerID)
+	return args.Error(0)
+}
+
+type MockIdentityRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdentityRepository) Get(ctx context.Context, issuer string, subject string) (*domain.Identity, error) {
+	args := m.Called(ctx, issuer, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Identity), args.Error(1)
+}
+
+func (m *MockIdentityRepository) Create(ctx context.Context, identity *domain.Identity) error {
+	args := m.Called(ctx, identity)
+	return args.Error(0)
+}
+
+type MockTwoFactorRepository struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorRepository) Get(ctx context.Context, playerID uuid.UUID, lock bool) (*domain.TwoFactor, error) {
+	args := m.Called(ctx, playerID, lock)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TwoFactor), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) Save(ctx context.Context, twoFactor *domain.TwoFactor) error {
+	args := m.Called(ctx, twoFactor)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) Delete(ctx context.Context, playerID uuid.UUID) error {
+	args := m.Called(ctx, playerID)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, playerID uuid.UUID, hashes []string) error {
+	args := m.Called(ctx, playerID, hashes)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) UseRecoveryCode(ctx context.Context, playerID uuid.UUID, hash string) (bool, error) {
+	args := m.Called(ctx, playerID, hash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) CountRecoveryCodes(ctx context.Context, playerID uuid.UUID) (int, error) {
+	args := m.Called(ctx, playerID)
+	return args.Int(0), args.Error(1)
+}
+
+type MockPasswordResetRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasswordResetRepository) Create(ctx context.Context, token *domain.PasswordResetToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetRepository) Consume(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	args := m.Called(ctx, tokenHash)
+	playerID, _ := args.Get(0).(uuid.UUID)
+	return playerID, args.Error(1)
+}
+
+func (m *MockPasswordResetRepository) DeleteByPlayer(ctx context.Context, playerID uuid.UUID) error {
+	args := m.Called(ctx, playerID)
+	return args.Error(0)
+}
+
+type MockFriendshipRepository struct {
+	mock.Mock
+}
+
+func (m *MockFriendshipRepository) Get(ctx context.Context, playerID uuid.UUID, otherID uuid.UUID) (*domain.Friendship, error) {
+	args := m.Called(ctx, playerID, otherID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Friendship), args.Error(1)
+}
+
+func (m *MockFriendshipRepository) Create(ctx context.Context, requesterID uuid.UUID, addresseeID uuid.UUID) error {
+	args := m.Called(ctx, requesterID, addresseeID)
+	return args.Error(0)
+}
+
+func (m *MockFriendshipRepository) Accept(ctx context.Context, requesterID uuid.UUID, addresseeID uuid.UUID) error {
+	args := m.Called(ctx, requesterID, addresseeID)
+	return args.Error(0)
+}
+
+func (m *MockFriendshipRepository) Delete(ctx context.Context, playerID uuid.UUID, otherID uuid.UUID) error {
+	args := m.Called(ctx, playerID, otherID)
+	return args.Error(0)
+}
+
+func (m *MockFriendshipRepository) GetByPlayer(ctx context.Context, playerID uuid.UUID) ([]*domain.Friend, error) {
+	args := m.Called(ctx, playerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Friend), args.Error(1)
+}
+
+type MockBlockRepository struct {
+	mock.Mock
+}
+
+func (m *MockBlockRepository) Create(ctx context.Context, playerID uuid.UUID, blockedID uuid.UUID) error {
+	args := m.Called(ctx, playerID, blockedID)
+	return args.Error(0)
+}
+
+func (m *MockBlockRepository) Delete(ctx context.Context, playerID uuid.UUID, blockedID uuid.UUID) error {
+	args := m.Called(ctx, playerID, blockedID)
+	return args.Error(0)
+}
+
+func (m *MockBlockRepository) GetByPlayer(ctx context.Context, playerID uuid.UUID) ([]*domain.BlockedPlayer, error) {
+	args := m.Called(ctx, playerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.BlockedPlayer), args.Error(1)
+}
+
+func (m *MockBlockRepository) Exists(ctx context.Context, playerID uuid.UUID, otherID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, playerID, otherID)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockInvitationRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvitationRepository) Create(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) error {
+	args := m.Called(ctx, roomID, playerID)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) Get(ctx context.Context, roomID uuid.UUID) (*domain.Invitation, error) {
+	args := m.Called(ctx, roomID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) GetByPlayer(ctx context.Context, playerID uuid.UUID) ([]*domain.Invitation, error) {
+	args := m.Called(ctx, playerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) Delete(ctx context.Context, roomID uuid.UUID) error {
+	args := m.Called(ctx, roomID)
+	return args.Error(0)
+}
+
+type MockPresenceRepository struct {
+	mock.Mock
+}
+
+func (m *MockPresenceRepository) Touch(ctx context.Context, playerID uuid.UUID, seenAt time.Time) error {
+	args := m.Called(ctx, playerID, seenAt)
+	return args.Error(0)
+}
+
+func (m *MockPresenceRepository) GetActivity(ctx context.Context, playerIDs []uuid.UUID) ([]*domain.PlayerActivity, error) {
+	args := m.Called(ctx, playerIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.PlayerActivity), args.Error(1)
+}
+
+type MockSeasonRepository struct {
+	mock.Mock
+}
+
+func (m *MockSeasonRepository) Create(ctx context.Context, season *domain.Season) (uuid.UUID, error) {
+	args := m.Called(ctx, season)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockSeasonRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Season, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Season), args.Error(1)
+}
+
+func (m *MockSeasonRepository) GetList(ctx context.Context) ([]*domain.Season, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Season), args.Error(1)
+}
+
+func (m *MockSeasonRepository) GetCurrent(ctx context.Context, at time.Time) (*domain.Season, error) {
+	args := m.Called(ctx, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Season), args.Error(1)
+}
+
+func (m *MockSeasonRepository) GetRating(ctx context.Context, seasonID uuid.UUID, playerID uuid.UUID) (int, error) {
+	args := m.Called(ctx, seasonID, playerID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockSeasonRepository) RecordResult(ctx context.Context, seasonID uuid.UUID, playerID uuid.UUID, result domain.GameResult, ratingDelta int) error {
+	args := m.Called(ctx, seasonID, playerID, result, ratingDelta)
+	return args.Error(0)
+}
+
+func (m *MockSeasonRepository) GetStandings(ctx context.Context, season *domain.Season, page int, pageSize int) ([]*domain.Standing, int, int, int, error) {
+	args := m.Called(ctx, season, page, pageSize)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Int(2), args.Int(3), args.Error(4)
+	}
+	return args.Get(0).([]*domain.Standing), args.Int(1), args.Int(2), args.Int(3), args.Error(4)
+}
+
+func (m *MockSeasonRepository) Archive(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type MockAchievementRepository struct {
+	mock.Mock
+}
+
+func (m *MockAchievementRepository) RecordStreak(ctx context.Context, playerID uuid.UUID, won bool) (*domain.Streak, error) {
+	args := m.Called(ctx, playerID, won)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Streak), args.Error(1)
+}
+
+func (m *MockAchievementRepository) Unlock(ctx context.Context, playerID uuid.UUID, code domain.AchievementCode) (bool, error) {
+	args := m.Called(ctx, playerID, code)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAchievementRepository) GetByPlayer(ctx context.Context, playerID uuid.UUID) ([]*domain.Achievement, error) {
+	args := m.Called(ctx, playerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Achievement), args.Error(1)
+}
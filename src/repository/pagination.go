@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/plamen-v/tic-tac-toe-models/models"
+)
+
+var InvalidCursorErrorMessage string = "invalid cursor"
+
+// paginate clamps page to the available pages for totalCnt records and
+// returns it with the matching LIMIT and OFFSET. page is 0 when there are no
+// records.
+func paginate(totalCnt int, page int, pageSize int) (int, int, int) {
+	lastPage := 0
+	if pageSize > 0 && totalCnt > 0 {
+		lastPage = (totalCnt + pageSize - 1) / pageSize
+	}
+
+	pageForQuery := page
+	if lastPage == 0 {
+		pageForQuery = 1
+	} else {
+		if pageForQuery < 1 {
+			pageForQuery = 1
+		} else if pageForQuery > lastPage {
+			pageForQuery = lastPage
+		}
+	}
+
+	limit := pageSize
+	offset := (pageForQuery - 1) * pageSize
+
+	if lastPage == 0 {
+		page = 0
+	} else {
+		page = pageForQuery
+	}
+
+	return page, limit, offset
+}
+
+// encodeCursor turns the sort keys of the last row of a page into an opaque
+// cursor for the next page.
+func encodeCursor(keys any) string {
+	data, _ := json.Marshal(keys)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(cursor string, keys any) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		err = json.Unmarshal(data, keys)
+	}
+	if err != nil {
+		return models.NewValidationError(InvalidCursorErrorMessage)
+	}
+
+	return nil
+}
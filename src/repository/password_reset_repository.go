@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+)
+
+type PasswordResetRepository interface {
+	Create(context.Context, *domain.PasswordResetToken) error
+	Consume(context.Context, string) (uuid.UUID, error)
+	DeleteByPlayer(context.Context, uuid.UUID) error
+}
+
+func NewPasswordResetRepository(db Querier) PasswordResetRepository {
+	return &passwordResetRepositoryImpl{
+		db: newInstrumentedQuerier(db),
+	}
+}
+
+type passwordResetRepositoryImpl struct {
+	db Querier
+}
+
+func (r *passwordResetRepositoryImpl) Create(ctx context.Context, token *domain.PasswordResetToken) error {
+	sqlStr := `
+		INSERT INTO password_reset_tokens(token_hash, player_id, expires_at)
+		VALUES($1, $2, $3)`
+
+	_, err := r.db.ExecContext(ctx, sqlStr, token.TokenHash, token.PlayerID, token.ExpiresAt)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	return nil
+}
+
+// Consume marks an unused, unexpired token as used and returns its player.
+func (r *passwordResetRepositoryImpl) Consume(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	sqlStr := `
+		UPDATE password_reset_tokens
+		SET used_at = now()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now()
+		RETURNING player_id`
+
+	var playerID uuid.UUID
+	err := r.db.QueryRowContext(ctx, sqlStr, tokenHash).Scan(&playerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, models.NewNotFoundError("password reset token not exist")
+		} else {
+			return uuid.Nil, models.NewGenericError(err.Error())
+		}
+	}
+
+	return playerID, nil
+}
+
+func (r *passwordResetRepositoryImpl) DeleteByPlayer(ctx context.Context, playerID uuid.UUID) error {
+	sqlStr := `
+		DELETE FROM password_reset_tokens
+		WHERE player_id = $1`
+
+	_, err := r.db.ExecContext(ctx, sqlStr, playerID)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/lib/pq"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+)
+
+type PresenceRepository interface {
+	Touch(context.Context, uuid.UUID, time.Time) error
+	GetActivity(context.Context, []uuid.UUID) ([]*domain.PlayerActivity, error)
+}
+
+func NewPresenceRepository(db Querier) PresenceRepository {
+	return &presenceRepositoryImpl{
+		db: newInstrumentedQuerier(db),
+	}
+}
+
+type presenceRepositoryImpl struct {
+	db Querier
+}
+
+func (r *presenceRepositoryImpl) Touch(ctx context.Context, playerID uuid.UUID, seenAt time.Time) error {
+	sqlStr := `
+		UPDATE players
+		SET last_seen_at = $2
+		WHERE id = $1 AND (last_seen_at IS NULL OR last_seen_at < $2)`
+
+	_, err := r.db.ExecContext(ctx, sqlStr, playerID, seenAt)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	return nil
+}
+
+// GetActivity returns the activity of the existing players among playerIDs.
+func (r *presenceRepositoryImpl) GetActivity(ctx context.Context, playerIDs []uuid.UUID) ([]*domain.PlayerActivity, error) {
+	ids := make([]string, len(playerIDs))
+	for i, id := range playerIDs {
+		ids[i] = id.String()
+	}
+
+	sqlStr := `
+		SELECT p.id, p.last_seen_at, r.id IS NOT NULL, COALESCE(g.phase = $2, false)
+		FROM players AS p
+		LEFT JOIN rooms AS r ON r.host_id = p.id OR r.guest_id = p.id
+		LEFT JOIN games AS g ON g.id = r.game_id
+		WHERE p.id = ANY($1::uuid[])`
+
+	rows, err := r.db.QueryContext(ctx, sqlStr, pq.Array(ids), models.GamePhaseInProgress)
+	if err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+	defer rows.Close()
+
+	activities := make([]*domain.PlayerActivity, 0, len(playerIDs))
+	for rows.Next() {
+		var activity domain.PlayerActivity
+		var lastSeenAt sql.NullTime
+		if err = rows.Scan(&activity.PlayerID, &lastSeenAt, &activity.InRoom, &activity.InGame); err != nil {
+			return nil, models.NewGenericError(err.Error())
+		}
+		if lastSeenAt.Valid {
+			activity.LastSeenAt = &lastSeenAt.Time
+		}
+		activities = append(activities, &activity)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+
+	return activities, nil
+}
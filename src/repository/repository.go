@@ -3,15 +3,24 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/lib/pq"
 	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
 )
 
 const (
 	DatabaseDriver            = "postgres"
 	NoRecordsAffectedErrorMsg = "no records affected"
+	// SchemaVersion is the highest db/scripts migration this build expects.
+	SchemaVersion = 24
+
+	uniqueViolation pq.ErrorCode = "23505"
 )
 
 type Querier interface {
@@ -20,15 +29,32 @@ type Querier interface {
 	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
 }
 
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
 type GameRepository interface {
 	Get(context.Context, uuid.UUID) (*models.Game, error)
-	Create(context.Context, *models.Game) (uuid.UUID, error)
+	Create(context.Context, *models.Game, *domain.VariantState) (uuid.UUID, error)
 	Update(context.Context, *models.Game) error
+	GetVariantState(context.Context, uuid.UUID) (*domain.VariantState, error)
+	SetNextBoard(context.Context, uuid.UUID, *int) error
+	Lock(context.Context, uuid.UUID) (bool, error)
+	IsRanked(context.Context, uuid.UUID) (bool, error)
+	Void(context.Context, uuid.UUID) error
+	SetRatingDeltas(context.Context, uuid.UUID, int, int) error
+	SetSeasonRatingDeltas(context.Context, uuid.UUID, uuid.UUID, int, int) error
+	GetLeaderboard(context.Context, time.Time, int, int) ([]*domain.Standing, int, int, int, error)
+	GetRecentByPlayer(context.Context, uuid.UUID, int) ([]*domain.GameSummary, error)
+	AddMove(context.Context, uuid.UUID, *domain.Move) error
+	AddHint(context.Context, uuid.UUID) (int, error)
+	GetMoves(context.Context, uuid.UUID) ([]*domain.Move, error)
 }
 
 func NewGameRepository(db Querier) GameRepository {
 	return &gameRepositoryImpl{
-		db: db,
+		db: newInstrumentedQuerier(db),
 	}
 }
 
@@ -62,228 +88,1063 @@ func (r *gameRepositoryImpl) Get(ctx context.Context, id uuid.UUID) (*models.Gam
 		&game.Board, &winnerID, &game.Phase)
 
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, models.NewNotFoundErrorf("game '%s' not exist", id.String())
-		} else {
-			return nil, models.NewGenericError(err.Error())
+		if err == sql.ErrNoRows {
+			return nil, models.NewNotFoundErrorf("game '%s' not exist", id.String())
+		} else {
+			return nil, models.NewGenericError(err.Error())
+		}
+	}
+
+	if winnerID.Valid {
+		game.WinnerID = &winnerID.UUID
+	}
+
+	return game, nil
+}
+
+func (r *gameRepositoryImpl) Create(ctx context.Context, game *models.Game, state *domain.VariantState) (uuid.UUID, error) {
+	sqlStr := `
+		INSERT INTO games(
+			host_id, 
+			host_mark, 
+			guest_id, 
+			guest_mark, 
+			current_player_id, 
+			board,
+			phase,
+			variant,
+			rule_set,
+			next_board,
+			mark_policy,
+			first_move_policy,
+			first_player_id,
+			ranked)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id`
+
+	var id uuid.UUID
+	err := r.db.QueryRowContext(ctx, sqlStr, game.Host.ID, game.Host.Mark, game.Guest.ID, game.Guest.Mark, game.CurrentPlayerID, game.Board, game.Phase, state.Variant, state.RuleSet, state.NextBoard, state.MarkPolicy, state.FirstMovePolicy, state.FirstPlayerID, state.Ranked).Scan(&id)
+
+	if err != nil {
+		err = models.NewGenericError(err.Error())
+	}
+
+	return id, err
+}
+
+func (r *gameRepositoryImpl) Update(ctx context.Context, game *models.Game) error {
+	sqlStr := `
+		UPDATE games
+		SET current_player_id = $2,
+			board             = $3,
+			phase 			  = $4, 
+			winner_id 		  = $5,
+			finished_at       = CASE WHEN $4 = $6 THEN COALESCE(finished_at, now()) END
+		WHERE id     		  = $1`
+
+	result, err := r.db.ExecContext(ctx, sqlStr, game.ID, game.CurrentPlayerID, game.Board, game.Phase, game.WinnerID, models.GamePhaseCompleted)
+
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return models.NewGenericError(NoRecordsAffectedErrorMsg)
+	}
+
+	return err
+}
+
+// GetVariantState returns the variant and the rule set of the game, the
+// sub-board the player in turn is sent to and how the game was started.
+func (r *gameRepositoryImpl) GetVariantState(ctx context.Context, id uuid.UUID) (*domain.VariantState, error) {
+	sqlStr := `
+		SELECT variant, rule_set, next_board, mark_policy, first_move_policy, first_player_id, ranked
+		FROM games
+		WHERE id = $1`
+
+	var nextBoard sql.NullInt16
+	var firstPlayerID uuid.NullUUID
+	state := &domain.VariantState{}
+	err := r.db.QueryRowContext(ctx, sqlStr, id).Scan(&state.Variant, &state.RuleSet, &nextBoard, &state.MarkPolicy, &state.FirstMovePolicy, &firstPlayerID, &state.Ranked)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.NewNotFoundErrorf("game '%s' not exist", id.String())
+		}
+		return nil, models.NewGenericError(err.Error())
+	}
+
+	if nextBoard.Valid {
+		board := int(nextBoard.Int16)
+		state.NextBoard = &board
+	}
+
+	if firstPlayerID.Valid {
+		state.FirstPlayerID = &firstPlayerID.UUID
+	}
+
+	return state, nil
+}
+
+// SetNextBoard stores the sub-board the player in turn is sent to; nil
+// allows any open sub-board.
+func (r *gameRepositoryImpl) SetNextBoard(ctx context.Context, id uuid.UUID, nextBoard *int) error {
+	sqlStr := `
+		UPDATE games
+		SET next_board = $2
+		WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, sqlStr, id, nextBoard)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return models.NewGenericError(NoRecordsAffectedErrorMsg)
+	}
+
+	return nil
+}
+
+// Lock takes a row lock on the game for the rest of the transaction and
+// reports whether it has been voided.
+func (r *gameRepositoryImpl) Lock(ctx context.Context, id uuid.UUID) (bool, error) {
+	sqlStr := `
+		SELECT g.voided
+		FROM games AS g
+		WHERE g.id = $1
+		FOR UPDATE`
+
+	voided := false
+	err := r.db.QueryRowContext(ctx, sqlStr, id).Scan(&voided)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, models.NewNotFoundErrorf("game '%s' not exist", id.String())
+		} else {
+			return false, models.NewGenericError(err.Error())
+		}
+	}
+
+	return voided, nil
+}
+
+// IsRanked reports whether the game was started in a ranked room, so that
+// its result counts for the stats and the ratings.
+func (r *gameRepositoryImpl) IsRanked(ctx context.Context, id uuid.UUID) (bool, error) {
+	sqlStr := `
+		SELECT g.ranked
+		FROM games AS g
+		WHERE g.id = $1`
+
+	ranked := false
+	err := r.db.QueryRowContext(ctx, sqlStr, id).Scan(&ranked)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, models.NewNotFoundErrorf("game '%s' not exist", id.String())
+		}
+		return false, models.NewGenericError(err.Error())
+	}
+
+	return ranked, nil
+}
+
+// Void marks the game as voided and reverts the rating changes it caused.
+func (r *gameRepositoryImpl) Void(ctx context.Context, id uuid.UUID) error {
+	sqlStr := `
+		WITH g AS (
+			SELECT host_id, guest_id, winner_id, season_id, variant, ranked,
+				COALESCE(host_rating_delta, 0) AS host_delta, COALESCE(guest_rating_delta, 0) AS guest_delta,
+				COALESCE(host_season_rating_delta, 0) AS host_season_delta, COALESCE(guest_season_rating_delta, 0) AS guest_season_delta
+			FROM games
+			WHERE id = $1
+		), ratings AS (
+			UPDATE players_stats AS ps
+			SET rating = ps.rating - CASE WHEN ps.player_id = g.host_id THEN g.host_delta ELSE g.guest_delta END
+			FROM g
+			WHERE ps.player_id IN (g.host_id, g.guest_id)
+		), season AS (
+			UPDATE season_stats AS ss
+			SET rating = ss.rating - CASE WHEN ss.player_id = g.host_id THEN g.host_season_delta ELSE g.guest_season_delta END,
+				wins   = GREATEST(ss.wins - CASE WHEN g.winner_id = ss.player_id THEN 1 ELSE 0 END, 0),
+				losses = GREATEST(ss.losses - CASE WHEN g.winner_id <> ss.player_id THEN 1 ELSE 0 END, 0),
+				draws  = GREATEST(ss.draws - CASE WHEN g.winner_id IS NULL THEN 1 ELSE 0 END, 0)
+			FROM g
+			WHERE ss.season_id = g.season_id AND ss.player_id IN (g.host_id, g.guest_id)
+		), game_stats AS (
+			UPDATE players_game_stats AS pgs
+			SET wins   = GREATEST(pgs.wins - CASE WHEN g.winner_id = pgs.player_id THEN 1 ELSE 0 END, 0),
+				losses = GREATEST(pgs.losses - CASE WHEN g.winner_id <> pgs.player_id THEN 1 ELSE 0 END, 0),
+				draws  = GREATEST(pgs.draws - CASE WHEN g.winner_id IS NULL THEN 1 ELSE 0 END, 0)
+			FROM g
+			WHERE g.ranked AND pgs.variant = g.variant AND pgs.player_id IN (g.host_id, g.guest_id)
+		)
+		UPDATE games
+		SET voided                    = true,
+			phase                     = $2,
+			winner_id                 = NULL,
+			finished_at               = COALESCE(finished_at, now()),
+			host_rating_delta         = NULL,
+			guest_rating_delta        = NULL,
+			season_id                 = NULL,
+			host_season_rating_delta  = NULL,
+			guest_season_rating_delta = NULL
+		WHERE id                      = $1`
+
+	result, err := r.db.ExecContext(ctx, sqlStr, id, models.GamePhaseCompleted)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return models.NewGenericError(NoRecordsAffectedErrorMsg)
+	}
+
+	return nil
+}
+
+func (r *gameRepositoryImpl) SetRatingDeltas(ctx context.Context, id uuid.UUID, hostDelta int, guestDelta int) error {
+	sqlStr := `
+		UPDATE games
+		SET host_rating_delta  = $2,
+			guest_rating_delta = $3
+		WHERE id               = $1`
+
+	result, err := r.db.ExecContext(ctx, sqlStr, id, hostDelta, guestDelta)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return models.NewGenericError(NoRecordsAffectedErrorMsg)
+	}
+
+	return nil
+}
+
+// SetSeasonRatingDeltas records the season of the completed game and the
+// season rating changes of its players so that they can be reverted.
+func (r *gameRepositoryImpl) SetSeasonRatingDeltas(ctx context.Context, id uuid.UUID, seasonID uuid.UUID, hostDelta int, guestDelta int) error {
+	sqlStr := `
+		UPDATE games
+		SET season_id                 = $2,
+			host_season_rating_delta  = $3,
+			guest_season_rating_delta = $4
+		WHERE id                      = $1`
+
+	result, err := r.db.ExecContext(ctx, sqlStr, id, seasonID, hostDelta, guestDelta)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return models.NewGenericError(NoRecordsAffectedErrorMsg)
+	}
+
+	return nil
+}
+
+// leaderboardResults are the results of the ranked games completed since $2.
+const leaderboardResults = `
+		WITH results AS (
+			SELECT g.host_id AS player_id, g.winner_id, COALESCE(g.host_rating_delta, 0) AS delta
+			FROM games AS g
+			WHERE g.phase = $1 AND NOT g.voided AND g.finished_at >= $2 AND g.ranked
+			UNION ALL
+			SELECT g.guest_id AS player_id, g.winner_id, COALESCE(g.guest_rating_delta, 0) AS delta
+			FROM games AS g
+			WHERE g.phase = $1 AND NOT g.voided AND g.finished_at >= $2 AND g.ranked
+		), totals AS (
+			SELECT r.player_id,
+				COUNT(*) FILTER (WHERE r.winner_id = r.player_id) AS wins,
+				COUNT(*) FILTER (WHERE r.winner_id <> r.player_id) AS losses,
+				COUNT(*) FILTER (WHERE r.winner_id IS NULL) AS draws,
+				SUM(r.delta) AS rating
+			FROM results AS r
+			GROUP BY r.player_id
+		)
+		`
+
+// GetLeaderboard ranks the players by the ranked games they completed since
+// the given time. The rating of a standing is the rating change in the period.
+func (r *gameRepositoryImpl) GetLeaderboard(ctx context.Context, from time.Time, page int, pageSize int) ([]*domain.Standing, int, int, int, error) {
+	sqlStr := leaderboardResults + `
+		SELECT COUNT(*)
+		FROM totals AS t
+		INNER JOIN players AS p ON p.id = t.player_id
+		WHERE NOT p.disabled`
+
+	totalCnt := 0
+	err := r.db.QueryRowContext(ctx, sqlStr, models.GamePhaseCompleted, from).Scan(&totalCnt)
+	if err != nil {
+		return nil, 0, 0, 0, models.NewGenericError(err.Error())
+	}
+
+	page, limit, offset := paginate(totalCnt, page, pageSize)
+
+	sqlStr = leaderboardResults + `
+		SELECT RANK() OVER (ORDER BY t.wins DESC, t.draws DESC, t.losses ASC), p.id, p.nickname, t.wins, t.losses, t.draws, t.rating
+		FROM totals AS t
+		INNER JOIN players AS p ON p.id = t.player_id
+		WHERE NOT p.disabled
+		ORDER BY t.wins DESC, t.draws DESC, t.losses ASC, p.nickname ASC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.QueryContext(ctx, sqlStr, models.GamePhaseCompleted, from, limit, offset)
+	if err != nil {
+		return nil, 0, 0, 0, models.NewGenericError(err.Error())
+	}
+	defer rows.Close()
+
+	standings, err := scanStandings(rows)
+	if err != nil {
+		return nil, 0, 0, 0, err
+	}
+
+	return standings, pageSize, page, totalCnt, nil
+}
+
+// GetRecentByPlayer returns the latest finished, not voided games of the
+// player, newest first.
+func (r *gameRepositoryImpl) GetRecentByPlayer(ctx context.Context, playerID uuid.UUID, limit int) ([]*domain.GameSummary, error) {
+	sqlStr := `
+		SELECT
+			g.id,
+			o.id,
+			o.nickname,
+			CASE
+				WHEN g.winner_id IS NULL THEN 'draw'
+				WHEN g.winner_id = $1 THEN 'win'
+				ELSE 'loss'
+			END,
+			COALESCE(CASE WHEN g.host_id = $1 THEN g.host_rating_delta ELSE g.guest_rating_delta END, 0),
+			g.finished_at
+		FROM games AS g
+		JOIN players o ON o.id = CASE WHEN g.host_id = $1 THEN g.guest_id ELSE g.host_id END
+		WHERE (g.host_id = $1 OR g.guest_id = $1)
+			AND g.phase = $2
+			AND g.voided = false
+			AND g.finished_at IS NOT NULL
+		ORDER BY g.finished_at DESC, g.id
+		LIMIT $3`
+
+	rows, err := r.db.QueryContext(ctx, sqlStr, playerID, models.GamePhaseCompleted, limit)
+	if err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+	defer rows.Close()
+
+	games := []*domain.GameSummary{}
+	for rows.Next() {
+		game := &domain.GameSummary{}
+		err = rows.Scan(&game.GameID, &game.OpponentID, &game.OpponentNickname, &game.Result, &game.RatingChange, &game.FinishedAt)
+		if err != nil {
+			return nil, models.NewGenericError(err.Error())
+		}
+		games = append(games, game)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+
+	return games, nil
+}
+
+// AddMove appends the move to the moves of the game.
+func (r *gameRepositoryImpl) AddMove(ctx context.Context, gameID uuid.UUID, move *domain.Move) error {
+	sqlStr := `
+		INSERT INTO game_moves(game_id, seq, player_id, position, mark)
+		SELECT $1, COALESCE(MAX(seq), 0) + 1, $2, $3, $4
+		FROM game_moves
+		WHERE game_id = $1`
+
+	_, err := r.db.ExecContext(ctx, sqlStr, gameID, move.PlayerID, move.Position, move.Mark)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	return nil
+}
+
+// AddHint counts a hint used in the game and returns the hints used so far.
+func (r *gameRepositoryImpl) AddHint(ctx context.Context, id uuid.UUID) (int, error) {
+	sqlStr := `
+		UPDATE games
+		SET hints_used = hints_used + 1
+		WHERE id = $1
+		RETURNING hints_used`
+
+	hintsUsed := 0
+	err := r.db.QueryRowContext(ctx, sqlStr, id).Scan(&hintsUsed)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, models.NewNotFoundErrorf("game '%s' not exist", id.String())
+		}
+		return 0, models.NewGenericError(err.Error())
+	}
+
+	return hintsUsed, nil
+}
+
+// GetMoves returns the moves of the game in the order they were made.
+func (r *gameRepositoryImpl) GetMoves(ctx context.Context, gameID uuid.UUID) ([]*domain.Move, error) {
+	sqlStr := `
+		SELECT seq, player_id, position, mark
+		FROM game_moves
+		WHERE game_id = $1
+		ORDER BY seq`
+
+	rows, err := r.db.QueryContext(ctx, sqlStr, gameID)
+	if err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+	defer rows.Close()
+
+	moves := []*domain.Move{}
+	for rows.Next() {
+		move := &domain.Move{}
+		err = rows.Scan(&move.Seq, &move.PlayerID, &move.Position, &move.Mark)
+		if err != nil {
+			return nil, models.NewGenericError(err.Error())
+		}
+		moves = append(moves, move)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+
+	return moves, nil
+}
+
+type PlayerRepository interface {
+	Get(context.Context, uuid.UUID) (*models.Player, error)
+	GetByLogin(context.Context, string) (*models.Player, error)
+	UpdateStats(context.Context, *models.Player) error
+	GetRanking(context.Context, int, int) ([]*models.Player, int, int, int, error)
+	GetRankingAfter(context.Context, string, int) ([]*models.Player, string, error)
+	UpdateGameStats(context.Context, uuid.UUID, domain.GameVariant, domain.GameResult) error
+	GetGameRanking(context.Context, domain.GameVariant, int, int) ([]*models.Player, int, int, int, error)
+	GetAccount(context.Context, uuid.UUID) (*domain.Account, error)
+	GetAccounts(context.Context, int, int) ([]*domain.Account, int, int, int, error)
+	UpdateRole(context.Context, uuid.UUID, domain.Role) error
+	UpdateDisabled(context.Context, uuid.UUID, bool) error
+	Create(context.Context, *models.Player) (uuid.UUID, error)
+	NicknameExists(context.Context, string) (bool, error)
+	GetRating(context.Context, uuid.UUID) (int, error)
+	AdjustRating(context.Context, uuid.UUID, int) error
+	GetProfile(context.Context, uuid.UUID) (*domain.Profile, error)
+	UpdateNickname(context.Context, uuid.UUID, string) error
+	UpdatePassword(context.Context, uuid.UUID, string) error
+	GetEmail(context.Context, uuid.UUID) (string, error)
+	UpdateEmail(context.Context, uuid.UUID, string) error
+	Anonymize(context.Context, uuid.UUID, string) error
+}
+
+func NewPlayerRepository(db Querier) PlayerRepository {
+	return &playerRepositoryImpl{
+		db: newInstrumentedQuerier(db),
+	}
+}
+
+type playerRepositoryImpl struct {
+	db Querier
+}
+
+func (r *playerRepositoryImpl) Get(ctx context.Context, id uuid.UUID) (*models.Player, error) {
+	sqlStr := `
+		SELECT p.id, p.login, COALESCE(p.password, ''), p.nickname, ps.wins, ps.losses, ps.draws
+		FROM players AS p
+		LEFT JOIN players_stats ps ON ps.player_id = p.id
+		WHERE p.id = $1
+		`
+
+	row := r.db.QueryRowContext(ctx, sqlStr, id)
+	player := &models.Player{}
+
+	err := row.Scan(&player.ID, &player.Login, &player.Password, &player.Nickname, &player.Stats.Wins, &player.Stats.Losses, &player.Stats.Draws)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.NewNotFoundErrorf("player '%s' not exist", id.String())
+		} else {
+			return nil, models.NewGenericError(err.Error())
+		}
+	}
+
+	return player, nil
+}
+
+func (r *playerRepositoryImpl) GetByLogin(ctx context.Context, login string) (*models.Player, error) {
+	sqlStr := `
+		SELECT p.id, p.login, COALESCE(p.password, ''), p.nickname, ps.wins, ps.losses, ps.draws
+		FROM players AS p
+		LEFT JOIN players_stats ps ON ps.player_id = p.id
+		WHERE p.login = $1
+		`
+	player := &models.Player{}
+	row := r.db.QueryRowContext(ctx, sqlStr, login)
+
+	err := row.Scan(&player.ID, &player.Login, &player.Password, &player.Nickname, &player.Stats.Wins, &player.Stats.Losses, &player.Stats.Draws)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.NewNotFoundErrorf("player '%s' not exist", login)
+		} else {
+			return nil, models.NewGenericError(err.Error())
+		}
+	}
+
+	return player, nil
+}
+
+func (r *playerRepositoryImpl) UpdateStats(ctx context.Context, player *models.Player) error {
+	sqlStr := `
+		UPDATE players_stats
+		SET wins    = $2,
+    		losses  = $3,
+    		draws   = $4
+		WHERE player_id = $1`
+
+	result, err := r.db.ExecContext(ctx, sqlStr, player.ID, player.Stats.Wins, player.Stats.Losses, player.Stats.Draws)
+
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return models.NewGenericError(NoRecordsAffectedErrorMsg)
+	}
+
+	return err
+}
+
+func (r *playerRepositoryImpl) GetRanking(ctx context.Context, page int, pageSize int) ([]*models.Player, int, int, int, error) {
+	sqlStr := `
+		SELECT COUNT(*)
+		FROM players AS p
+		WHERE NOT p.disabled
+		`
+
+	totalCnt := 0
+	row := r.db.QueryRowContext(ctx, sqlStr)
+	err := row.Scan(&totalCnt)
+	if err != nil {
+		return nil, 0, 0, 0, models.NewGenericError(err.Error())
+	}
+
+	page, limit, offset := paginate(totalCnt, page, pageSize)
+
+	sqlStr = `
+		SELECT p.id, p.nickname, ps.wins, ps.losses, ps.draws
+		FROM players AS p
+		LEFT JOIN players_stats ps ON ps.player_id = p.id
+		WHERE NOT p.disabled
+		ORDER BY ps.wins DESC, ps.draws DESC, ps.losses ASC, ps.player_id ASC
+		LIMIT $1 OFFSET $2
+		`
+
+	rows, err := r.db.QueryContext(ctx, sqlStr, limit, offset)
+	if err != nil {
+		return nil, 0, 0, 0, models.NewGenericError(err.Error())
+	}
+	defer rows.Close()
+
+	players := make([]*models.Player, 0)
+	for rows.Next() {
+		player := &models.Player{}
+		err := rows.Scan(&player.ID, &player.Nickname, &player.Stats.Wins, &player.Stats.Losses, &player.Stats.Draws)
+		if err != nil {
+			return nil, 0, 0, 0, models.NewGenericError(err.Error())
+		}
+		players = append(players, player)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, 0, models.NewGenericError(err.Error())
+	}
+
+	return players, pageSize, page, totalCnt, nil
+}
+
+type rankingCursor struct {
+	Wins     int       `json:"w"`
+	Draws    int       `json:"d"`
+	Losses   int       `json:"l"`
+	PlayerID uuid.UUID `json:"p"`
+}
+
+// GetRankingAfter returns up to limit players of the ranking that follow the
+// cursor, or the first ones for an empty cursor, and the cursor of the next
+// page. The order is the one of GetRanking; the player id breaks ties. The
+// keys are compared as one row in the order of players_stats_ranking_idx,
+// with the descending ones negated, so that the index serves the query.
+func (r *playerRepositoryImpl) GetRankingAfter(ctx context.Context, cursor string, limit int) ([]*models.Player, string, error) {
+	var after rankingCursor
+	if len(cursor) > 0 {
+		if err := decodeCursor(cursor, &after); err != nil {
+			return nil, "", err
+		}
+	}
+
+	sqlStr := `
+		SELECT p.id, p.nickname, ps.wins, ps.losses, ps.draws
+		FROM players_stats AS ps
+		JOIN players AS p ON p.id = ps.player_id
+		WHERE NOT p.disabled
+			AND ($1 OR (-ps.wins, -ps.draws, ps.losses, ps.player_id) > (-$2::integer, -$3::integer, $4::integer, $5::uuid))
+		ORDER BY -ps.wins, -ps.draws, ps.losses, ps.player_id
+		LIMIT $6
+		`
+
+	rows, err := r.db.QueryContext(ctx, sqlStr, len(cursor) == 0, after.Wins, after.Draws, after.Losses, after.PlayerID, limit+1)
+	if err != nil {
+		return nil, "", models.NewGenericError(err.Error())
+	}
+	defer rows.Close()
+
+	players := make([]*models.Player, 0, limit)
+	next := ""
+	for rows.Next() {
+		player := &models.Player{}
+		err := rows.Scan(&player.ID, &player.Nickname, &player.Stats.Wins, &player.Stats.Losses, &player.Stats.Draws)
+		if err != nil {
+			return nil, "", models.NewGenericError(err.Error())
+		}
+		if len(players) == limit {
+			last := players[limit-1]
+			next = encodeCursor(rankingCursor{Wins: last.Stats.Wins, Draws: last.Stats.Draws, Losses: last.Stats.Losses, PlayerID: last.ID})
+			break
+		}
+		players = append(players, player)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, "", models.NewGenericError(err.Error())
+	}
+
+	return players, next, nil
+}
+
+// UpdateGameStats counts the result of a ranked game in the stats of the
+// player for the game it was played in.
+func (r *playerRepositoryImpl) UpdateGameStats(ctx context.Context, id uuid.UUID, variant domain.GameVariant, result domain.GameResult) error {
+	sqlStr := `
+		INSERT INTO players_game_stats(player_id, variant, wins, losses, draws)
+		VALUES($1, $2,
+			CASE WHEN $3 = 'win' THEN 1 ELSE 0 END,
+			CASE WHEN $3 = 'loss' THEN 1 ELSE 0 END,
+			CASE WHEN $3 = 'draw' THEN 1 ELSE 0 END)
+		ON CONFLICT (player_id, variant) DO UPDATE
+		SET wins   = players_game_stats.wins + EXCLUDED.wins,
+			losses = players_game_stats.losses + EXCLUDED.losses,
+			draws  = players_game_stats.draws + EXCLUDED.draws`
+
+	_, err := r.db.ExecContext(ctx, sqlStr, id, variant, result)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	return nil
+}
+
+// GetGameRanking ranks the players who played ranked games of the variant by
+// their stats in it, in the order of GetRanking.
+func (r *playerRepositoryImpl) GetGameRanking(ctx context.Context, variant domain.GameVariant, page int, pageSize int) ([]*models.Player, int, int, int, error) {
+	sqlStr := `
+		SELECT COUNT(*)
+		FROM players AS p
+		INNER JOIN players_game_stats AS pgs ON pgs.player_id = p.id
+		WHERE NOT p.disabled AND pgs.variant = $1
+		`
+
+	totalCnt := 0
+	err := r.db.QueryRowContext(ctx, sqlStr, variant).Scan(&totalCnt)
+	if err != nil {
+		return nil, 0, 0, 0, models.NewGenericError(err.Error())
+	}
+
+	page, limit, offset := paginate(totalCnt, page, pageSize)
+
+	sqlStr = `
+		SELECT p.id, p.nickname, pgs.wins, pgs.losses, pgs.draws
+		FROM players AS p
+		INNER JOIN players_game_stats AS pgs ON pgs.player_id = p.id
+		WHERE NOT p.disabled AND pgs.variant = $1
+		ORDER BY pgs.wins DESC, pgs.draws DESC, pgs.losses ASC, p.nickname ASC
+		LIMIT $2 OFFSET $3
+		`
+
+	rows, err := r.db.QueryContext(ctx, sqlStr, variant, limit, offset)
+	if err != nil {
+		return nil, 0, 0, 0, models.NewGenericError(err.Error())
+	}
+	defer rows.Close()
+
+	players := make([]*models.Player, 0)
+	for rows.Next() {
+		player := &models.Player{}
+		err := rows.Scan(&player.ID, &player.Nickname, &player.Stats.Wins, &player.Stats.Losses, &player.Stats.Draws)
+		if err != nil {
+			return nil, 0, 0, 0, models.NewGenericError(err.Error())
+		}
+		players = append(players, player)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, 0, models.NewGenericError(err.Error())
+	}
+
+	return players, pageSize, page, totalCnt, nil
+}
+
+func (r *playerRepositoryImpl) GetAccount(ctx context.Context, id uuid.UUID) (*domain.Account, error) {
+	sqlStr := `
+		SELECT p.id, p.login, p.nickname, ps.wins, ps.losses, ps.draws, p.role, p.disabled
+		FROM players AS p
+		LEFT JOIN players_stats ps ON ps.player_id = p.id
+		WHERE p.id = $1
+		`
+
+	account, err := scanAccount(r.db.QueryRowContext(ctx, sqlStr, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.NewNotFoundErrorf("player '%s' not exist", id.String())
+		} else {
+			return nil, models.NewGenericError(err.Error())
+		}
+	}
+
+	return account, nil
+}
+
+func (r *playerRepositoryImpl) GetAccounts(ctx context.Context, page int, pageSize int) ([]*domain.Account, int, int, int, error) {
+	sqlStr := `
+		SELECT COUNT(*)
+		FROM players AS p
+		`
+
+	totalCnt := 0
+	err := r.db.QueryRowContext(ctx, sqlStr).Scan(&totalCnt)
+	if err != nil {
+		return nil, 0, 0, 0, models.NewGenericError(err.Error())
+	}
+
+	page, limit, offset := paginate(totalCnt, page, pageSize)
+
+	sqlStr = `
+		SELECT p.id, p.login, p.nickname, ps.wins, ps.losses, ps.draws, p.role, p.disabled
+		FROM players AS p
+		LEFT JOIN players_stats ps ON ps.player_id = p.id
+		ORDER BY p.login ASC
+		LIMIT $1 OFFSET $2
+		`
+
+	rows, err := r.db.QueryContext(ctx, sqlStr, limit, offset)
+	if err != nil {
+		return nil, 0, 0, 0, models.NewGenericError(err.Error())
+	}
+	defer rows.Close()
+
+	accounts := make([]*domain.Account, 0)
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, 0, 0, 0, models.NewGenericError(err.Error())
 		}
+		accounts = append(accounts, account)
 	}
 
-	if winnerID.Valid {
-		game.WinnerID = &winnerID.UUID
+	if err = rows.Err(); err != nil {
+		return nil, 0, 0, 0, models.NewGenericError(err.Error())
 	}
 
-	return game, nil
+	return accounts, pageSize, page, totalCnt, nil
 }
 
-func (r *gameRepositoryImpl) Create(ctx context.Context, game *models.Game) (uuid.UUID, error) {
+func (r *playerRepositoryImpl) UpdateRole(ctx context.Context, id uuid.UUID, role domain.Role) error {
 	sqlStr := `
-		INSERT INTO games(
-			host_id, 
-			host_mark, 
-			guest_id, 
-			guest_mark, 
-			current_player_id, 
-			phase)
-		VALUES($1, $2, $3, $4, $5, $6)
-		RETURNING id`
-
-	var id uuid.UUID
-	err := r.db.QueryRowContext(ctx, sqlStr, game.Host.ID, game.Host.Mark, game.Guest.ID, game.Guest.Mark, game.CurrentPlayerID, game.Phase).Scan(&id)
+		UPDATE players
+		SET role = $2
+		WHERE id = $1`
 
+	result, err := r.db.ExecContext(ctx, sqlStr, id, role)
 	if err != nil {
-		err = models.NewGenericError(err.Error())
+		return models.NewGenericError(err.Error())
 	}
 
-	return id, err
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return models.NewNotFoundErrorf("player '%s' not exist", id.String())
+	}
+
+	return nil
 }
 
-func (r *gameRepositoryImpl) Update(ctx context.Context, game *models.Game) error {
+func (r *playerRepositoryImpl) UpdateDisabled(ctx context.Context, id uuid.UUID, disabled bool) error {
 	sqlStr := `
-		UPDATE games
-		SET current_player_id = $2,
-			board             = $3,
-			phase 			  = $4, 
-			winner_id 		  = $5
-		WHERE id     		  = $1`
-
-	result, err := r.db.ExecContext(ctx, sqlStr, game.ID, game.CurrentPlayerID, game.Board, game.Phase, game.WinnerID)
+		UPDATE players
+		SET disabled = $2
+		WHERE id = $1`
 
+	result, err := r.db.ExecContext(ctx, sqlStr, id, disabled)
 	if err != nil {
 		return models.NewGenericError(err.Error())
 	}
 
 	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
-		return models.NewGenericError(NoRecordsAffectedErrorMsg)
+		return models.NewNotFoundErrorf("player '%s' not exist", id.String())
 	}
 
-	return err
+	return nil
 }
 
-type PlayerRepository interface {
-	Get(context.Context, uuid.UUID) (*models.Player, error)
-	GetByLogin(context.Context, string) (*models.Player, error)
-	UpdateStats(context.Context, *models.Player) error
-	GetRanking(context.Context, int, int) ([]*models.Player, int, int, int, error)
-}
+// Create inserts the player together with its empty stats. An empty
+// password is stored as NULL; such players can only log in through an
+// external identity.
+func (r *playerRepositoryImpl) Create(ctx context.Context, player *models.Player) (uuid.UUID, error) {
+	sqlStr := `
+		WITH p AS (
+			INSERT INTO players(login, password, nickname)
+			VALUES($1, NULLIF($2, ''), $3)
+			RETURNING id
+		)
+		INSERT INTO players_stats(player_id)
+		SELECT p.id FROM p
+		RETURNING player_id`
 
-func NewPlayerRepository(db Querier) PlayerRepository {
-	return &playerRepositoryImpl{
-		db: db,
+	var id uuid.UUID
+	err := r.db.QueryRowContext(ctx, sqlStr, player.Login, player.Password, player.Nickname).Scan(&id)
+	if err != nil {
+		return uuid.Nil, models.NewGenericError(err.Error())
 	}
-}
 
-type playerRepositoryImpl struct {
-	db Querier
+	return id, nil
 }
 
-func (r *playerRepositoryImpl) Get(ctx context.Context, id uuid.UUID) (*models.Player, error) {
+func (r *playerRepositoryImpl) NicknameExists(ctx context.Context, nickname string) (bool, error) {
 	sqlStr := `
-		SELECT p.id, p.login, p.password, p.nickname, ps.wins, ps.losses, ps.draws
-		FROM players AS p
-		LEFT JOIN players_stats ps ON ps.player_id = p.id
-		WHERE p.id = $1
-		`
+		SELECT EXISTS(SELECT 1 FROM players AS p WHERE p.nickname = $1)`
 
-	row := r.db.QueryRowContext(ctx, sqlStr, id)
-	player := &models.Player{}
+	exists := false
+	err := r.db.QueryRowContext(ctx, sqlStr, nickname).Scan(&exists)
+	if err != nil {
+		return false, models.NewGenericError(err.Error())
+	}
 
-	err := row.Scan(&player.ID, &player.Login, &player.Password, &player.Nickname, &player.Stats.Wins, &player.Stats.Losses, &player.Stats.Draws)
+	return exists, nil
+}
+
+func (r *playerRepositoryImpl) GetRating(ctx context.Context, id uuid.UUID) (int, error) {
+	sqlStr := `
+		SELECT ps.rating
+		FROM players_stats AS ps
+		WHERE ps.player_id = $1`
+
+	rating := 0
+	err := r.db.QueryRowContext(ctx, sqlStr, id).Scan(&rating)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, models.NewNotFoundErrorf("player '%s' not exist", id.String())
+			return 0, models.NewNotFoundErrorf("player '%s' not exist", id.String())
 		} else {
-			return nil, models.NewGenericError(err.Error())
+			return 0, models.NewGenericError(err.Error())
 		}
 	}
 
-	return player, nil
+	return rating, nil
 }
 
-func (r *playerRepositoryImpl) GetByLogin(ctx context.Context, login string) (*models.Player, error) {
+func (r *playerRepositoryImpl) AdjustRating(ctx context.Context, id uuid.UUID, delta int) error {
+	sqlStr := `
+		UPDATE players_stats
+		SET rating = rating + $2
+		WHERE player_id = $1`
+
+	result, err := r.db.ExecContext(ctx, sqlStr, id, delta)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return models.NewGenericError(NoRecordsAffectedErrorMsg)
+	}
+
+	return nil
+}
+
+// GetProfile returns the public profile without recent games. Deleted
+// players have no profile.
+func (r *playerRepositoryImpl) GetProfile(ctx context.Context, id uuid.UUID) (*domain.Profile, error) {
 	sqlStr := `
-		SELECT p.id, p.login, p.password, p.nickname, ps.wins, ps.losses, ps.draws
+		SELECT p.id, p.nickname, ps.wins, ps.losses, ps.draws, ps.rating, ps.current_streak, ps.longest_streak, p.created_at
 		FROM players AS p
 		LEFT JOIN players_stats ps ON ps.player_id = p.id
-		WHERE p.login = $1
+		WHERE p.id = $1 AND p.deleted_at IS NULL
 		`
-	player := &models.Player{}
-	row := r.db.QueryRowContext(ctx, sqlStr, login)
 
-	err := row.Scan(&player.ID, &player.Login, &player.Password, &player.Nickname, &player.Stats.Wins, &player.Stats.Losses, &player.Stats.Draws)
+	profile := &domain.Profile{}
+	err := r.db.QueryRowContext(ctx, sqlStr, id).Scan(&profile.ID, &profile.Nickname,
+		&profile.Stats.Wins, &profile.Stats.Losses, &profile.Stats.Draws, &profile.Rating,
+		&profile.Streak.Current, &profile.Streak.Longest, &profile.JoinedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, models.NewNotFoundErrorf("player '%s' not exist", login)
+			return nil, models.NewNotFoundErrorf("player '%s' not exist", id.String())
 		} else {
 			return nil, models.NewGenericError(err.Error())
 		}
 	}
 
-	return player, nil
+	return profile, nil
 }
 
-func (r *playerRepositoryImpl) UpdateStats(ctx context.Context, player *models.Player) error {
+func (r *playerRepositoryImpl) UpdateNickname(ctx context.Context, id uuid.UUID, nickname string) error {
 	sqlStr := `
-		UPDATE players_stats
-		SET wins    = $2,
-    		losses  = $3,
-    		draws   = $4
-		WHERE player_id = $1`
-
-	result, err := r.db.ExecContext(ctx, sqlStr, player.ID, player.Stats.Wins, player.Stats.Losses, player.Stats.Draws)
+		UPDATE players
+		SET nickname = $2
+		WHERE id = $1`
 
+	result, err := r.db.ExecContext(ctx, sqlStr, id, nickname)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return models.NewValidationErrorf("nickname '%s' is already taken", nickname)
+		}
 		return models.NewGenericError(err.Error())
 	}
 
 	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
-		return models.NewGenericError(NoRecordsAffectedErrorMsg)
+		return models.NewNotFoundErrorf("player '%s' not exist", id.String())
 	}
 
-	return err
+	return nil
 }
 
-func (r *playerRepositoryImpl) GetRanking(ctx context.Context, page int, pageSize int) ([]*models.Player, int, int, int, error) {
+func (r *playerRepositoryImpl) UpdatePassword(ctx context.Context, id uuid.UUID, password string) error {
 	sqlStr := `
-		SELECT COUNT(*)
-		FROM players AS p
-		`
+		UPDATE players
+		SET password = $2
+		WHERE id = $1`
 
-	totalCnt := 0
-	row := r.db.QueryRowContext(ctx, sqlStr)
-	err := row.Scan(&totalCnt)
+	result, err := r.db.ExecContext(ctx, sqlStr, id, password)
 	if err != nil {
-		return nil, 0, 0, 0, models.NewGenericError(err.Error())
+		return models.NewGenericError(err.Error())
 	}
 
-	lastPage := 0
-	if pageSize > 0 && totalCnt > 0 {
-		lastPage = (totalCnt + pageSize - 1) / pageSize
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return models.NewNotFoundErrorf("player '%s' not exist", id.String())
 	}
 
-	pageForQuery := page
-	if lastPage == 0 {
-		pageForQuery = 1
-	} else {
-		if pageForQuery < 1 {
-			pageForQuery = 1
-		} else if pageForQuery > lastPage {
-			pageForQuery = lastPage
+	return nil
+}
+
+// GetEmail returns the email address of the player, empty when none is set.
+func (r *playerRepositoryImpl) GetEmail(ctx context.Context, id uuid.UUID) (string, error) {
+	sqlStr := `
+		SELECT COALESCE(p.email, '')
+		FROM players AS p
+		WHERE p.id = $1`
+
+	email := ""
+	err := r.db.QueryRowContext(ctx, sqlStr, id).Scan(&email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", models.NewNotFoundErrorf("player '%s' not exist", id.String())
+		} else {
+			return "", models.NewGenericError(err.Error())
 		}
 	}
 
-	limit := pageSize
-	offset := (pageForQuery - 1) * pageSize
+	return email, nil
+}
+
+// UpdateEmail sets the email address of the player; an empty one removes it.
+func (r *playerRepositoryImpl) UpdateEmail(ctx context.Context, id uuid.UUID, email string) error {
+	sqlStr := `
+		UPDATE players
+		SET email = NULLIF($2, '')
+		WHERE id = $1`
 
-	if lastPage == 0 {
-		page = 0
-	} else {
-		page = pageForQuery
+	result, err := r.db.ExecContext(ctx, sqlStr, id, email)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.NewValidationErrorf("email '%s' is already in use", email)
+		}
+		return models.NewGenericError(err.Error())
 	}
 
-	sqlStr = `
-		SELECT p.id, p.nickname, ps.wins, ps.losses, ps.draws
-		FROM players AS p
-		LEFT JOIN players_stats ps ON ps.player_id = p.id
-		ORDER BY ps.wins DESC, ps.draws DESC, ps.losses ASC, p.nickname ASC
-		LIMIT $1 OFFSET $2
-		`
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return models.NewNotFoundErrorf("player '%s' not exist", id.String())
+	}
 
-	rows, err := r.db.QueryContext(ctx, sqlStr, limit, offset)
+	return nil
+}
+
+// Anonymize removes the personal data of the player while keeping the row
+// so that game history stays consistent. The player can no longer log in and
+// is shown under the given placeholder nickname.
+func (r *playerRepositoryImpl) Anonymize(ctx context.Context, id uuid.UUID, nickname string) error {
+	sqlStr := `
+		WITH identities AS (
+			DELETE FROM player_identities WHERE player_id = $1
+		), recovery_codes AS (
+			DELETE FROM player_recovery_codes WHERE player_id = $1
+		), two_factor AS (
+			DELETE FROM player_two_factor WHERE player_id = $1
+		), lockouts AS (
+			DELETE FROM account_lockouts WHERE player_id = $1
+		), reset_tokens AS (
+			DELETE FROM password_reset_tokens WHERE player_id = $1
+		), friendships AS (
+			DELETE FROM friendships WHERE requester_id = $1 OR addressee_id = $1
+		), blocks AS (
+			DELETE FROM player_blocks WHERE player_id = $1 OR blocked_id = $1
+		), invitations AS (
+			DELETE FROM room_invitations WHERE player_id = $1
+		)
+		UPDATE players
+		SET login        = 'deleted:' || id::text,
+			nickname     = $2,
+			password     = NULL,
+			email        = NULL,
+			last_seen_at = NULL,
+			disabled     = true,
+			deleted_at   = now()
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, sqlStr, id, nickname)
 	if err != nil {
-		return nil, 0, 0, 0, models.NewGenericError(err.Error())
+		return models.NewGenericError(err.Error())
 	}
-	defer rows.Close()
 
-	players := make([]*models.Player, 0)
-	for rows.Next() {
-		player := &models.Player{}
-		err := rows.Scan(&player.ID, &player.Nickname, &player.Stats.Wins, &player.Stats.Losses, &player.Stats.Draws)
-		if err != nil {
-			return nil, 0, 0, 0, models.NewGenericError(err.Error())
-		}
-		players = append(players, player)
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return models.NewNotFoundErrorf("player '%s' not exist", id.String())
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, 0, 0, 0, models.NewGenericError(err.Error())
+	return nil
+}
+
+func scanAccount(row rowScanner) (*domain.Account, error) {
+	account := &domain.Account{}
+	err := row.Scan(&account.ID, &account.Login, &account.Nickname,
+		&account.Stats.Wins, &account.Stats.Losses, &account.Stats.Draws,
+		&account.Role, &account.Disabled)
+	if err != nil {
+		return nil, err
 	}
 
-	return players, pageSize, page, totalCnt, nil
+	return account, nil
 }
 
 type RoomRepository interface {
 	Get(context.Context, uuid.UUID, bool) (*models.Room, error)
 	GetByPlayerID(context.Context, uuid.UUID) (*models.Room, error)
-	GetList(context.Context, models.RoomPhase, int, int) ([]*models.Room, int, int, int, error)
-	Create(context.Context, *models.Room) (uuid.UUID, error)
+	GetList(context.Context, models.RoomPhase, *domain.RoomFilter, int, int) ([]*models.Room, int, int, int, error)
+	GetListAfter(context.Context, models.RoomPhase, *domain.RoomFilter, string, int) ([]*models.Room, string, error)
+	Create(context.Context, *models.Room, *domain.RoomSettings) (uuid.UUID, error)
+	GetSettings(context.Context, uuid.UUID) (*domain.RoomSettings, error)
 	Update(context.Context, *models.Room) error
 	Delete(context.Context, uuid.UUID) error
 }
 
 func NewRoomRepository(db Querier) RoomRepository {
 	return &roomRepositoryImpl{
-		db: db,
+		db: newInstrumentedQuerier(db),
 	}
 }
 
@@ -502,58 +1363,85 @@ func (r *roomRepositoryImpl) GetByPlayerID(ctx context.Context, playerID uuid.UU
 	return room, nil
 }
 
-func (r *roomRepositoryImpl) GetList(ctx context.Context, phase models.RoomPhase, page int, pageSize int) ([]*models.Room, int, int, int, error) {
-	sqlStr := `
-		SELECT COUNT(*)
+// roomListFilter selects the rooms matching a domain.RoomFilter; $1 is the
+// phase, $2..$5 the filter values, $6 the rating of hosts without stats and
+// $7 the variant and $8 the time control.
+const roomListFilter = `
 		FROM rooms AS r
-		WHERE (r.phase = $1)
+		INNER JOIN players AS ph ON ph.id = r.host_id
+		LEFT JOIN players_stats AS ps ON ps.player_id = r.host_id
+		WHERE r.phase = $1
+			AND NOT EXISTS (SELECT 1 FROM room_invitations AS ri WHERE ri.room_id = r.id)
+			AND ($2 = '' OR r.title ILIKE '%' || $2 || '%')
+			AND ($3 = '' OR ph.nickname ILIKE '%' || $3 || '%')
+			AND ($4::integer IS NULL OR COALESCE(ps.rating, $6) >= $4)
+			AND ($5::integer IS NULL OR COALESCE(ps.rating, $6) <= $5)
+			AND ($7 = '' OR r.variant = $7)
+			AND ($8 = '' OR r.time_control = $8)
 		`
-	totalCnt := 0
-	row := r.db.QueryRowContext(ctx, sqlStr, phase)
-	err := row.Scan(&totalCnt)
-	if err != nil {
-		return nil, 0, 0, 0, models.NewGenericError(err.Error())
-	}
 
-	lastPage := 0
-	if pageSize > 0 && totalCnt > 0 {
-		lastPage = (totalCnt + pageSize - 1) / pageSize
-	}
+// roomListOrder ends with the id so that rooms created at the same time keep
+// their order between pages.
+var roomListOrder = map[domain.RoomSort]string{
+	domain.RoomSortNewest: "r.created_at DESC, r.id DESC",
+	domain.RoomSortRating: "COALESCE(ps.rating, $6) DESC, r.created_at DESC, r.id DESC",
+}
 
-	pageForQuery := page
-	if lastPage == 0 {
-		pageForQuery = 1
-	} else {
-		if pageForQuery < 1 {
-			pageForQuery = 1
-		} else if pageForQuery > lastPage {
-			pageForQuery = lastPage
-		}
+// roomListKey is the leading sort key of roomListOrder that the cursor
+// compares besides the creation time and the id.
+var roomListKey = map[domain.RoomSort]string{
+	domain.RoomSortNewest: "0",
+	domain.RoomSortRating: "COALESCE(ps.rating, $6)",
+}
+
+type roomCursor struct {
+	Sort      domain.RoomSort `json:"s"`
+	Key       int             `json:"k"`
+	CreatedAt time.Time       `json:"c"`
+	ID        uuid.UUID       `json:"i"`
+}
+
+// likeEscaper makes user input match literally in a LIKE pattern, with the
+// default escape character of PostgreSQL.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+func (r *roomRepositoryImpl) GetList(ctx context.Context, phase models.RoomPhase, filter *domain.RoomFilter, page int, pageSize int) ([]*models.Room, int, int, int, error) {
+	if filter == nil {
+		filter = &domain.RoomFilter{}
 	}
+	order, ok := roomListOrder[filter.Sort]
+	if !ok {
+		order = roomListOrder[domain.RoomSortNewest]
+	}
+	args := []any{phase, escapeLike(filter.Title), escapeLike(filter.HostNickname), filter.MinRating, filter.MaxRating, domain.DefaultRating, filter.Variant, filter.TimeControl}
 
-	limit := pageSize
-	offset := (pageForQuery - 1) * pageSize
+	sqlStr := `
+		SELECT COUNT(*)` + roomListFilter
 
-	if lastPage == 0 {
-		page = 0
-	} else {
-		page = pageForQuery
+	totalCnt := 0
+	err := r.db.QueryRowContext(ctx, sqlStr, args...).Scan(&totalCnt)
+	if err != nil {
+		return nil, 0, 0, 0, models.NewGenericError(err.Error())
 	}
 
+	page, limit, offset := paginate(totalCnt, page, pageSize)
+
 	sqlStr = `
-		SELECT 
+		SELECT
 			r.id,
-			ph.id AS host_id, 
+			ph.id AS host_id,
 			ph.nickname AS host_nickname,
-			r.title, 
-			r.description, 
-			r.phase
-		FROM rooms AS r
-		INNER JOIN players AS ph ON ph.id = r.host_id
-		WHERE (r.phase = $1)
-		LIMIT $2 OFFSET $3
+			r.title,
+			r.description,
+			r.phase` + roomListFilter + `
+		ORDER BY ` + order + `
+		LIMIT $9 OFFSET $10
 		`
-	rows, err := r.db.QueryContext(ctx, sqlStr, phase, limit, offset)
+	rows, err := r.db.QueryContext(ctx, sqlStr, append(args, limit, offset)...)
 	if err != nil {
 		return nil, 0, 0, 0, models.NewGenericError(err.Error())
 	}
@@ -582,14 +1470,91 @@ func (r *roomRepositoryImpl) GetList(ctx context.Context, phase models.RoomPhase
 	return rooms, pageSize, page, totalCnt, nil
 }
 
-func (r *roomRepositoryImpl) Create(ctx context.Context, room *models.Room) (uuid.UUID, error) {
+// GetListAfter returns up to limit rooms that follow the cursor in the order
+// of the filter, or the first ones for an empty cursor, and the cursor of the
+// next page. A cursor is only valid with the sort it was issued for.
+func (r *roomRepositoryImpl) GetListAfter(ctx context.Context, phase models.RoomPhase, filter *domain.RoomFilter, cursor string, limit int) ([]*models.Room, string, error) {
+	if filter == nil {
+		filter = &domain.RoomFilter{}
+	}
+	sort := filter.Sort
+	if _, ok := roomListOrder[sort]; !ok {
+		sort = domain.RoomSortNewest
+	}
+
+	var after roomCursor
+	if len(cursor) > 0 {
+		if err := decodeCursor(cursor, &after); err != nil {
+			return nil, "", err
+		}
+		if after.Sort != sort {
+			return nil, "", models.NewValidationError(InvalidCursorErrorMessage)
+		}
+	}
+
+	sqlStr := `
+		SELECT
+			r.id,
+			ph.id AS host_id,
+			ph.nickname AS host_nickname,
+			r.title,
+			r.description,
+			r.phase,
+			r.created_at,
+			` + roomListKey[sort] + roomListFilter + `
+			AND ($9 OR (` + roomListKey[sort] + `, r.created_at, r.id) < ($10::integer, $11::timestamptz, $12::uuid))
+		ORDER BY ` + roomListOrder[sort] + `
+		LIMIT $13
+		`
+	args := []any{phase, escapeLike(filter.Title), escapeLike(filter.HostNickname), filter.MinRating, filter.MaxRating, domain.DefaultRating, filter.Variant, filter.TimeControl,
+		len(cursor) == 0, after.Key, after.CreatedAt, after.ID, limit + 1}
+	rows, err := r.db.QueryContext(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, "", models.NewGenericError(err.Error())
+	}
+	defer rows.Close()
+
+	rooms := make([]*models.Room, 0, limit)
+	next := ""
+	var last roomCursor
+	var sqlDescription sql.NullString
+	for rows.Next() {
+		room := &models.Room{}
+		var current roomCursor
+		err := rows.Scan(&room.ID, &room.Host.ID, &room.Host.Nickname, &room.Title, &sqlDescription, &room.Phase, &current.CreatedAt, &current.Key)
+		if err != nil {
+			return nil, "", models.NewGenericError(err.Error())
+		}
+		if len(rooms) == limit {
+			next = encodeCursor(last)
+			break
+		}
+
+		if sqlDescription.Valid {
+			room.Description = sqlDescription.String
+		}
+		current.Sort = sort
+		current.ID = room.ID
+		last = current
+
+		rooms = append(rooms, room)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, "", models.NewGenericError(err.Error())
+	}
+
+	return rooms, next, nil
+}
+
+func (r *roomRepositoryImpl) Create(ctx context.Context, room *models.Room, settings *domain.RoomSettings) (uuid.UUID, error) {
 	sqlStr := `
-		INSERT INTO rooms(host_id, host_continue, title, description, phase)
-		VALUES($1, $2, $3, $4, $5)
+		INSERT INTO rooms(host_id, host_continue, title, description, phase, ranked, variant, rule_set, mark_policy, first_move_policy, time_control)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id
 		`
 	var id uuid.UUID
-	err := r.db.QueryRowContext(ctx, sqlStr, room.Host.ID, room.Host.Continue, room.Title, room.Description, room.Phase).Scan(&id)
+	err := r.db.QueryRowContext(ctx, sqlStr, room.Host.ID, room.Host.Continue, room.Title, room.Description, room.Phase, settings.Ranked, settings.Variant, settings.RuleSet, settings.MarkPolicy, settings.FirstMovePolicy, settings.TimeControl).Scan(&id)
 	if err != nil {
 		err = models.NewGenericError(err.Error())
 	}
@@ -597,6 +1562,24 @@ func (r *roomRepositoryImpl) Create(ctx context.Context, room *models.Room) (uui
 	return id, err
 }
 
+func (r *roomRepositoryImpl) GetSettings(ctx context.Context, id uuid.UUID) (*domain.RoomSettings, error) {
+	sqlStr := `
+		SELECT ranked, variant, rule_set, mark_policy, first_move_policy, time_control
+		FROM rooms
+		WHERE id = $1`
+
+	settings := &domain.RoomSettings{}
+	err := r.db.QueryRowContext(ctx, sqlStr, id).Scan(&settings.Ranked, &settings.Variant, &settings.RuleSet, &settings.MarkPolicy, &settings.FirstMovePolicy, &settings.TimeControl)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.NewNotFoundErrorf("room '%s' not exist", id.String())
+		}
+		return nil, models.NewGenericError(err.Error())
+	}
+
+	return settings, nil
+}
+
 func (r *roomRepositoryImpl) Update(ctx context.Context, room *models.Room) error {
 	sqlStr := `
 		UPDATE rooms
@@ -645,3 +1628,37 @@ func (r *roomRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error {
 
 	return err
 }
+
+type SchemaRepository interface {
+	GetVersion(context.Context) (int, error)
+}
+
+func NewSchemaRepository(db Querier) SchemaRepository {
+	return &schemaRepositoryImpl{
+		db: newInstrumentedQuerier(db),
+	}
+}
+
+type schemaRepositoryImpl struct {
+	db Querier
+}
+
+func (r *schemaRepositoryImpl) GetVersion(ctx context.Context) (int, error) {
+	sqlStr := `
+		SELECT COALESCE(MAX(m.version), 0)
+		FROM schema_migrations AS m
+		`
+
+	version := 0
+	err := r.db.QueryRowContext(ctx, sqlStr).Scan(&version)
+	if err != nil {
+		return 0, models.NewGenericError(err.Error())
+	}
+
+	return version, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
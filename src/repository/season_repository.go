@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+)
+
+var SeasonOverlapErrorMessage string = "season overlaps with another season"
+
+type SeasonRepository interface {
+	Create(context.Context, *domain.Season) (uuid.UUID, error)
+	Get(context.Context, uuid.UUID) (*domain.Season, error)
+	GetList(context.Context) ([]*domain.Season, error)
+	GetCurrent(context.Context, time.Time) (*domain.Season, error)
+	GetRating(context.Context, uuid.UUID, uuid.UUID) (int, error)
+	RecordResult(context.Context, uuid.UUID, uuid.UUID, domain.GameResult, int) error
+	GetStandings(context.Context, *domain.Season, int, int) ([]*domain.Standing, int, int, int, error)
+	Archive(context.Context, uuid.UUID) error
+}
+
+func NewSeasonRepository(db Querier) SeasonRepository {
+	return &seasonRepositoryImpl{
+		db: newInstrumentedQuerier(db),
+	}
+}
+
+type seasonRepositoryImpl struct {
+	db Querier
+}
+
+const seasonSelect = `
+		SELECT s.id, s.name, s.starts_at, s.ends_at, s.archived_at
+		FROM seasons AS s
+		`
+
+// Create adds the season unless it overlaps with an existing one.
+func (r *seasonRepositoryImpl) Create(ctx context.Context, season *domain.Season) (uuid.UUID, error) {
+	sqlStr := `
+		INSERT INTO seasons(name, starts_at, ends_at)
+		SELECT $1, $2, $3
+		WHERE NOT EXISTS (
+			SELECT 1
+			FROM seasons
+			WHERE starts_at < $3 AND ends_at > $2
+		)
+		RETURNING id`
+
+	var id uuid.UUID
+	err := r.db.QueryRowContext(ctx, sqlStr, season.Name, season.StartsAt, season.EndsAt).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, models.NewValidationError(SeasonOverlapErrorMessage)
+		}
+		return uuid.Nil, models.NewGenericError(err.Error())
+	}
+
+	return id, nil
+}
+
+func (r *seasonRepositoryImpl) Get(ctx context.Context, id uuid.UUID) (*domain.Season, error) {
+	sqlStr := seasonSelect + `WHERE s.id = $1`
+
+	season, err := scanSeason(r.db.QueryRowContext(ctx, sqlStr, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.NewNotFoundErrorf("season '%s' not exist", id.String())
+		}
+		return nil, models.NewGenericError(err.Error())
+	}
+
+	return season, nil
+}
+
+// GetList returns all seasons, newest first.
+func (r *seasonRepositoryImpl) GetList(ctx context.Context) ([]*domain.Season, error) {
+	sqlStr := seasonSelect + `ORDER BY s.starts_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, sqlStr)
+	if err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+	defer rows.Close()
+
+	seasons := []*domain.Season{}
+	for rows.Next() {
+		season, err := scanSeason(rows)
+		if err != nil {
+			return nil, models.NewGenericError(err.Error())
+		}
+		seasons = append(seasons, season)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+
+	return seasons, nil
+}
+
+// GetCurrent returns the season running at the given time.
+func (r *seasonRepositoryImpl) GetCurrent(ctx context.Context, at time.Time) (*domain.Season, error) {
+	sqlStr := seasonSelect + `
+		WHERE s.starts_at <= $1 AND s.ends_at > $1
+		LIMIT 1`
+
+	season, err := scanSeason(r.db.QueryRowContext(ctx, sqlStr, at))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.NewNotFoundError("no season is running")
+		}
+		return nil, models.NewGenericError(err.Error())
+	}
+
+	return season, nil
+}
+
+// GetRating returns the season rating of the player. The first time the
+// player is seen in a season the rating is seeded by a soft reset: it keeps
+// SeasonRatingCarryOver of the distance of the rating in the previous season
+// from DefaultRating.
+func (r *seasonRepositoryImpl) GetRating(ctx context.Context, seasonID uuid.UUID, playerID uuid.UUID) (int, error) {
+	sqlStr := `
+		WITH previous AS (
+			SELECT ss.rating
+			FROM season_stats AS ss
+			INNER JOIN seasons AS s ON s.id = ss.season_id
+			WHERE ss.player_id = $2
+				AND s.ends_at <= (SELECT starts_at FROM seasons WHERE id = $1)
+			ORDER BY s.ends_at DESC
+			LIMIT 1
+		), inserted AS (
+			INSERT INTO season_stats(season_id, player_id, rating)
+			SELECT $1, $2, $3 + ROUND((COALESCE((SELECT rating FROM previous), $3) - $3) * $4)::integer
+			ON CONFLICT (season_id, player_id) DO NOTHING
+			RETURNING rating
+		)
+		SELECT rating FROM inserted
+		UNION ALL
+		SELECT rating FROM season_stats WHERE season_id = $1 AND player_id = $2
+		LIMIT 1`
+
+	rating := 0
+	err := r.db.QueryRowContext(ctx, sqlStr, seasonID, playerID, domain.DefaultRating, domain.SeasonRatingCarryOver).Scan(&rating)
+	if err != nil {
+		return 0, models.NewGenericError(err.Error())
+	}
+
+	return rating, nil
+}
+
+// RecordResult adds the game result and the rating change to the season stats
+// of the player. GetRating must have been called for the player first.
+func (r *seasonRepositoryImpl) RecordResult(ctx context.Context, seasonID uuid.UUID, playerID uuid.UUID, result domain.GameResult, ratingDelta int) error {
+	sqlStr := `
+		UPDATE season_stats
+		SET wins   = wins + $3,
+			losses = losses + $4,
+			draws  = draws + $5,
+			rating = rating + $6
+		WHERE season_id = $1 AND player_id = $2`
+
+	wins, losses, draws := 0, 0, 0
+	switch result {
+	case domain.GameResultWin:
+		wins = 1
+	case domain.GameResultLoss:
+		losses = 1
+	default:
+		draws = 1
+	}
+
+	res, err := r.db.ExecContext(ctx, sqlStr, seasonID, playerID, wins, losses, draws, ratingDelta)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return models.NewGenericError(NoRecordsAffectedErrorMsg)
+	}
+
+	return nil
+}
+
+// GetStandings returns a page of the standings of the season. Archived
+// seasons are read from their final standings, running ones are ranked live.
+func (r *seasonRepositoryImpl) GetStandings(ctx context.Context, season *domain.Season, page int, pageSize int) ([]*domain.Standing, int, int, int, error) {
+	countStr := `
+		SELECT COUNT(*)
+		FROM season_stats AS ss
+		INNER JOIN players AS p ON p.id = ss.player_id
+		WHERE ss.season_id = $1 AND NOT p.disabled`
+	sqlStr := `
+		SELECT RANK() OVER (ORDER BY ss.rating DESC), p.id, p.nickname, ss.wins, ss.losses, ss.draws, ss.rating
+		FROM season_stats AS ss
+		INNER JOIN players AS p ON p.id = ss.player_id
+		WHERE ss.season_id = $1 AND NOT p.disabled
+		ORDER BY ss.rating DESC, ss.wins DESC, p.nickname ASC
+		LIMIT $2 OFFSET $3`
+	if season.ArchivedAt != nil {
+		countStr = `
+		SELECT COUNT(*)
+		FROM season_standings
+		WHERE season_id = $1`
+		sqlStr = `
+		SELECT st.rank, p.id, p.nickname, st.wins, st.losses, st.draws, st.rating
+		FROM season_standings AS st
+		INNER JOIN players AS p ON p.id = st.player_id
+		WHERE st.season_id = $1
+		ORDER BY st.rank ASC, st.wins DESC, p.nickname ASC
+		LIMIT $2 OFFSET $3`
+	}
+
+	totalCnt := 0
+	err := r.db.QueryRowContext(ctx, countStr, season.ID).Scan(&totalCnt)
+	if err != nil {
+		return nil, 0, 0, 0, models.NewGenericError(err.Error())
+	}
+
+	page, limit, offset := paginate(totalCnt, page, pageSize)
+
+	rows, err := r.db.QueryContext(ctx, sqlStr, season.ID, limit, offset)
+	if err != nil {
+		return nil, 0, 0, 0, models.NewGenericError(err.Error())
+	}
+	defer rows.Close()
+
+	standings, err := scanStandings(rows)
+	if err != nil {
+		return nil, 0, 0, 0, err
+	}
+
+	return standings, pageSize, page, totalCnt, nil
+}
+
+// Archive stores the final standings of the season and marks it archived.
+func (r *seasonRepositoryImpl) Archive(ctx context.Context, id uuid.UUID) error {
+	sqlStr := `
+		WITH standings AS (
+			INSERT INTO season_standings(season_id, player_id, rank, wins, losses, draws, rating)
+			SELECT season_id, player_id, RANK() OVER (ORDER BY rating DESC), wins, losses, draws, rating
+			FROM season_stats
+			WHERE season_id = $1
+			ON CONFLICT (season_id, player_id) DO NOTHING
+		)
+		UPDATE seasons
+		SET archived_at = now()
+		WHERE id = $1 AND archived_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, sqlStr, id)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return models.NewGenericError(NoRecordsAffectedErrorMsg)
+	}
+
+	return nil
+}
+
+func scanSeason(row rowScanner) (*domain.Season, error) {
+	season := &domain.Season{}
+	var archivedAt sql.NullTime
+	err := row.Scan(&season.ID, &season.Name, &season.StartsAt, &season.EndsAt, &archivedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if archivedAt.Valid {
+		season.ArchivedAt = &archivedAt.Time
+	}
+
+	return season, nil
+}
+
+func scanStandings(rows *sql.Rows) ([]*domain.Standing, error) {
+	standings := []*domain.Standing{}
+	for rows.Next() {
+		standing := &domain.Standing{}
+		err := rows.Scan(&standing.Rank, &standing.PlayerID, &standing.Nickname,
+			&standing.Stats.Wins, &standing.Stats.Losses, &standing.Stats.Draws, &standing.Rating)
+		if err != nil {
+			return nil, models.NewGenericError(err.Error())
+		}
+		standings = append(standings, standing)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+
+	return standings, nil
+}
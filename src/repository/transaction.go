@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/plamen-v/tic-tac-toe/src/services/logger"
+)
+
+func WithTransactionT[T any](ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) (T, error)) (result T, err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			rollback(ctx, tx)
+			panic(p)
+		} else if err != nil {
+			rollback(ctx, tx)
+		} else {
+			err = tx.Commit()
+			if err != nil {
+				logger.FromContext(ctx).Error("transaction commit failed", logger.Err(err))
+			}
+		}
+	}()
+
+	result, err = fn(tx)
+	return result, err
+}
+
+func WithTransaction(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			rollback(ctx, tx)
+			panic(p)
+		} else if err != nil {
+			rollback(ctx, tx)
+		} else {
+			err = tx.Commit()
+			if err != nil {
+				logger.FromContext(ctx).Error("transaction commit failed", logger.Err(err))
+			}
+		}
+	}()
+
+	err = fn(tx)
+	return err
+}
+
+func rollback(ctx context.Context, tx *sql.Tx) {
+	if err := tx.Rollback(); err != nil {
+		logger.FromContext(ctx).Error("transaction rollback failed", logger.Err(err))
+	}
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/gofrs/uuid"
+	"github.com/lib/pq"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+)
+
+type TwoFactorRepository interface {
+	Get(context.Context, uuid.UUID, bool) (*domain.TwoFactor, error)
+	Save(context.Context, *domain.TwoFactor) error
+	Delete(context.Context, uuid.UUID) error
+	ReplaceRecoveryCodes(context.Context, uuid.UUID, []string) error
+	UseRecoveryCode(context.Context, uuid.UUID, string) (bool, error)
+	CountRecoveryCodes(context.Context, uuid.UUID) (int, error)
+}
+
+func NewTwoFactorRepository(db Querier) TwoFactorRepository {
+	return &twoFactorRepositoryImpl{
+		db: newInstrumentedQuerier(db),
+	}
+}
+
+type twoFactorRepositoryImpl struct {
+	db Querier
+}
+
+// Get returns the enrolment of the player. With lock set the row stays locked
+// until the surrounding transaction ends.
+func (r *twoFactorRepositoryImpl) Get(ctx context.Context, playerID uuid.UUID, lock bool) (*domain.TwoFactor, error) {
+	sqlStr := `
+		SELECT tf.player_id, tf.secret, tf.enabled, tf.last_used_step, tf.confirmed_at
+		FROM player_two_factor AS tf
+		WHERE tf.player_id = $1
+		`
+	if lock {
+		sqlStr += " FOR UPDATE"
+	}
+
+	twoFactor := &domain.TwoFactor{}
+	var confirmedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, sqlStr, playerID).Scan(
+		&twoFactor.PlayerID, &twoFactor.Secret, &twoFactor.Enabled, &twoFactor.LastUsedStep, &confirmedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.NewNotFoundErrorf("two-factor enrolment of player '%s' not exist", playerID.String())
+		} else {
+			return nil, models.NewGenericError(err.Error())
+		}
+	}
+
+	if confirmedAt.Valid {
+		twoFactor.ConfirmedAt = &confirmedAt.Time
+	}
+
+	return twoFactor, nil
+}
+
+func (r *twoFactorRepositoryImpl) Save(ctx context.Context, twoFactor *domain.TwoFactor) error {
+	sqlStr := `
+		INSERT INTO player_two_factor(player_id, secret, enabled, last_used_step, confirmed_at)
+		VALUES($1, $2, $3, $4, $5)
+		ON CONFLICT (player_id) DO UPDATE
+		SET secret         = EXCLUDED.secret,
+			enabled        = EXCLUDED.enabled,
+			last_used_step = EXCLUDED.last_used_step,
+			confirmed_at   = EXCLUDED.confirmed_at`
+
+	_, err := r.db.ExecContext(ctx, sqlStr, twoFactor.PlayerID, twoFactor.Secret, twoFactor.Enabled, twoFactor.LastUsedStep, twoFactor.ConfirmedAt)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	return nil
+}
+
+// Delete removes the enrolment together with its recovery codes.
+func (r *twoFactorRepositoryImpl) Delete(ctx context.Context, playerID uuid.UUID) error {
+	sqlStr := `
+		WITH codes AS (
+			DELETE FROM player_recovery_codes WHERE player_id = $1
+		)
+		DELETE FROM player_two_factor
+		WHERE player_id = $1`
+
+	_, err := r.db.ExecContext(ctx, sqlStr, playerID)
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes discards all recovery codes of the player and stores
+// the given hashes instead.
+func (r *twoFactorRepositoryImpl) ReplaceRecoveryCodes(ctx context.Context, playerID uuid.UUID, hashes []string) error {
+	sqlStr := `
+		WITH codes AS (
+			DELETE FROM player_recovery_codes WHERE player_id = $1
+		)
+		INSERT INTO player_recovery_codes(player_id, code_hash)
+		SELECT $1, UNNEST($2::text[])`
+
+	_, err := r.db.ExecContext(ctx, sqlStr, playerID, pq.Array(hashes))
+	if err != nil {
+		return models.NewGenericError(err.Error())
+	}
+
+	return nil
+}
+
+// UseRecoveryCode marks the code as used and reports whether it was valid
+// and unused.
+func (r *twoFactorRepositoryImpl) UseRecoveryCode(ctx context.Context, playerID uuid.UUID, hash string) (bool, error) {
+	sqlStr := `
+		UPDATE player_recovery_codes
+		SET used_at = now()
+		WHERE player_id = $1 AND code_hash = $2 AND used_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, sqlStr, playerID, hash)
+	if err != nil {
+		return false, models.NewGenericError(err.Error())
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+func (r *twoFactorRepositoryImpl) CountRecoveryCodes(ctx context.Context, playerID uuid.UUID) (int, error) {
+	sqlStr := `
+		SELECT COUNT(*)
+		FROM player_recovery_codes AS rc
+		WHERE rc.player_id = $1 AND rc.used_at IS NULL
+		`
+
+	count := 0
+	err := r.db.QueryRowContext(ctx, sqlStr, playerID).Scan(&count)
+	if err != nil {
+		return 0, models.NewGenericError(err.Error())
+	}
+
+	return count, nil
+}
@@ -0,0 +1,241 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/services/logger"
+	"github.com/plamen-v/tic-tac-toe/src/services/metrics"
+)
+
+var (
+	InvalidRoleErrorMessage       string = "invalid role"
+	InvalidStatsErrorMessage      string = "stats can not be negative"
+	SelfModerationErrorMessage    string = "can not change own role or disable own account"
+	GameAlreadyVoidedErrorMessage string = "game is already voided"
+)
+
+// AdminService holds the moderation operations exposed by the admin API.
+// Role changes and disabled accounts also apply to the tokens issued earlier
+// once the cached account expires, see auth.AccountCacheTTL.
+type AdminService interface {
+	GetPlayers(context.Context, int, int) ([]*domain.Account, int, int, int, error)
+	SetPlayerRole(context.Context, uuid.UUID, uuid.UUID, domain.Role) error
+	SetPlayerDisabled(context.Context, uuid.UUID, uuid.UUID, bool) error
+	UpdatePlayerStats(context.Context, uuid.UUID, models.PlayerStats) error
+	CloseRoom(context.Context, uuid.UUID) error
+	VoidGame(context.Context, uuid.UUID) error
+}
+
+func NewAdminService(db *sql.DB,
+	metrics metrics.MetricsService,
+	playerRepositoryFactory func(q repository.Querier) repository.PlayerRepository,
+	gameRepositoryFactory func(q repository.Querier) repository.GameRepository,
+	roomRepositoryFactory func(q repository.Querier) repository.RoomRepository) AdminService {
+	return &adminServiceImpl{
+		db:                      db,
+		metrics:                 metrics,
+		playerRepositoryFactory: playerRepositoryFactory,
+		gameRepositoryFactory:   gameRepositoryFactory,
+		roomRepositoryFactory:   roomRepositoryFactory,
+	}
+}
+
+type adminServiceImpl struct {
+	db                      *sql.DB
+	metrics                 metrics.MetricsService
+	playerRepositoryFactory func(q repository.Querier) repository.PlayerRepository
+	gameRepositoryFactory   func(q repository.Querier) repository.GameRepository
+	roomRepositoryFactory   func(q repository.Querier) repository.RoomRepository
+}
+
+func (a *adminServiceImpl) GetPlayers(ctx context.Context, page int, pageSize int) ([]*domain.Account, int, int, int, error) {
+	return a.playerRepositoryFactory(a.db).GetAccounts(ctx, page, pageSize)
+}
+
+func (a *adminServiceImpl) SetPlayerRole(ctx context.Context, actorID uuid.UUID, playerID uuid.UUID, role domain.Role) error {
+	if !role.Valid() {
+		return models.NewValidationError(InvalidRoleErrorMessage)
+	}
+
+	if actorID == playerID {
+		return models.NewValidationError(SelfModerationErrorMessage)
+	}
+
+	err := a.playerRepositoryFactory(a.db).UpdateRole(ctx, playerID, role)
+	if err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("player role changed", logger.String("target_player_id", playerID.String()), logger.String("role", string(role)))
+	return nil
+}
+
+func (a *adminServiceImpl) SetPlayerDisabled(ctx context.Context, actorID uuid.UUID, playerID uuid.UUID, disabled bool) error {
+	if actorID == playerID {
+		return models.NewValidationError(SelfModerationErrorMessage)
+	}
+
+	err := a.playerRepositoryFactory(a.db).UpdateDisabled(ctx, playerID, disabled)
+	if err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("player disabled changed", logger.String("target_player_id", playerID.String()), logger.Any("disabled", disabled))
+	return nil
+}
+
+func (a *adminServiceImpl) UpdatePlayerStats(ctx context.Context, playerID uuid.UUID, stats models.PlayerStats) error {
+	if stats.Wins < 0 || stats.Losses < 0 || stats.Draws < 0 {
+		return models.NewValidationError(InvalidStatsErrorMessage)
+	}
+
+	err := repository.WithTransaction(ctx, a.db, func(tx *sql.Tx) error {
+		playerRepository := a.playerRepositoryFactory(tx)
+		player, err := playerRepository.Get(ctx, playerID)
+		if err != nil {
+			return err
+		}
+
+		player.Stats = stats
+		return playerRepository.UpdateStats(ctx, player)
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("player stats corrected", logger.String("target_player_id", playerID.String()))
+	return nil
+}
+
+// CloseRoom deletes the room. A game still in progress is voided so that
+// neither player is credited with a result.
+func (a *adminServiceImpl) CloseRoom(ctx context.Context, roomID uuid.UUID) error {
+	gameVoided := false
+	err := repository.WithTransaction(ctx, a.db, func(tx *sql.Tx) error {
+		roomRepository := a.roomRepositoryFactory(tx)
+		room, err := roomRepository.Get(ctx, roomID, true)
+		if err != nil {
+			return err
+		}
+
+		if room.GameID != nil {
+			gameRepository := a.gameRepositoryFactory(tx)
+			voided, err := gameRepository.Lock(ctx, *room.GameID)
+			if err != nil {
+				return err
+			}
+
+			game, err := gameRepository.Get(ctx, *room.GameID)
+			if err != nil {
+				return err
+			}
+
+			if !voided && game.Phase == models.GamePhaseInProgress {
+				err = gameRepository.Void(ctx, game.ID)
+				if err != nil {
+					return err
+				}
+				gameVoided = true
+			}
+		}
+
+		return roomRepository.Delete(ctx, room.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	log := logger.FromContext(ctx).With(logger.String("room_id", roomID.String()))
+	if gameVoided {
+		a.metrics.GameVoided()
+		log.Info("game voided")
+	}
+	log.Info("room force-closed")
+	return nil
+}
+
+// VoidGame cancels the game. If it was already completed in a ranked room
+// the result is removed from both players' stats; unranked games never
+// changed them.
+func (a *adminServiceImpl) VoidGame(ctx context.Context, gameID uuid.UUID) error {
+	wasInProgress := false
+	err := repository.WithTransaction(ctx, a.db, func(tx *sql.Tx) error {
+		gameRepository := a.gameRepositoryFactory(tx)
+		voided, err := gameRepository.Lock(ctx, gameID)
+		if err != nil {
+			return err
+		}
+
+		if voided {
+			return models.NewValidationError(GameAlreadyVoidedErrorMessage)
+		}
+
+		game, err := gameRepository.Get(ctx, gameID)
+		if err != nil {
+			return err
+		}
+
+		if game.Phase == models.GamePhaseCompleted {
+			ranked, err := gameRepository.IsRanked(ctx, gameID)
+			if err != nil {
+				return err
+			}
+
+			if ranked {
+				err = a.revertResult(ctx, a.playerRepositoryFactory(tx), game)
+				if err != nil {
+					return err
+				}
+			}
+		} else {
+			wasInProgress = true
+		}
+
+		return gameRepository.Void(ctx, gameID)
+	})
+	if err != nil {
+		return err
+	}
+
+	if wasInProgress {
+		a.metrics.GameVoided()
+	}
+	logger.FromContext(ctx).Info("game voided", logger.String("game_id", gameID.String()))
+	return nil
+}
+
+func (a *adminServiceImpl) revertResult(ctx context.Context, playerRepository repository.PlayerRepository, game *models.Game) error {
+	host, err := playerRepository.Get(ctx, game.Host.ID)
+	if err != nil {
+		return err
+	}
+
+	guest, err := playerRepository.Get(ctx, game.Guest.ID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case game.WinnerID == nil:
+		host.Stats.Draws = max(host.Stats.Draws-1, 0)
+		guest.Stats.Draws = max(guest.Stats.Draws-1, 0)
+	case *game.WinnerID == host.ID:
+		host.Stats.Wins = max(host.Stats.Wins-1, 0)
+		guest.Stats.Losses = max(guest.Stats.Losses-1, 0)
+	default:
+		guest.Stats.Wins = max(guest.Stats.Wins-1, 0)
+		host.Stats.Losses = max(host.Stats.Losses-1, 0)
+	}
+
+	err = playerRepository.UpdateStats(ctx, host)
+	if err != nil {
+		return err
+	}
+
+	return playerRepository.UpdateStats(ctx, guest)
+}
@@ -0,0 +1,259 @@
+package admin_test
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofrs/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/repository/mocks"
+	"github.com/plamen-v/tic-tac-toe/src/services/admin"
+	metricsmocks "github.com/plamen-v/tic-tac-toe/src/services/metrics/mocks"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("Admin", func() {
+	var (
+		db                   *sql.DB
+		mock                 sqlmock.Sqlmock
+		ctx                  context.Context
+		mockRoomRepository   *mocks.MockRoomRepository
+		mockGameRepository   *mocks.MockGameRepository
+		mockPlayerRepository *mocks.MockPlayerRepository
+		mockMetricsService   *metricsmocks.MockMetricsService
+		adminService         admin.AdminService
+		actorID              uuid.UUID
+		hostID               uuid.UUID
+		guestID              uuid.UUID
+		err                  error
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		db, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		mockRoomRepository = new(mocks.MockRoomRepository)
+		mockGameRepository = new(mocks.MockGameRepository)
+		mockPlayerRepository = new(mocks.MockPlayerRepository)
+		mockMetricsService = new(metricsmocks.MockMetricsService)
+		mockMetricsService.On("GameVoided").Maybe()
+		actorID = uuid.Must(uuid.NewV4())
+		hostID = uuid.Must(uuid.NewV4())
+		guestID = uuid.Must(uuid.NewV4())
+		adminService = admin.NewAdminService(
+			db,
+			mockMetricsService,
+			func(db repository.Querier) repository.PlayerRepository {
+				return mockPlayerRepository
+			},
+			func(db repository.Querier) repository.GameRepository {
+				return mockGameRepository
+			},
+			func(db repository.Querier) repository.RoomRepository {
+				return mockRoomRepository
+			},
+		)
+	})
+
+	AfterEach(func() {
+		err = mock.ExpectationsWereMet()
+		Expect(err).ToNot(HaveOccurred())
+		db.Close()
+	})
+
+	Context("SetPlayerRole", func() {
+		It("should update the role", func() {
+			mockPlayerRepository.On("UpdateRole", ctx, hostID, domain.RoleModerator).Return(nil)
+
+			Expect(adminService.SetPlayerRole(ctx, actorID, hostID, domain.RoleModerator)).To(Succeed())
+			mockPlayerRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should reject unknown roles", func() {
+			err := adminService.SetPlayerRole(ctx, actorID, hostID, domain.Role("root"))
+
+			Expect(err).To(Equal(models.NewValidationError(admin.InvalidRoleErrorMessage)))
+		})
+
+		It("should not let admins change their own role", func() {
+			err := adminService.SetPlayerRole(ctx, actorID, actorID, domain.RolePlayer)
+
+			Expect(err).To(Equal(models.NewValidationError(admin.SelfModerationErrorMessage)))
+		})
+	})
+
+	Context("SetPlayerDisabled", func() {
+		It("should disable the player", func() {
+			mockPlayerRepository.On("UpdateDisabled", ctx, hostID, true).Return(nil)
+
+			Expect(adminService.SetPlayerDisabled(ctx, actorID, hostID, true)).To(Succeed())
+			mockPlayerRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should not let admins disable themselves", func() {
+			err := adminService.SetPlayerDisabled(ctx, actorID, actorID, true)
+
+			Expect(err).To(Equal(models.NewValidationError(admin.SelfModerationErrorMessage)))
+		})
+	})
+
+	Context("UpdatePlayerStats", func() {
+		It("should overwrite the stats", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			stats := models.PlayerStats{Wins: 3, Losses: 2, Draws: 1}
+			mockPlayerRepository.On("Get", ctx, hostID).Return(&models.Player{ID: hostID}, nil)
+			mockPlayerRepository.On("UpdateStats", ctx, &models.Player{ID: hostID, Stats: stats}).Return(nil)
+
+			Expect(adminService.UpdatePlayerStats(ctx, hostID, stats)).To(Succeed())
+			mockPlayerRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should reject negative stats", func() {
+			err := adminService.UpdatePlayerStats(ctx, hostID, models.PlayerStats{Wins: -1})
+
+			Expect(err).To(Equal(models.NewValidationError(admin.InvalidStatsErrorMessage)))
+		})
+	})
+
+	Context("CloseRoom", func() {
+		It("should void a game in progress and delete the room", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			roomID := uuid.Must(uuid.NewV4())
+			gameID := uuid.Must(uuid.NewV4())
+			mockRoomRepository.On("Get", ctx, roomID, true).Return(&models.Room{ID: roomID, GameID: &gameID}, nil)
+			mockGameRepository.On("Lock", ctx, gameID).Return(false, nil)
+			mockGameRepository.On("Get", ctx, gameID).Return(&models.Game{ID: gameID, Phase: models.GamePhaseInProgress}, nil)
+			mockGameRepository.On("Void", ctx, gameID).Return(nil)
+			mockRoomRepository.On("Delete", ctx, roomID).Return(nil)
+
+			Expect(adminService.CloseRoom(ctx, roomID)).To(Succeed())
+			mockGameRepository.AssertExpectations(GinkgoT())
+			mockRoomRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should keep a completed game", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			roomID := uuid.Must(uuid.NewV4())
+			gameID := uuid.Must(uuid.NewV4())
+			mockRoomRepository.On("Get", ctx, roomID, true).Return(&models.Room{ID: roomID, GameID: &gameID}, nil)
+			mockGameRepository.On("Lock", ctx, gameID).Return(false, nil)
+			mockGameRepository.On("Get", ctx, gameID).Return(&models.Game{ID: gameID, Phase: models.GamePhaseCompleted}, nil)
+			mockRoomRepository.On("Delete", ctx, roomID).Return(nil)
+
+			Expect(adminService.CloseRoom(ctx, roomID)).To(Succeed())
+			mockGameRepository.AssertNotCalled(GinkgoT(), "Void", tmock.Anything, tmock.Anything)
+		})
+
+		It("should return not found for a missing room", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+			roomID := uuid.Must(uuid.NewV4())
+			mockRoomRepository.On("Get", ctx, roomID, true).Return(nil, models.NewNotFoundError("not found"))
+
+			err := adminService.CloseRoom(ctx, roomID)
+			Expect(models.IsNotFoundError(err)).To(BeTrue())
+		})
+	})
+
+	Context("VoidGame", func() {
+		var gameID uuid.UUID
+
+		BeforeEach(func() {
+			gameID = uuid.Must(uuid.NewV4())
+		})
+
+		It("should remove a win from the stats", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			mockGameRepository.On("Lock", ctx, gameID).Return(false, nil)
+			mockGameRepository.On("Get", ctx, gameID).Return(&models.Game{
+				ID:       gameID,
+				Host:     models.GamePlayer{ID: hostID},
+				Guest:    models.GamePlayer{ID: guestID},
+				WinnerID: &guestID,
+				Phase:    models.GamePhaseCompleted,
+			}, nil)
+			mockGameRepository.On("IsRanked", ctx, gameID).Return(true, nil)
+			mockPlayerRepository.On("Get", ctx, hostID).Return(&models.Player{ID: hostID, Stats: models.PlayerStats{Losses: 1}}, nil)
+			mockPlayerRepository.On("Get", ctx, guestID).Return(&models.Player{ID: guestID, Stats: models.PlayerStats{Wins: 2}}, nil)
+			mockPlayerRepository.On("UpdateStats", ctx, &models.Player{ID: hostID}).Return(nil)
+			mockPlayerRepository.On("UpdateStats", ctx, &models.Player{ID: guestID, Stats: models.PlayerStats{Wins: 1}}).Return(nil)
+			mockGameRepository.On("Void", ctx, gameID).Return(nil)
+
+			Expect(adminService.VoidGame(ctx, gameID)).To(Succeed())
+			mockPlayerRepository.AssertExpectations(GinkgoT())
+			mockGameRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should remove a draw from the stats", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			mockGameRepository.On("Lock", ctx, gameID).Return(false, nil)
+			mockGameRepository.On("Get", ctx, gameID).Return(&models.Game{
+				ID:    gameID,
+				Host:  models.GamePlayer{ID: hostID},
+				Guest: models.GamePlayer{ID: guestID},
+				Phase: models.GamePhaseCompleted,
+			}, nil)
+			mockGameRepository.On("IsRanked", ctx, gameID).Return(true, nil)
+			mockPlayerRepository.On("Get", ctx, hostID).Return(&models.Player{ID: hostID, Stats: models.PlayerStats{Draws: 1}}, nil)
+			mockPlayerRepository.On("Get", ctx, guestID).Return(&models.Player{ID: guestID, Stats: models.PlayerStats{Draws: 1}}, nil)
+			mockPlayerRepository.On("UpdateStats", ctx, &models.Player{ID: hostID}).Return(nil)
+			mockPlayerRepository.On("UpdateStats", ctx, &models.Player{ID: guestID}).Return(nil)
+			mockGameRepository.On("Void", ctx, gameID).Return(nil)
+
+			Expect(adminService.VoidGame(ctx, gameID)).To(Succeed())
+			mockPlayerRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should not touch stats for a completed unranked game", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			mockGameRepository.On("Lock", ctx, gameID).Return(false, nil)
+			mockGameRepository.On("Get", ctx, gameID).Return(&models.Game{
+				ID:       gameID,
+				Host:     models.GamePlayer{ID: hostID},
+				Guest:    models.GamePlayer{ID: guestID},
+				WinnerID: &guestID,
+				Phase:    models.GamePhaseCompleted,
+			}, nil)
+			mockGameRepository.On("IsRanked", ctx, gameID).Return(false, nil)
+			mockGameRepository.On("Void", ctx, gameID).Return(nil)
+
+			Expect(adminService.VoidGame(ctx, gameID)).To(Succeed())
+			mockGameRepository.AssertExpectations(GinkgoT())
+			mockPlayerRepository.AssertNotCalled(GinkgoT(), "Get", tmock.Anything, tmock.Anything)
+			mockPlayerRepository.AssertNotCalled(GinkgoT(), "UpdateStats", tmock.Anything, tmock.Anything)
+		})
+
+		It("should not touch stats for a game in progress", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			mockGameRepository.On("Lock", ctx, gameID).Return(false, nil)
+			mockGameRepository.On("Get", ctx, gameID).Return(&models.Game{ID: gameID, Phase: models.GamePhaseInProgress}, nil)
+			mockGameRepository.On("Void", ctx, gameID).Return(nil)
+
+			Expect(adminService.VoidGame(ctx, gameID)).To(Succeed())
+			mockPlayerRepository.AssertNotCalled(GinkgoT(), "UpdateStats", tmock.Anything, tmock.Anything)
+			mockMetricsService.AssertCalled(GinkgoT(), "GameVoided")
+			mockMetricsService.AssertNotCalled(GinkgoT(), "GameCompleted", tmock.Anything)
+		})
+
+		It("should reject a game that is already voided", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+			mockGameRepository.On("Lock", ctx, gameID).Return(true, nil)
+
+			err := adminService.VoidGame(ctx, gameID)
+			Expect(err).To(Equal(models.NewValidationError(admin.GameAlreadyVoidedErrorMessage)))
+		})
+	})
+})
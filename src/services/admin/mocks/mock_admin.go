@@ -0,0 +1,54 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAdminService struct {
+	mock.Mock
+}
+
+func (m *MockAdminService) GetPlayers(ctx context.Context, page int, pageSize int) ([]*domain.Account, int, int, int, error) {
+	args := m.Called(ctx, page, pageSize)
+
+	accounts, okAccounts := args.Get(0).([]*domain.Account)
+	pageSize, okPageSize := args.Get(1).(int)
+	page, okPage := args.Get(2).(int)
+	total, okTotal := args.Get(3).(int)
+
+	if accounts == nil || !okAccounts || !okPageSize || !okPage || !okTotal {
+		return nil, 0, 0, 0, args.Error(4)
+	}
+
+	return accounts, pageSize, page, total, args.Error(4)
+}
+
+func (m *MockAdminService) SetPlayerRole(ctx context.Context, actorID uuid.UUID, playerID uuid.UUID, role domain.Role) error {
+	args := m.Called(ctx, actorID, playerID, role)
+	return args.Error(0)
+}
+
+func (m *MockAdminService) SetPlayerDisabled(ctx context.Context, actorID uuid.UUID, playerID uuid.UUID, disabled bool) error {
+	args := m.Called(ctx, actorID, playerID, disabled)
+	return args.Error(0)
+}
+
+func (m *MockAdminService) UpdatePlayerStats(ctx context.Context, playerID uuid.UUID, stats models.PlayerStats) error {
+	args := m.Called(ctx, playerID, stats)
+	return args.Error(0)
+}
+
+func (m *MockAdminService) CloseRoom(ctx context.Context, roomID uuid.UUID) error {
+	args := m.Called(ctx, roomID)
+	return args.Error(0)
+}
+
+func (m *MockAdminService) VoidGame(ctx context.Context, gameID uuid.UUID) error {
+	args := m.Called(ctx, gameID)
+	return args.Error(0)
+}
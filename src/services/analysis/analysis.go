@@ -0,0 +1,171 @@
+package analysis
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/services/engine"
+)
+
+var (
+	GameNotCompletedErrorMessage   string = "only completed games can be analysed"
+	NoMovesErrorMessage            string = "game has no recorded moves"
+	InvalidMovesErrorMessage       string = "recorded moves of the game are invalid"
+	RankedRoomHintErrorMessage     string = "hints are only available in unranked rooms"
+	NoGameErrorMessage             string = "room has no game"
+	UnsupportedVariantErrorMessage string = "only classic games with standard rules can be analysed"
+)
+
+// AnalysisService replays completed games and compares every move with
+// perfect play. In unranked rooms it also recommends moves during the game.
+type AnalysisService interface {
+	AnalyzeGame(context.Context, uuid.UUID) (*domain.GameAnalysis, error)
+	GetHint(context.Context, uuid.UUID, uuid.UUID) (*domain.Hint, error)
+}
+
+func NewAnalysisService(db *sql.DB,
+	gameRepositoryFactory func(q repository.Querier) repository.GameRepository,
+	roomRepositoryFactory func(q repository.Querier) repository.RoomRepository) AnalysisService {
+	return &analysisServiceImpl{
+		db:                    db,
+		gameRepositoryFactory: gameRepositoryFactory,
+		roomRepositoryFactory: roomRepositoryFactory,
+	}
+}
+
+type analysisServiceImpl struct {
+	db                    *sql.DB
+	gameRepositoryFactory func(q repository.Querier) repository.GameRepository
+	roomRepositoryFactory func(q repository.Querier) repository.RoomRepository
+}
+
+// AnalyzeGame evaluates the position before and after each move of the game,
+// suggests the best moves and flags the blunders. Games in progress are not
+// analysed so that the analysis cannot be used to play them.
+func (s *analysisServiceImpl) AnalyzeGame(ctx context.Context, gameID uuid.UUID) (*domain.GameAnalysis, error) {
+	gameRepository := s.gameRepositoryFactory(s.db)
+	game, err := gameRepository.Get(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	if game.Phase != models.GamePhaseCompleted {
+		return nil, models.NewValidationError(GameNotCompletedErrorMessage)
+	}
+
+	variantState, err := gameRepository.GetVariantState(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	if variantState.Variant != domain.GameVariantClassic || variantState.RuleSet != domain.RuleSetStandard {
+		return nil, models.NewValidationError(UnsupportedVariantErrorMessage)
+	}
+
+	moves, err := gameRepository.GetMoves(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(moves) == 0 {
+		return nil, models.NewValidationError(NoMovesErrorMessage)
+	}
+
+	solver := newSolver()
+	board := []byte(engine.DefaultBoard)
+	analysis := &domain.GameAnalysis{
+		GameID: gameID,
+		Moves:  make([]*domain.AnalyzedMove, 0, len(moves)),
+	}
+	for _, move := range moves {
+		if len(move.Mark) != 1 || move.Position < 1 || move.Position > len(board) ||
+			board[move.Position-1] != engine.DefaultBoardTile ||
+			hasLine(board, engine.XMark) || hasLine(board, engine.OMark) {
+			return nil, models.NewGenericError(InvalidMovesErrorMessage)
+		}
+
+		mark := move.Mark[0]
+		analyzed := &domain.AnalyzedMove{
+			Move:             *move,
+			EvaluationBefore: solver.evaluate(board, mark),
+			BestMoves:        solver.bestMoves(board, mark),
+		}
+
+		board[move.Position-1] = mark
+		analyzed.EvaluationAfter = solver.after(board, mark)
+		analyzed.Blunder = analyzed.EvaluationBefore != domain.EvaluationLoss && analyzed.EvaluationAfter == domain.EvaluationLoss
+
+		analysis.Moves = append(analysis.Moves, analyzed)
+	}
+
+	return analysis, nil
+}
+
+// GetHint recommends the next move to the player in turn and counts the hint
+// on the game.
+func (s *analysisServiceImpl) GetHint(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) (*domain.Hint, error) {
+	return repository.WithTransactionT(ctx, s.db, func(tx *sql.Tx) (*domain.Hint, error) {
+		roomRepository := s.roomRepositoryFactory(tx)
+		room, err := roomRepository.Get(ctx, roomID, true)
+		if err != nil {
+			return nil, err
+		}
+
+		if room.Host.ID != playerID && (room.Guest == nil || room.Guest.ID != playerID) {
+			return nil, models.NewValidationError(engine.PlayerNotInRoomErrorMessage)
+		}
+
+		settings, err := roomRepository.GetSettings(ctx, roomID)
+		if err != nil {
+			return nil, err
+		}
+
+		if settings.Ranked {
+			return nil, models.NewValidationError(RankedRoomHintErrorMessage)
+		}
+
+		if settings.Variant != domain.GameVariantClassic || settings.RuleSet != domain.RuleSetStandard {
+			return nil, models.NewValidationError(UnsupportedVariantErrorMessage)
+		}
+
+		if room.GameID == nil {
+			return nil, models.NewValidationError(NoGameErrorMessage)
+		}
+
+		gameRepository := s.gameRepositoryFactory(tx)
+		game, err := gameRepository.Get(ctx, *room.GameID)
+		if err != nil {
+			return nil, err
+		}
+
+		if game.Phase != models.GamePhaseInProgress {
+			return nil, models.NewValidationError(engine.GameCompletedErrorMessage)
+		}
+
+		if game.CurrentPlayerID != playerID {
+			return nil, models.NewValidationError(engine.PlayerNotInTurnErrorMessage)
+		}
+
+		mark := game.Host.Mark[0]
+		if playerID == game.Guest.ID {
+			mark = game.Guest.Mark[0]
+		}
+
+		solver := newSolver()
+		board := []byte(game.Board)
+		hint := &domain.Hint{
+			Position:   solver.recommend(board, mark),
+			Evaluation: solver.evaluate(board, mark),
+		}
+
+		if hint.HintsUsed, err = gameRepository.AddHint(ctx, game.ID); err != nil {
+			return nil, err
+		}
+
+		return hint, nil
+	})
+}
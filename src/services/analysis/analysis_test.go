@@ -0,0 +1,211 @@
+package analysis_test
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofrs/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/repository/mocks"
+	"github.com/plamen-v/tic-tac-toe/src/services/analysis"
+	"github.com/plamen-v/tic-tac-toe/src/services/engine"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("Analysis", func() {
+	var (
+		db                 *sql.DB
+		mock               sqlmock.Sqlmock
+		ctx                context.Context
+		mockGameRepository *mocks.MockGameRepository
+		mockRoomRepository *mocks.MockRoomRepository
+		analysisService    analysis.AnalysisService
+		gameID             uuid.UUID
+		hostID             uuid.UUID
+		guestID            uuid.UUID
+		err                error
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		db, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		mockGameRepository = new(mocks.MockGameRepository)
+		mockRoomRepository = new(mocks.MockRoomRepository)
+		gameID = uuid.Must(uuid.NewV4())
+		hostID = uuid.Must(uuid.NewV4())
+		guestID = uuid.Must(uuid.NewV4())
+		analysisService = analysis.NewAnalysisService(
+			db,
+			func(db repository.Querier) repository.GameRepository {
+				return mockGameRepository
+			},
+			func(db repository.Querier) repository.RoomRepository {
+				return mockRoomRepository
+			},
+		)
+	})
+
+	AfterEach(func() {
+		err = mock.ExpectationsWereMet()
+		Expect(err).ToNot(HaveOccurred())
+		db.Close()
+	})
+
+	moves := func(positions ...int) []*domain.Move {
+		result := []*domain.Move{}
+		for i, position := range positions {
+			move := &domain.Move{Seq: i + 1, PlayerID: hostID, Position: position, Mark: "X"}
+			if i%2 == 1 {
+				move.PlayerID, move.Mark = guestID, "O"
+			}
+			result = append(result, move)
+		}
+		return result
+	}
+
+	expectCompletedGame := func(positions ...int) {
+		mockGameRepository.On("Get", ctx, gameID).Return(&models.Game{ID: gameID, Phase: models.GamePhaseCompleted}, nil)
+		mockGameRepository.On("GetVariantState", ctx, gameID).Return(&domain.VariantState{Variant: domain.GameVariantClassic, RuleSet: domain.RuleSetStandard}, nil)
+		mockGameRepository.On("GetMoves", ctx, gameID).Return(moves(positions...), nil)
+	}
+
+	It("should flag the edge reply to the centre opening as a blunder", func() {
+		// X centre, O edge, X corner, O blocks, X forks.
+		expectCompletedGame(5, 2, 1, 9, 7, 3, 4)
+
+		result, err := analysisService.AnalyzeGame(ctx, gameID)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Moves).To(HaveLen(7))
+
+		opening := result.Moves[0]
+		Expect(opening.EvaluationBefore).To(Equal(domain.EvaluationDraw))
+		Expect(opening.BestMoves).To(Equal([]int{1, 2, 3, 4, 5, 6, 7, 8, 9}))
+		Expect(opening.Blunder).To(BeFalse())
+
+		reply := result.Moves[1]
+		Expect(reply.EvaluationBefore).To(Equal(domain.EvaluationDraw))
+		Expect(reply.EvaluationAfter).To(Equal(domain.EvaluationLoss))
+		Expect(reply.BestMoves).To(Equal([]int{1, 3, 7, 9}))
+		Expect(reply.Blunder).To(BeTrue())
+
+		win := result.Moves[6]
+		Expect(win.EvaluationBefore).To(Equal(domain.EvaluationWin))
+		Expect(win.EvaluationAfter).To(Equal(domain.EvaluationWin))
+		Expect(win.Blunder).To(BeFalse())
+	})
+
+	It("should not flag the moves of a perfectly played draw", func() {
+		expectCompletedGame(1, 5, 9, 2, 8, 7, 3, 6, 4)
+
+		result, err := analysisService.AnalyzeGame(ctx, gameID)
+
+		Expect(err).ToNot(HaveOccurred())
+		for _, move := range result.Moves {
+			Expect(move.Blunder).To(BeFalse())
+			Expect(move.EvaluationAfter).To(Equal(domain.EvaluationDraw))
+		}
+	})
+
+	It("should return error if the game is in progress", func() {
+		mockGameRepository.On("Get", ctx, gameID).Return(&models.Game{ID: gameID, Phase: models.GamePhaseInProgress}, nil)
+
+		_, err := analysisService.AnalyzeGame(ctx, gameID)
+
+		Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+		Expect(err.Error()).To(ContainSubstring(analysis.GameNotCompletedErrorMessage))
+		mockGameRepository.AssertNotCalled(GinkgoT(), "GetMoves", tmock.Anything, tmock.Anything)
+	})
+
+	It("should return error if the game has no recorded moves", func() {
+		expectCompletedGame()
+
+		_, err := analysisService.AnalyzeGame(ctx, gameID)
+
+		Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+		Expect(err.Error()).To(ContainSubstring(analysis.NoMovesErrorMessage))
+	})
+
+	It("should return error if the game is not classic", func() {
+		mockGameRepository.On("Get", ctx, gameID).Return(&models.Game{ID: gameID, Phase: models.GamePhaseCompleted}, nil)
+		mockGameRepository.On("GetVariantState", ctx, gameID).Return(&domain.VariantState{Variant: domain.GameVariantUltimate}, nil)
+
+		_, err := analysisService.AnalyzeGame(ctx, gameID)
+
+		Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+		Expect(err.Error()).To(ContainSubstring(analysis.UnsupportedVariantErrorMessage))
+		mockGameRepository.AssertNotCalled(GinkgoT(), "GetMoves", tmock.Anything, tmock.Anything)
+	})
+
+	Context("GetHint", func() {
+		var roomID uuid.UUID
+
+		BeforeEach(func() {
+			roomID = uuid.Must(uuid.NewV4())
+			room := &models.Room{
+				ID:     roomID,
+				Host:   models.RoomPlayer{ID: hostID},
+				Guest:  &models.RoomPlayer{ID: guestID},
+				GameID: &gameID,
+				Phase:  models.RoomPhaseFull,
+			}
+			mockRoomRepository.On("Get", ctx, roomID, true).Return(room, nil)
+		})
+
+		expectGame := func(board string, currentPlayerID uuid.UUID) {
+			mockGameRepository.On("Get", ctx, gameID).Return(&models.Game{
+				ID:              gameID,
+				Host:            models.GamePlayer{ID: hostID, Mark: "X"},
+				Guest:           models.GamePlayer{ID: guestID, Mark: "O"},
+				CurrentPlayerID: currentPlayerID,
+				Board:           board,
+				Phase:           models.GamePhaseInProgress,
+			}, nil)
+		}
+
+		It("should recommend the winning move and count the hint", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			mockRoomRepository.On("GetSettings", ctx, roomID).Return(&domain.RoomSettings{Ranked: false, Variant: domain.GameVariantClassic, RuleSet: domain.RuleSetStandard}, nil)
+			// Blocking at 2 also wins, but 4 wins at once.
+			expectGame("O_O_XX___", hostID)
+			mockGameRepository.On("AddHint", ctx, gameID).Return(2, nil)
+
+			hint, err := analysisService.GetHint(ctx, roomID, hostID)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hint).To(Equal(&domain.Hint{Position: 4, Evaluation: domain.EvaluationWin, HintsUsed: 2}))
+		})
+
+		It("should return error in a ranked room", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+			mockRoomRepository.On("GetSettings", ctx, roomID).Return(domain.DefaultRoomSettings(), nil)
+
+			_, err := analysisService.GetHint(ctx, roomID, hostID)
+
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+			Expect(err.Error()).To(ContainSubstring(analysis.RankedRoomHintErrorMessage))
+			mockGameRepository.AssertNotCalled(GinkgoT(), "AddHint", tmock.Anything, tmock.Anything)
+		})
+
+		It("should return error if the player is not in turn", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+			mockRoomRepository.On("GetSettings", ctx, roomID).Return(&domain.RoomSettings{Ranked: false, Variant: domain.GameVariantClassic, RuleSet: domain.RuleSetStandard}, nil)
+			expectGame("X________", hostID)
+
+			_, err := analysisService.GetHint(ctx, roomID, guestID)
+
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+			Expect(err.Error()).To(ContainSubstring(engine.PlayerNotInTurnErrorMessage))
+			mockGameRepository.AssertNotCalled(GinkgoT(), "AddHint", tmock.Anything, tmock.Anything)
+		})
+	})
+})
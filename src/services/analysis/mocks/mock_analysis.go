@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAnalysisService struct {
+	mock.Mock
+}
+
+func (m *MockAnalysisService) AnalyzeGame(ctx context.Context, gameID uuid.UUID) (*domain.GameAnalysis, error) {
+	args := m.Called(ctx, gameID)
+
+	analysis, ok := args.Get(0).(*domain.GameAnalysis)
+	if analysis == nil || !ok {
+		return nil, args.Error(1)
+	}
+
+	return analysis, args.Error(1)
+}
+
+func (m *MockAnalysisService) GetHint(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) (*domain.Hint, error) {
+	args := m.Called(ctx, roomID, playerID)
+
+	hint, ok := args.Get(0).(*domain.Hint)
+	if hint == nil || !ok {
+		return nil, args.Error(1)
+	}
+
+	return hint, args.Error(1)
+}
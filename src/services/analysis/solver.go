@@ -0,0 +1,126 @@
+package analysis
+
+import (
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/engine"
+)
+
+var lines = [][3]int{
+	{0, 1, 2}, {3, 4, 5}, {6, 7, 8},
+	{0, 3, 6}, {1, 4, 7}, {2, 5, 8},
+	{0, 4, 8}, {2, 4, 6},
+}
+
+// solver evaluates positions under perfect play by a full minimax search.
+// Evaluated positions are cached by board and player to move.
+type solver struct {
+	cache map[string]domain.Evaluation
+}
+
+func newSolver() *solver {
+	return &solver{
+		cache: map[string]domain.Evaluation{},
+	}
+}
+
+// evaluate returns the evaluation of a position that is not over for the
+// player with the given mark to move.
+func (s *solver) evaluate(board []byte, mark byte) domain.Evaluation {
+	key := string(board) + string(mark)
+	if evaluation, ok := s.cache[key]; ok {
+		return evaluation
+	}
+
+	best := domain.EvaluationLoss
+	for i := range board {
+		if board[i] != engine.DefaultBoardTile {
+			continue
+		}
+
+		board[i] = mark
+		if evaluation := s.after(board, mark); evaluation > best {
+			best = evaluation
+		}
+		board[i] = engine.DefaultBoardTile
+	}
+
+	s.cache[key] = best
+	return best
+}
+
+// after returns the evaluation for the player that just placed mark.
+func (s *solver) after(board []byte, mark byte) domain.Evaluation {
+	if hasLine(board, mark) {
+		return domain.EvaluationWin
+	}
+
+	if isFull(board) {
+		return domain.EvaluationDraw
+	}
+
+	return -s.evaluate(board, opponent(mark))
+}
+
+// bestMoves returns the 1-based positions that keep the evaluation of the
+// position for the player to move.
+func (s *solver) bestMoves(board []byte, mark byte) []int {
+	best := s.evaluate(board, mark)
+	moves := []int{}
+	for i := range board {
+		if board[i] != engine.DefaultBoardTile {
+			continue
+		}
+
+		board[i] = mark
+		if s.after(board, mark) == best {
+			moves = append(moves, i+1)
+		}
+		board[i] = engine.DefaultBoardTile
+	}
+
+	return moves
+}
+
+// recommend returns one of the best moves, preferring a move that wins at
+// once.
+func (s *solver) recommend(board []byte, mark byte) int {
+	moves := s.bestMoves(board, mark)
+	for _, move := range moves {
+		board[move-1] = mark
+		won := hasLine(board, mark)
+		board[move-1] = engine.DefaultBoardTile
+		if won {
+			return move
+		}
+	}
+
+	return moves[0]
+}
+
+func hasLine(board []byte, mark byte) bool {
+	for _, line := range lines {
+		if board[line[0]] == mark && board[line[1]] == mark && board[line[2]] == mark {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isFull(board []byte) bool {
+	for _, tile := range board {
+		if tile == engine.DefaultBoardTile {
+			return false
+		}
+	}
+
+	return true
+}
+
+func opponent(mark byte) byte {
+	if mark == engine.XMark {
+		return engine.OMark
+	}
+
+	return engine.XMark
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+)
+
+// AccountCacheTTL bounds how long a role change or a disabled account takes
+// to reach the access tokens issued earlier.
+const AccountCacheTTL time.Duration = 30 * time.Second
+
+type cachedAccount struct {
+	account *domain.Account
+	expires time.Time
+}
+
+// accountCache keeps the accounts of recently authenticated players. Expired
+// entries are dropped once per TTL to bound memory.
+type accountCache struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	ttl       time.Duration
+	accounts  map[uuid.UUID]cachedAccount
+	lastSweep time.Time
+}
+
+func newAccountCache(now func() time.Time, ttl time.Duration) *accountCache {
+	return &accountCache{
+		now:       now,
+		ttl:       ttl,
+		accounts:  map[uuid.UUID]cachedAccount{},
+		lastSweep: now(),
+	}
+}
+
+func (c *accountCache) Get(playerID uuid.UUID) (*domain.Account, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.accounts[playerID]
+	if !ok || !c.now().Before(cached.expires) {
+		return nil, false
+	}
+
+	return cached.account, true
+}
+
+func (c *accountCache) Put(account *domain.Account) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if now.Sub(c.lastSweep) >= c.ttl {
+		c.lastSweep = now
+		for playerID, cached := range c.accounts {
+			if !now.Before(cached.expires) {
+				delete(c.accounts, playerID)
+			}
+		}
+	}
+
+	c.accounts[account.ID] = cachedAccount{account: account, expires: now.Add(c.ttl)}
+}
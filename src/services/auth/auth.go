@@ -3,13 +3,21 @@ package auth
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"sync"
 	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/apperrors"
 	"github.com/plamen-v/tic-tac-toe/src/config"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
 	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/services/lockout"
+	"github.com/plamen-v/tic-tac-toe/src/services/logger"
+	"github.com/plamen-v/tic-tac-toe/src/services/metrics"
+	"github.com/plamen-v/tic-tac-toe/src/services/twofactor"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -21,11 +29,32 @@ const (
 
 type AuthenticationService interface {
 	ValidateToken(token string) (*jwt.Token, error)
-	Authenticate(context.Context, string, string) (*models.Player, string, error)
+	JWKS() *JWKSet
+	Authenticate(context.Context, string, string, string) (*domain.LoginResponse, error)
+	VerifyTwoFactor(context.Context, string, string, string) (*models.Player, string, error)
+	AuthenticatePlayer(context.Context, uuid.UUID, string) (*domain.LoginResponse, error)
+	AuthorizeAccount(context.Context, uuid.UUID) (domain.Role, error)
 }
 
+const (
+	AccountDisabledErrorMessage    string = "account is disabled"
+	InvalidCredentialsErrorMessage string = "invalid login or password"
+	// TwoFactorChallengePurpose marks tokens that only allow to complete the
+	// second login step. They are not accepted as access tokens.
+	TwoFactorChallengePurpose string = "2fa"
+)
+
+// dummyPasswordHash is compared for unknown logins, so that they take as
+// long to answer as a wrong password.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	return hash
+})
+
 type ExtendedClaims struct {
 	PlayerID uuid.NullUUID `json:"player_id"`
+	Role     domain.Role   `json:"role,omitempty"`
+	Purpose  string        `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -37,16 +66,26 @@ func (c ExtendedClaims) Validate() error {
 	return nil
 }
 
-func NewAuthenticationService(config *config.AppConfiguration, db *sql.DB) AuthenticationService {
+func NewAuthenticationService(config *config.AppConfiguration, keys *KeySet, db *sql.DB, metrics metrics.MetricsService, lockoutService lockout.LockoutService, twoFactorService twofactor.TwoFactorService) AuthenticationService {
 	return &authenticationServiceImpl{
-		config: config,
-		db:     db,
+		config:           config,
+		keys:             keys,
+		db:               db,
+		metrics:          metrics,
+		lockoutService:   lockoutService,
+		twoFactorService: twoFactorService,
+		accounts:         newAccountCache(time.Now, AccountCacheTTL),
 	}
 }
 
 type authenticationServiceImpl struct {
-	config *config.AppConfiguration
-	db     *sql.DB
+	config           *config.AppConfiguration
+	keys             *KeySet
+	db               *sql.DB
+	metrics          metrics.MetricsService
+	lockoutService   lockout.LockoutService
+	twoFactorService twofactor.TwoFactorService
+	accounts         *accountCache
 }
 
 func (s *authenticationServiceImpl) playerRepositoryFactory(q repository.Querier) repository.PlayerRepository {
@@ -54,36 +93,209 @@ func (s *authenticationServiceImpl) playerRepositoryFactory(q repository.Querier
 }
 
 func (s *authenticationServiceImpl) ValidateToken(tokenString string) (*jwt.Token, error) {
-	if jwtToken, err := jwt.ParseWithClaims(tokenString, &ExtendedClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(s.config.Secret), nil
-	}); err != nil {
+	jwtToken, err := s.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims := jwtToken.Claims.(*ExtendedClaims); len(claims.Purpose) > 0 {
+		return nil, models.NewAuthorizationError("token is not an access token")
+	}
+
+	return jwtToken, nil
+}
+
+func (s *authenticationServiceImpl) parseToken(tokenString string) (*jwt.Token, error) {
+	if jwtToken, err := jwt.ParseWithClaims(tokenString, &ExtendedClaims{}, s.keys.Keyfunc, jwt.WithValidMethods(s.keys.Methods())); err != nil {
 		return nil, models.NewAuthorizationError(err.Error())
 	} else {
 		return jwtToken, nil
 	}
 }
 
-func (s *authenticationServiceImpl) Authenticate(ctx context.Context, login string, password string) (*models.Player, string, error) {
+func (s *authenticationServiceImpl) JWKS() *JWKSet {
+	return s.keys.JWKS()
+}
+
+// Authenticate verifies the credentials of login. ip is the client address
+// the attempt came from and is used for lockout and anomaly tracking. For
+// players with two-factor authentication only a challenge token is returned,
+// see VerifyTwoFactor. Unknown logins and locked accounts are answered like
+// a wrong password, so that the response reveals neither.
+func (s *authenticationServiceImpl) Authenticate(ctx context.Context, login string, password string, ip string) (*domain.LoginResponse, error) {
 	player, err := s.playerRepositoryFactory(s.db).GetByLogin(ctx, login)
 	if err != nil {
-		return nil, "", models.NewAuthorizationError(err.Error())
+		if !models.IsNotFoundError(err) {
+			return nil, err
+		}
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+		s.loginFailed(ctx, login, nil, ip, err)
+		return nil, models.NewAuthorizationError(InvalidCredentialsErrorMessage)
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(player.Password), []byte(password))
 	if err != nil {
-		return nil, "", models.NewAuthorizationErrorf("invalid password")
+		s.loginFailed(ctx, login, &player.ID, ip, errors.New("invalid password"))
+		return nil, models.NewAuthorizationError(InvalidCredentialsErrorMessage)
+	}
+
+	if err = s.lockoutService.Check(ctx, player.ID); err != nil {
+		if !errors.As(err, new(*apperrors.TooManyRequestsError)) {
+			return nil, err
+		}
+		s.loginFailed(ctx, login, &player.ID, ip, err)
+		return nil, models.NewAuthorizationError(InvalidCredentialsErrorMessage)
+	}
+
+	account, err := s.activeAccount(ctx, player, ip)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.twoFactorChallenge(ctx, player)
+	if err != nil || challenge != nil {
+		return challenge, err
 	}
 
-	token, err := s.createToken(player)
+	if err = s.lockoutService.RecordSuccess(ctx, login, player.ID, ip); err != nil {
+		logger.FromContext(ctx).Error("recording login attempt failed", logger.Err(err))
+	}
+
+	token, err := s.createToken(player, account.Role)
+	if err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+	return &domain.LoginResponse{Player: player, Token: token}, nil
+}
+
+// VerifyTwoFactor completes a login started by Authenticate. Wrong codes
+// count towards the account lockout like wrong passwords.
+func (s *authenticationServiceImpl) VerifyTwoFactor(ctx context.Context, challengeToken string, code string, ip string) (*models.Player, string, error) {
+	jwtToken, err := s.parseToken(challengeToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	claims := jwtToken.Claims.(*ExtendedClaims)
+	if claims.Purpose != TwoFactorChallengePurpose {
+		return nil, "", models.NewAuthorizationError("token is not a two-factor challenge")
+	}
+
+	player, err := s.playerRepositoryFactory(s.db).Get(ctx, claims.PlayerID.UUID)
+	if err != nil {
+		return nil, "", models.NewAuthorizationError(err.Error())
+	}
+
+	if err = s.lockoutService.Check(ctx, player.ID); err != nil {
+		s.loginFailed(ctx, player.Login, &player.ID, ip, err)
+		return nil, "", err
+	}
+
+	if err = s.twoFactorService.Verify(ctx, player.ID, code); err != nil {
+		if errors.As(err, new(*models.AuthorizationError)) {
+			s.loginFailed(ctx, player.Login, &player.ID, ip, err)
+		}
+		return nil, "", err
+	}
+
+	account, err := s.activeAccount(ctx, player, ip)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err = s.lockoutService.RecordSuccess(ctx, player.Login, player.ID, ip); err != nil {
+		logger.FromContext(ctx).Error("recording login attempt failed", logger.Err(err))
+	}
+
+	token, err := s.createToken(player, account.Role)
 	if err != nil {
 		return nil, "", models.NewGenericError(err.Error())
 	}
 	return player, token, nil
 }
 
-func (s *authenticationServiceImpl) createToken(player *models.Player) (string, error) {
+// AuthenticatePlayer issues a token for a player whose identity has already
+// been established elsewhere, e.g. by an external OpenID Connect provider.
+// Like Authenticate it only returns a challenge token for players with
+// two-factor authentication.
+func (s *authenticationServiceImpl) AuthenticatePlayer(ctx context.Context, playerID uuid.UUID, ip string) (*domain.LoginResponse, error) {
+	player, err := s.playerRepositoryFactory(s.db).Get(ctx, playerID)
+	if err != nil {
+		return nil, models.NewAuthorizationError(err.Error())
+	}
+
+	account, err := s.activeAccount(ctx, player, ip)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.twoFactorChallenge(ctx, player)
+	if err != nil || challenge != nil {
+		return challenge, err
+	}
+
+	token, err := s.createToken(player, account.Role)
+	if err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+	return &domain.LoginResponse{Player: player, Token: token}, nil
+}
+
+// AuthorizeAccount returns the stored role of the player an access token was
+// issued to. Disabled and deleted accounts are rejected, so that neither
+// depends on the claims of tokens issued before the change.
+func (s *authenticationServiceImpl) AuthorizeAccount(ctx context.Context, playerID uuid.UUID) (domain.Role, error) {
+	account, ok := s.accounts.Get(playerID)
+	if !ok {
+		var err error
+		account, err = s.playerRepositoryFactory(s.db).GetAccount(ctx, playerID)
+		if err != nil {
+			if models.IsNotFoundError(err) {
+				return "", models.NewAuthorizationError(err.Error())
+			}
+			return "", err
+		}
+		s.accounts.Put(account)
+	}
+
+	if account.Disabled {
+		return "", apperrors.NewForbiddenError(AccountDisabledErrorMessage)
+	}
+
+	if len(account.Role) == 0 {
+		return domain.RolePlayer, nil
+	}
+	return account.Role, nil
+}
+
+func (s *authenticationServiceImpl) activeAccount(ctx context.Context, player *models.Player, ip string) (*domain.Account, error) {
+	account, err := s.playerRepositoryFactory(s.db).GetAccount(ctx, player.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.Disabled {
+		s.metrics.LoginFailed()
+		logger.FromContext(ctx).Warn("login failed", logger.String("login", player.Login), logger.String("ip", ip), logger.String("reason", AccountDisabledErrorMessage))
+		return nil, apperrors.NewForbiddenError(AccountDisabledErrorMessage)
+	}
+
+	return account, nil
+}
+
+func (s *authenticationServiceImpl) loginFailed(ctx context.Context, login string, playerID *uuid.UUID, ip string, reason error) {
+	s.metrics.LoginFailed()
+	logger.FromContext(ctx).Warn("login failed", logger.String("login", login), logger.String("ip", ip), logger.Err(reason))
+
+	if err := s.lockoutService.RecordFailure(ctx, login, playerID, ip); err != nil {
+		logger.FromContext(ctx).Error("recording login attempt failed", logger.Err(err))
+	}
+}
+
+func (s *authenticationServiceImpl) createToken(player *models.Player, role domain.Role) (string, error) {
 	claims := ExtendedClaims{
 		PlayerID: uuid.NullUUID{UUID: player.ID, Valid: true},
+		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   s.config.AppName,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(3600 * time.Second)),
@@ -92,12 +304,40 @@ func (s *authenticationServiceImpl) createToken(player *models.Player) (string,
 		},
 	}
 
-	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	tokenString, err := jwtToken.SignedString([]byte(s.config.Secret))
+	tokenString, err := s.keys.Sign(claims)
 	if err != nil {
 		return "", err
 	}
 
 	return tokenString, nil
 }
+
+// twoFactorChallenge returns the response with a challenge token for players
+// with two-factor authentication, and nil for the others.
+func (s *authenticationServiceImpl) twoFactorChallenge(ctx context.Context, player *models.Player) (*domain.LoginResponse, error) {
+	enabled, err := s.twoFactorService.Enabled(ctx, player.ID)
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	challengeToken, err := s.createChallengeToken(player)
+	if err != nil {
+		return nil, models.NewGenericError(err.Error())
+	}
+	return &domain.LoginResponse{TwoFactorRequired: true, ChallengeToken: challengeToken}, nil
+}
+
+func (s *authenticationServiceImpl) createChallengeToken(player *models.Player) (string, error) {
+	claims := ExtendedClaims{
+		PlayerID: uuid.NullUUID{UUID: player.ID, Valid: true},
+		Purpose:  TwoFactorChallengePurpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   s.config.AppName,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.config.TwoFactor.ChallengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    player.Login,
+		},
+	}
+
+	return s.keys.Sign(claims)
+}
@@ -0,0 +1,242 @@
+package auth_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofrs/uuid"
+	"github.com/golang-jwt/jwt/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/apperrors"
+	"github.com/plamen-v/tic-tac-toe/src/config"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/auth"
+	lockoutmocks "github.com/plamen-v/tic-tac-toe/src/services/lockout/mocks"
+	metricsmocks "github.com/plamen-v/tic-tac-toe/src/services/metrics/mocks"
+	twofactormocks "github.com/plamen-v/tic-tac-toe/src/services/twofactor/mocks"
+	tmock "github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var _ = Describe("ValidateToken", func() {
+	var (
+		keys        *auth.KeySet
+		authService auth.AuthenticationService
+	)
+
+	BeforeEach(func() {
+		var err error
+		keys, err = auth.NewKeySet(config.SigningConfiguration{}, "secret")
+		Expect(err).ToNot(HaveOccurred())
+		authService = auth.NewAuthenticationService(&config.AppConfiguration{AppName: "test"}, keys, nil, nil, nil, nil)
+	})
+
+	sign := func(purpose string) string {
+		token, err := keys.Sign(auth.ExtendedClaims{
+			PlayerID: uuid.NullUUID{UUID: uuid.Must(uuid.NewV4()), Valid: true},
+			Purpose:  purpose,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		return token
+	}
+
+	It("should accept access tokens", func() {
+		_, err := authService.ValidateToken(sign(""))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should reject two-factor challenge tokens", func() {
+		_, err := authService.ValidateToken(sign(auth.TwoFactorChallengePurpose))
+		Expect(err).To(BeAssignableToTypeOf(&models.AuthorizationError{}))
+	})
+})
+
+var _ = Describe("AuthorizeAccount", func() {
+	var (
+		db          *sql.DB
+		mock        sqlmock.Sqlmock
+		authService auth.AuthenticationService
+		playerID    uuid.UUID
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		authService = auth.NewAuthenticationService(&config.AppConfiguration{AppName: "test"}, nil, db, nil, nil, nil)
+		playerID = uuid.Must(uuid.NewV4())
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+		db.Close()
+	})
+
+	expectAccount := func(role domain.Role, disabled bool) {
+		mock.ExpectQuery("SELECT p.id, p.login").
+			WithArgs(playerID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "login", "nickname", "wins", "losses", "draws", "role", "disabled"}).
+				AddRow(playerID, "login", "nickname", 0, 0, 0, role, disabled))
+	}
+
+	It("should return the stored role and cache the account", func() {
+		expectAccount(domain.RoleModerator, false)
+
+		for range 2 {
+			role, err := authService.AuthorizeAccount(context.Background(), playerID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(role).To(Equal(domain.RoleModerator))
+		}
+	})
+
+	It("should reject a disabled account", func() {
+		expectAccount(domain.RoleAdmin, true)
+
+		_, err := authService.AuthorizeAccount(context.Background(), playerID)
+		Expect(err).To(BeAssignableToTypeOf(&apperrors.ForbiddenError{}))
+	})
+
+	It("should reject a deleted account", func() {
+		mock.ExpectQuery("SELECT p.id, p.login").WithArgs(playerID).WillReturnError(sql.ErrNoRows)
+
+		_, err := authService.AuthorizeAccount(context.Background(), playerID)
+		Expect(err).To(BeAssignableToTypeOf(&models.AuthorizationError{}))
+	})
+})
+
+var _ = Describe("Authenticate", func() {
+	var (
+		db          *sql.DB
+		mock        sqlmock.Sqlmock
+		metrics     *metricsmocks.MockMetricsService
+		lockout     *lockoutmocks.MockLockoutService
+		authService auth.AuthenticationService
+		playerID    uuid.UUID
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		metrics = &metricsmocks.MockMetricsService{}
+		metrics.On("LoginFailed").Maybe()
+		lockout = &lockoutmocks.MockLockoutService{}
+		lockout.On("RecordFailure", tmock.Anything, "login", tmock.Anything, "192.0.2.1").Return(nil).Maybe()
+		authService = auth.NewAuthenticationService(&config.AppConfiguration{AppName: "test"}, nil, db, metrics, lockout, nil)
+		playerID = uuid.Must(uuid.NewV4())
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+		lockout.AssertExpectations(GinkgoT())
+		db.Close()
+	})
+
+	expectPlayer := func(password string) {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		Expect(err).ToNot(HaveOccurred())
+		mock.ExpectQuery("SELECT p.id, p.login").
+			WithArgs("login").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "login", "password", "nickname", "wins", "losses", "draws"}).
+				AddRow(playerID, "login", string(hash), "nickname", 0, 0, 0))
+	}
+
+	expectInvalidCredentials := func(err error) {
+		Expect(err).To(BeAssignableToTypeOf(&models.AuthorizationError{}))
+		Expect(err.Error()).To(ContainSubstring(auth.InvalidCredentialsErrorMessage))
+	}
+
+	It("should reject an unknown login like a wrong password", func() {
+		mock.ExpectQuery("SELECT p.id, p.login").WithArgs("login").WillReturnError(sql.ErrNoRows)
+
+		_, err := authService.Authenticate(context.Background(), "login", "password", "192.0.2.1")
+		expectInvalidCredentials(err)
+		lockout.AssertCalled(GinkgoT(), "RecordFailure", tmock.Anything, "login", (*uuid.UUID)(nil), "192.0.2.1")
+	})
+
+	It("should reject a wrong password without checking the lockout", func() {
+		expectPlayer("password")
+
+		_, err := authService.Authenticate(context.Background(), "login", "wrong", "192.0.2.1")
+		expectInvalidCredentials(err)
+		lockout.AssertNotCalled(GinkgoT(), "Check", tmock.Anything, tmock.Anything)
+	})
+
+	It("should answer a locked account like a wrong password", func() {
+		expectPlayer("password")
+		lockout.On("Check", tmock.Anything, playerID).Return(apperrors.NewTooManyRequestsError(time.Minute, "account is locked"))
+
+		_, err := authService.Authenticate(context.Background(), "login", "password", "192.0.2.1")
+		expectInvalidCredentials(err)
+		Expect(err).ToNot(BeAssignableToTypeOf(&apperrors.TooManyRequestsError{}))
+	})
+})
+
+var _ = Describe("AuthenticatePlayer", func() {
+	var (
+		db          *sql.DB
+		mock        sqlmock.Sqlmock
+		twoFactor   *twofactormocks.MockTwoFactorService
+		authService auth.AuthenticationService
+		playerID    uuid.UUID
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		keys, err := auth.NewKeySet(config.SigningConfiguration{}, "secret")
+		Expect(err).ToNot(HaveOccurred())
+		twoFactor = &twofactormocks.MockTwoFactorService{}
+		configuration := &config.AppConfiguration{AppName: "test", TwoFactor: config.TwoFactorConfiguration{ChallengeTTL: time.Minute}}
+		authService = auth.NewAuthenticationService(configuration, keys, db, nil, nil, twoFactor)
+		playerID = uuid.Must(uuid.NewV4())
+
+		mock.ExpectQuery("SELECT p.id, p.login").
+			WithArgs(playerID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "login", "password", "nickname", "wins", "losses", "draws"}).
+				AddRow(playerID, "login", "", "nickname", 0, 0, 0))
+		mock.ExpectQuery("SELECT p.id, p.login").
+			WithArgs(playerID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "login", "nickname", "wins", "losses", "draws", "role", "disabled"}).
+				AddRow(playerID, "login", "nickname", 0, 0, 0, domain.RolePlayer, false))
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+		db.Close()
+	})
+
+	It("should issue a token to players without two-factor authentication", func() {
+		twoFactor.On("Enabled", tmock.Anything, playerID).Return(false, nil)
+
+		response, err := authService.AuthenticatePlayer(context.Background(), playerID, "192.0.2.1")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.TwoFactorRequired).To(BeFalse())
+		Expect(response.Player.ID).To(Equal(playerID))
+		_, err = authService.ValidateToken(response.Token)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should only return a challenge to players with two-factor authentication", func() {
+		twoFactor.On("Enabled", tmock.Anything, playerID).Return(true, nil)
+
+		response, err := authService.AuthenticatePlayer(context.Background(), playerID, "192.0.2.1")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.TwoFactorRequired).To(BeTrue())
+		Expect(response.ChallengeToken).ToNot(BeEmpty())
+		Expect(response.Token).To(BeEmpty())
+		Expect(response.Player).To(BeNil())
+		_, err = authService.ValidateToken(response.ChallengeToken)
+		Expect(err).To(HaveOccurred())
+	})
+})
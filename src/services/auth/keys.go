@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/plamen-v/tic-tac-toe/src/config"
+)
+
+const (
+	KEY_ID_HEADER      string = "kid"
+	JWKSPath           string = "/.well-known/jwks.json"
+	UnknownKeyMessage  string = "unknown signing key"
+	KeyMismatchMessage string = "public key does not match the private key"
+)
+
+// JWK is the public part of a signing key as published in the JWKS document
+// (RFC 7517). RSA keys fill N and E, Ed25519 keys fill Crv and X.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+type signingKey struct {
+	id         string
+	method     jwt.SigningMethod
+	signingKey any
+	verifyKey  any
+}
+
+// KeySet holds the keys tokens are signed and verified with. The active key
+// signs new tokens; every key verifies tokens carrying its id, so a rotated
+// key stays usable until the tokens it signed expire.
+type KeySet struct {
+	active *signingKey
+	keys   map[string]*signingKey
+}
+
+// NewKeySet loads the configured signing keys. Without keys it falls back to
+// HS256 with secret, which is never published in the JWKS document.
+func NewKeySet(configuration config.SigningConfiguration, secret string) (*KeySet, error) {
+	keySet := &KeySet{keys: map[string]*signingKey{}}
+	if len(configuration.Keys) == 0 {
+		if len(secret) == 0 {
+			return nil, errors.New("signing secret is required")
+		}
+		keySet.active = &signingKey{
+			method:     jwt.SigningMethodHS256,
+			signingKey: []byte(secret),
+			verifyKey:  []byte(secret),
+		}
+		keySet.keys[""] = keySet.active
+		return keySet, nil
+	}
+
+	for _, keyConfiguration := range configuration.Keys {
+		key, err := loadSigningKey(keyConfiguration)
+		if err != nil {
+			return nil, fmt.Errorf("signing key '%s': %w", keyConfiguration.ID, err)
+		}
+		keySet.keys[key.id] = key
+	}
+
+	keySet.active = keySet.keys[configuration.ActiveKey]
+	if keySet.active == nil || keySet.active.signingKey == nil {
+		return nil, fmt.Errorf("active signing key '%s' can not sign", configuration.ActiveKey)
+	}
+
+	return keySet, nil
+}
+
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.active.method, claims)
+	if len(k.active.id) > 0 {
+		token.Header[KEY_ID_HEADER] = k.active.id
+	}
+
+	return token.SignedString(k.active.signingKey)
+}
+
+// Keyfunc selects the verification key by the token's kid header and rejects
+// tokens whose algorithm does not match that key.
+func (k *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header[KEY_ID_HEADER].(string)
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, errors.New(UnknownKeyMessage)
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method '%s'", token.Method.Alg())
+	}
+
+	return key.verifyKey, nil
+}
+
+func (k *KeySet) Methods() []string {
+	methods := []string{}
+	seen := map[string]bool{}
+	for _, key := range k.keys {
+		if alg := key.method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+
+	return methods
+}
+
+// JWKS returns the public keys. Symmetric keys are left out.
+func (k *KeySet) JWKS() *JWKSet {
+	jwks := &JWKSet{Keys: []JWK{}}
+	for _, key := range k.keys {
+		jwk := JWK{KeyID: key.id, Use: "sig", Algorithm: key.method.Alg()}
+		switch publicKey := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		default:
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	slices.SortFunc(jwks.Keys, func(a, b JWK) int {
+		return strings.Compare(a.KeyID, b.KeyID)
+	})
+	return jwks
+}
+
+func loadSigningKey(configuration config.SigningKeyConfiguration) (*signingKey, error) {
+	key := &signingKey{id: configuration.ID}
+	switch configuration.Algorithm {
+	case config.RS256SigningAlgorithm:
+		key.method = jwt.SigningMethodRS256
+	case config.EdDSASigningAlgorithm:
+		key.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported algorithm '%s'", configuration.Algorithm)
+	}
+
+	if len(configuration.PrivateKeyFile) > 0 {
+		data, err := os.ReadFile(configuration.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		var privateKey crypto.Signer
+		if configuration.Algorithm == config.RS256SigningAlgorithm {
+			privateKey, err = jwt.ParseRSAPrivateKeyFromPEM(data)
+		} else {
+			var edKey crypto.PrivateKey
+			edKey, err = jwt.ParseEdPrivateKeyFromPEM(data)
+			if err == nil {
+				privateKey = edKey.(crypto.Signer)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		key.signingKey = privateKey
+		key.verifyKey = privateKey.Public()
+	}
+
+	if len(configuration.PublicKeyFile) > 0 {
+		data, err := os.ReadFile(configuration.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		var publicKey crypto.PublicKey
+		if configuration.Algorithm == config.RS256SigningAlgorithm {
+			publicKey, err = jwt.ParseRSAPublicKeyFromPEM(data)
+		} else {
+			publicKey, err = jwt.ParseEdPublicKeyFromPEM(data)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// With both files the public key has to be the one of the private
+		// key, or the tokens the key signs would fail to verify.
+		if key.verifyKey != nil && !key.verifyKey.(interface{ Equal(crypto.PublicKey) bool }).Equal(publicKey) {
+			return nil, errors.New(KeyMismatchMessage)
+		}
+		key.verifyKey = publicKey
+	}
+
+	return key, nil
+}
@@ -0,0 +1,187 @@
+package auth_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe/src/config"
+	"github.com/plamen-v/tic-tac-toe/src/services/auth"
+)
+
+var _ = Describe("KeySet", func() {
+	var dir string
+
+	writePEM := func(name string, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600)).To(Succeed())
+		return path
+	}
+
+	writeEdKeys := func(name string) (string, string) {
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		Expect(err).ToNot(HaveOccurred())
+		publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+		Expect(err).ToNot(HaveOccurred())
+		return writePEM(name+".pem", "PRIVATE KEY", privateDER), writePEM(name+".pub.pem", "PUBLIC KEY", publicDER)
+	}
+
+	writeRSAKey := func(name string) string {
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ToNot(HaveOccurred())
+		return writePEM(name+".pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(privateKey))
+	}
+
+	claims := func() jwt.Claims {
+		return jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}
+	}
+
+	parse := func(keys *auth.KeySet, token string) error {
+		_, err := jwt.Parse(token, keys.Keyfunc, jwt.WithValidMethods(keys.Methods()))
+		return err
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	It("should fall back to HS256 with the secret", func() {
+		keys, err := auth.NewKeySet(config.SigningConfiguration{}, "secret")
+		Expect(err).ToNot(HaveOccurred())
+
+		token, err := keys.Sign(claims())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parse(keys, token)).To(Succeed())
+		Expect(keys.JWKS().Keys).To(BeEmpty())
+	})
+
+	It("should sign with the active key and publish every public key", func() {
+		edPrivate, _ := writeEdKeys("ed")
+		rsaPrivate := writeRSAKey("rsa")
+		keys, err := auth.NewKeySet(config.SigningConfiguration{
+			ActiveKey: "ed",
+			Keys: []config.SigningKeyConfiguration{
+				{ID: "ed", Algorithm: config.EdDSASigningAlgorithm, PrivateKeyFile: edPrivate},
+				{ID: "rsa", Algorithm: config.RS256SigningAlgorithm, PrivateKeyFile: rsaPrivate},
+			},
+		}, "")
+		Expect(err).ToNot(HaveOccurred())
+
+		token, err := keys.Sign(claims())
+		Expect(err).ToNot(HaveOccurred())
+		parsed, err := jwt.Parse(token, keys.Keyfunc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parsed.Header["kid"]).To(Equal("ed"))
+		Expect(parsed.Method).To(Equal(jwt.SigningMethodEdDSA))
+
+		jwks := keys.JWKS()
+		Expect(jwks.Keys).To(HaveLen(2))
+		Expect(jwks.Keys[0].KeyID).To(Equal("ed"))
+		Expect(jwks.Keys[0].KeyType).To(Equal("OKP"))
+		Expect(jwks.Keys[0].X).ToNot(BeEmpty())
+		Expect(jwks.Keys[1].KeyID).To(Equal("rsa"))
+		Expect(jwks.Keys[1].KeyType).To(Equal("RSA"))
+		Expect(jwks.Keys[1].E).To(Equal("AQAB"))
+	})
+
+	It("should keep verifying tokens of a rotated key", func() {
+		oldPrivate, oldPublic := writeEdKeys("old")
+		newPrivate, _ := writeEdKeys("new")
+		oldKeys, err := auth.NewKeySet(config.SigningConfiguration{
+			ActiveKey: "old",
+			Keys:      []config.SigningKeyConfiguration{{ID: "old", Algorithm: config.EdDSASigningAlgorithm, PrivateKeyFile: oldPrivate}},
+		}, "")
+		Expect(err).ToNot(HaveOccurred())
+		token, err := oldKeys.Sign(claims())
+		Expect(err).ToNot(HaveOccurred())
+
+		rotatedKeys, err := auth.NewKeySet(config.SigningConfiguration{
+			ActiveKey: "new",
+			Keys: []config.SigningKeyConfiguration{
+				{ID: "new", Algorithm: config.EdDSASigningAlgorithm, PrivateKeyFile: newPrivate},
+				{ID: "old", Algorithm: config.EdDSASigningAlgorithm, PublicKeyFile: oldPublic},
+			},
+		}, "")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(parse(rotatedKeys, token)).To(Succeed())
+	})
+
+	It("should reject tokens with an unknown key id", func() {
+		private, _ := writeEdKeys("a")
+		other, _ := writeEdKeys("b")
+		keys, err := auth.NewKeySet(config.SigningConfiguration{
+			ActiveKey: "a",
+			Keys:      []config.SigningKeyConfiguration{{ID: "a", Algorithm: config.EdDSASigningAlgorithm, PrivateKeyFile: private}},
+		}, "")
+		Expect(err).ToNot(HaveOccurred())
+		otherKeys, err := auth.NewKeySet(config.SigningConfiguration{
+			ActiveKey: "b",
+			Keys:      []config.SigningKeyConfiguration{{ID: "b", Algorithm: config.EdDSASigningAlgorithm, PrivateKeyFile: other}},
+		}, "")
+		Expect(err).ToNot(HaveOccurred())
+
+		token, err := otherKeys.Sign(claims())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parse(keys, token)).ToNot(Succeed())
+	})
+
+	It("should reject HS256 tokens when asymmetric keys are configured", func() {
+		private, _ := writeEdKeys("a")
+		keys, err := auth.NewKeySet(config.SigningConfiguration{
+			ActiveKey: "a",
+			Keys:      []config.SigningKeyConfiguration{{ID: "a", Algorithm: config.EdDSASigningAlgorithm, PrivateKeyFile: private}},
+		}, "")
+		Expect(err).ToNot(HaveOccurred())
+
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+		token.Header["kid"] = "a"
+		tokenString, err := token.SignedString([]byte("secret"))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(parse(keys, tokenString)).ToNot(Succeed())
+	})
+
+	It("should fail to load a missing key file", func() {
+		_, err := auth.NewKeySet(config.SigningConfiguration{
+			ActiveKey: "a",
+			Keys:      []config.SigningKeyConfiguration{{ID: "a", Algorithm: config.EdDSASigningAlgorithm, PrivateKeyFile: filepath.Join(dir, "missing.pem")}},
+		}, "")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail when the public key does not match the private key", func() {
+		privateKeyFile, _ := writeEdKeys("a")
+		_, publicKeyFile := writeEdKeys("b")
+
+		_, err := auth.NewKeySet(config.SigningConfiguration{
+			ActiveKey: "a",
+			Keys:      []config.SigningKeyConfiguration{{ID: "a", Algorithm: config.EdDSASigningAlgorithm, PrivateKeyFile: privateKeyFile, PublicKeyFile: publicKeyFile}},
+		}, "")
+		Expect(err).To(MatchError(ContainSubstring(auth.KeyMismatchMessage)))
+	})
+
+	It("should load a private key with its public key", func() {
+		privateKeyFile, publicKeyFile := writeEdKeys("a")
+
+		keys, err := auth.NewKeySet(config.SigningConfiguration{
+			ActiveKey: "a",
+			Keys:      []config.SigningKeyConfiguration{{ID: "a", Algorithm: config.EdDSASigningAlgorithm, PrivateKeyFile: privateKeyFile, PublicKeyFile: publicKeyFile}},
+		}, "")
+		Expect(err).ToNot(HaveOccurred())
+
+		tokenString, err := keys.Sign(claims())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parse(keys, tokenString)).To(Succeed())
+	})
+})
@@ -3,8 +3,11 @@ package mocks
 import (
 	"context"
 
+	"github.com/gofrs/uuid"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/auth"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -20,10 +23,39 @@ func (m *MockAuthenticationService) ValidateToken(token string) (*jwt.Token, err
 	return nil, args.Error(1)
 }
 
-func (m *MockAuthenticationService) Authenticate(ctx context.Context, login string, password string) (*models.Player, string, error) {
-	args := m.Called(ctx, login, password)
+func (m *MockAuthenticationService) JWKS() *auth.JWKSet {
+	args := m.Called()
+	if jwks, ok := args.Get(0).(*auth.JWKSet); ok {
+		return jwks
+	}
+	return nil
+}
+
+func (m *MockAuthenticationService) Authenticate(ctx context.Context, login string, password string, ip string) (*domain.LoginResponse, error) {
+	args := m.Called(ctx, login, password, ip)
+	if response, ok := args.Get(0).(*domain.LoginResponse); ok {
+		return response, nil
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAuthenticationService) VerifyTwoFactor(ctx context.Context, challengeToken string, code string, ip string) (*models.Player, string, error) {
+	args := m.Called(ctx, challengeToken, code, ip)
 	if player, ok := args.Get(0).(*models.Player); ok {
 		return player, args.Get(1).(string), nil
 	}
 	return nil, "", args.Error(2)
 }
+
+func (m *MockAuthenticationService) AuthenticatePlayer(ctx context.Context, playerID uuid.UUID, ip string) (*domain.LoginResponse, error) {
+	args := m.Called(ctx, playerID, ip)
+	if response, ok := args.Get(0).(*domain.LoginResponse); ok {
+		return response, nil
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAuthenticationService) AuthorizeAccount(ctx context.Context, playerID uuid.UUID) (domain.Role, error) {
+	args := m.Called(ctx, playerID)
+	return args.Get(0).(domain.Role), args.Error(1)
+}
@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+)
+
+const (
+	// WinStreakLength is the win streak that unlocks AchievementWinStreak.
+	WinStreakLength int = 10
+	// RoundRobinOpponents is the number of different opponents a player has to
+	// beat in consecutive games for AchievementPerfectRoundRobin.
+	RoundRobinOpponents int = 5
+)
+
+// EarnedAchievements returns the achievements the winner earned with the
+// completed game. winningMoves is the fewest moves the game can be won with,
+// see GameRules.MinWinningMoves, if the winner won it with a line of own marks
+// and 0 otherwise, for example when it was forfeited. streak is the streak of
+// the winner including the game and recent are the games the winner completed
+// before it, newest first.
+func EarnedAchievements(game *models.Game, winnerID uuid.UUID, winningMoves int, streak *domain.Streak, recent []*domain.GameSummary) []domain.AchievementCode {
+	codes := []domain.AchievementCode{domain.AchievementFirstWin}
+
+	if streak != nil && streak.Current >= WinStreakLength {
+		codes = append(codes, domain.AchievementWinStreak)
+	}
+
+	mark, opponentID := game.Host.Mark, game.Guest.ID
+	if winnerID == game.Guest.ID {
+		mark, opponentID = game.Guest.Mark, game.Host.ID
+	}
+
+	if winningMoves > 0 {
+		// The winner made the last move, so an even number of marks means
+		// that the winner moved second.
+		marks := strings.Count(game.Board, string(XMark)) + strings.Count(game.Board, string(OMark))
+		if mark == string(OMark) && marks%2 == 0 {
+			codes = append(codes, domain.AchievementSecondMoverWin)
+		}
+
+		if strings.Count(game.Board, mark) == winningMoves {
+			codes = append(codes, domain.AchievementQuickestWin)
+		}
+	}
+
+	if perfectRoundRobin(opponentID, recent) {
+		codes = append(codes, domain.AchievementPerfectRoundRobin)
+	}
+
+	return codes
+}
+
+func perfectRoundRobin(opponentID uuid.UUID, recent []*domain.GameSummary) bool {
+	if len(recent) < RoundRobinOpponents-1 {
+		return false
+	}
+
+	opponents := map[uuid.UUID]bool{opponentID: true}
+	for _, game := range recent[:RoundRobinOpponents-1] {
+		if game.Result != domain.GameResultWin || opponents[game.OpponentID] {
+			return false
+		}
+		opponents[game.OpponentID] = true
+	}
+
+	return true
+}
@@ -0,0 +1,127 @@
+package engine_test
+
+import (
+	"strings"
+
+	"github.com/gofrs/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/engine"
+)
+
+var _ = Describe("EarnedAchievements", func() {
+	var (
+		hostID  uuid.UUID
+		guestID uuid.UUID
+		game    *models.Game
+	)
+
+	BeforeEach(func() {
+		hostID = uuid.Must(uuid.NewV4())
+		guestID = uuid.Must(uuid.NewV4())
+		game = &models.Game{
+			Host:  models.GamePlayer{ID: hostID, Mark: string(engine.XMark)},
+			Guest: models.GamePlayer{ID: guestID, Mark: string(engine.OMark)},
+		}
+	})
+
+	wins := func(opponents ...uuid.UUID) []*domain.GameSummary {
+		games := []*domain.GameSummary{}
+		for _, opponentID := range opponents {
+			games = append(games, &domain.GameSummary{OpponentID: opponentID, Result: domain.GameResultWin})
+		}
+		return games
+	}
+
+	minWinningMoves := func(variant domain.GameVariant, ruleSet domain.RuleSet) int {
+		rules, ok := engine.LookupGame(variant)
+		Expect(ok).To(BeTrue())
+		return rules.MinWinningMoves(ruleSet)
+	}
+
+	It("should unlock the first win and the quickest win", func() {
+		game.Board = "XXXOO____"
+
+		codes := engine.EarnedAchievements(game, hostID, 3, &domain.Streak{Current: 1, Longest: 1}, nil)
+
+		Expect(codes).To(ConsistOf(domain.AchievementFirstWin, domain.AchievementQuickestWin))
+	})
+
+	It("should unlock the win of O moving second", func() {
+		game.Board = "XX_OOOX__"
+
+		codes := engine.EarnedAchievements(game, guestID, 3, &domain.Streak{Current: 1, Longest: 1}, nil)
+
+		Expect(codes).To(ConsistOf(domain.AchievementFirstWin, domain.AchievementQuickestWin, domain.AchievementSecondMoverWin))
+	})
+
+	It("should not unlock the board achievements of a forfeited game", func() {
+		game.Board = "XO_______"
+
+		codes := engine.EarnedAchievements(game, guestID, 0, &domain.Streak{Current: 1, Longest: 1}, nil)
+
+		Expect(codes).To(ConsistOf(domain.AchievementFirstWin))
+	})
+
+	It("should unlock the win streak", func() {
+		game.Board = "XOXOXOX__"
+
+		codes := engine.EarnedAchievements(game, hostID, 3, &domain.Streak{Current: engine.WinStreakLength, Longest: engine.WinStreakLength}, nil)
+
+		Expect(codes).To(ContainElement(domain.AchievementWinStreak))
+	})
+
+	It("should unlock the perfect round-robin after beating different opponents in a row", func() {
+		game.Board = "XOXOXOX__"
+		recent := wins(uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()))
+
+		codes := engine.EarnedAchievements(game, hostID, 3, &domain.Streak{Current: 5, Longest: 5}, recent)
+
+		Expect(codes).To(ContainElement(domain.AchievementPerfectRoundRobin))
+	})
+
+	It("should not unlock the perfect round-robin after beating an opponent twice", func() {
+		game.Board = "XOXOXOX__"
+		recent := wins(uuid.Must(uuid.NewV4()), guestID, uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()))
+
+		codes := engine.EarnedAchievements(game, hostID, 3, &domain.Streak{Current: 5, Longest: 5}, recent)
+
+		Expect(codes).ToNot(ContainElement(domain.AchievementPerfectRoundRobin))
+	})
+
+	DescribeTable("should unlock the quickest win with the fewest winning moves of the game",
+		func(variant domain.GameVariant, board string, quickest bool) {
+			game.Board = board
+			winningMoves := minWinningMoves(variant, domain.RuleSetStandard)
+
+			codes := engine.EarnedAchievements(game, hostID, winningMoves, &domain.Streak{Current: 1, Longest: 1}, nil)
+
+			if quickest {
+				Expect(codes).To(ContainElement(domain.AchievementQuickestWin))
+			} else {
+				Expect(codes).ToNot(ContainElement(domain.AchievementQuickestWin))
+			}
+		},
+		Entry("classic", domain.GameVariantClassic, "XXXOO____", true),
+		Entry("classic with more moves", domain.GameVariantClassic, "XOXOXOX__", false),
+		Entry("ultimate", domain.GameVariantUltimate,
+			"XXXOO____XXXOO____XXXOO____"+strings.Repeat(engine.DefaultBoard, 6), true),
+		Entry("ultimate with three marks", domain.GameVariantUltimate,
+			"XXXOO____"+strings.Repeat(engine.DefaultBoard, 8), false),
+		Entry("connect four", domain.GameVariantConnectFour,
+			strings.Repeat("_", 28)+"O______O______XXXXO__", true),
+		Entry("connect four with three marks", domain.GameVariantConnectFour,
+			strings.Repeat("_", 35)+"XXXOO__", false),
+	)
+
+	It("should not unlock the board achievements of a misere win", func() {
+		game.Board = "XXOOO_X__"
+		winningMoves := minWinningMoves(domain.GameVariantClassic, domain.RuleSetMisere)
+
+		codes := engine.EarnedAchievements(game, guestID, winningMoves, &domain.Streak{Current: 1, Longest: 1}, nil)
+
+		Expect(codes).To(ConsistOf(domain.AchievementFirstWin))
+	})
+})
@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+)
+
+// classicGame is tic-tac-toe on a single 3x3 board under any of the rule
+// sets.
+type classicGame struct{}
+
+func (classicGame) MoveKind() MoveKind {
+	return MoveKindCell
+}
+
+func (classicGame) MinWinningMoves(ruleSet domain.RuleSet) int {
+	if ruleSet != domain.RuleSetStandard {
+		return 0
+	}
+
+	return len(boardLines[0])
+}
+
+func (classicGame) NewState(ruleSet domain.RuleSet) (*State, error) {
+	if _, ok := ruleSets[ruleSet]; !ok {
+		return nil, models.NewValidationErrorf(InvalidRuleSetErrorMessage, ruleSet)
+	}
+
+	return &State{Board: DefaultBoard, RuleSet: ruleSet}, nil
+}
+
+func (g classicGame) LegalMoves(state *State) []int {
+	if g.Outcome(state) != ResultNone {
+		return []int{}
+	}
+
+	moves := []int{}
+	for i := 0; i < len(state.Board); i++ {
+		if state.Board[i] == DefaultBoardTile {
+			moves = append(moves, i+1)
+		}
+	}
+
+	return moves
+}
+
+func (classicGame) Apply(state *State, move Move) (byte, error) {
+	if move.Position < 1 || move.Position > len(state.Board) {
+		return 0, models.NewValidationError(InvalidBoardPositionErrorMessage)
+	}
+
+	if state.Board[move.Position-1] != DefaultBoardTile {
+		return 0, models.NewValidationError(BoardPositionOcopiedErrorMessage)
+	}
+
+	mark, err := ruleSets[state.RuleSet].Mark(move.PlayerMark, move.Mark)
+	if err != nil {
+		return 0, err
+	}
+
+	state.Board = placeMark(state.Board, move.Position, mark)
+	return mark, nil
+}
+
+func (classicGame) Outcome(state *State) Result {
+	return ruleSets[state.RuleSet].Result(state.Board)
+}
+
+func (classicGame) Encode(state *State) string {
+	return state.Board
+}
+
+func (classicGame) Decode(board string, variantState *domain.VariantState) (*State, error) {
+	if _, ok := ruleSets[variantState.RuleSet]; !ok {
+		return nil, models.NewGenericError(fmt.Sprintf(InvalidRuleSetErrorMessage, variantState.RuleSet))
+	}
+
+	if err := decodeBoard(domain.GameVariantClassic, board, len(DefaultBoard), string([]byte{XMark, OMark})); err != nil {
+		return nil, err
+	}
+
+	return &State{Board: board, RuleSet: variantState.RuleSet}, nil
+}
@@ -0,0 +1,155 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+)
+
+// A Connect Four board is stored row by row from the top row down, so the
+// cell in row r and column c (both 0-based) is at index r*ConnectFourColumns+c.
+// The position of a move is its 1-based column.
+const (
+	ConnectFourColumns    int = 7
+	ConnectFourRows       int = 6
+	ConnectFourLineLength int = 4
+)
+
+var (
+	ConnectFourBoard string = strings.Repeat(string(DefaultBoardTile), ConnectFourColumns*ConnectFourRows)
+
+	InvalidColumnErrorMessage string = "invalid column index"
+	ColumnFullErrorMessage    string = "column is full"
+)
+
+// connectFourDirections are the steps of a row, a column and the two
+// diagonals.
+var connectFourDirections = [4][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+
+// connectFourGame is Connect Four, played under the standard rules only.
+type connectFourGame struct{}
+
+func (connectFourGame) MoveKind() MoveKind {
+	return MoveKindColumn
+}
+
+func (connectFourGame) MinWinningMoves(ruleSet domain.RuleSet) int {
+	if ruleSet != domain.RuleSetStandard {
+		return 0
+	}
+
+	return ConnectFourLineLength
+}
+
+func (connectFourGame) NewState(ruleSet domain.RuleSet) (*State, error) {
+	if ruleSet != domain.RuleSetStandard {
+		return nil, models.NewValidationErrorf(RuleSetVariantErrorMessage, ruleSet, domain.GameVariantConnectFour)
+	}
+
+	return &State{Board: ConnectFourBoard, RuleSet: ruleSet}, nil
+}
+
+func (g connectFourGame) LegalMoves(state *State) []int {
+	if g.Outcome(state) != ResultNone {
+		return []int{}
+	}
+
+	moves := []int{}
+	for column := 0; column < ConnectFourColumns; column++ {
+		if state.Board[column] == DefaultBoardTile {
+			moves = append(moves, column+1)
+		}
+	}
+
+	return moves
+}
+
+func (connectFourGame) Apply(state *State, move Move) (byte, error) {
+	if move.Position < 1 || move.Position > ConnectFourColumns {
+		return 0, models.NewValidationError(InvalidColumnErrorMessage)
+	}
+
+	row := connectFourDropRow(state.Board, move.Position-1)
+	if row < 0 {
+		return 0, models.NewValidationError(ColumnFullErrorMessage)
+	}
+
+	mark, err := standardRules{}.Mark(move.PlayerMark, move.Mark)
+	if err != nil {
+		return 0, err
+	}
+
+	state.Board = placeMark(state.Board, row*ConnectFourColumns+move.Position, mark)
+	return mark, nil
+}
+
+func (connectFourGame) Outcome(state *State) Result {
+	if connectFourHasLine(state.Board) {
+		return ResultWin
+	}
+
+	if !strings.Contains(state.Board, string(DefaultBoardTile)) {
+		return ResultDraw
+	}
+
+	return ResultNone
+}
+
+func (connectFourGame) Encode(state *State) string {
+	return state.Board
+}
+
+// Decode also rejects boards with a mark above a free cell.
+func (connectFourGame) Decode(board string, variantState *domain.VariantState) (*State, error) {
+	if err := decodeBoard(domain.GameVariantConnectFour, board, len(ConnectFourBoard), string([]byte{XMark, OMark})); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < len(board)-ConnectFourColumns; i++ {
+		if board[i] != DefaultBoardTile && board[i+ConnectFourColumns] == DefaultBoardTile {
+			return nil, models.NewGenericError(fmt.Sprintf(InvalidBoardErrorMessage, domain.GameVariantConnectFour))
+		}
+	}
+
+	return &State{Board: board, RuleSet: domain.RuleSetStandard}, nil
+}
+
+// connectFourDropRow returns the lowest free row of the 0-based column, or -1
+// when the column is full.
+func connectFourDropRow(board string, column int) int {
+	for row := ConnectFourRows - 1; row >= 0; row-- {
+		if board[row*ConnectFourColumns+column] == DefaultBoardTile {
+			return row
+		}
+	}
+
+	return -1
+}
+
+func connectFourHasLine(board string) bool {
+	for row := 0; row < ConnectFourRows; row++ {
+		for column := 0; column < ConnectFourColumns; column++ {
+			tile := board[row*ConnectFourColumns+column]
+			if tile == DefaultBoardTile {
+				continue
+			}
+
+			for _, direction := range connectFourDirections {
+				length := 1
+				for ; length < ConnectFourLineLength; length++ {
+					r, c := row+length*direction[0], column+length*direction[1]
+					if r >= ConnectFourRows || c < 0 || c >= ConnectFourColumns || board[r*ConnectFourColumns+c] != tile {
+						break
+					}
+				}
+				if length == ConnectFourLineLength {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
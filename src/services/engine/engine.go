@@ -5,12 +5,16 @@ import (
 	"database/sql"
 	"fmt"
 	"math/rand/v2"
-	"strings"
+	"time"
 
 	"github.com/gofrs/uuid"
 
 	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/apperrors"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
 	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/services/logger"
+	"github.com/plamen-v/tic-tac-toe/src/services/metrics"
 )
 
 const (
@@ -37,36 +41,68 @@ var (
 	PlayerNotInTurnErrorMessage            string = "player not in turn"
 	InvalidBoardPositionErrorMessage       string = "invalid position index"
 	BoardPositionOcopiedErrorMessage       string = "position ocopied"
+	PrivateRoomErrorMessage                string = "room is private"
+	PlayerBlockedErrorMessage              string = "player is blocked"
+	InviteSelfErrorMessage                 string = "player can not invite himself"
+	InvitationAcceptedErrorMessage         string = "invitation is already accepted"
+	InvalidRoomSortErrorMessage            string = "invalid sort '%s'"
+	InvalidRatingRangeErrorMessage         string = "invalid rating range"
+	InvalidVariantErrorMessage             string = "invalid variant '%s'"
+	GameRankingCursorErrorMessage          string = "cursor pagination is only available for the overall ranking"
+	InvalidMarkPolicyErrorMessage          string = "invalid mark policy '%s'"
+	InvalidFirstMovePolicyErrorMessage     string = "invalid first move policy '%s'"
+	InvalidTimeControlErrorMessage         string = "invalid time control '%s'"
 )
 
 type GameEngineService interface {
 	GetRoom(context.Context, uuid.UUID) (*models.Room, error)
-	GetOpenRooms(context.Context, int, int) ([]*models.Room, int, int, int, error)
-	CreateRoom(context.Context, uuid.UUID, string, string) (uuid.UUID, error)
+	GetOpenRooms(context.Context, *domain.RoomFilter, int, int) ([]*models.Room, int, int, int, error)
+	GetOpenRoomsAfter(context.Context, *domain.RoomFilter, string, int) ([]*models.Room, string, error)
+	CreateRoom(context.Context, uuid.UUID, string, string, *domain.RoomSettings) (uuid.UUID, error)
 	PlayerJoinRoom(context.Context, uuid.UUID, uuid.UUID) error
 	PlayerLeaveRoom(context.Context, uuid.UUID, uuid.UUID) error
 	CreateGame(context.Context, uuid.UUID, uuid.UUID) (uuid.UUID, error)
-	GetGameState(context.Context, uuid.UUID, uuid.UUID) (*models.Game, error)
-	PlayerMakeMove(context.Context, uuid.UUID, uuid.UUID, int) error
+	GetGameState(context.Context, uuid.UUID, uuid.UUID) (*domain.GameState, error)
+	PlayerMakeMove(context.Context, uuid.UUID, uuid.UUID, MoveKind, int, string) error
 	GetRanking(context.Context, int, int) ([]*models.Player, int, int, int, error)
+	GetRankingAfter(context.Context, string, int) ([]*models.Player, string, error)
+	GetGameRanking(context.Context, domain.GameVariant, int, int) ([]*models.Player, int, int, int, error)
+	InvitePlayer(context.Context, uuid.UUID, uuid.UUID, string, string) (uuid.UUID, error)
+	GetInvitations(context.Context, uuid.UUID) ([]*domain.Invitation, error)
+	DeclineInvitation(context.Context, uuid.UUID, uuid.UUID) error
 }
 
 type gameEngineServiceImpl struct {
-	db                      *sql.DB
-	playerRepositoryFactory func(q repository.Querier) repository.PlayerRepository
-	gameRepositoryFactory   func(q repository.Querier) repository.GameRepository
-	roomRepositoryFactory   func(q repository.Querier) repository.RoomRepository
+	db                           *sql.DB
+	metrics                      metrics.MetricsService
+	playerRepositoryFactory      func(q repository.Querier) repository.PlayerRepository
+	gameRepositoryFactory        func(q repository.Querier) repository.GameRepository
+	roomRepositoryFactory        func(q repository.Querier) repository.RoomRepository
+	blockRepositoryFactory       func(q repository.Querier) repository.BlockRepository
+	invitationRepositoryFactory  func(q repository.Querier) repository.InvitationRepository
+	seasonRepositoryFactory      func(q repository.Querier) repository.SeasonRepository
+	achievementRepositoryFactory func(q repository.Querier) repository.AchievementRepository
 }
 
 func NewGameEngineService(db *sql.DB,
+	metrics metrics.MetricsService,
 	playerRepositoryFactory func(q repository.Querier) repository.PlayerRepository,
 	gameRepositoryFactory func(q repository.Querier) repository.GameRepository,
-	roomRepositoryFactory func(q repository.Querier) repository.RoomRepository) GameEngineService {
+	roomRepositoryFactory func(q repository.Querier) repository.RoomRepository,
+	blockRepositoryFactory func(q repository.Querier) repository.BlockRepository,
+	invitationRepositoryFactory func(q repository.Querier) repository.InvitationRepository,
+	seasonRepositoryFactory func(q repository.Querier) repository.SeasonRepository,
+	achievementRepositoryFactory func(q repository.Querier) repository.AchievementRepository) GameEngineService {
 	return &gameEngineServiceImpl{
-		db:                      db,
-		playerRepositoryFactory: playerRepositoryFactory,
-		gameRepositoryFactory:   gameRepositoryFactory,
-		roomRepositoryFactory:   roomRepositoryFactory,
+		db:                           db,
+		metrics:                      metrics,
+		playerRepositoryFactory:      playerRepositoryFactory,
+		gameRepositoryFactory:        gameRepositoryFactory,
+		roomRepositoryFactory:        roomRepositoryFactory,
+		blockRepositoryFactory:       blockRepositoryFactory,
+		invitationRepositoryFactory:  invitationRepositoryFactory,
+		seasonRepositoryFactory:      seasonRepositoryFactory,
+		achievementRepositoryFactory: achievementRepositoryFactory,
 	}
 }
 
@@ -74,11 +110,59 @@ func (g *gameEngineServiceImpl) GetRoom(ctx context.Context, playerID uuid.UUID)
 	return g.roomRepositoryFactory(g.db).GetByPlayerID(ctx, playerID)
 }
 
-func (g *gameEngineServiceImpl) GetOpenRooms(ctx context.Context, page int, pageSize int) ([]*models.Room, int, int, int, error) {
-	return g.roomRepositoryFactory(g.db).GetList(ctx, models.RoomPhaseOpen, page, pageSize)
+func (g *gameEngineServiceImpl) GetOpenRooms(ctx context.Context, filter *domain.RoomFilter, page int, pageSize int) ([]*models.Room, int, int, int, error) {
+	if err := g.validateGetOpenRooms(filter); err != nil {
+		return nil, 0, 0, 0, err
+	}
+
+	return g.roomRepositoryFactory(g.db).GetList(ctx, models.RoomPhaseOpen, filter, page, pageSize)
 }
 
-func (g *gameEngineServiceImpl) CreateRoom(ctx context.Context, playerID uuid.UUID, title string, description string) (id uuid.UUID, err error) {
+// GetOpenRoomsAfter reads the open rooms by cursor instead of page number, so
+// that rooms opened or closed meanwhile do not shift the following pages.
+func (g *gameEngineServiceImpl) GetOpenRoomsAfter(ctx context.Context, filter *domain.RoomFilter, cursor string, pageSize int) ([]*models.Room, string, error) {
+	if err := g.validateGetOpenRooms(filter); err != nil {
+		return nil, "", err
+	}
+
+	if pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+
+	return g.roomRepositoryFactory(g.db).GetListAfter(ctx, models.RoomPhaseOpen, filter, cursor, pageSize)
+}
+
+func (g *gameEngineServiceImpl) validateGetOpenRooms(filter *domain.RoomFilter) error {
+	if filter == nil {
+		return nil
+	}
+
+	switch filter.Sort {
+	case "", domain.RoomSortNewest, domain.RoomSortRating:
+	default:
+		return models.NewValidationErrorf(InvalidRoomSortErrorMessage, filter.Sort)
+	}
+
+	if filter.MinRating != nil && filter.MaxRating != nil && *filter.MinRating > *filter.MaxRating {
+		return models.NewValidationError(InvalidRatingRangeErrorMessage)
+	}
+
+	if _, ok := LookupGame(filter.Variant); filter.Variant != "" && !ok {
+		return models.NewValidationErrorf(InvalidVariantErrorMessage, filter.Variant)
+	}
+
+	if filter.TimeControl != "" {
+		return validateTimeControl(filter.TimeControl)
+	}
+
+	return nil
+}
+
+func (g *gameEngineServiceImpl) CreateRoom(ctx context.Context, playerID uuid.UUID, title string, description string, settings *domain.RoomSettings) (id uuid.UUID, err error) {
+	if settings == nil {
+		settings = domain.DefaultRoomSettings()
+	}
+
 	room := &models.Room{
 		Host: models.RoomPlayer{
 			ID:       playerID,
@@ -88,19 +172,60 @@ func (g *gameEngineServiceImpl) CreateRoom(ctx context.Context, playerID uuid.UU
 		Description: description,
 		Phase:       models.RoomPhaseOpen,
 	}
+	rules, ok := LookupGame(settings.Variant)
+	if !ok {
+		return uuid.Nil, models.NewValidationErrorf(InvalidVariantErrorMessage, settings.Variant)
+	}
+	if _, err = rules.NewState(settings.RuleSet); err != nil {
+		return uuid.Nil, err
+	}
+	if err = validateStartPolicies(settings); err != nil {
+		return uuid.Nil, err
+	}
+	if err = validateTimeControl(settings.TimeControl); err != nil {
+		return uuid.Nil, err
+	}
+
 	roomRepository := g.roomRepositoryFactory(g.db)
 	err = g.validateCreateRoom(ctx, roomRepository, room, room.Host.ID)
 	if err != nil {
 		return uuid.Nil, err
 	}
 
-	id, err = roomRepository.Create(ctx, room)
+	id, err = roomRepository.Create(ctx, room, settings)
 	if err != nil {
 		return uuid.Nil, err
 	}
+
+	logger.FromContext(ctx).Info("room created", logger.String("room_id", id.String()))
 	return id, nil
 }
 
+func validateStartPolicies(settings *domain.RoomSettings) error {
+	switch settings.MarkPolicy {
+	case domain.MarkPolicyRandom, domain.MarkPolicyHostX:
+	default:
+		return models.NewValidationErrorf(InvalidMarkPolicyErrorMessage, settings.MarkPolicy)
+	}
+
+	switch settings.FirstMovePolicy {
+	case domain.FirstMovePolicyAlternate, domain.FirstMovePolicyCoinFlip, domain.FirstMovePolicyXFirst, domain.FirstMovePolicyLoserStarts:
+	default:
+		return models.NewValidationErrorf(InvalidFirstMovePolicyErrorMessage, settings.FirstMovePolicy)
+	}
+
+	return nil
+}
+
+func validateTimeControl(timeControl domain.TimeControl) error {
+	switch timeControl {
+	case domain.TimeControlUntimed, domain.TimeControlBlitz, domain.TimeControlRapid:
+		return nil
+	default:
+		return models.NewValidationErrorf(InvalidTimeControlErrorMessage, timeControl)
+	}
+}
+
 func (g *gameEngineServiceImpl) validateCreateRoom(ctx context.Context, roomRepository repository.RoomRepository, room *models.Room, playerID uuid.UUID) error {
 	playerRoom, err := roomRepository.GetByPlayerID(ctx, playerID)
 	if err != nil && !models.IsNotFoundError(err) {
@@ -128,14 +253,14 @@ func (g *gameEngineServiceImpl) validateCreateRoom(ctx context.Context, roomRepo
 }
 
 func (g *gameEngineServiceImpl) PlayerJoinRoom(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) error {
-	return withTransaction(ctx, g.db, func(tx *sql.Tx) error {
+	err := repository.WithTransaction(ctx, g.db, func(tx *sql.Tx) error {
 		roomRepository := g.roomRepositoryFactory(tx)
 		room, err := roomRepository.Get(ctx, roomID, true)
 		if err != nil {
 			return err
 		}
 
-		err = g.validatePlayerJoinRoom(ctx, roomRepository, room, playerID)
+		err = g.validatePlayerJoinRoom(ctx, tx, roomRepository, room, playerID)
 		if err != nil {
 			return err
 		}
@@ -146,7 +271,7 @@ func (g *gameEngineServiceImpl) PlayerJoinRoom(ctx context.Context, roomID uuid.
 		}
 
 		gameRepository := g.gameRepositoryFactory(tx)
-		err = g.createGame(ctx, gameRepository, room)
+		err = g.createGame(ctx, roomRepository, gameRepository, room)
 		if err != nil {
 			return err
 		}
@@ -159,9 +284,16 @@ func (g *gameEngineServiceImpl) PlayerJoinRoom(ctx context.Context, roomID uuid.
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	g.metrics.GameStarted()
+	logger.FromContext(ctx).Info("player joined room", logger.String("room_id", roomID.String()))
+	return nil
 }
 
-func (g *gameEngineServiceImpl) validatePlayerJoinRoom(ctx context.Context, roomRepository repository.RoomRepository, room *models.Room, playerID uuid.UUID) error {
+func (g *gameEngineServiceImpl) validatePlayerJoinRoom(ctx context.Context, q repository.Querier, roomRepository repository.RoomRepository, room *models.Room, playerID uuid.UUID) error {
 	if room.Phase == models.RoomPhaseFull {
 		return models.NewValidationError(FullRoomErrorMessage)
 	}
@@ -176,15 +308,38 @@ func (g *gameEngineServiceImpl) validatePlayerJoinRoom(ctx context.Context, room
 
 	if _, err := roomRepository.GetByPlayerID(ctx, playerID); err == nil {
 		return models.NewValidationError(PlayerPartOfOtherRoomErrorMessage)
-	} else if models.IsNotFoundError(err) {
-		return nil
-	} else {
+	} else if !models.IsNotFoundError(err) {
+		return err
+	}
+
+	invitation, err := g.invitationRepositoryFactory(q).Get(ctx, room.ID)
+	if err != nil && !models.IsNotFoundError(err) {
 		return err
 	}
+	if invitation != nil && invitation.PlayerID != playerID {
+		return apperrors.NewForbiddenError(PrivateRoomErrorMessage)
+	}
+
+	return g.validateNotBlocked(ctx, q, room.Host.ID, playerID)
+}
+
+// validateNotBlocked fails when either of the players blocked the other.
+func (g *gameEngineServiceImpl) validateNotBlocked(ctx context.Context, q repository.Querier, playerID uuid.UUID, otherID uuid.UUID) error {
+	blocked, err := g.blockRepositoryFactory(q).Exists(ctx, playerID, otherID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return apperrors.NewForbiddenError(PlayerBlockedErrorMessage)
+	}
+
+	return nil
 }
 
 func (g *gameEngineServiceImpl) PlayerLeaveRoom(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) (err error) {
-	return withTransaction(ctx, g.db, func(tx *sql.Tx) error {
+	gameCompleted := false
+	emptyRoom := false
+	err = repository.WithTransaction(ctx, g.db, func(tx *sql.Tx) error {
 		roomRepository := g.roomRepositoryFactory(tx)
 
 		room, err := roomRepository.Get(ctx, roomID, true)
@@ -222,12 +377,7 @@ func (g *gameEngineServiceImpl) PlayerLeaveRoom(ctx context.Context, roomID uuid
 					g.finalizeGameWithWin(game, host, guest)
 				}
 
-				err = playerRepository.UpdateStats(ctx, host)
-				if err != nil {
-					return err
-				}
-
-				err = playerRepository.UpdateStats(ctx, guest)
+				err = g.recordResult(ctx, tx, room.ID, gameRepository, game, host, guest, 0)
 				if err != nil {
 					return err
 				}
@@ -236,15 +386,20 @@ func (g *gameEngineServiceImpl) PlayerLeaveRoom(ctx context.Context, roomID uuid
 				if err != nil {
 					return err
 				}
+				gameCompleted = true
 			}
 		}
 
-		emptyRoom := false
 		room.Phase = models.RoomPhaseOpen
 		if playerIsHost {
 			if room.Guest != nil {
 				room.Host = *room.Guest
 				room.Guest = nil
+				// The invitation was the host's; the room of the new host is public.
+				err = g.invitationRepositoryFactory(tx).Delete(ctx, room.ID)
+				if err != nil {
+					return err
+				}
 			} else {
 				emptyRoom = true
 			}
@@ -266,6 +421,20 @@ func (g *gameEngineServiceImpl) PlayerLeaveRoom(ctx context.Context, roomID uuid
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	log := logger.FromContext(ctx).With(logger.String("room_id", roomID.String()))
+	if gameCompleted {
+		g.metrics.GameCompleted(false)
+		log.Info("game forfeited")
+	}
+	if emptyRoom {
+		log.Info("room closed")
+	}
+	log.Info("player left room")
+	return nil
 }
 
 func (g *gameEngineServiceImpl) validatePlayerLeaveRoom(room *models.Room, playerID uuid.UUID) error {
@@ -278,7 +447,7 @@ func (g *gameEngineServiceImpl) validatePlayerLeaveRoom(room *models.Room, playe
 }
 
 func (g *gameEngineServiceImpl) CreateGame(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) (uuid.UUID, error) {
-	return withTransactionT(ctx, g.db, func(tx *sql.Tx) (uuid.UUID, error) {
+	gameID, err := repository.WithTransactionT(ctx, g.db, func(tx *sql.Tx) (uuid.UUID, error) {
 		roomRepository := g.roomRepositoryFactory(tx)
 		room, err := roomRepository.Get(ctx, roomID, true)
 		if err != nil {
@@ -302,7 +471,7 @@ func (g *gameEngineServiceImpl) CreateGame(ctx context.Context, roomID uuid.UUID
 		gameRepository := g.gameRepositoryFactory(tx)
 		if room.Guest != nil {
 			if room.Guest.Continue && room.Host.Continue {
-				err := g.createGame(ctx, gameRepository, room)
+				err := g.createGame(ctx, roomRepository, gameRepository, room)
 				if err != nil {
 					return uuid.Nil, err
 				}
@@ -317,6 +486,15 @@ func (g *gameEngineServiceImpl) CreateGame(ctx context.Context, roomID uuid.UUID
 		}
 		return gameID, nil
 	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if gameID != uuid.Nil {
+		g.metrics.GameStarted()
+		logger.FromContext(ctx).Info("game started", logger.String("room_id", roomID.String()), logger.String("game_id", gameID.String()))
+	}
+	return gameID, nil
 }
 
 func (g *gameEngineServiceImpl) validateCreateGame(ctx context.Context, room *models.Room, playerID uuid.UUID) error {
@@ -328,7 +506,7 @@ func (g *gameEngineServiceImpl) validateCreateGame(ctx context.Context, room *mo
 	return nil
 }
 
-func (g *gameEngineServiceImpl) GetGameState(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) (*models.Game, error) {
+func (g *gameEngineServiceImpl) GetGameState(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) (*domain.GameState, error) {
 	roomRepository := g.roomRepositoryFactory(g.db)
 	room, err := roomRepository.Get(ctx, roomID, false)
 	if err != nil {
@@ -349,7 +527,33 @@ func (g *gameEngineServiceImpl) GetGameState(ctx context.Context, roomID uuid.UU
 	if err != nil {
 		return nil, err
 	}
-	return game, nil
+
+	variantState, err := gameRepository.GetVariantState(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := gameRules(variantState.Variant)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := rules.Decode(game.Board, variantState)
+	if err != nil {
+		return nil, err
+	}
+
+	gameState := &domain.GameState{
+		Game:         game,
+		VariantState: *variantState,
+	}
+	if game.Phase == models.GamePhaseInProgress {
+		gameState.LegalMoves = rules.LegalMoves(state)
+	}
+	if metaBoarder, ok := rules.(MetaBoarder); ok {
+		gameState.MetaBoard = metaBoarder.MetaBoard(state)
+	}
+	return gameState, nil
 }
 
 func (g *gameEngineServiceImpl) validateGetGameState(room *models.Room, playerID uuid.UUID) error {
@@ -361,8 +565,14 @@ func (g *gameEngineServiceImpl) validateGetGameState(room *models.Room, playerID
 	return nil
 }
 
-func (g *gameEngineServiceImpl) PlayerMakeMove(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID, position int) error {
-	return withTransaction(ctx, g.db, func(tx *sql.Tx) error {
+// PlayerMakeMove places a mark of the player at the position, which names a
+// cell or a column as kind says and has to match the game. The requested
+// mark is only used by rule sets that let the players choose their mark.
+func (g *gameEngineServiceImpl) PlayerMakeMove(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID, kind MoveKind, position int, requestedMark string) error {
+	moveMade := false
+	gameCompleted := false
+	draw := false
+	err := repository.WithTransaction(ctx, g.db, func(tx *sql.Tx) error {
 		roomRepository := g.roomRepositoryFactory(tx)
 		room, err := roomRepository.Get(ctx, roomID, true)
 		if err != nil {
@@ -376,22 +586,47 @@ func (g *gameEngineServiceImpl) PlayerMakeMove(ctx context.Context, roomID uuid.
 				return err
 			}
 
-			err = g.validatePlayerMakeMove(game, playerID, position)
+			variantState, err := gameRepository.GetVariantState(ctx, game.ID)
 			if err != nil {
 				return err
 			}
 
-			mark := []byte(game.Host.Mark)[0]
+			err = g.validatePlayerMakeMove(game, playerID)
+			if err != nil {
+				return err
+			}
+
+			rules, err := gameRules(variantState.Variant)
+			if err != nil {
+				return err
+			}
+			if rules.MoveKind() != kind {
+				return models.NewValidationErrorf(MoveKindErrorMessage, kind, variantState.Variant)
+			}
+
+			state, err := rules.Decode(game.Board, variantState)
+			if err != nil {
+				return err
+			}
+
+			playerMark := []byte(game.Host.Mark)[0]
 			if playerID != room.Host.ID {
-				mark = []byte(game.Guest.Mark)[0]
+				playerMark = []byte(game.Guest.Mark)[0]
 			}
 
-			boardBytes := []byte(game.Board)
-			boardBytes[position-1] = mark
-			game.Board = string(boardBytes)
+			mark, err := rules.Apply(state, Move{Position: position, PlayerMark: playerMark, Mark: requestedMark})
+			if err != nil {
+				return err
+			}
+			game.Board = rules.Encode(state)
 
-			win := g.inWinState(game)
-			if win || !strings.Contains(game.Board, string(DefaultBoardTile)) {
+			err = gameRepository.AddMove(ctx, game.ID, &domain.Move{PlayerID: playerID, Position: position, Mark: string(mark)})
+			if err != nil {
+				return err
+			}
+
+			result := rules.Outcome(state)
+			if result != ResultNone {
 				playerRepository := g.playerRepositoryFactory(tx)
 				host, err := playerRepository.Get(ctx, room.Host.ID)
 				if err != nil {
@@ -409,18 +644,24 @@ func (g *gameEngineServiceImpl) PlayerMakeMove(ctx context.Context, roomID uuid.
 					winner = guest
 					loser = host
 				}
-				if win {
-					g.finalizeGameWithWin(game, winner, loser)
-				} else {
+				if result == ResultLoss {
+					winner, loser = loser, winner
+				}
+				if result == ResultDraw {
 					g.finalizeGameWithDraw(game, host, guest)
+				} else {
+					g.finalizeGameWithWin(game, winner, loser)
 				}
-
-				err = playerRepository.UpdateStats(ctx, host)
-				if err != nil {
-					return err
+				gameCompleted = true
+				draw = result == ResultDraw
+
+				// Only a line of the winner counts for the achievements that
+				// look at the marks of the winner.
+				winningMoves := 0
+				if result == ResultWin {
+					winningMoves = rules.MinWinningMoves(variantState.RuleSet)
 				}
-
-				err = playerRepository.UpdateStats(ctx, guest)
+				err = g.recordResult(ctx, tx, room.ID, gameRepository, game, host, guest, winningMoves)
 				if err != nil {
 					return err
 				}
@@ -430,19 +671,44 @@ func (g *gameEngineServiceImpl) PlayerMakeMove(ctx context.Context, roomID uuid.
 				} else {
 					game.CurrentPlayerID = game.Host.ID
 				}
+
+				if state.NextBoard != nil || variantState.NextBoard != nil {
+					err = gameRepository.SetNextBoard(ctx, game.ID, state.NextBoard)
+					if err != nil {
+						return err
+					}
+				}
 			}
 
 			err = gameRepository.Update(ctx, game)
 			if err != nil {
 				return err
 			}
+			moveMade = true
 		}
 
 		return nil
 	})
+	if err != nil {
+		logger.FromContext(ctx).Debug("move rejected", logger.String("room_id", roomID.String()), logger.Int("position", position), logger.Err(err))
+		return err
+	}
+
+	log := logger.FromContext(ctx).With(logger.String("room_id", roomID.String()))
+	if moveMade {
+		g.metrics.MoveMade()
+		log.Debug("move made", logger.Int("position", position))
+	}
+	if gameCompleted {
+		g.metrics.GameCompleted(draw)
+		log.Info("game completed", logger.Any("draw", draw))
+	}
+	return nil
 }
 
-func (g *gameEngineServiceImpl) validatePlayerMakeMove(game *models.Game, playerID uuid.UUID, position int) error {
+// validatePlayerMakeMove checks that the player may move; the rules of the
+// game validate the move itself.
+func (g *gameEngineServiceImpl) validatePlayerMakeMove(game *models.Game, playerID uuid.UUID) error {
 	if game.Host.ID != playerID && game.Guest.ID != playerID {
 		return models.NewValidationError(PlayerNotInRoomErrorMessage)
 	}
@@ -455,13 +721,6 @@ func (g *gameEngineServiceImpl) validatePlayerMakeMove(game *models.Game, player
 		return models.NewValidationError(PlayerNotInTurnErrorMessage)
 	}
 
-	if position < 1 || position > len(DefaultBoard) {
-		return models.NewValidationError(InvalidBoardPositionErrorMessage)
-	}
-	if game.Board[position-1] != DefaultBoardTile {
-		return models.NewValidationError(BoardPositionOcopiedErrorMessage)
-	}
-
 	return nil
 }
 
@@ -475,13 +734,137 @@ func (g *gameEngineServiceImpl) GetRanking(ctx context.Context, page int, pageSi
 	return players, pageSize, page, total, nil
 }
 
-func (g *gameEngineServiceImpl) createGame(ctx context.Context, gameRepository repository.GameRepository, room *models.Room) error {
+// GetRankingAfter reads the ranking by cursor instead of page number, so that
+// players moving in the ranking are neither skipped nor repeated.
+func (g *gameEngineServiceImpl) GetRankingAfter(ctx context.Context, cursor string, pageSize int) ([]*models.Player, string, error) {
+	if pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+
+	return g.playerRepositoryFactory(g.db).GetRankingAfter(ctx, cursor, pageSize)
+}
+
+// GetGameRanking ranks the players by their ranked games of the variant only.
+func (g *gameEngineServiceImpl) GetGameRanking(ctx context.Context, variant domain.GameVariant, page int, pageSize int) ([]*models.Player, int, int, int, error) {
+	if _, ok := LookupGame(variant); !ok {
+		return nil, 0, 0, 0, models.NewValidationErrorf(InvalidVariantErrorMessage, variant)
+	}
+
+	return g.playerRepositoryFactory(g.db).GetGameRanking(ctx, variant, page, pageSize)
+}
+
+// InvitePlayer creates a private room hosted by the player that only the
+// invited player can join.
+func (g *gameEngineServiceImpl) InvitePlayer(ctx context.Context, playerID uuid.UUID, inviteeID uuid.UUID, title string, description string) (uuid.UUID, error) {
+	if playerID == inviteeID {
+		return uuid.Nil, models.NewValidationError(InviteSelfErrorMessage)
+	}
+
+	room := &models.Room{
+		Host: models.RoomPlayer{
+			ID:       playerID,
+			Continue: true,
+		},
+		Title:       title,
+		Description: description,
+		Phase:       models.RoomPhaseOpen,
+	}
+
+	id, err := repository.WithTransactionT(ctx, g.db, func(tx *sql.Tx) (uuid.UUID, error) {
+		roomRepository := g.roomRepositoryFactory(tx)
+		err := g.validateCreateRoom(ctx, roomRepository, room, playerID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+
+		if _, err = g.playerRepositoryFactory(tx).GetProfile(ctx, inviteeID); err != nil {
+			return uuid.Nil, err
+		}
+
+		if err = g.validateNotBlocked(ctx, tx, playerID, inviteeID); err != nil {
+			return uuid.Nil, err
+		}
+
+		id, err := roomRepository.Create(ctx, room, domain.DefaultRoomSettings())
+		if err != nil {
+			return uuid.Nil, err
+		}
+
+		return id, g.invitationRepositoryFactory(tx).Create(ctx, id, inviteeID)
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	logger.FromContext(ctx).Info("player invited", logger.String("room_id", id.String()))
+	return id, nil
+}
+
+func (g *gameEngineServiceImpl) GetInvitations(ctx context.Context, playerID uuid.UUID) ([]*domain.Invitation, error) {
+	return g.invitationRepositoryFactory(g.db).GetByPlayer(ctx, playerID)
+}
+
+// DeclineInvitation closes the private room the player was invited to.
+func (g *gameEngineServiceImpl) DeclineInvitation(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) error {
+	err := repository.WithTransaction(ctx, g.db, func(tx *sql.Tx) error {
+		roomRepository := g.roomRepositoryFactory(tx)
+		room, err := roomRepository.Get(ctx, roomID, true)
+		if err != nil {
+			return err
+		}
+
+		invitation, err := g.invitationRepositoryFactory(tx).Get(ctx, roomID)
+		if err != nil {
+			return err
+		}
+		if invitation.PlayerID != playerID {
+			return models.NewNotFoundErrorf("invitation to room '%s' not exist", roomID.String())
+		}
+
+		if room.Guest != nil {
+			return models.NewValidationError(InvitationAcceptedErrorMessage)
+		}
+
+		return roomRepository.Delete(ctx, roomID)
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("invitation declined", logger.String("room_id", roomID.String()))
+	return nil
+}
+
+func (g *gameEngineServiceImpl) createGame(ctx context.Context, roomRepository repository.RoomRepository, gameRepository repository.GameRepository, room *models.Room) error {
+	settings, err := roomRepository.GetSettings(ctx, room.ID)
+	if err != nil {
+		return err
+	}
+
+	rules, err := gameRules(settings.Variant)
+	if err != nil {
+		return err
+	}
+
+	state, err := rules.NewState(settings.RuleSet)
+	if err != nil {
+		return err
+	}
 
-	game, err := g.initializeGame(ctx, gameRepository, room)
+	game, err := g.initializeGame(ctx, gameRepository, room, settings)
 	if err != nil {
 		return err
 	}
-	game.ID, err = gameRepository.Create(ctx, game)
+	game.Board = rules.Encode(state)
+	game.ID, err = gameRepository.Create(ctx, game, &domain.VariantState{
+		Variant:         settings.Variant,
+		RuleSet:         state.RuleSet,
+		NextBoard:       state.NextBoard,
+		MarkPolicy:      settings.MarkPolicy,
+		FirstMovePolicy: settings.FirstMovePolicy,
+		FirstPlayerID:   &game.CurrentPlayerID,
+		Ranked:          settings.Ranked,
+	})
 	if err != nil {
 		return err
 	}
@@ -492,114 +875,277 @@ func (g *gameEngineServiceImpl) createGame(ctx context.Context, gameRepository r
 	return nil
 }
 
-func (g *gameEngineServiceImpl) initializeGame(ctx context.Context, gameRepository repository.GameRepository, room *models.Room) (*models.Game, error) {
-	marks := []byte{XMark, OMark}
-	rand.Shuffle(len(marks), func(i, j int) {
-		marks[i], marks[j] = marks[j], marks[i]
-	})
-	playerIDs := []uuid.UUID{room.Host.ID, room.Guest.ID}
-
-	game := &models.Game{
-		Host:            models.GamePlayer{ID: room.Host.ID, Mark: string(marks[0])},
-		Guest:           models.GamePlayer{ID: room.Guest.ID, Mark: string(marks[1])},
-		CurrentPlayerID: playerIDs[rand.IntN(2)],
-		Board:           DefaultBoard,
-		Phase:           models.GamePhaseInProgress,
-	}
-
+// initializeGame assigns the marks and picks the player who starts by the
+// policies of the room; the rules of the game set up the board. Only a
+// rematch of the same pairing looks at the previous game.
+func (g *gameEngineServiceImpl) initializeGame(ctx context.Context, gameRepository repository.GameRepository, room *models.Room, settings *domain.RoomSettings) (*models.Game, error) {
+	var prevGame *models.Game
+	var prevFirstPlayerID *uuid.UUID
 	if room.GameID != nil {
-		prevGame, err := gameRepository.Get(ctx, *room.GameID)
+		game, err := gameRepository.Get(ctx, *room.GameID)
 		if err != nil {
 			return nil, err
 		}
 
-		if prevGame.Phase == models.GamePhaseInProgress {
+		if game.Phase == models.GamePhaseInProgress {
 			return nil, models.NewValidationError(GameInProgressErrorMessage)
 		}
 
-		if prevGame.Host.ID == room.Host.ID && prevGame.Guest.ID == room.Guest.ID {
-			game.Host.Mark = prevGame.Host.Mark
-			game.Guest.Mark = prevGame.Guest.Mark
+		if game.Host.ID == room.Host.ID && game.Guest.ID == room.Guest.ID {
+			prevGame = game
 
-			game.CurrentPlayerID = room.Host.ID
-			if prevGame.CurrentPlayerID == room.Host.ID {
-				game.CurrentPlayerID = room.Guest.ID
+			variantState, err := gameRepository.GetVariantState(ctx, game.ID)
+			if err != nil {
+				return nil, err
 			}
+			prevFirstPlayerID = variantState.FirstPlayerID
 		}
 	}
 
+	hostMark, guestMark := assignMarks(settings.MarkPolicy, prevGame)
+	game := &models.Game{
+		Host:  models.GamePlayer{ID: room.Host.ID, Mark: hostMark},
+		Guest: models.GamePlayer{ID: room.Guest.ID, Mark: guestMark},
+		Phase: models.GamePhaseInProgress,
+	}
+	game.CurrentPlayerID = firstPlayer(settings.FirstMovePolicy, game, prevGame, prevFirstPlayerID)
+
 	return game, nil
 }
 
-func (g *gameEngineServiceImpl) inWinState(game *models.Game) bool {
+// assignMarks returns the marks of the host and the guest. Rematches keep
+// the marks of the previous game unless the policy fixes them.
+func assignMarks(policy domain.MarkPolicy, prevGame *models.Game) (string, string) {
+	switch {
+	case policy == domain.MarkPolicyHostX:
+		return string(XMark), string(OMark)
+	case prevGame != nil:
+		return prevGame.Host.Mark, prevGame.Guest.Mark
+	case rand.IntN(2) == 0:
+		return string(XMark), string(OMark)
+	default:
+		return string(OMark), string(XMark)
+	}
+}
 
-	if (game.Board[0] != DefaultBoardTile && game.Board[0] == game.Board[1] && game.Board[0] == game.Board[2]) ||
-		(game.Board[3] != DefaultBoardTile && game.Board[3] == game.Board[4] && game.Board[3] == game.Board[5]) ||
-		(game.Board[6] != DefaultBoardTile && game.Board[6] == game.Board[7] && game.Board[6] == game.Board[8]) ||
+// firstPlayer returns the player who moves first in the game. Policies that
+// depend on the previous game fall back to a coin flip for a new pairing.
+func firstPlayer(policy domain.FirstMovePolicy, game *models.Game, prevGame *models.Game, prevFirstPlayerID *uuid.UUID) uuid.UUID {
+	other := func(playerID uuid.UUID) uuid.UUID {
+		if playerID == game.Host.ID {
+			return game.Guest.ID
+		}
+		return game.Host.ID
+	}
 
-		(game.Board[0] != DefaultBoardTile && game.Board[0] == game.Board[3] && game.Board[0] == game.Board[6]) ||
-		(game.Board[1] != DefaultBoardTile && game.Board[1] == game.Board[4] && game.Board[1] == game.Board[7]) ||
-		(game.Board[2] != DefaultBoardTile && game.Board[2] == game.Board[5] && game.Board[2] == game.Board[8]) ||
+	switch policy {
+	case domain.FirstMovePolicyXFirst:
+		if game.Host.Mark == string(XMark) {
+			return game.Host.ID
+		}
+		return game.Guest.ID
+	case domain.FirstMovePolicyCoinFlip:
+		prevGame = nil
+	case domain.FirstMovePolicyLoserStarts:
+		if prevGame != nil && prevGame.WinnerID != nil {
+			return other(*prevGame.WinnerID)
+		}
+	}
 
-		(game.Board[0] != DefaultBoardTile && game.Board[0] == game.Board[4] && game.Board[0] == game.Board[8]) ||
-		(game.Board[2] != DefaultBoardTile && game.Board[2] == game.Board[4] && game.Board[2] == game.Board[6]) {
-		return true
+	if prevGame == nil {
+		return []uuid.UUID{game.Host.ID, game.Guest.ID}[rand.IntN(2)]
 	}
 
-	return false
+	// Games started before the first player was recorded alternate on the
+	// player who was in turn when they ended.
+	if prevFirstPlayerID != nil {
+		return other(*prevFirstPlayerID)
+	}
+	return other(prevGame.CurrentPlayerID)
 }
 
-func (g *gameEngineServiceImpl) finalizeGameWithWin(game *models.Game, winner *models.Player, loser *models.Player) {
-	game.Phase = models.GamePhaseCompleted
-	game.WinnerID = &winner.ID
-	winner.Stats.Wins++
-	loser.Stats.Losses++
+// recordResult stores the result of the completed game in the stats, the
+// ratings, the season stats and the achievements of both players. Games in
+// unranked rooms are not recorded.
+func (g *gameEngineServiceImpl) recordResult(ctx context.Context, tx *sql.Tx, roomID uuid.UUID, gameRepository repository.GameRepository, game *models.Game, host *models.Player, guest *models.Player, winningMoves int) error {
+	settings, err := g.roomRepositoryFactory(tx).GetSettings(ctx, roomID)
+	if err != nil {
+		return err
+	}
+
+	if !settings.Ranked {
+		return nil
+	}
+
+	playerRepository := g.playerRepositoryFactory(tx)
+	err = playerRepository.UpdateStats(ctx, host)
+	if err != nil {
+		return err
+	}
+
+	err = playerRepository.UpdateStats(ctx, guest)
+	if err != nil {
+		return err
+	}
+
+	err = g.updateGameStats(ctx, playerRepository, settings.Variant, game, host, guest)
+	if err != nil {
+		return err
+	}
+
+	err = g.updateRatings(ctx, playerRepository, gameRepository, game, host, guest)
+	if err != nil {
+		return err
+	}
+
+	err = g.updateSeasonStats(ctx, g.seasonRepositoryFactory(tx), gameRepository, game, host, guest)
+	if err != nil {
+		return err
+	}
+
+	return g.updateAchievements(ctx, g.achievementRepositoryFactory(tx), gameRepository, game, host, guest, winningMoves)
 }
 
-func (g *gameEngineServiceImpl) finalizeGameWithDraw(game *models.Game, host *models.Player, guest *models.Player) {
-	game.Phase = models.GamePhaseCompleted
-	host.Stats.Draws++
-	guest.Stats.Draws++
+// updateGameStats counts the completed game in the stats both players have
+// for the game of the room.
+func (g *gameEngineServiceImpl) updateGameStats(ctx context.Context, playerRepository repository.PlayerRepository, variant domain.GameVariant, game *models.Game, host *models.Player, guest *models.Player) error {
+	hostResult, guestResult := domain.GameResultDraw, domain.GameResultDraw
+	if game.WinnerID != nil {
+		hostResult, guestResult = domain.GameResultLoss, domain.GameResultWin
+		if *game.WinnerID == host.ID {
+			hostResult, guestResult = domain.GameResultWin, domain.GameResultLoss
+		}
+	}
+
+	if err := playerRepository.UpdateGameStats(ctx, host.ID, variant, hostResult); err != nil {
+		return err
+	}
+
+	return playerRepository.UpdateGameStats(ctx, guest.ID, variant, guestResult)
 }
 
-func withTransactionT[T any](ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) (T, error)) (result T, err error) {
-	tx, err := db.BeginTx(ctx, nil)
+// updateRatings applies the Elo rating changes of the completed game to both
+// players and records them on the game so that they can be reverted.
+func (g *gameEngineServiceImpl) updateRatings(ctx context.Context, playerRepository repository.PlayerRepository, gameRepository repository.GameRepository, game *models.Game, host *models.Player, guest *models.Player) error {
+	hostRating, err := playerRepository.GetRating(ctx, host.ID)
 	if err != nil {
-		return result, err
+		return err
 	}
 
-	defer func() {
-		if p := recover(); p != nil {
-			_ = tx.Rollback()
-			panic(p)
-		} else if err != nil {
-			_ = tx.Rollback()
-		} else {
-			err = tx.Commit()
+	guestRating, err := playerRepository.GetRating(ctx, guest.ID)
+	if err != nil {
+		return err
+	}
+
+	hostScore := 0.5
+	if game.WinnerID != nil {
+		hostScore = 0
+		if *game.WinnerID == host.ID {
+			hostScore = 1
 		}
-	}()
+	}
 
-	result, err = fn(tx)
-	return result, err
+	hostDelta, guestDelta := EloDeltas(hostRating, guestRating, hostScore)
+	if err = playerRepository.AdjustRating(ctx, host.ID, hostDelta); err != nil {
+		return err
+	}
+
+	if err = playerRepository.AdjustRating(ctx, guest.ID, guestDelta); err != nil {
+		return err
+	}
+
+	return gameRepository.SetRatingDeltas(ctx, game.ID, hostDelta, guestDelta)
 }
 
-func withTransaction(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
-	tx, err := db.BeginTx(ctx, nil)
+// updateSeasonStats records the completed game in the running season, if
+// any, with the rating changes computed from the season ratings.
+func (g *gameEngineServiceImpl) updateSeasonStats(ctx context.Context, seasonRepository repository.SeasonRepository, gameRepository repository.GameRepository, game *models.Game, host *models.Player, guest *models.Player) error {
+	season, err := seasonRepository.GetCurrent(ctx, time.Now())
 	if err != nil {
+		if models.IsNotFoundError(err) {
+			return nil
+		}
 		return err
 	}
 
-	defer func() {
-		if p := recover(); p != nil {
-			_ = tx.Rollback()
-			panic(p)
-		} else if err != nil {
-			_ = tx.Rollback()
-		} else {
-			err = tx.Commit()
+	hostRating, err := seasonRepository.GetRating(ctx, season.ID, host.ID)
+	if err != nil {
+		return err
+	}
+
+	guestRating, err := seasonRepository.GetRating(ctx, season.ID, guest.ID)
+	if err != nil {
+		return err
+	}
+
+	hostScore, hostResult, guestResult := 0.5, domain.GameResultDraw, domain.GameResultDraw
+	if game.WinnerID != nil {
+		hostScore, hostResult, guestResult = 0, domain.GameResultLoss, domain.GameResultWin
+		if *game.WinnerID == host.ID {
+			hostScore, hostResult, guestResult = 1, domain.GameResultWin, domain.GameResultLoss
+		}
+	}
+
+	hostDelta, guestDelta := EloDeltas(hostRating, guestRating, hostScore)
+	if err = seasonRepository.RecordResult(ctx, season.ID, host.ID, hostResult, hostDelta); err != nil {
+		return err
+	}
+
+	if err = seasonRepository.RecordResult(ctx, season.ID, guest.ID, guestResult, guestDelta); err != nil {
+		return err
+	}
+
+	return gameRepository.SetSeasonRatingDeltas(ctx, game.ID, season.ID, hostDelta, guestDelta)
+}
+
+// updateAchievements tracks the win streaks of both players and unlocks the
+// achievements the winner earned with the completed game. winningMoves is 0
+// for draws, forfeits and games not won with a line of the winner.
+func (g *gameEngineServiceImpl) updateAchievements(ctx context.Context, achievementRepository repository.AchievementRepository, gameRepository repository.GameRepository, game *models.Game, host *models.Player, guest *models.Player, winningMoves int) error {
+	for _, player := range []*models.Player{host, guest} {
+		won := game.WinnerID != nil && *game.WinnerID == player.ID
+		streak, err := achievementRepository.RecordStreak(ctx, player.ID, won)
+		if err != nil {
+			return err
+		}
+
+		if !won {
+			continue
 		}
-	}()
 
-	err = fn(tx)
-	return err
+		// The completed game is not stored yet, so these are the games
+		// before it.
+		recent, err := gameRepository.GetRecentByPlayer(ctx, player.ID, RoundRobinOpponents-1)
+		if err != nil {
+			return err
+		}
+
+		for _, code := range EarnedAchievements(game, player.ID, winningMoves, streak, recent) {
+			unlocked, err := achievementRepository.Unlock(ctx, player.ID, code)
+			if err != nil {
+				return err
+			}
+
+			if unlocked {
+				logger.FromContext(ctx).Info("achievement unlocked",
+					logger.String("player_id", player.ID.String()),
+					logger.String("achievement", string(code)))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (g *gameEngineServiceImpl) finalizeGameWithWin(game *models.Game, winner *models.Player, loser *models.Player) {
+	game.Phase = models.GamePhaseCompleted
+	game.WinnerID = &winner.ID
+	winner.Stats.Wins++
+	loser.Stats.Losses++
+}
+
+func (g *gameEngineServiceImpl) finalizeGameWithDraw(game *models.Game, host *models.Player, guest *models.Player) {
+	game.Phase = models.GamePhaseCompleted
+	host.Stats.Draws++
+	guest.Stats.Draws++
 }
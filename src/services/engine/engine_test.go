@@ -3,6 +3,7 @@ package engine_test
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"strings"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -10,22 +11,30 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/apperrors"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
 	"github.com/plamen-v/tic-tac-toe/src/repository"
 	"github.com/plamen-v/tic-tac-toe/src/repository/mocks"
 	"github.com/plamen-v/tic-tac-toe/src/services/engine"
+	metricsmocks "github.com/plamen-v/tic-tac-toe/src/services/metrics/mocks"
 	tmock "github.com/stretchr/testify/mock"
 )
 
 var _ = Describe("GameEngine", func() {
 	var (
-		db                   *sql.DB
-		mock                 sqlmock.Sqlmock
-		ctx                  context.Context
-		mockRoomRepository   *mocks.MockRoomRepository
-		mockGameRepository   *mocks.MockGameRepository
-		mockPlayerRepository *mocks.MockPlayerRepository
-		gameEngineService    engine.GameEngineService
-		err                  error
+		db                        *sql.DB
+		mock                      sqlmock.Sqlmock
+		ctx                       context.Context
+		mockRoomRepository        *mocks.MockRoomRepository
+		mockGameRepository        *mocks.MockGameRepository
+		mockPlayerRepository      *mocks.MockPlayerRepository
+		mockBlockRepository       *mocks.MockBlockRepository
+		mockInvitationRepository  *mocks.MockInvitationRepository
+		mockSeasonRepository      *mocks.MockSeasonRepository
+		mockAchievementRepository *mocks.MockAchievementRepository
+		mockMetricsService        *metricsmocks.MockMetricsService
+		gameEngineService         engine.GameEngineService
+		err                       error
 	)
 
 	BeforeEach(func() {
@@ -33,10 +42,51 @@ var _ = Describe("GameEngine", func() {
 		db, mock, err = sqlmock.New()
 		Expect(err).ToNot(HaveOccurred())
 		mockRoomRepository = new(mocks.MockRoomRepository)
+		mockRoomRepository.
+			On("GetSettings", tmock.Anything, tmock.Anything).
+			Return(domain.DefaultRoomSettings(), nil).
+			Maybe()
 		mockGameRepository = new(mocks.MockGameRepository)
+		mockGameRepository.
+			On("GetRecentByPlayer", tmock.Anything, tmock.Anything, tmock.Anything).
+			Return([]*domain.GameSummary{}, nil).
+			Maybe()
+		mockGameRepository.
+			On("AddMove", tmock.Anything, tmock.Anything, tmock.Anything).
+			Return(nil).
+			Maybe()
+		mockGameRepository.
+			On("GetVariantState", tmock.Anything, tmock.Anything).
+			Return(&domain.VariantState{Variant: domain.GameVariantClassic, RuleSet: domain.RuleSetStandard}, nil).
+			Maybe()
 		mockPlayerRepository = new(mocks.MockPlayerRepository)
+		mockPlayerRepository.
+			On("UpdateGameStats", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything).
+			Return(nil).
+			Maybe()
+		mockBlockRepository = new(mocks.MockBlockRepository)
+		mockInvitationRepository = new(mocks.MockInvitationRepository)
+		mockSeasonRepository = new(mocks.MockSeasonRepository)
+		mockAchievementRepository = new(mocks.MockAchievementRepository)
+		mockAchievementRepository.
+			On("RecordStreak", tmock.Anything, tmock.Anything, tmock.Anything).
+			Return(&domain.Streak{}, nil).
+			Maybe()
+		mockAchievementRepository.
+			On("Unlock", tmock.Anything, tmock.Anything, tmock.Anything).
+			Return(true, nil).
+			Maybe()
+		mockSeasonRepository.
+			On("GetCurrent", tmock.Anything, tmock.Anything).
+			Return(nil, models.NewNotFoundError("no season is running")).
+			Maybe()
+		mockMetricsService = new(metricsmocks.MockMetricsService)
+		mockMetricsService.On("GameStarted").Maybe()
+		mockMetricsService.On("GameCompleted", tmock.Anything).Maybe()
+		mockMetricsService.On("MoveMade").Maybe()
 		gameEngineService = engine.NewGameEngineService(
 			db,
+			mockMetricsService,
 			func(db repository.Querier) repository.PlayerRepository {
 				return mockPlayerRepository
 			},
@@ -46,6 +96,18 @@ var _ = Describe("GameEngine", func() {
 			func(db repository.Querier) repository.RoomRepository {
 				return mockRoomRepository
 			},
+			func(db repository.Querier) repository.BlockRepository {
+				return mockBlockRepository
+			},
+			func(db repository.Querier) repository.InvitationRepository {
+				return mockInvitationRepository
+			},
+			func(db repository.Querier) repository.SeasonRepository {
+				return mockSeasonRepository
+			},
+			func(db repository.Querier) repository.AchievementRepository {
+				return mockAchievementRepository
+			},
 		)
 
 	})
@@ -87,10 +149,11 @@ var _ = Describe("GameEngine", func() {
 				},
 			}
 
+			filter := &domain.RoomFilter{Title: "title", Sort: domain.RoomSortRating}
 			mockRoomRepository.
-				On("GetList", ctx, models.RoomPhaseOpen, tmock.Anything, tmock.Anything).
+				On("GetList", ctx, models.RoomPhaseOpen, filter, tmock.Anything, tmock.Anything).
 				Return(expectedRooms, 1, 1, 1, nil)
-			rooms, _, _, _, err := gameEngineService.GetOpenRooms(ctx, 1, 1)
+			rooms, _, _, _, err := gameEngineService.GetOpenRooms(ctx, filter, 1, 1)
 
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(rooms)).To(Equal(len(expectedRooms)))
@@ -98,6 +161,44 @@ var _ = Describe("GameEngine", func() {
 				Expect(rooms[i]).To(Equal(expectedRooms[i]))
 			}
 		})
+
+		It("should return the rooms after the cursor", func() {
+			expectedRooms := []*models.Room{{ID: uuid.Must(uuid.NewV4()), Phase: models.RoomPhaseOpen}}
+			filter := &domain.RoomFilter{Sort: domain.RoomSortNewest}
+			mockRoomRepository.
+				On("GetListAfter", ctx, models.RoomPhaseOpen, filter, "", 5).
+				Return(expectedRooms, "next", nil)
+
+			rooms, next, err := gameEngineService.GetOpenRoomsAfter(ctx, filter, "", 5)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rooms).To(Equal(expectedRooms))
+			Expect(next).To(Equal("next"))
+		})
+
+		It("should return error for an unknown sort", func() {
+			_, _, _, _, err := gameEngineService.GetOpenRooms(ctx, &domain.RoomFilter{Sort: "oldest"}, 1, 1)
+
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+			mockRoomRepository.AssertNotCalled(GinkgoT(), "GetList", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything)
+		})
+
+		It("should return error for an empty rating range", func() {
+			minRating, maxRating := 1500, 1400
+			_, _, _, _, err := gameEngineService.GetOpenRooms(ctx, &domain.RoomFilter{MinRating: &minRating, MaxRating: &maxRating}, 1, 1)
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(engine.InvalidRatingRangeErrorMessage))
+		})
+
+		It("should return error for an unknown time control", func() {
+			_, _, _, _, err := gameEngineService.GetOpenRooms(ctx, &domain.RoomFilter{TimeControl: "bullet"}, 1, 1)
+
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+			Expect(err.Error()).To(Equal(fmt.Sprintf(engine.InvalidTimeControlErrorMessage, "bullet")))
+			mockRoomRepository.AssertNotCalled(GinkgoT(), "GetList", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything)
+		})
 	})
 
 	Context("GetGameState", func() {
@@ -105,7 +206,8 @@ var _ = Describe("GameEngine", func() {
 			gameID, err := uuid.NewV4()
 			Expect(err).To(BeNil())
 			expectedGame := &models.Game{
-				ID: gameID,
+				ID:    gameID,
+				Board: engine.DefaultBoard,
 			}
 
 			roomID, err := uuid.NewV4()
@@ -131,14 +233,16 @@ var _ = Describe("GameEngine", func() {
 			game, err := gameEngineService.GetGameState(ctx, roomID, playerID)
 
 			Expect(err).ToNot(HaveOccurred())
-			Expect(*game).To(Equal(*expectedGame))
+			Expect(*game.Game).To(Equal(*expectedGame))
+			Expect(game.LegalMoves).To(Equal([]int{1, 2, 3, 4, 5, 6, 7, 8, 9}))
 		})
 
 		It("should returns the game status if the player is guest in a existing room that has a game", func() {
 			gameID, err := uuid.NewV4()
 			Expect(err).To(BeNil())
 			expectedGame := &models.Game{
-				ID: gameID,
+				ID:    gameID,
+				Board: engine.DefaultBoard,
 			}
 
 			roomID, err := uuid.NewV4()
@@ -164,7 +268,7 @@ var _ = Describe("GameEngine", func() {
 			game, err := gameEngineService.GetGameState(ctx, roomID, playerID)
 
 			Expect(err).ToNot(HaveOccurred())
-			Expect(*game).To(Equal(*expectedGame))
+			Expect(*game.Game).To(Equal(*expectedGame))
 		})
 
 		It("should returns an error if player is not in the room", func() {
@@ -222,6 +326,39 @@ var _ = Describe("GameEngine", func() {
 				Expect(ranking[i]).To(Equal(expectedRanking[i]))
 			}
 		})
+
+		It("should return the ranking after the cursor", func() {
+			expectedRanking := []*models.Player{{ID: uuid.Must(uuid.NewV4())}}
+			mockPlayerRepository.
+				On("GetRankingAfter", ctx, "cursor", engine.DefaultPageSize).
+				Return(expectedRanking, "next", nil)
+
+			ranking, next, err := gameEngineService.GetRankingAfter(ctx, "cursor", 0)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ranking).To(Equal(expectedRanking))
+			Expect(next).To(Equal("next"))
+		})
+
+		It("should return the ranking of a variant", func() {
+			expectedRanking := []*models.Player{{ID: uuid.Must(uuid.NewV4())}}
+			mockPlayerRepository.
+				On("GetGameRanking", ctx, domain.GameVariantConnectFour, 1, 10).
+				Return(expectedRanking, 10, 1, 1, nil)
+
+			ranking, _, _, total, err := gameEngineService.GetGameRanking(ctx, domain.GameVariantConnectFour, 1, 10)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ranking).To(Equal(expectedRanking))
+			Expect(total).To(Equal(1))
+		})
+
+		It("should return error for the ranking of an unknown variant", func() {
+			_, _, _, _, err := gameEngineService.GetGameRanking(ctx, "chess", 1, 10)
+
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+			mockPlayerRepository.AssertNotCalled(GinkgoT(), "GetGameRanking", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything)
+		})
 	})
 
 	Context("CreateRoom", func() {
@@ -245,15 +382,41 @@ var _ = Describe("GameEngine", func() {
 				Return(nil, models.NewNotFoundError("error"))
 
 			mockRoomRepository.
-				On("Create", ctx, tmock.Anything).
+				On("Create", ctx, tmock.Anything, domain.DefaultRoomSettings()).
 				Return(expectedRoom.ID, nil)
 
-			resultRoomID, err := gameEngineService.CreateRoom(ctx, playerID, expectedRoom.Title, expectedRoom.Description)
+			resultRoomID, err := gameEngineService.CreateRoom(ctx, playerID, expectedRoom.Title, expectedRoom.Description, nil)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(resultRoomID).To(Equal(expectedRoom.ID))
 
 		})
 
+		It("should returns error if the first move policy is unknown", func() {
+			playerID, err := uuid.NewV4()
+			Expect(err).To(BeNil())
+			settings := domain.DefaultRoomSettings()
+			settings.FirstMovePolicy = "winner_starts"
+
+			_, err = gameEngineService.CreateRoom(ctx, playerID, "title", "description", settings)
+
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+			Expect(err.Error()).To(Equal(fmt.Sprintf(engine.InvalidFirstMovePolicyErrorMessage, settings.FirstMovePolicy)))
+			mockRoomRepository.AssertNotCalled(GinkgoT(), "Create", tmock.Anything, tmock.Anything, tmock.Anything)
+		})
+
+		It("should returns error if the time control is unknown", func() {
+			playerID, err := uuid.NewV4()
+			Expect(err).To(BeNil())
+			settings := domain.DefaultRoomSettings()
+			settings.TimeControl = "bullet"
+
+			_, err = gameEngineService.CreateRoom(ctx, playerID, "title", "description", settings)
+
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+			Expect(err.Error()).To(Equal(fmt.Sprintf(engine.InvalidTimeControlErrorMessage, settings.TimeControl)))
+			mockRoomRepository.AssertNotCalled(GinkgoT(), "Create", tmock.Anything, tmock.Anything, tmock.Anything)
+		})
+
 		It("should returns error if player is in other room", func() {
 			roomID, err := uuid.NewV4()
 			Expect(err).To(BeNil())
@@ -279,7 +442,7 @@ var _ = Describe("GameEngine", func() {
 				On("GetByPlayerID", ctx, playerID).
 				Return(playerRoom, nil)
 
-			_, err = gameEngineService.CreateRoom(ctx, playerID, expectedRoom.Title, expectedRoom.Description)
+			_, err = gameEngineService.CreateRoom(ctx, playerID, expectedRoom.Title, expectedRoom.Description, nil)
 
 			expectedErrorMessage := engine.PlayerPartOfOtherRoomErrorMessage
 			Expect(err).To(HaveOccurred())
@@ -305,7 +468,7 @@ var _ = Describe("GameEngine", func() {
 				On("GetByPlayerID", ctx, playerID).
 				Return(nil, models.NewNotFoundError("error"))
 
-			_, err = gameEngineService.CreateRoom(ctx, playerID, expectedRoom.Title, expectedRoom.Description)
+			_, err = gameEngineService.CreateRoom(ctx, playerID, expectedRoom.Title, expectedRoom.Description, nil)
 
 			expectedErrorMessage := engine.TitleRequiredErrorMessage
 			Expect(err).To(HaveOccurred())
@@ -331,7 +494,7 @@ var _ = Describe("GameEngine", func() {
 				On("GetByPlayerID", ctx, playerID).
 				Return(nil, models.NewNotFoundError("error"))
 
-			_, err = gameEngineService.CreateRoom(ctx, playerID, expectedRoom.Title, expectedRoom.Description)
+			_, err = gameEngineService.CreateRoom(ctx, playerID, expectedRoom.Title, expectedRoom.Description, nil)
 
 			expectedErrorMessage := engine.TitleTooLongErrorMessage
 			Expect(err).To(HaveOccurred())
@@ -358,7 +521,7 @@ var _ = Describe("GameEngine", func() {
 				On("GetByPlayerID", ctx, playerID).
 				Return(nil, models.NewNotFoundError("error"))
 
-			_, err = gameEngineService.CreateRoom(ctx, playerID, expectedRoom.Title, expectedRoom.Description)
+			_, err = gameEngineService.CreateRoom(ctx, playerID, expectedRoom.Title, expectedRoom.Description, nil)
 
 			expectedErrorMessage := engine.DescriptionTooLongErrorMessage
 			Expect(err).To(HaveOccurred())
@@ -388,6 +551,14 @@ var _ = Describe("GameEngine", func() {
 				On("GetByPlayerID", ctx, playerID).
 				Return(nil, models.NewNotFoundError("not found"))
 
+			mockInvitationRepository.
+				On("Get", ctx, roomID).
+				Return(nil, models.NewNotFoundError("not found"))
+
+			mockBlockRepository.
+				On("Exists", ctx, room.Host.ID, playerID).
+				Return(false, nil)
+
 			mockRoomRepository.
 				On("Update", ctx, room).
 				Return(nil)
@@ -396,7 +567,12 @@ var _ = Describe("GameEngine", func() {
 			Expect(err).To(BeNil())
 
 			mockGameRepository.
-				On("Create", ctx, tmock.Anything).Return(gameID, nil)
+				On("Create", ctx, tmock.Anything, tmock.MatchedBy(func(state *domain.VariantState) bool {
+					return state.Variant == domain.GameVariantClassic &&
+						state.RuleSet == domain.RuleSetStandard &&
+						state.FirstMovePolicy == domain.FirstMovePolicyAlternate &&
+						state.FirstPlayerID != nil
+				})).Return(gameID, nil)
 
 			err = gameEngineService.PlayerJoinRoom(ctx, roomID, playerID)
 
@@ -513,6 +689,189 @@ var _ = Describe("GameEngine", func() {
 			mockGameRepository.AssertExpectations(GinkgoT())
 		})
 
+		It("should return error if the room is private to another player", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+
+			playerID := uuid.Must(uuid.NewV4())
+			roomID := uuid.Must(uuid.NewV4())
+			room := &models.Room{
+				ID:    roomID,
+				Host:  models.RoomPlayer{ID: uuid.Must(uuid.NewV4())},
+				Phase: models.RoomPhaseOpen,
+			}
+			mockRoomRepository.
+				On("Get", ctx, roomID, true).
+				Return(room, nil)
+
+			mockRoomRepository.
+				On("GetByPlayerID", ctx, playerID).
+				Return(nil, models.NewNotFoundError("not found"))
+
+			mockInvitationRepository.
+				On("Get", ctx, roomID).
+				Return(&domain.Invitation{RoomID: roomID, PlayerID: uuid.Must(uuid.NewV4())}, nil)
+
+			err = gameEngineService.PlayerJoinRoom(ctx, roomID, playerID)
+
+			Expect(err).To(BeAssignableToTypeOf(&apperrors.ForbiddenError{}))
+			Expect(err.Error()).To(Equal(engine.PrivateRoomErrorMessage))
+			mockGameRepository.AssertNotCalled(GinkgoT(), "Create", tmock.Anything, tmock.Anything)
+		})
+
+		It("should return error if the host and the player blocked each other", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+
+			playerID := uuid.Must(uuid.NewV4())
+			roomID := uuid.Must(uuid.NewV4())
+			room := &models.Room{
+				ID:    roomID,
+				Host:  models.RoomPlayer{ID: uuid.Must(uuid.NewV4())},
+				Phase: models.RoomPhaseOpen,
+			}
+			mockRoomRepository.
+				On("Get", ctx, roomID, true).
+				Return(room, nil)
+
+			mockRoomRepository.
+				On("GetByPlayerID", ctx, playerID).
+				Return(nil, models.NewNotFoundError("not found"))
+
+			mockInvitationRepository.
+				On("Get", ctx, roomID).
+				Return(nil, models.NewNotFoundError("not found"))
+
+			mockBlockRepository.
+				On("Exists", ctx, room.Host.ID, playerID).
+				Return(true, nil)
+
+			err = gameEngineService.PlayerJoinRoom(ctx, roomID, playerID)
+
+			Expect(err).To(BeAssignableToTypeOf(&apperrors.ForbiddenError{}))
+			Expect(err.Error()).To(Equal(engine.PlayerBlockedErrorMessage))
+			mockGameRepository.AssertNotCalled(GinkgoT(), "Create", tmock.Anything, tmock.Anything)
+		})
+	})
+
+	Context("InvitePlayer", func() {
+		var (
+			playerID  uuid.UUID
+			inviteeID uuid.UUID
+		)
+
+		BeforeEach(func() {
+			playerID = uuid.Must(uuid.NewV4())
+			inviteeID = uuid.Must(uuid.NewV4())
+		})
+
+		It("should create a private room for the invited player", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+
+			roomID := uuid.Must(uuid.NewV4())
+			mockRoomRepository.
+				On("GetByPlayerID", ctx, playerID).
+				Return(nil, models.NewNotFoundError("not found"))
+
+			mockPlayerRepository.
+				On("GetProfile", ctx, inviteeID).
+				Return(&domain.Profile{ID: inviteeID}, nil)
+
+			mockBlockRepository.
+				On("Exists", ctx, playerID, inviteeID).
+				Return(false, nil)
+
+			mockRoomRepository.
+				On("Create", ctx, tmock.Anything, tmock.Anything).
+				Return(roomID, nil)
+
+			mockInvitationRepository.
+				On("Create", ctx, roomID, inviteeID).
+				Return(nil)
+
+			id, err := gameEngineService.InvitePlayer(ctx, playerID, inviteeID, "title", "")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(id).To(Equal(roomID))
+			mockInvitationRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should return error if the players blocked each other", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+
+			mockRoomRepository.
+				On("GetByPlayerID", ctx, playerID).
+				Return(nil, models.NewNotFoundError("not found"))
+
+			mockPlayerRepository.
+				On("GetProfile", ctx, inviteeID).
+				Return(&domain.Profile{ID: inviteeID}, nil)
+
+			mockBlockRepository.
+				On("Exists", ctx, playerID, inviteeID).
+				Return(true, nil)
+
+			_, err := gameEngineService.InvitePlayer(ctx, playerID, inviteeID, "title", "")
+
+			Expect(err).To(BeAssignableToTypeOf(&apperrors.ForbiddenError{}))
+			mockRoomRepository.AssertNotCalled(GinkgoT(), "Create", tmock.Anything, tmock.Anything)
+		})
+
+		It("should return error if the player invites himself", func() {
+			_, err := gameEngineService.InvitePlayer(ctx, playerID, playerID, "title", "")
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(engine.InviteSelfErrorMessage))
+		})
+	})
+
+	Context("DeclineInvitation", func() {
+		It("should close the private room", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+
+			playerID := uuid.Must(uuid.NewV4())
+			roomID := uuid.Must(uuid.NewV4())
+			room := &models.Room{ID: roomID, Phase: models.RoomPhaseOpen}
+			mockRoomRepository.
+				On("Get", ctx, roomID, true).
+				Return(room, nil)
+
+			mockInvitationRepository.
+				On("Get", ctx, roomID).
+				Return(&domain.Invitation{RoomID: roomID, PlayerID: playerID}, nil)
+
+			mockRoomRepository.
+				On("Delete", ctx, roomID).
+				Return(nil)
+
+			err := gameEngineService.DeclineInvitation(ctx, roomID, playerID)
+
+			Expect(err).ToNot(HaveOccurred())
+			mockRoomRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should return not found for invitations of other players", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+
+			playerID := uuid.Must(uuid.NewV4())
+			roomID := uuid.Must(uuid.NewV4())
+			mockRoomRepository.
+				On("Get", ctx, roomID, true).
+				Return(&models.Room{ID: roomID}, nil)
+
+			mockInvitationRepository.
+				On("Get", ctx, roomID).
+				Return(&domain.Invitation{RoomID: roomID, PlayerID: uuid.Must(uuid.NewV4())}, nil)
+
+			err := gameEngineService.DeclineInvitation(ctx, roomID, playerID)
+
+			Expect(models.IsNotFoundError(err)).To(BeTrue())
+			mockRoomRepository.AssertNotCalled(GinkgoT(), "Delete", tmock.Anything, tmock.Anything)
+		})
 	})
 
 	Context("PlayerLeaveRoom", func() {
@@ -580,6 +939,18 @@ var _ = Describe("GameEngine", func() {
 				On("UpdateStats", ctx, tmock.Anything).
 				Return(nil)
 
+			mockPlayerRepository.
+				On("GetRating", ctx, tmock.Anything).
+				Return(domain.DefaultRating, nil)
+
+			mockPlayerRepository.
+				On("AdjustRating", ctx, tmock.Anything, tmock.Anything).
+				Return(nil)
+
+			mockGameRepository.
+				On("SetRatingDeltas", ctx, tmock.Anything, tmock.Anything, tmock.Anything).
+				Return(nil)
+
 			mockGameRepository.
 				On("Update", ctx, game).
 				Return(nil)
@@ -588,10 +959,15 @@ var _ = Describe("GameEngine", func() {
 				On("Update", ctx, room).
 				Return(nil)
 
+			mockInvitationRepository.
+				On("Delete", ctx, roomID).
+				Return(nil)
+
 			err = gameEngineService.PlayerLeaveRoom(ctx, roomID, playerID)
 
 			Expect(err).ToNot(HaveOccurred())
 			mockGameRepository.AssertExpectations(GinkgoT())
+			mockInvitationRepository.AssertExpectations(GinkgoT())
 		})
 
 		It("should return no error if room exist, game is in progress and player is in the room as guest", func() {
@@ -657,6 +1033,18 @@ var _ = Describe("GameEngine", func() {
 				On("UpdateStats", ctx, tmock.Anything).
 				Return(nil)
 
+			mockPlayerRepository.
+				On("GetRating", ctx, tmock.Anything).
+				Return(domain.DefaultRating, nil)
+
+			mockPlayerRepository.
+				On("AdjustRating", ctx, tmock.Anything, tmock.Anything).
+				Return(nil)
+
+			mockGameRepository.
+				On("SetRatingDeltas", ctx, tmock.Anything, tmock.Anything, tmock.Anything).
+				Return(nil)
+
 			mockGameRepository.
 				On("Update", ctx, game).
 				Return(nil)
@@ -746,6 +1134,7 @@ var _ = Describe("GameEngine", func() {
 
 			Expect(err).ToNot(HaveOccurred())
 			mockGameRepository.AssertExpectations(GinkgoT())
+			mockMetricsService.AssertNotCalled(GinkgoT(), "GameCompleted", tmock.Anything)
 		})
 	})
 
@@ -806,7 +1195,14 @@ var _ = Describe("GameEngine", func() {
 			expectedNewGameID, err := uuid.NewV4()
 			Expect(err).To(BeNil())
 			mockGameRepository.
-				On("Create", ctx, tmock.Anything).
+				On("Create", ctx, tmock.Anything, &domain.VariantState{
+					Variant:         domain.GameVariantClassic,
+					RuleSet:         domain.RuleSetStandard,
+					MarkPolicy:      domain.MarkPolicyRandom,
+					FirstMovePolicy: domain.FirstMovePolicyAlternate,
+					FirstPlayerID:   &guest.ID,
+					Ranked:          true,
+				}).
 				Return(expectedNewGameID, nil)
 
 			mockRoomRepository.
@@ -820,6 +1216,100 @@ var _ = Describe("GameEngine", func() {
 			mockGameRepository.AssertExpectations(GinkgoT())
 		})
 
+		Context("with start policies", func() {
+			var (
+				hostID   uuid.UUID
+				guestID  uuid.UUID
+				roomID   uuid.UUID
+				prevGame *models.Game
+				created  *models.Game
+				recorded *domain.VariantState
+			)
+
+			BeforeEach(func() {
+				mock.ExpectBegin()
+				mock.ExpectCommit()
+
+				hostID = uuid.Must(uuid.NewV4())
+				guestID = uuid.Must(uuid.NewV4())
+				roomID = uuid.Must(uuid.NewV4())
+				prevGame = &models.Game{
+					ID:              uuid.Must(uuid.NewV4()),
+					Phase:           models.GamePhaseCompleted,
+					Host:            models.GamePlayer{ID: hostID, Mark: string(engine.OMark)},
+					Guest:           models.GamePlayer{ID: guestID, Mark: string(engine.XMark)},
+					CurrentPlayerID: hostID,
+				}
+				room := &models.Room{
+					ID:     roomID,
+					Host:   models.RoomPlayer{ID: hostID},
+					Guest:  &models.RoomPlayer{ID: guestID, Continue: true},
+					GameID: &prevGame.ID,
+					Phase:  models.RoomPhaseFull,
+				}
+
+				mockRoomRepository.ExpectedCalls = nil
+				mockRoomRepository.On("Get", ctx, roomID, true).Return(room, nil)
+				mockRoomRepository.On("Update", ctx, room).Return(nil)
+				mockGameRepository.ExpectedCalls = nil
+				mockGameRepository.On("Get", ctx, prevGame.ID).Return(prevGame, nil)
+				mockGameRepository.
+					On("Create", ctx, tmock.Anything, tmock.Anything).
+					Run(func(args tmock.Arguments) {
+						created = args.Get(1).(*models.Game)
+						recorded = args.Get(2).(*domain.VariantState)
+					}).
+					Return(uuid.Must(uuid.NewV4()), nil)
+			})
+
+			createGame := func(markPolicy domain.MarkPolicy, firstMovePolicy domain.FirstMovePolicy, prevFirstPlayerID *uuid.UUID) {
+				settings := domain.DefaultRoomSettings()
+				settings.MarkPolicy = markPolicy
+				settings.FirstMovePolicy = firstMovePolicy
+				mockRoomRepository.On("GetSettings", ctx, roomID).Return(settings, nil)
+				mockGameRepository.
+					On("GetVariantState", ctx, prevGame.ID).
+					Return(&domain.VariantState{Variant: domain.GameVariantClassic, RuleSet: domain.RuleSetStandard, FirstPlayerID: prevFirstPlayerID}, nil)
+
+				_, err := gameEngineService.CreateGame(ctx, roomID, hostID)
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			It("should give X to the host and let X move first", func() {
+				createGame(domain.MarkPolicyHostX, domain.FirstMovePolicyXFirst, &hostID)
+
+				Expect(created.Host.Mark).To(Equal(string(engine.XMark)))
+				Expect(created.Guest.Mark).To(Equal(string(engine.OMark)))
+				Expect(created.CurrentPlayerID).To(Equal(hostID))
+				Expect(recorded.MarkPolicy).To(Equal(domain.MarkPolicyHostX))
+				Expect(recorded.FirstMovePolicy).To(Equal(domain.FirstMovePolicyXFirst))
+				Expect(recorded.FirstPlayerID).To(Equal(&hostID))
+			})
+
+			It("should let the loser of the previous game move first", func() {
+				prevGame.WinnerID = &hostID
+
+				createGame(domain.MarkPolicyRandom, domain.FirstMovePolicyLoserStarts, &hostID)
+
+				Expect(created.Host.Mark).To(Equal(prevGame.Host.Mark))
+				Expect(created.Guest.Mark).To(Equal(prevGame.Guest.Mark))
+				Expect(created.CurrentPlayerID).To(Equal(guestID))
+				Expect(recorded.FirstPlayerID).To(Equal(&guestID))
+			})
+
+			It("should alternate the recorded first player after a draw", func() {
+				createGame(domain.MarkPolicyRandom, domain.FirstMovePolicyLoserStarts, &guestID)
+
+				Expect(created.CurrentPlayerID).To(Equal(hostID))
+			})
+
+			It("should alternate on the player in turn for games without a recorded first player", func() {
+				createGame(domain.MarkPolicyRandom, domain.FirstMovePolicyAlternate, nil)
+
+				Expect(created.CurrentPlayerID).To(Equal(guestID))
+			})
+		})
+
 		It("should return error if previous game is in progress", func() {
 			mock.ExpectBegin()
 			mock.ExpectRollback()
@@ -1001,7 +1491,7 @@ var _ = Describe("GameEngine", func() {
 				Return(nil)
 
 			position := 3
-			err = gameEngineService.PlayerMakeMove(ctx, roomID, playerID, position)
+			err = gameEngineService.PlayerMakeMove(ctx, roomID, playerID, engine.MoveKindCell, position, "")
 
 			Expect(err).ToNot(HaveOccurred())
 			mockGameRepository.AssertExpectations(GinkgoT())
@@ -1078,15 +1568,243 @@ var _ = Describe("GameEngine", func() {
 				On("UpdateStats", ctx, tmock.Anything).
 				Return(nil)
 
+			mockPlayerRepository.
+				On("GetRating", ctx, tmock.Anything).
+				Return(domain.DefaultRating, nil)
+
+			mockPlayerRepository.
+				On("AdjustRating", ctx, tmock.Anything, tmock.Anything).
+				Return(nil)
+
+			mockGameRepository.
+				On("SetRatingDeltas", ctx, tmock.Anything, tmock.Anything, tmock.Anything).
+				Return(nil)
+
+			mockRoomRepository.
+				On("Update", ctx, room).
+				Return(nil)
+
+			position := 3
+			err = gameEngineService.PlayerMakeMove(ctx, roomID, playerID, engine.MoveKindCell, position, "")
+
+			Expect(err).ToNot(HaveOccurred())
+			mockGameRepository.AssertExpectations(GinkgoT())
+			mockMetricsService.AssertCalled(GinkgoT(), "MoveMade")
+			mockMetricsService.AssertCalled(GinkgoT(), "GameCompleted", false)
+			mockPlayerRepository.AssertCalled(GinkgoT(), "AdjustRating", ctx, guest.ID, 16)
+			mockPlayerRepository.AssertCalled(GinkgoT(), "AdjustRating", ctx, host.ID, -16)
+			mockGameRepository.AssertCalled(GinkgoT(), "SetRatingDeltas", ctx, gameID, -16, 16)
+			mockAchievementRepository.AssertCalled(GinkgoT(), "RecordStreak", ctx, host.ID, false)
+			mockAchievementRepository.AssertCalled(GinkgoT(), "RecordStreak", ctx, guest.ID, true)
+			mockAchievementRepository.AssertCalled(GinkgoT(), "Unlock", ctx, guest.ID, domain.AchievementFirstWin)
+			mockAchievementRepository.AssertNotCalled(GinkgoT(), "Unlock", ctx, host.ID, tmock.Anything)
+			mockGameRepository.AssertCalled(GinkgoT(), "AddMove", ctx, gameID, &domain.Move{PlayerID: playerID, Position: position, Mark: string(engine.OMark)})
+		})
+
+		It("should not record the result of a game in an unranked room", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+
+			hostID, err := uuid.NewV4()
+			Expect(err).To(BeNil())
+			host := &models.Player{
+				ID: hostID,
+			}
+
+			playerID, err := uuid.NewV4()
+			Expect(err).To(BeNil())
+			guest := &models.Player{
+				ID: playerID,
+			}
+
+			gameID, err := uuid.NewV4()
+			Expect(err).To(BeNil())
+			game := &models.Game{
+				ID:    gameID,
+				Phase: models.GamePhaseInProgress,
+				Host: models.GamePlayer{
+					ID:   host.ID,
+					Mark: string(engine.XMark),
+				},
+				Guest: models.GamePlayer{
+					ID:   guest.ID,
+					Mark: string(engine.OMark),
+				},
+				CurrentPlayerID: guest.ID,
+				Board:           "XX__O_O__",
+			}
+
+			roomID, err := uuid.NewV4()
+			Expect(err).To(BeNil())
+			room := &models.Room{
+				ID: roomID,
+				Host: models.RoomPlayer{
+					ID: host.ID,
+				},
+				Guest: &models.RoomPlayer{
+					ID: guest.ID,
+				},
+				GameID: &game.ID,
+				Phase:  models.RoomPhaseFull,
+			}
+
+			mockGameRepository.
+				On("Get", ctx, gameID).
+				Return(game, nil)
+
+			mockGameRepository.
+				On("Update", ctx, game).
+				Return(nil)
+
+			mockPlayerRepository.
+				On("Get", ctx, guest.ID).
+				Return(guest, nil)
+
+			mockPlayerRepository.
+				On("Get", ctx, host.ID).
+				Return(host, nil)
+
+			mockRoomRepository.ExpectedCalls = nil
+			mockRoomRepository.
+				On("Get", ctx, roomID, true).
+				Return(room, nil)
+
+			mockRoomRepository.
+				On("GetSettings", ctx, roomID).
+				Return(&domain.RoomSettings{Ranked: false}, nil)
+
+			mockRoomRepository.
+				On("Update", ctx, room).
+				Return(nil)
+
+			position := 3
+			err = gameEngineService.PlayerMakeMove(ctx, roomID, playerID, engine.MoveKindCell, position, "")
+
+			Expect(err).ToNot(HaveOccurred())
+			mockMetricsService.AssertCalled(GinkgoT(), "GameCompleted", false)
+			mockPlayerRepository.AssertNotCalled(GinkgoT(), "UpdateStats", tmock.Anything, tmock.Anything)
+			mockPlayerRepository.AssertNotCalled(GinkgoT(), "AdjustRating", tmock.Anything, tmock.Anything, tmock.Anything)
+			mockAchievementRepository.AssertNotCalled(GinkgoT(), "RecordStreak", tmock.Anything, tmock.Anything, tmock.Anything)
+		})
+
+		It("should record the completed game in the running season", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+
+			hostID, err := uuid.NewV4()
+			Expect(err).To(BeNil())
+			host := &models.Player{
+				ID: hostID,
+			}
+
+			playerID, err := uuid.NewV4()
+			Expect(err).To(BeNil())
+			guest := &models.Player{
+				ID: playerID,
+			}
+
+			gameID, err := uuid.NewV4()
+			Expect(err).To(BeNil())
+			game := &models.Game{
+				ID:    gameID,
+				Phase: models.GamePhaseInProgress,
+				Host: models.GamePlayer{
+					ID:   host.ID,
+					Mark: string(engine.XMark),
+				},
+				Guest: models.GamePlayer{
+					ID:   guest.ID,
+					Mark: string(engine.OMark),
+				},
+				CurrentPlayerID: guest.ID,
+				Board:           "XX__O_O__",
+			}
+
+			roomID, err := uuid.NewV4()
+			Expect(err).To(BeNil())
+			room := &models.Room{
+				ID: roomID,
+				Host: models.RoomPlayer{
+					ID: host.ID,
+				},
+				Guest: &models.RoomPlayer{
+					ID: guest.ID,
+				},
+				GameID: &game.ID,
+				Phase:  models.RoomPhaseFull,
+			}
+
+			mockRoomRepository.
+				On("Get", ctx, roomID, true).
+				Return(room, nil)
+
+			mockGameRepository.
+				On("Get", ctx, gameID).
+				Return(game, nil)
+
+			mockGameRepository.
+				On("Update", ctx, game).
+				Return(nil)
+
+			mockPlayerRepository.
+				On("Get", ctx, guest.ID).
+				Return(guest, nil)
+
+			mockPlayerRepository.
+				On("Get", ctx, host.ID).
+				Return(host, nil)
+
+			mockPlayerRepository.
+				On("UpdateStats", ctx, tmock.Anything).
+				Return(nil)
+
+			mockPlayerRepository.
+				On("GetRating", ctx, tmock.Anything).
+				Return(domain.DefaultRating, nil)
+
+			mockPlayerRepository.
+				On("AdjustRating", ctx, tmock.Anything, tmock.Anything).
+				Return(nil)
+
+			mockGameRepository.
+				On("SetRatingDeltas", ctx, tmock.Anything, tmock.Anything, tmock.Anything).
+				Return(nil)
+
+			seasonID, err := uuid.NewV4()
+			Expect(err).To(BeNil())
+			mockSeasonRepository.ExpectedCalls = nil
+			mockSeasonRepository.
+				On("GetCurrent", ctx, tmock.Anything).
+				Return(&domain.Season{ID: seasonID}, nil)
+
+			mockSeasonRepository.
+				On("GetRating", ctx, seasonID, host.ID).
+				Return(1400, nil)
+
+			mockSeasonRepository.
+				On("GetRating", ctx, seasonID, guest.ID).
+				Return(1200, nil)
+
+			mockSeasonRepository.
+				On("RecordResult", ctx, seasonID, tmock.Anything, tmock.Anything, tmock.Anything).
+				Return(nil)
+
+			mockGameRepository.
+				On("SetSeasonRatingDeltas", ctx, gameID, seasonID, tmock.Anything, tmock.Anything).
+				Return(nil)
+
 			mockRoomRepository.
 				On("Update", ctx, room).
 				Return(nil)
 
 			position := 3
-			err = gameEngineService.PlayerMakeMove(ctx, roomID, playerID, position)
+			err = gameEngineService.PlayerMakeMove(ctx, roomID, playerID, engine.MoveKindCell, position, "")
 
 			Expect(err).ToNot(HaveOccurred())
 			mockGameRepository.AssertExpectations(GinkgoT())
+			mockSeasonRepository.AssertCalled(GinkgoT(), "RecordResult", ctx, seasonID, host.ID, domain.GameResultLoss, -24)
+			mockSeasonRepository.AssertCalled(GinkgoT(), "RecordResult", ctx, seasonID, guest.ID, domain.GameResultWin, 24)
+			mockGameRepository.AssertCalled(GinkgoT(), "SetSeasonRatingDeltas", ctx, gameID, seasonID, -24, 24)
 		})
 
 		It("should return error if player is make incorrect move", func() {
@@ -1145,7 +1863,7 @@ var _ = Describe("GameEngine", func() {
 				Return(game, nil)
 
 			position := 99
-			err = gameEngineService.PlayerMakeMove(ctx, roomID, playerID, position)
+			err = gameEngineService.PlayerMakeMove(ctx, roomID, playerID, engine.MoveKindCell, position, "")
 
 			expectedErrorMessage := engine.InvalidBoardPositionErrorMessage
 			Expect(err).To(HaveOccurred())
@@ -1209,7 +1927,7 @@ var _ = Describe("GameEngine", func() {
 				Return(game, nil)
 
 			position := 1
-			err = gameEngineService.PlayerMakeMove(ctx, roomID, playerID, position)
+			err = gameEngineService.PlayerMakeMove(ctx, roomID, playerID, engine.MoveKindCell, position, "")
 
 			expectedErrorMessage := engine.BoardPositionOcopiedErrorMessage
 			Expect(err).To(HaveOccurred())
@@ -1217,6 +1935,293 @@ var _ = Describe("GameEngine", func() {
 			mockGameRepository.AssertExpectations(GinkgoT())
 		})
 
+		Context("in an ultimate game", func() {
+			var (
+				host   *models.Player
+				guest  *models.Player
+				game   *models.Game
+				roomID uuid.UUID
+			)
+
+			BeforeEach(func() {
+				host = &models.Player{ID: uuid.Must(uuid.NewV4())}
+				guest = &models.Player{ID: uuid.Must(uuid.NewV4())}
+				game = &models.Game{
+					ID:    uuid.Must(uuid.NewV4()),
+					Phase: models.GamePhaseInProgress,
+					Host: models.GamePlayer{
+						ID:   host.ID,
+						Mark: string(engine.XMark),
+					},
+					Guest: models.GamePlayer{
+						ID:   guest.ID,
+						Mark: string(engine.OMark),
+					},
+					CurrentPlayerID: guest.ID,
+					// X played the centre of the first sub-board.
+					Board: "____X____" + strings.Repeat(engine.DefaultBoard, 8),
+				}
+				roomID = uuid.Must(uuid.NewV4())
+				room := &models.Room{
+					ID:     roomID,
+					Host:   models.RoomPlayer{ID: host.ID},
+					Guest:  &models.RoomPlayer{ID: guest.ID},
+					GameID: &game.ID,
+					Phase:  models.RoomPhaseFull,
+				}
+
+				nextBoard := 5
+				mockGameRepository.ExpectedCalls = nil
+				mockGameRepository.
+					On("GetVariantState", ctx, game.ID).
+					Return(&domain.VariantState{Variant: domain.GameVariantUltimate, RuleSet: domain.RuleSetStandard, NextBoard: &nextBoard}, nil)
+				mockGameRepository.
+					On("AddMove", tmock.Anything, tmock.Anything, tmock.Anything).
+					Return(nil).
+					Maybe()
+				mockGameRepository.
+					On("Get", ctx, game.ID).
+					Return(game, nil)
+				mockRoomRepository.
+					On("Get", ctx, roomID, true).
+					Return(room, nil)
+			})
+
+			It("should send the opponent to the corresponding sub-board", func() {
+				mock.ExpectBegin()
+				mock.ExpectCommit()
+
+				nextBoard := 3
+				mockGameRepository.
+					On("SetNextBoard", ctx, game.ID, &nextBoard).
+					Return(nil)
+				mockGameRepository.
+					On("Update", ctx, game).
+					Return(nil)
+
+				// The third cell of the centre sub-board.
+				err := gameEngineService.PlayerMakeMove(ctx, roomID, guest.ID, engine.MoveKindCell, 39, "")
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(game.Board[38]).To(Equal(engine.OMark))
+				Expect(game.CurrentPlayerID).To(Equal(host.ID))
+				mockGameRepository.AssertExpectations(GinkgoT())
+			})
+
+			It("should return error if the move is not in the forced sub-board", func() {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+
+				err := gameEngineService.PlayerMakeMove(ctx, roomID, guest.ID, engine.MoveKindCell, 1, "")
+
+				Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+				Expect(err.Error()).To(Equal(fmt.Sprintf(engine.ForcedSubBoardErrorMessage, 5)))
+				mockGameRepository.AssertNotCalled(GinkgoT(), "Update", tmock.Anything, tmock.Anything)
+			})
+		})
+
+		Context("with a move of the wrong kind", func() {
+			var (
+				roomID uuid.UUID
+				guest  *models.Player
+				game   *models.Game
+			)
+
+			setup := func(variant domain.GameVariant, board string) {
+				host := &models.Player{ID: uuid.Must(uuid.NewV4())}
+				guest = &models.Player{ID: uuid.Must(uuid.NewV4())}
+				game = &models.Game{
+					ID:              uuid.Must(uuid.NewV4()),
+					Phase:           models.GamePhaseInProgress,
+					Host:            models.GamePlayer{ID: host.ID, Mark: string(engine.XMark)},
+					Guest:           models.GamePlayer{ID: guest.ID, Mark: string(engine.OMark)},
+					CurrentPlayerID: guest.ID,
+					Board:           board,
+				}
+				roomID = uuid.Must(uuid.NewV4())
+				room := &models.Room{
+					ID:     roomID,
+					Host:   models.RoomPlayer{ID: host.ID},
+					Guest:  &models.RoomPlayer{ID: guest.ID},
+					GameID: &game.ID,
+					Phase:  models.RoomPhaseFull,
+				}
+
+				mockGameRepository.ExpectedCalls = nil
+				mockGameRepository.
+					On("GetVariantState", ctx, game.ID).
+					Return(&domain.VariantState{Variant: variant, RuleSet: domain.RuleSetStandard}, nil)
+				mockGameRepository.
+					On("Get", ctx, game.ID).
+					Return(game, nil)
+				mockRoomRepository.
+					On("Get", ctx, roomID, true).
+					Return(room, nil)
+
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			}
+
+			It("should return error for a column move in a classic game", func() {
+				setup(domain.GameVariantClassic, engine.DefaultBoard)
+
+				err := gameEngineService.PlayerMakeMove(ctx, roomID, guest.ID, engine.MoveKindColumn, 1, "")
+
+				Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+				Expect(err.Error()).To(Equal(fmt.Sprintf(engine.MoveKindErrorMessage, engine.MoveKindColumn, domain.GameVariantClassic)))
+				mockGameRepository.AssertNotCalled(GinkgoT(), "AddMove", tmock.Anything, tmock.Anything, tmock.Anything)
+			})
+
+			It("should return error for a cell move in a connect four game", func() {
+				setup(domain.GameVariantConnectFour, engine.ConnectFourBoard)
+
+				err := gameEngineService.PlayerMakeMove(ctx, roomID, guest.ID, engine.MoveKindCell, 1, "")
+
+				Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+				Expect(err.Error()).To(Equal(fmt.Sprintf(engine.MoveKindErrorMessage, engine.MoveKindCell, domain.GameVariantConnectFour)))
+				mockGameRepository.AssertNotCalled(GinkgoT(), "AddMove", tmock.Anything, tmock.Anything, tmock.Anything)
+			})
+		})
+
+		Context("under alternative rule sets", func() {
+			var (
+				host   *models.Player
+				guest  *models.Player
+				game   *models.Game
+				room   *models.Room
+				roomID uuid.UUID
+			)
+
+			expectRuleSet := func(ruleSet domain.RuleSet, board string) {
+				game.Board = board
+				mockGameRepository.ExpectedCalls = nil
+				mockGameRepository.
+					On("GetVariantState", ctx, game.ID).
+					Return(&domain.VariantState{Variant: domain.GameVariantClassic, RuleSet: ruleSet}, nil)
+				mockGameRepository.
+					On("AddMove", tmock.Anything, tmock.Anything, tmock.Anything).
+					Return(nil).
+					Maybe()
+				mockGameRepository.
+					On("GetRecentByPlayer", tmock.Anything, tmock.Anything, tmock.Anything).
+					Return([]*domain.GameSummary{}, nil).
+					Maybe()
+				mockGameRepository.
+					On("SetRatingDeltas", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything).
+					Return(nil).
+					Maybe()
+				mockGameRepository.
+					On("Get", ctx, game.ID).
+					Return(game, nil)
+				mockGameRepository.
+					On("Update", ctx, game).
+					Return(nil).
+					Maybe()
+			}
+
+			BeforeEach(func() {
+				host = &models.Player{ID: uuid.Must(uuid.NewV4())}
+				guest = &models.Player{ID: uuid.Must(uuid.NewV4())}
+				game = &models.Game{
+					ID:    uuid.Must(uuid.NewV4()),
+					Phase: models.GamePhaseInProgress,
+					Host: models.GamePlayer{
+						ID:   host.ID,
+						Mark: string(engine.XMark),
+					},
+					Guest: models.GamePlayer{
+						ID:   guest.ID,
+						Mark: string(engine.OMark),
+					},
+					CurrentPlayerID: guest.ID,
+				}
+				roomID = uuid.Must(uuid.NewV4())
+				room = &models.Room{
+					ID:     roomID,
+					Host:   models.RoomPlayer{ID: host.ID},
+					Guest:  &models.RoomPlayer{ID: guest.ID},
+					GameID: &game.ID,
+					Phase:  models.RoomPhaseFull,
+				}
+
+				mockRoomRepository.
+					On("Get", ctx, roomID, true).
+					Return(room, nil)
+				mockRoomRepository.
+					On("Update", ctx, room).
+					Return(nil).
+					Maybe()
+				mockPlayerRepository.
+					On("Get", ctx, guest.ID).
+					Return(guest, nil).
+					Maybe()
+				mockPlayerRepository.
+					On("Get", ctx, host.ID).
+					Return(host, nil).
+					Maybe()
+				mockPlayerRepository.
+					On("UpdateStats", ctx, tmock.Anything).
+					Return(nil).
+					Maybe()
+				mockPlayerRepository.
+					On("GetRating", ctx, tmock.Anything).
+					Return(domain.DefaultRating, nil).
+					Maybe()
+				mockPlayerRepository.
+					On("AdjustRating", ctx, tmock.Anything, tmock.Anything).
+					Return(nil).
+					Maybe()
+			})
+
+			It("should lose a misere game for the player who completes a line", func() {
+				mock.ExpectBegin()
+				mock.ExpectCommit()
+				expectRuleSet(domain.RuleSetMisere, "O_XO_X_X_")
+
+				err := gameEngineService.PlayerMakeMove(ctx, roomID, guest.ID, engine.MoveKindCell, 7, "")
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(game.Phase).To(Equal(models.GamePhaseCompleted))
+				Expect(*game.WinnerID).To(Equal(host.ID))
+			})
+
+			It("should place the mark the player chose in a wild game", func() {
+				mock.ExpectBegin()
+				mock.ExpectCommit()
+				expectRuleSet(domain.RuleSetWild, "XX_______")
+
+				err := gameEngineService.PlayerMakeMove(ctx, roomID, guest.ID, engine.MoveKindCell, 3, string(engine.XMark))
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(game.Board).To(Equal("XXX______"))
+				Expect(*game.WinnerID).To(Equal(guest.ID))
+			})
+
+			It("should return error if no mark is chosen in a wild game", func() {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+				expectRuleSet(domain.RuleSetWild, "XX_______")
+
+				err := gameEngineService.PlayerMakeMove(ctx, roomID, guest.ID, engine.MoveKindCell, 3, "")
+
+				Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+				Expect(err.Error()).To(Equal(fmt.Sprintf(engine.MarkRequiredErrorMessage, domain.RuleSetWild)))
+			})
+
+			It("should place X for both players in a notakto game", func() {
+				mock.ExpectBegin()
+				mock.ExpectCommit()
+				expectRuleSet(domain.RuleSetNotakto, "X________")
+
+				err := gameEngineService.PlayerMakeMove(ctx, roomID, guest.ID, engine.MoveKindCell, 5, "")
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(game.Board).To(Equal("X___X____"))
+				Expect(game.Phase).To(Equal(models.GamePhaseInProgress))
+				Expect(game.CurrentPlayerID).To(Equal(host.ID))
+			})
+		})
+
 		It("should return error if player is not in turn", func() {
 			mock.ExpectBegin()
 			mock.ExpectRollback()
@@ -1273,7 +2278,7 @@ var _ = Describe("GameEngine", func() {
 				Return(game, nil)
 
 			position := 2
-			err = gameEngineService.PlayerMakeMove(ctx, roomID, playerID, position)
+			err = gameEngineService.PlayerMakeMove(ctx, roomID, playerID, engine.MoveKindCell, position, "")
 
 			expectedErrorMessage := engine.PlayerNotInTurnErrorMessage
 			Expect(err).To(HaveOccurred())
@@ -1337,7 +2342,7 @@ var _ = Describe("GameEngine", func() {
 				Return(game, nil)
 
 			position := 2
-			err = gameEngineService.PlayerMakeMove(ctx, roomID, playerID, position)
+			err = gameEngineService.PlayerMakeMove(ctx, roomID, playerID, engine.MoveKindCell, position, "")
 
 			expectedErrorMessage := engine.GameCompletedErrorMessage
 			Expect(err).To(HaveOccurred())
@@ -1400,7 +2405,7 @@ var _ = Describe("GameEngine", func() {
 				On("Get", ctx, gameID).
 				Return(game, nil)
 			position := 2
-			err = gameEngineService.PlayerMakeMove(ctx, roomID, uuid.Nil, position)
+			err = gameEngineService.PlayerMakeMove(ctx, roomID, uuid.Nil, engine.MoveKindCell, position, "")
 
 			expectedErrorMessage := engine.PlayerNotInRoomErrorMessage
 			Expect(err).To(HaveOccurred())
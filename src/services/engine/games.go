@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+)
+
+var (
+	InvalidBoardErrorMessage string = "stored board of the '%s' game is invalid"
+	UnknownGameErrorMessage  string = "no rules are registered for the '%s' game"
+	MoveKindErrorMessage     string = "%s moves can not be played in the '%s' game"
+)
+
+// MoveKind is what the position of a move names.
+type MoveKind string
+
+const (
+	MoveKindCell   MoveKind = "cell"
+	MoveKindColumn MoveKind = "column"
+)
+
+// State is the state of a game that the rules plug-ins work on.
+type State struct {
+	Board   string
+	RuleSet domain.RuleSet
+	// NextBoard restricts the next move to a part of the board, for games
+	// that have such a rule; nil allows the whole board.
+	NextBoard *int
+}
+
+// Move is a move of the player in turn. Position is the 1-based place the
+// move is played at: a cell of the board, or a column in games where the
+// marks drop down the columns. PlayerMark is the mark the player was
+// assigned and Mark the mark the player asked for, empty if none.
+type Move struct {
+	Position   int
+	PlayerMark byte
+	Mark       string
+}
+
+// GameRules is the rules plug-in of a turn-based game for two players. The
+// rooms, the ranking and the rest of the engine host any game whose rules
+// are registered with RegisterGame.
+type GameRules interface {
+	// MoveKind returns what the positions of the moves of the game name.
+	MoveKind() MoveKind
+	// MinWinningMoves returns the fewest moves a player can win the game
+	// with by completing a line of own marks under the rule set, or 0 if
+	// the game is not won that way under it.
+	MinWinningMoves(domain.RuleSet) int
+	// NewState returns the state of a new game under the rule set, or a
+	// validation error if the game can not be played under it.
+	NewState(domain.RuleSet) (*State, error)
+	// LegalMoves returns the positions the player in turn may play.
+	LegalMoves(*State) []int
+	// Apply validates the move and plays it on the state. It returns the
+	// mark that was placed.
+	Apply(*State, Move) (byte, error)
+	// Outcome returns the result of the state after a move, seen from the
+	// player who made it.
+	Outcome(*State) Result
+	// Encode returns the board of the state as it is stored in the games
+	// table and Decode restores the state from the stored board and the
+	// variant state of the game.
+	Encode(*State) string
+	Decode(string, *domain.VariantState) (*State, error)
+}
+
+// MetaBoarder is implemented by the rules of games that are played on a
+// board of boards. MetaBoard returns the result of each of the boards.
+type MetaBoarder interface {
+	MetaBoard(*State) string
+}
+
+var gameRegistry = map[domain.GameVariant]GameRules{
+	domain.GameVariantClassic:     classicGame{},
+	domain.GameVariantUltimate:    ultimateGame{},
+	domain.GameVariantConnectFour: connectFourGame{},
+}
+
+// RegisterGame makes the game available to the rooms. It is not safe for
+// concurrent use and is meant to be called while the application starts.
+func RegisterGame(variant domain.GameVariant, rules GameRules) {
+	gameRegistry[variant] = rules
+}
+
+// LookupGame returns the rules registered for the game.
+func LookupGame(variant domain.GameVariant) (GameRules, bool) {
+	rules, ok := gameRegistry[variant]
+	return rules, ok
+}
+
+// gameRules returns the rules of a stored game; a game without registered
+// rules can not be played.
+func gameRules(variant domain.GameVariant) (GameRules, error) {
+	rules, ok := gameRegistry[variant]
+	if !ok {
+		return nil, models.NewGenericError(fmt.Sprintf(UnknownGameErrorMessage, variant))
+	}
+
+	return rules, nil
+}
+
+// decodeBoard checks the length and the tiles of a stored board.
+func decodeBoard(variant domain.GameVariant, board string, size int, tiles string) error {
+	if len(board) != size {
+		return models.NewGenericError(fmt.Sprintf(InvalidBoardErrorMessage, variant))
+	}
+
+	for i := 0; i < len(board); i++ {
+		if board[i] != DefaultBoardTile && strings.IndexByte(tiles, board[i]) < 0 {
+			return models.NewGenericError(fmt.Sprintf(InvalidBoardErrorMessage, variant))
+		}
+	}
+
+	return nil
+}
+
+func placeMark(board string, position int, mark byte) string {
+	boardBytes := []byte(board)
+	boardBytes[position-1] = mark
+	return string(boardBytes)
+}
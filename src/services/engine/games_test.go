@@ -0,0 +1,172 @@
+package engine_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/engine"
+)
+
+var _ = Describe("GameRules", func() {
+	lookup := func(variant domain.GameVariant) engine.GameRules {
+		rules, ok := engine.LookupGame(variant)
+		Expect(ok).To(BeTrue())
+		return rules
+	}
+
+	Context("classic", func() {
+		It("should start with an empty board under any rule set", func() {
+			state, err := lookup(domain.GameVariantClassic).NewState(domain.RuleSetMisere)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(state.Board).To(Equal(engine.DefaultBoard))
+			Expect(state.RuleSet).To(Equal(domain.RuleSetMisere))
+		})
+
+		It("should reject an unknown rule set", func() {
+			_, err := lookup(domain.GameVariantClassic).NewState("suicide")
+
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+		})
+
+		It("should apply a move and list the free cells", func() {
+			rules := lookup(domain.GameVariantClassic)
+			state := &engine.State{Board: "X___O____", RuleSet: domain.RuleSetStandard}
+
+			mark, err := rules.Apply(state, engine.Move{Position: 9, PlayerMark: engine.XMark})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mark).To(Equal(engine.XMark))
+			Expect(rules.Encode(state)).To(Equal("X___O___X"))
+			Expect(rules.LegalMoves(state)).To(Equal([]int{2, 3, 4, 6, 7, 8}))
+			Expect(rules.Outcome(state)).To(Equal(engine.ResultNone))
+		})
+
+		It("should have no legal moves once the game is decided", func() {
+			rules := lookup(domain.GameVariantClassic)
+			state := &engine.State{Board: "XXXOO____", RuleSet: domain.RuleSetStandard}
+
+			Expect(rules.Outcome(state)).To(Equal(engine.ResultWin))
+			Expect(rules.LegalMoves(state)).To(BeEmpty())
+		})
+
+		It("should reject a corrupt stored board", func() {
+			_, err := lookup(domain.GameVariantClassic).Decode("XX", &domain.VariantState{RuleSet: domain.RuleSetStandard})
+
+			Expect(err).To(BeAssignableToTypeOf(&models.GenericError{}))
+		})
+	})
+
+	Context("ultimate", func() {
+		It("should only be played under the standard rules", func() {
+			_, err := lookup(domain.GameVariantUltimate).NewState(domain.RuleSetWild)
+
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+		})
+
+		It("should only allow the forced sub-board", func() {
+			rules := lookup(domain.GameVariantUltimate)
+			nextBoard := 2
+			state := &engine.State{Board: engine.UltimateBoard, RuleSet: domain.RuleSetStandard, NextBoard: &nextBoard}
+
+			Expect(rules.LegalMoves(state)).To(Equal([]int{10, 11, 12, 13, 14, 15, 16, 17, 18}))
+		})
+
+		It("should send the opponent to the sub-board of the played cell", func() {
+			rules := lookup(domain.GameVariantUltimate)
+			state, err := rules.NewState(domain.RuleSetStandard)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = rules.Apply(state, engine.Move{Position: 9, PlayerMark: engine.OMark})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*state.NextBoard).To(Equal(9))
+			Expect(rules.(engine.MetaBoarder).MetaBoard(state)).To(Equal(strings.Repeat("_", 9)))
+		})
+	})
+
+	Context("connect four", func() {
+		var (
+			rules engine.GameRules
+			state *engine.State
+		)
+
+		// board builds a board from its rows, bottom row first.
+		board := func(rows ...string) string {
+			for len(rows) < engine.ConnectFourRows {
+				rows = append(rows, "_______")
+			}
+			result := ""
+			for _, row := range rows {
+				result = row + result
+			}
+			return result
+		}
+
+		BeforeEach(func() {
+			rules = lookup(domain.GameVariantConnectFour)
+			var err error
+			state, err = rules.NewState(domain.RuleSetStandard)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should drop the mark to the lowest free cell of the column", func() {
+			state.Board = board("___X___")
+
+			_, err := rules.Apply(state, engine.Move{Position: 4, PlayerMark: engine.OMark})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(state.Board).To(Equal(board("___X___", "___O___")))
+			Expect(rules.Outcome(state)).To(Equal(engine.ResultNone))
+		})
+
+		It("should return error for a full column", func() {
+			state.Board = board("X______", "O______", "X______", "O______", "X______", "O______")
+
+			_, err := rules.Apply(state, engine.Move{Position: 1, PlayerMark: engine.XMark})
+
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+			Expect(err.Error()).To(Equal(engine.ColumnFullErrorMessage))
+			Expect(rules.LegalMoves(state)).To(Equal([]int{2, 3, 4, 5, 6, 7}))
+		})
+
+		It("should return error for a column outside the board", func() {
+			_, err := rules.Apply(state, engine.Move{Position: 8, PlayerMark: engine.XMark})
+
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+			Expect(err.Error()).To(Equal(engine.InvalidColumnErrorMessage))
+		})
+
+		It("should win with four in a diagonal", func() {
+			state.Board = board("XOOO___", "_XOO___", "__XX___", "_______")
+
+			_, err := rules.Apply(state, engine.Move{Position: 4, PlayerMark: engine.XMark})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rules.Outcome(state)).To(Equal(engine.ResultWin))
+		})
+
+		It("should not win with three in a row", func() {
+			state.Board = board("XXX_OO_")
+
+			Expect(rules.Outcome(state)).To(Equal(engine.ResultNone))
+		})
+
+		It("should reject a stored board with a floating mark", func() {
+			_, err := rules.Decode(board("_______", "X______"), &domain.VariantState{})
+
+			Expect(err).To(BeAssignableToTypeOf(&models.GenericError{}))
+		})
+	})
+
+	It("should host registered games", func() {
+		engine.RegisterGame("classic-copy", lookup(domain.GameVariantClassic))
+
+		_, ok := engine.LookupGame("classic-copy")
+
+		Expect(ok).To(BeTrue())
+	})
+})
@@ -5,6 +5,8 @@ import (
 
 	"github.com/gofrs/uuid"
 	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/engine"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -19,8 +21,9 @@ func (m *MockGameEngineService) GetRoom(ctx context.Context, playerID uuid.UUID)
 	}
 	return args.Get(0).(*models.Room), args.Error(1)
 }
-func (m *MockGameEngineService) GetOpenRooms(ctx context.Context, pPageSize int, pPage int) ([]*models.Room, int, int, int, error) {
-	args := m.Called(ctx)
+
+func (m *MockGameEngineService) GetOpenRooms(ctx context.Context, filter *domain.RoomFilter, pPageSize int, pPage int) ([]*models.Room, int, int, int, error) {
+	args := m.Called(ctx, filter)
 
 	rooms, okRooms := args.Get(0).([]*models.Room)
 	pageSize, okPageSize := args.Get(1).(int)
@@ -33,21 +36,33 @@ func (m *MockGameEngineService) GetOpenRooms(ctx context.Context, pPageSize int,
 
 	return rooms, pageSize, page, total, args.Error(4)
 }
-func (m *MockGameEngineService) CreateRoom(ctx context.Context, playerID uuid.UUID, title string, description string) (uuid.UUID, error) {
-	args := m.Called(ctx, playerID, title, description)
+
+func (m *MockGameEngineService) GetOpenRoomsAfter(ctx context.Context, filter *domain.RoomFilter, cursor string, pageSize int) ([]*models.Room, string, error) {
+	args := m.Called(ctx, filter, cursor, pageSize)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).([]*models.Room), args.String(1), args.Error(2)
+}
+
+func (m *MockGameEngineService) CreateRoom(ctx context.Context, playerID uuid.UUID, title string, description string, settings *domain.RoomSettings) (uuid.UUID, error) {
+	args := m.Called(ctx, playerID, title, description, settings)
 	if args.Get(0) == nil {
 		return uuid.Nil, args.Error(1)
 	}
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
+
 func (m *MockGameEngineService) PlayerJoinRoom(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) error {
 	args := m.Called(ctx, roomID, playerID)
 	return args.Error(0)
 }
+
 func (m *MockGameEngineService) PlayerLeaveRoom(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) error {
 	args := m.Called(ctx, roomID, playerID)
 	return args.Error(0)
 }
+
 func (m *MockGameEngineService) CreateGame(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) (uuid.UUID, error) {
 	args := m.Called(ctx, roomID, playerID)
 	if args.Get(0) == nil {
@@ -55,17 +70,20 @@ func (m *MockGameEngineService) CreateGame(ctx context.Context, roomID uuid.UUID
 	}
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
-func (m *MockGameEngineService) GetGameState(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) (*models.Game, error) {
+
+func (m *MockGameEngineService) GetGameState(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) (*domain.GameState, error) {
 	args := m.Called(ctx, roomID, playerID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*models.Game), args.Error(1)
+	return args.Get(0).(*domain.GameState), args.Error(1)
 }
-func (m *MockGameEngineService) PlayerMakeMove(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID, position int) error {
-	args := m.Called(ctx, roomID, playerID, position)
+
+func (m *MockGameEngineService) PlayerMakeMove(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID, kind engine.MoveKind, position int, mark string) error {
+	args := m.Called(ctx, roomID, playerID, kind, position, mark)
 	return args.Error(0)
 }
+
 func (m *MockGameEngineService) GetRanking(ctx context.Context, pageSize int, page int) ([]*models.Player, int, int, int, error) {
 	args := m.Called(ctx)
 
@@ -80,3 +98,40 @@ func (m *MockGameEngineService) GetRanking(ctx context.Context, pageSize int, pa
 
 	return players, pageSize, page, total, args.Error(4)
 }
+
+func (m *MockGameEngineService) GetRankingAfter(ctx context.Context, cursor string, pageSize int) ([]*models.Player, string, error) {
+	args := m.Called(ctx, cursor, pageSize)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).([]*models.Player), args.String(1), args.Error(2)
+}
+
+func (m *MockGameEngineService) GetGameRanking(ctx context.Context, variant domain.GameVariant, page int, pageSize int) ([]*models.Player, int, int, int, error) {
+	args := m.Called(ctx, variant, page, pageSize)
+	if args.Get(0) == nil {
+		return nil, 0, 0, 0, args.Error(4)
+	}
+	return args.Get(0).([]*models.Player), args.Int(1), args.Int(2), args.Int(3), args.Error(4)
+}
+
+func (m *MockGameEngineService) InvitePlayer(ctx context.Context, playerID uuid.UUID, inviteeID uuid.UUID, title string, description string) (uuid.UUID, error) {
+	args := m.Called(ctx, playerID, inviteeID, title, description)
+	if args.Get(0) == nil {
+		return uuid.Nil, args.Error(1)
+	}
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockGameEngineService) GetInvitations(ctx context.Context, playerID uuid.UUID) ([]*domain.Invitation, error) {
+	args := m.Called(ctx, playerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Invitation), args.Error(1)
+}
+
+func (m *MockGameEngineService) DeclineInvitation(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) error {
+	args := m.Called(ctx, roomID, playerID)
+	return args.Error(0)
+}
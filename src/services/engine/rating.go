@@ -0,0 +1,14 @@
+package engine
+
+import "math"
+
+// EloK is the maximum rating change of a single game.
+const EloK float64 = 32
+
+// EloDeltas returns the rating changes of both players after a game in which
+// the first player scored score (1 win, 0.5 draw, 0 loss).
+func EloDeltas(rating int, opponentRating int, score float64) (int, int) {
+	expected := 1 / (1 + math.Pow(10, float64(opponentRating-rating)/400))
+	delta := int(math.Round(EloK * (score - expected)))
+	return delta, -delta
+}
@@ -0,0 +1,23 @@
+package engine_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe/src/services/engine"
+)
+
+var _ = Describe("EloDeltas", func() {
+	DescribeTable("should move the ratings towards the result",
+		func(rating int, opponentRating int, score float64, expected int) {
+			delta, opponentDelta := engine.EloDeltas(rating, opponentRating, score)
+			Expect(delta).To(Equal(expected))
+			Expect(opponentDelta).To(Equal(-expected))
+		},
+		Entry("win between equals", 1200, 1200, 1.0, 16),
+		Entry("loss between equals", 1200, 1200, 0.0, -16),
+		Entry("draw between equals", 1200, 1200, 0.5, 0),
+		Entry("expected win of the stronger player", 1600, 1200, 1.0, 3),
+		Entry("upset win of the weaker player", 1200, 1600, 1.0, 29),
+		Entry("draw against a stronger player", 1200, 1600, 0.5, 13),
+	)
+})
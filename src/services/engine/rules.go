@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"strings"
+
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+)
+
+var (
+	MarkNotSelectableErrorMessage string = "mark can not be chosen under the '%s' rules"
+	MarkRequiredErrorMessage      string = "mark X or O is required under the '%s' rules"
+	InvalidRuleSetErrorMessage    string = "invalid rule set '%s'"
+	RuleSetVariantErrorMessage    string = "rule set '%s' is not available for the '%s' variant"
+)
+
+// Result is the result of a game after a move, seen from the player who
+// made the move.
+type Result int
+
+const (
+	ResultNone Result = iota
+	ResultWin
+	ResultLoss
+	ResultDraw
+)
+
+// Rules are the move validation and the terminal-state logic of a rule set
+// of the classic board.
+type Rules interface {
+	// Mark returns the mark a player who was assigned playerMark places when
+	// requesting the mark requested, which is empty if the player did not
+	// choose one.
+	Mark(playerMark byte, requested string) (byte, error)
+	// Result returns the result of the board after a move.
+	Result(board string) Result
+}
+
+var ruleSets = map[domain.RuleSet]Rules{
+	domain.RuleSetStandard: standardRules{},
+	domain.RuleSetMisere:   misereRules{},
+	domain.RuleSetWild:     wildRules{},
+	domain.RuleSetNotakto:  notaktoRules{},
+}
+
+// assignedMark allows only the mark the player was assigned.
+func assignedMark(ruleSet domain.RuleSet, playerMark byte, requested string) (byte, error) {
+	if len(requested) > 0 && requested != string(playerMark) {
+		return 0, models.NewValidationErrorf(MarkNotSelectableErrorMessage, ruleSet)
+	}
+
+	return playerMark, nil
+}
+
+// lineResult returns lineResult for a board with a line, a draw for a full
+// board and no result otherwise.
+func lineResult(board string, line Result) Result {
+	if lineOwner(board) != DefaultBoardTile {
+		return line
+	}
+
+	if !strings.Contains(board, string(DefaultBoardTile)) {
+		return ResultDraw
+	}
+
+	return ResultNone
+}
+
+type standardRules struct{}
+
+func (standardRules) Mark(playerMark byte, requested string) (byte, error) {
+	return assignedMark(domain.RuleSetStandard, playerMark, requested)
+}
+
+func (standardRules) Result(board string) Result {
+	return lineResult(board, ResultWin)
+}
+
+type misereRules struct{}
+
+func (misereRules) Mark(playerMark byte, requested string) (byte, error) {
+	return assignedMark(domain.RuleSetMisere, playerMark, requested)
+}
+
+func (misereRules) Result(board string) Result {
+	return lineResult(board, ResultLoss)
+}
+
+type wildRules struct{}
+
+func (wildRules) Mark(playerMark byte, requested string) (byte, error) {
+	if requested != string(XMark) && requested != string(OMark) {
+		return 0, models.NewValidationErrorf(MarkRequiredErrorMessage, domain.RuleSetWild)
+	}
+
+	return requested[0], nil
+}
+
+func (wildRules) Result(board string) Result {
+	return lineResult(board, ResultWin)
+}
+
+// notaktoRules never end in a draw: a full board of X always has a line.
+type notaktoRules struct{}
+
+func (notaktoRules) Mark(playerMark byte, requested string) (byte, error) {
+	return assignedMark(domain.RuleSetNotakto, XMark, requested)
+}
+
+func (notaktoRules) Result(board string) Result {
+	return lineResult(board, ResultLoss)
+}
@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/services/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewTracedGameEngineService wraps next so that every call runs in its own
+// span. Repository queries issued by next become children of that span.
+func NewTracedGameEngineService(next GameEngineService) GameEngineService {
+	return &tracedGameEngineService{next: next}
+}
+
+type tracedGameEngineService struct {
+	next GameEngineService
+}
+
+func (t *tracedGameEngineService) GetRoom(ctx context.Context, playerID uuid.UUID) (room *models.Room, err error) {
+	ctx, span := startSpan(ctx, "GetRoom", attribute.String("player.id", playerID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.GetRoom(ctx, playerID)
+}
+
+func (t *tracedGameEngineService) GetOpenRooms(ctx context.Context, filter *domain.RoomFilter, page int, pageSize int) (rooms []*models.Room, _ int, _ int, _ int, err error) {
+	ctx, span := startSpan(ctx, "GetOpenRooms", attribute.Int("page", page), attribute.Int("page.size", pageSize))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.GetOpenRooms(ctx, filter, page, pageSize)
+}
+
+func (t *tracedGameEngineService) GetOpenRoomsAfter(ctx context.Context, filter *domain.RoomFilter, cursor string, pageSize int) (rooms []*models.Room, next string, err error) {
+	ctx, span := startSpan(ctx, "GetOpenRoomsAfter", attribute.Int("page.size", pageSize))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.GetOpenRoomsAfter(ctx, filter, cursor, pageSize)
+}
+
+func (t *tracedGameEngineService) CreateRoom(ctx context.Context, playerID uuid.UUID, title string, description string, settings *domain.RoomSettings) (id uuid.UUID, err error) {
+	ctx, span := startSpan(ctx, "CreateRoom", attribute.String("player.id", playerID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.CreateRoom(ctx, playerID, title, description, settings)
+}
+
+func (t *tracedGameEngineService) PlayerJoinRoom(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "PlayerJoinRoom", attribute.String("room.id", roomID.String()), attribute.String("player.id", playerID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.PlayerJoinRoom(ctx, roomID, playerID)
+}
+
+func (t *tracedGameEngineService) PlayerLeaveRoom(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "PlayerLeaveRoom", attribute.String("room.id", roomID.String()), attribute.String("player.id", playerID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.PlayerLeaveRoom(ctx, roomID, playerID)
+}
+
+func (t *tracedGameEngineService) CreateGame(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) (id uuid.UUID, err error) {
+	ctx, span := startSpan(ctx, "CreateGame", attribute.String("room.id", roomID.String()), attribute.String("player.id", playerID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.CreateGame(ctx, roomID, playerID)
+}
+
+func (t *tracedGameEngineService) GetGameState(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) (game *domain.GameState, err error) {
+	ctx, span := startSpan(ctx, "GetGameState", attribute.String("room.id", roomID.String()), attribute.String("player.id", playerID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.GetGameState(ctx, roomID, playerID)
+}
+
+func (t *tracedGameEngineService) PlayerMakeMove(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID, kind MoveKind, position int, mark string) (err error) {
+	ctx, span := startSpan(ctx, "PlayerMakeMove", attribute.String("room.id", roomID.String()), attribute.String("player.id", playerID.String()), attribute.String("move.kind", string(kind)), attribute.Int("position", position))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.PlayerMakeMove(ctx, roomID, playerID, kind, position, mark)
+}
+
+func (t *tracedGameEngineService) GetRanking(ctx context.Context, page int, pageSize int) (players []*models.Player, _ int, _ int, _ int, err error) {
+	ctx, span := startSpan(ctx, "GetRanking", attribute.Int("page", page), attribute.Int("page.size", pageSize))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.GetRanking(ctx, page, pageSize)
+}
+
+func (t *tracedGameEngineService) GetRankingAfter(ctx context.Context, cursor string, pageSize int) (players []*models.Player, next string, err error) {
+	ctx, span := startSpan(ctx, "GetRankingAfter", attribute.Int("page.size", pageSize))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.GetRankingAfter(ctx, cursor, pageSize)
+}
+
+func (t *tracedGameEngineService) GetGameRanking(ctx context.Context, variant domain.GameVariant, page int, pageSize int) (players []*models.Player, _ int, _ int, _ int, err error) {
+	ctx, span := startSpan(ctx, "GetGameRanking", attribute.String("game.variant", string(variant)), attribute.Int("page", page), attribute.Int("page.size", pageSize))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.GetGameRanking(ctx, variant, page, pageSize)
+}
+
+func (t *tracedGameEngineService) InvitePlayer(ctx context.Context, playerID uuid.UUID, inviteeID uuid.UUID, title string, description string) (id uuid.UUID, err error) {
+	ctx, span := startSpan(ctx, "InvitePlayer", attribute.String("player.id", playerID.String()), attribute.String("invitee.id", inviteeID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.InvitePlayer(ctx, playerID, inviteeID, title, description)
+}
+
+func (t *tracedGameEngineService) GetInvitations(ctx context.Context, playerID uuid.UUID) (invitations []*domain.Invitation, err error) {
+	ctx, span := startSpan(ctx, "GetInvitations", attribute.String("player.id", playerID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.GetInvitations(ctx, playerID)
+}
+
+func (t *tracedGameEngineService) DeclineInvitation(ctx context.Context, roomID uuid.UUID, playerID uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "DeclineInvitation", attribute.String("room.id", roomID.String()), attribute.String("player.id", playerID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	return t.next.DeclineInvitation(ctx, roomID, playerID)
+}
+
+func startSpan(ctx context.Context, method string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Tracer("engine").Start(ctx, "GameEngineService."+method, trace.WithAttributes(attributes...))
+}
@@ -0,0 +1,73 @@
+package engine_test
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/services/engine"
+	"github.com/plamen-v/tic-tac-toe/src/services/engine/mocks"
+	tmock "github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ = Describe("TracedGameEngine", func() {
+	var (
+		ctx                   context.Context
+		recorder              *tracetest.SpanRecorder
+		mockGameEngineService *mocks.MockGameEngineService
+		gameEngineService     engine.GameEngineService
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		recorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+		mockGameEngineService = new(mocks.MockGameEngineService)
+		gameEngineService = engine.NewTracedGameEngineService(mockGameEngineService)
+	})
+
+	It("should record a span per call", func() {
+		mockGameEngineService.On("GetRoom", tmock.Anything, tmock.Anything).Return(&models.Room{}, nil)
+
+		_, err := gameEngineService.GetRoom(ctx, uuid.Must(uuid.NewV4()))
+
+		Expect(err).ToNot(HaveOccurred())
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name()).To(Equal("GameEngineService.GetRoom"))
+		Expect(spans[0].Status().Code).To(Equal(codes.Unset))
+	})
+
+	It("should mark the span as failed when the call returns an error", func() {
+		mockGameEngineService.On("PlayerMakeMove", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything).
+			Return(models.NewValidationError(engine.PlayerNotInTurnErrorMessage))
+
+		err := gameEngineService.PlayerMakeMove(ctx, uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), engine.MoveKindCell, 1, "")
+
+		Expect(err).To(HaveOccurred())
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name()).To(Equal("GameEngineService.PlayerMakeMove"))
+		Expect(spans[0].Status().Code).To(Equal(codes.Error))
+		Expect(spans[0].Status().Description).To(Equal(engine.PlayerNotInTurnErrorMessage))
+	})
+
+	It("should pass the span context to the wrapped service", func() {
+		mockGameEngineService.On("GetRoom", tmock.Anything, tmock.Anything).Return(&models.Room{}, nil)
+
+		_, err := gameEngineService.GetRoom(ctx, uuid.Must(uuid.NewV4()))
+
+		Expect(err).ToNot(HaveOccurred())
+		passedCtx := mockGameEngineService.Calls[0].Arguments.Get(0).(context.Context)
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(trace.SpanContextFromContext(passedCtx).SpanID()).To(Equal(spans[0].SpanContext().SpanID()))
+	})
+})
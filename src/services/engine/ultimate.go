@@ -0,0 +1,188 @@
+package engine
+
+import (
+	"strings"
+
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+)
+
+// An Ultimate board is stored sub-board by sub-board: position p (1..81) is
+// cell (p-1)%9 of sub-board (p-1)/9, both numbered row by row like the
+// cells of a classic board.
+const (
+	UltimateSubBoardSize int  = len(DefaultBoard)
+	DrawnBoardTile       byte = 'D'
+)
+
+var (
+	UltimateBoard string = strings.Repeat(DefaultBoard, UltimateSubBoardSize)
+
+	ForcedSubBoardErrorMessage  string = "move must be played in sub-board %d"
+	SubBoardDecidedErrorMessage string = "sub-board is already decided"
+)
+
+// boardLines are the rows, the columns and the diagonals of a 3x3 board.
+var boardLines = [8][3]int{
+	{0, 1, 2}, {3, 4, 5}, {6, 7, 8},
+	{0, 3, 6}, {1, 4, 7}, {2, 5, 8},
+	{0, 4, 8}, {2, 4, 6},
+}
+
+// lineOwner returns the mark that fills a line of the 3x3 board, or the
+// empty tile when there is none. Drawn tiles never form a line.
+func lineOwner(board string) byte {
+	for _, line := range boardLines {
+		tile := board[line[0]]
+		if tile != DefaultBoardTile && tile != DrawnBoardTile && tile == board[line[1]] && tile == board[line[2]] {
+			return tile
+		}
+	}
+
+	return DefaultBoardTile
+}
+
+func subBoard(board string, index int) string {
+	return board[index*UltimateSubBoardSize : (index+1)*UltimateSubBoardSize]
+}
+
+// UltimateMetaBoard returns the result of each sub-board of an Ultimate
+// board: the mark of its winner, DrawnBoardTile for a full sub-board without
+// a line, or the empty tile while it is still open.
+func UltimateMetaBoard(board string) string {
+	meta := []byte(DefaultBoard)
+	for i := range meta {
+		sub := subBoard(board, i)
+		if owner := lineOwner(sub); owner != DefaultBoardTile {
+			meta[i] = owner
+		} else if !strings.Contains(sub, string(DefaultBoardTile)) {
+			meta[i] = DrawnBoardTile
+		}
+	}
+
+	return string(meta)
+}
+
+// ultimateNextBoard returns the 1-based sub-board the opponent is sent to
+// after a mark at position, or nil when that sub-board is decided and any
+// open one may be played.
+func ultimateNextBoard(board string, position int) *int {
+	next := (position-1)%UltimateSubBoardSize + 1
+	if UltimateMetaBoard(board)[next-1] != DefaultBoardTile {
+		return nil
+	}
+
+	return &next
+}
+
+func validateUltimateMove(board string, nextBoard *int, position int) error {
+	index := (position-1)/UltimateSubBoardSize + 1
+	if nextBoard != nil && *nextBoard != index {
+		return models.NewValidationErrorf(ForcedSubBoardErrorMessage, *nextBoard)
+	}
+
+	if UltimateMetaBoard(board)[index-1] != DefaultBoardTile {
+		return models.NewValidationError(SubBoardDecidedErrorMessage)
+	}
+
+	return nil
+}
+
+// ultimateOutcome reports whether a player has won three sub-boards in a
+// line and whether the game is over because no sub-board is left open.
+func ultimateOutcome(board string) (win bool, over bool) {
+	meta := UltimateMetaBoard(board)
+	win = lineOwner(meta) != DefaultBoardTile
+
+	return win, win || !strings.Contains(meta, string(DefaultBoardTile))
+}
+
+// ultimateGame is Ultimate tic-tac-toe, played under the standard rules
+// only.
+type ultimateGame struct{}
+
+func (ultimateGame) MoveKind() MoveKind {
+	return MoveKindCell
+}
+
+// MinWinningMoves is a line of sub-boards, each won with a line.
+func (ultimateGame) MinWinningMoves(ruleSet domain.RuleSet) int {
+	if ruleSet != domain.RuleSetStandard {
+		return 0
+	}
+
+	return len(boardLines[0]) * len(boardLines[0])
+}
+
+func (ultimateGame) NewState(ruleSet domain.RuleSet) (*State, error) {
+	if ruleSet != domain.RuleSetStandard {
+		return nil, models.NewValidationErrorf(RuleSetVariantErrorMessage, ruleSet, domain.GameVariantUltimate)
+	}
+
+	return &State{Board: UltimateBoard, RuleSet: ruleSet}, nil
+}
+
+func (g ultimateGame) LegalMoves(state *State) []int {
+	if g.Outcome(state) != ResultNone {
+		return []int{}
+	}
+
+	moves := []int{}
+	for i := 0; i < len(state.Board); i++ {
+		if state.Board[i] == DefaultBoardTile && validateUltimateMove(state.Board, state.NextBoard, i+1) == nil {
+			moves = append(moves, i+1)
+		}
+	}
+
+	return moves
+}
+
+func (ultimateGame) Apply(state *State, move Move) (byte, error) {
+	if move.Position < 1 || move.Position > len(state.Board) {
+		return 0, models.NewValidationError(InvalidBoardPositionErrorMessage)
+	}
+
+	if err := validateUltimateMove(state.Board, state.NextBoard, move.Position); err != nil {
+		return 0, err
+	}
+
+	if state.Board[move.Position-1] != DefaultBoardTile {
+		return 0, models.NewValidationError(BoardPositionOcopiedErrorMessage)
+	}
+
+	mark, err := standardRules{}.Mark(move.PlayerMark, move.Mark)
+	if err != nil {
+		return 0, err
+	}
+
+	state.Board = placeMark(state.Board, move.Position, mark)
+	state.NextBoard = ultimateNextBoard(state.Board, move.Position)
+	return mark, nil
+}
+
+func (ultimateGame) Outcome(state *State) Result {
+	switch win, over := ultimateOutcome(state.Board); {
+	case win:
+		return ResultWin
+	case over:
+		return ResultDraw
+	default:
+		return ResultNone
+	}
+}
+
+func (ultimateGame) MetaBoard(state *State) string {
+	return UltimateMetaBoard(state.Board)
+}
+
+func (ultimateGame) Encode(state *State) string {
+	return state.Board
+}
+
+func (ultimateGame) Decode(board string, variantState *domain.VariantState) (*State, error) {
+	if err := decodeBoard(domain.GameVariantUltimate, board, len(UltimateBoard), string([]byte{XMark, OMark})); err != nil {
+		return nil, err
+	}
+
+	return &State{Board: board, RuleSet: domain.RuleSetStandard, NextBoard: variantState.NextBoard}, nil
+}
@@ -0,0 +1,29 @@
+package engine_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe/src/services/engine"
+)
+
+var _ = Describe("UltimateMetaBoard", func() {
+	open := engine.DefaultBoard
+
+	It("should leave the sub-boards of a new game open", func() {
+		Expect(engine.UltimateMetaBoard(engine.UltimateBoard)).To(Equal(engine.DefaultBoard))
+	})
+
+	It("should mark won and drawn sub-boards", func() {
+		board := "XXX______" + open + "XOXXOOOXX" + strings.Repeat(open, 3) + "O__O__O__" + open + open
+
+		Expect(engine.UltimateMetaBoard(board)).To(Equal("X_D___O__"))
+	})
+
+	It("should keep a sub-board with a line open until it is complete", func() {
+		board := "XOXOXO___" + strings.Repeat(open, 8)
+
+		Expect(engine.UltimateMetaBoard(board)).To(Equal(engine.DefaultBoard))
+	})
+})
@@ -0,0 +1,114 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+)
+
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusFail Status = "fail"
+
+	DatabaseCheckName  string = "database"
+	MigrationCheckName string = "migrations"
+	ShutdownCheckName  string = "shutdown"
+
+	CheckTimeout          time.Duration = 2 * time.Second
+	ShuttingDownMessage   string        = "shutting down"
+	UnreachableMessage    string        = "unreachable"
+	SkippedMessage        string        = "skipped"
+	SchemaOutdatedMessage string        = "schema version %d, expected at least %d"
+)
+
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+func (r *Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+type HealthService interface {
+	Liveness(context.Context) *Report
+	Readiness(context.Context) *Report
+	Health(context.Context) *Report
+	SetShuttingDown()
+}
+
+func NewHealthService(db *sql.DB, schemaRepositoryFactory func(q repository.Querier) repository.SchemaRepository) HealthService {
+	return &healthServiceImpl{
+		db:                      db,
+		schemaRepositoryFactory: schemaRepositoryFactory,
+	}
+}
+
+type healthServiceImpl struct {
+	db                      *sql.DB
+	schemaRepositoryFactory func(q repository.Querier) repository.SchemaRepository
+	shuttingDown            atomic.Bool
+}
+
+// Liveness reports whether the process is able to serve requests at all. It
+// does not depend on the database so that an outage does not restart the app.
+func (h *healthServiceImpl) Liveness(ctx context.Context) *Report {
+	return &Report{Status: StatusOK}
+}
+
+// Readiness reports whether the instance should receive traffic. It fails
+// while the application is draining connections during shutdown.
+func (h *healthServiceImpl) Readiness(ctx context.Context) *Report {
+	report := h.Health(ctx)
+	if h.shuttingDown.Load() {
+		report.Status = StatusFail
+		report.Checks[ShutdownCheckName] = ShuttingDownMessage
+	} else {
+		report.Checks[ShutdownCheckName] = string(StatusOK)
+	}
+
+	return report
+}
+
+// Health reports the state of the dependencies: database connectivity and
+// the applied schema version.
+func (h *healthServiceImpl) Health(ctx context.Context) *Report {
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+
+	report := &Report{
+		Status: StatusOK,
+		Checks: map[string]string{},
+	}
+
+	if err := h.db.PingContext(ctx); err != nil {
+		report.Status = StatusFail
+		report.Checks[DatabaseCheckName] = UnreachableMessage
+		report.Checks[MigrationCheckName] = SkippedMessage
+		return report
+	}
+	report.Checks[DatabaseCheckName] = string(StatusOK)
+
+	version, err := h.schemaRepositoryFactory(h.db).GetVersion(ctx)
+	if err != nil {
+		report.Status = StatusFail
+		report.Checks[MigrationCheckName] = UnreachableMessage
+	} else if version < repository.SchemaVersion {
+		report.Status = StatusFail
+		report.Checks[MigrationCheckName] = fmt.Sprintf(SchemaOutdatedMessage, version, repository.SchemaVersion)
+	} else {
+		report.Checks[MigrationCheckName] = string(StatusOK)
+	}
+
+	return report
+}
+
+func (h *healthServiceImpl) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
@@ -0,0 +1,105 @@
+package health_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/repository/mocks"
+	"github.com/plamen-v/tic-tac-toe/src/services/health"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("Health", func() {
+	var (
+		db                   *sql.DB
+		mock                 sqlmock.Sqlmock
+		ctx                  context.Context
+		mockSchemaRepository *mocks.MockSchemaRepository
+		healthService        health.HealthService
+		err                  error
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		db, mock, err = sqlmock.New(sqlmock.MonitorPingsOption(true))
+		Expect(err).ToNot(HaveOccurred())
+		mockSchemaRepository = new(mocks.MockSchemaRepository)
+		healthService = health.NewHealthService(db, func(q repository.Querier) repository.SchemaRepository {
+			return mockSchemaRepository
+		})
+	})
+
+	AfterEach(func() {
+		err = mock.ExpectationsWereMet()
+		Expect(err).ToNot(HaveOccurred())
+		db.Close()
+	})
+
+	It("should be alive without touching the database", func() {
+		report := healthService.Liveness(ctx)
+
+		Expect(report.OK()).To(BeTrue())
+	})
+
+	It("should be ready if the database is reachable and the schema is current", func() {
+		mock.ExpectPing()
+		mockSchemaRepository.On("GetVersion", tmock.Anything).Return(repository.SchemaVersion, nil)
+
+		report := healthService.Readiness(ctx)
+
+		Expect(report.OK()).To(BeTrue())
+		Expect(report.Checks).To(HaveKeyWithValue(health.DatabaseCheckName, string(health.StatusOK)))
+		Expect(report.Checks).To(HaveKeyWithValue(health.MigrationCheckName, string(health.StatusOK)))
+		Expect(report.Checks).To(HaveKeyWithValue(health.ShutdownCheckName, string(health.StatusOK)))
+	})
+
+	It("should not be ready if the database is unreachable", func() {
+		mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+
+		report := healthService.Readiness(ctx)
+
+		Expect(report.OK()).To(BeFalse())
+		Expect(report.Checks).To(HaveKeyWithValue(health.DatabaseCheckName, health.UnreachableMessage))
+		mockSchemaRepository.AssertNotCalled(GinkgoT(), "GetVersion", tmock.Anything)
+	})
+
+	It("should not be ready if the schema is behind", func() {
+		mock.ExpectPing()
+		mockSchemaRepository.On("GetVersion", tmock.Anything).Return(repository.SchemaVersion-1, nil)
+
+		report := healthService.Readiness(ctx)
+
+		Expect(report.OK()).To(BeFalse())
+		Expect(report.Checks[health.MigrationCheckName]).ToNot(Equal(string(health.StatusOK)))
+	})
+
+	It("should not be ready if the schema version can't be read", func() {
+		mock.ExpectPing()
+		mockSchemaRepository.On("GetVersion", tmock.Anything).Return(0, models.NewGenericError("relation does not exist"))
+
+		report := healthService.Readiness(ctx)
+
+		Expect(report.OK()).To(BeFalse())
+		Expect(report.Checks).To(HaveKeyWithValue(health.MigrationCheckName, health.UnreachableMessage))
+	})
+
+	It("should not be ready while shutting down but still be healthy", func() {
+		mock.ExpectPing()
+		mock.ExpectPing()
+		mockSchemaRepository.On("GetVersion", tmock.Anything).Return(repository.SchemaVersion, nil)
+		healthService.SetShuttingDown()
+
+		readiness := healthService.Readiness(ctx)
+		healthReport := healthService.Health(ctx)
+
+		Expect(readiness.OK()).To(BeFalse())
+		Expect(readiness.Checks).To(HaveKeyWithValue(health.ShutdownCheckName, health.ShuttingDownMessage))
+		Expect(healthReport.OK()).To(BeTrue())
+	})
+})
@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/plamen-v/tic-tac-toe/src/services/health"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockHealthService struct {
+	mock.Mock
+}
+
+func (m *MockHealthService) Liveness(ctx context.Context) *health.Report {
+	args := m.Called(ctx)
+	return args.Get(0).(*health.Report)
+}
+
+func (m *MockHealthService) Readiness(ctx context.Context) *health.Report {
+	args := m.Called(ctx)
+	return args.Get(0).(*health.Report)
+}
+
+func (m *MockHealthService) Health(ctx context.Context) *health.Report {
+	args := m.Called(ctx)
+	return args.Get(0).(*health.Report)
+}
+
+func (m *MockHealthService) SetShuttingDown() {
+	m.Called()
+}
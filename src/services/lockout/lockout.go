@@ -0,0 +1,230 @@
+package lockout
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/apperrors"
+	"github.com/plamen-v/tic-tac-toe/src/config"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/services/logger"
+)
+
+const (
+	AccountLockedErrorMessage string = "account is temporarily locked"
+	// AttemptPruneInterval is how often the login attempts older than the
+	// retention period are deleted.
+	AttemptPruneInterval time.Duration = time.Hour
+)
+
+// LockoutService tracks failed logins per account and per address. Accounts
+// are locked for an exponentially growing period after repeated failures and
+// the counters are cleared by a successful login.
+type LockoutService interface {
+	Check(context.Context, uuid.UUID) error
+	RecordFailure(context.Context, string, *uuid.UUID, string) error
+	RecordSuccess(context.Context, string, uuid.UUID, string) error
+	GetLockout(context.Context, uuid.UUID) (*domain.Lockout, error)
+	GetLockouts(context.Context) ([]*domain.Lockout, error)
+	Unlock(context.Context, uuid.UUID) error
+}
+
+func NewLockoutService(configuration config.LockoutConfiguration,
+	db *sql.DB,
+	loginAttemptRepositoryFactory func(q repository.Querier) repository.LoginAttemptRepository,
+	lockoutRepositoryFactory func(q repository.Querier) repository.LockoutRepository) LockoutService {
+	return &lockoutServiceImpl{
+		configuration:                 configuration,
+		db:                            db,
+		loginAttemptRepositoryFactory: loginAttemptRepositoryFactory,
+		lockoutRepositoryFactory:      lockoutRepositoryFactory,
+	}
+}
+
+type lockoutServiceImpl struct {
+	configuration                 config.LockoutConfiguration
+	db                            *sql.DB
+	loginAttemptRepositoryFactory func(q repository.Querier) repository.LoginAttemptRepository
+	lockoutRepositoryFactory      func(q repository.Querier) repository.LockoutRepository
+	pruneMu                       sync.Mutex
+	lastPrune                     time.Time
+}
+
+// Check returns a TooManyRequestsError while the account is locked.
+func (s *lockoutServiceImpl) Check(ctx context.Context, playerID uuid.UUID) error {
+	lockout, err := s.lockoutRepositoryFactory(s.db).Get(ctx, playerID, false)
+	if err != nil {
+		if models.IsNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+
+	now := time.Now()
+	if lockout.IsLocked(now) {
+		return apperrors.NewTooManyRequestsError(lockout.LockedUntil.Sub(now), AccountLockedErrorMessage)
+	}
+
+	return nil
+}
+
+// RecordFailure stores a failed attempt for login from ip. playerID is nil
+// when the login does not belong to any account. Failures while the account
+// is already locked are recorded but do not extend the lockout.
+func (s *lockoutServiceImpl) RecordFailure(ctx context.Context, login string, playerID *uuid.UUID, ip string) error {
+	now := time.Now()
+	var locked *domain.Lockout
+	err := repository.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		err := s.loginAttemptRepositoryFactory(tx).Create(ctx, &domain.LoginAttempt{
+			Login:       login,
+			PlayerID:    playerID,
+			IP:          ip,
+			Succeeded:   false,
+			AttemptedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+
+		if playerID == nil {
+			return nil
+		}
+
+		lockoutRepository := s.lockoutRepositoryFactory(tx)
+		lockout, err := lockoutRepository.Get(ctx, *playerID, true)
+		if err != nil {
+			if !models.IsNotFoundError(err) {
+				return err
+			}
+			lockout = &domain.Lockout{PlayerID: *playerID, Login: login}
+		}
+
+		if lockout.IsLocked(now) {
+			return nil
+		}
+
+		lockout.FailedAttempts++
+		lockout.LastFailedAt = &now
+		if lockout.FailedAttempts >= s.configuration.Threshold {
+			lockedUntil := now.Add(s.lockoutDuration(lockout.LockoutCount))
+			lockout.LockedUntil = &lockedUntil
+			lockout.LockoutCount++
+			lockout.FailedAttempts = 0
+			locked = lockout
+		}
+
+		return lockoutRepository.Save(ctx, lockout)
+	})
+	if err != nil {
+		return err
+	}
+
+	log := logger.FromContext(ctx)
+	if locked != nil {
+		log.Warn("account locked",
+			logger.String("login", login),
+			logger.String("player_id", locked.PlayerID.String()),
+			logger.String("locked_until", locked.LockedUntil.Format(time.RFC3339)),
+			logger.Int("lockout_count", locked.LockoutCount))
+	}
+
+	s.pruneAttempts(ctx, now)
+
+	accounts, err := s.loginAttemptRepositoryFactory(s.db).CountFailedLoginsByIP(ctx, ip, now.Add(-s.configuration.AnomalyWindow))
+	if err != nil {
+		return err
+	}
+	if accounts >= s.configuration.AnomalyThreshold {
+		log.Warn("suspicious login activity",
+			logger.String("ip", ip),
+			logger.Int("accounts", accounts),
+			logger.Duration("window", s.configuration.AnomalyWindow))
+	}
+
+	return nil
+}
+
+// RecordSuccess stores a successful attempt and clears the account counters.
+func (s *lockoutServiceImpl) RecordSuccess(ctx context.Context, login string, playerID uuid.UUID, ip string) error {
+	now := time.Now()
+	err := repository.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		err := s.loginAttemptRepositoryFactory(tx).Create(ctx, &domain.LoginAttempt{
+			Login:       login,
+			PlayerID:    &playerID,
+			IP:          ip,
+			Succeeded:   true,
+			AttemptedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.lockoutRepositoryFactory(tx).Delete(ctx, playerID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.pruneAttempts(ctx, now)
+	return nil
+}
+
+// pruneAttempts deletes the attempts older than the retention period, at
+// most once per AttemptPruneInterval. A failure is logged and does not fail
+// the login.
+func (s *lockoutServiceImpl) pruneAttempts(ctx context.Context, now time.Time) {
+	s.pruneMu.Lock()
+	if now.Sub(s.lastPrune) < AttemptPruneInterval {
+		s.pruneMu.Unlock()
+		return
+	}
+	s.lastPrune = now
+	s.pruneMu.Unlock()
+
+	deleted, err := s.loginAttemptRepositoryFactory(s.db).DeleteBefore(ctx, now.Add(-s.configuration.AttemptRetention))
+	if err != nil {
+		logger.FromContext(ctx).Error("pruning login attempts failed", logger.Err(err))
+		return
+	}
+
+	if deleted > 0 {
+		logger.FromContext(ctx).Info("login attempts pruned", logger.Int("count", deleted))
+	}
+}
+
+func (s *lockoutServiceImpl) GetLockout(ctx context.Context, playerID uuid.UUID) (*domain.Lockout, error) {
+	return s.lockoutRepositoryFactory(s.db).Get(ctx, playerID, false)
+}
+
+func (s *lockoutServiceImpl) GetLockouts(ctx context.Context) ([]*domain.Lockout, error) {
+	return s.lockoutRepositoryFactory(s.db).GetList(ctx)
+}
+
+func (s *lockoutServiceImpl) Unlock(ctx context.Context, playerID uuid.UUID) error {
+	lockoutRepository := s.lockoutRepositoryFactory(s.db)
+	if _, err := lockoutRepository.Get(ctx, playerID, false); err != nil {
+		return err
+	}
+
+	err := lockoutRepository.Delete(ctx, playerID)
+	if err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("account unlocked", logger.String("player_id", playerID.String()))
+	return nil
+}
+
+func (s *lockoutServiceImpl) lockoutDuration(lockoutCount int) time.Duration {
+	duration := s.configuration.Duration
+	for i := 0; i < lockoutCount && duration < s.configuration.MaxDuration; i++ {
+		duration *= 2
+	}
+
+	return min(duration, s.configuration.MaxDuration)
+}
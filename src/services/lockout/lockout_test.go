@@ -0,0 +1,223 @@
+package lockout_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofrs/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/apperrors"
+	"github.com/plamen-v/tic-tac-toe/src/config"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/repository/mocks"
+	"github.com/plamen-v/tic-tac-toe/src/services/lockout"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("Lockout", func() {
+	var (
+		db                         *sql.DB
+		mock                       sqlmock.Sqlmock
+		ctx                        context.Context
+		mockLoginAttemptRepository *mocks.MockLoginAttemptRepository
+		mockLockoutRepository      *mocks.MockLockoutRepository
+		lockoutService             lockout.LockoutService
+		playerID                   uuid.UUID
+		err                        error
+	)
+
+	configuration := config.LockoutConfiguration{
+		Threshold:        3,
+		Duration:         time.Minute,
+		MaxDuration:      3 * time.Minute,
+		AnomalyWindow:    time.Minute,
+		AnomalyThreshold: 5,
+		AttemptRetention: time.Hour,
+	}
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		db, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		mockLoginAttemptRepository = new(mocks.MockLoginAttemptRepository)
+		mockLoginAttemptRepository.On("DeleteBefore", tmock.Anything, tmock.Anything).Return(0, nil).Maybe()
+		mockLockoutRepository = new(mocks.MockLockoutRepository)
+		playerID = uuid.Must(uuid.NewV4())
+		lockoutService = lockout.NewLockoutService(
+			configuration,
+			db,
+			func(q repository.Querier) repository.LoginAttemptRepository {
+				return mockLoginAttemptRepository
+			},
+			func(q repository.Querier) repository.LockoutRepository {
+				return mockLockoutRepository
+			},
+		)
+	})
+
+	AfterEach(func() {
+		err = mock.ExpectationsWereMet()
+		Expect(err).ToNot(HaveOccurred())
+		db.Close()
+	})
+
+	Context("Check", func() {
+		It("should allow accounts without failures", func() {
+			mockLockoutRepository.On("Get", ctx, playerID, false).Return(nil, models.NewNotFoundError("not found"))
+
+			Expect(lockoutService.Check(ctx, playerID)).To(Succeed())
+		})
+
+		It("should reject locked accounts with the remaining time", func() {
+			lockedUntil := time.Now().Add(time.Minute)
+			mockLockoutRepository.On("Get", ctx, playerID, false).Return(&domain.Lockout{PlayerID: playerID, LockedUntil: &lockedUntil}, nil)
+
+			err := lockoutService.Check(ctx, playerID)
+
+			var tooManyRequestsError *apperrors.TooManyRequestsError
+			Expect(err).To(BeAssignableToTypeOf(tooManyRequestsError))
+			tooManyRequestsError = err.(*apperrors.TooManyRequestsError)
+			Expect(tooManyRequestsError.RetryAfter).To(BeNumerically("~", time.Minute, time.Second))
+		})
+
+		It("should allow accounts whose lockout expired", func() {
+			lockedUntil := time.Now().Add(-time.Second)
+			mockLockoutRepository.On("Get", ctx, playerID, false).Return(&domain.Lockout{PlayerID: playerID, LockedUntil: &lockedUntil}, nil)
+
+			Expect(lockoutService.Check(ctx, playerID)).To(Succeed())
+		})
+	})
+
+	Context("RecordFailure", func() {
+		BeforeEach(func() {
+			mockLoginAttemptRepository.On("Create", ctx, tmock.Anything).Return(nil)
+			mockLoginAttemptRepository.On("CountFailedLoginsByIP", ctx, "192.0.2.1", tmock.Anything).Return(1, nil)
+		})
+
+		It("should count a failure without locking below the threshold", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			mockLockoutRepository.On("Get", ctx, playerID, true).Return(nil, models.NewNotFoundError("not found"))
+			mockLockoutRepository.On("Save", ctx, tmock.MatchedBy(func(l *domain.Lockout) bool {
+				return l.FailedAttempts == 1 && l.LockedUntil == nil
+			})).Return(nil)
+
+			Expect(lockoutService.RecordFailure(ctx, "player_1", &playerID, "192.0.2.1")).To(Succeed())
+			mockLockoutRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should lock the account when the threshold is reached", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			mockLockoutRepository.On("Get", ctx, playerID, true).Return(&domain.Lockout{PlayerID: playerID, FailedAttempts: 2}, nil)
+			mockLockoutRepository.On("Save", ctx, tmock.MatchedBy(func(l *domain.Lockout) bool {
+				return l.FailedAttempts == 0 && l.LockoutCount == 1 &&
+					l.LockedUntil != nil && time.Until(*l.LockedUntil) > 50*time.Second && time.Until(*l.LockedUntil) <= time.Minute
+			})).Return(nil)
+
+			Expect(lockoutService.RecordFailure(ctx, "player_1", &playerID, "192.0.2.1")).To(Succeed())
+			mockLockoutRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should back off exponentially up to the maximum", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			mockLockoutRepository.On("Get", ctx, playerID, true).Return(&domain.Lockout{PlayerID: playerID, FailedAttempts: 2, LockoutCount: 5}, nil)
+			mockLockoutRepository.On("Save", ctx, tmock.MatchedBy(func(l *domain.Lockout) bool {
+				return l.LockoutCount == 6 && l.LockedUntil != nil &&
+					time.Until(*l.LockedUntil) > 2*time.Minute && time.Until(*l.LockedUntil) <= 3*time.Minute
+			})).Return(nil)
+
+			Expect(lockoutService.RecordFailure(ctx, "player_1", &playerID, "192.0.2.1")).To(Succeed())
+			mockLockoutRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should not extend an active lockout", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			lockedUntil := time.Now().Add(time.Minute)
+			mockLockoutRepository.On("Get", ctx, playerID, true).Return(&domain.Lockout{PlayerID: playerID, LockoutCount: 1, LockedUntil: &lockedUntil}, nil)
+
+			Expect(lockoutService.RecordFailure(ctx, "player_1", &playerID, "192.0.2.1")).To(Succeed())
+			mockLockoutRepository.AssertNotCalled(GinkgoT(), "Save", tmock.Anything, tmock.Anything)
+		})
+
+		It("should only record the attempt for unknown logins", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+
+			Expect(lockoutService.RecordFailure(ctx, "unknown", nil, "192.0.2.1")).To(Succeed())
+			mockLoginAttemptRepository.AssertCalled(GinkgoT(), "Create", ctx, tmock.MatchedBy(func(a *domain.LoginAttempt) bool {
+				return a.Login == "unknown" && a.PlayerID == nil && !a.Succeeded
+			}))
+			mockLockoutRepository.AssertNotCalled(GinkgoT(), "Get", tmock.Anything, tmock.Anything, tmock.Anything)
+		})
+
+		It("should roll back when the lockout can not be saved", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+			mockLockoutRepository.On("Get", ctx, playerID, true).Return(nil, models.NewNotFoundError("not found"))
+			mockLockoutRepository.On("Save", ctx, tmock.Anything).Return(models.NewGenericError("server error"))
+
+			Expect(lockoutService.RecordFailure(ctx, "player_1", &playerID, "192.0.2.1")).NotTo(Succeed())
+		})
+	})
+
+	Context("RecordSuccess", func() {
+		It("should record the attempt and clear the counters", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			mockLoginAttemptRepository.On("Create", ctx, tmock.MatchedBy(func(a *domain.LoginAttempt) bool {
+				return a.Succeeded && *a.PlayerID == playerID
+			})).Return(nil)
+			mockLockoutRepository.On("Delete", ctx, playerID).Return(nil)
+
+			Expect(lockoutService.RecordSuccess(ctx, "player_1", playerID, "192.0.2.1")).To(Succeed())
+			mockLockoutRepository.AssertExpectations(GinkgoT())
+		})
+	})
+
+	Context("retention", func() {
+		BeforeEach(func() {
+			mockLoginAttemptRepository.On("Create", ctx, tmock.Anything).Return(nil)
+			mockLockoutRepository.On("Delete", ctx, playerID).Return(nil)
+		})
+
+		It("should delete the attempts older than the retention period once per interval", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+
+			Expect(lockoutService.RecordSuccess(ctx, "player_1", playerID, "192.0.2.1")).To(Succeed())
+			Expect(lockoutService.RecordSuccess(ctx, "player_1", playerID, "192.0.2.1")).To(Succeed())
+
+			mockLoginAttemptRepository.AssertNumberOfCalls(GinkgoT(), "DeleteBefore", 1)
+			mockLoginAttemptRepository.AssertCalled(GinkgoT(), "DeleteBefore", ctx, tmock.MatchedBy(func(before time.Time) bool {
+				return time.Since(before) > 59*time.Minute && time.Since(before) <= time.Hour+time.Minute
+			}))
+		})
+	})
+
+	Context("Unlock", func() {
+		It("should delete the lockout", func() {
+			mockLockoutRepository.On("Get", ctx, playerID, false).Return(&domain.Lockout{PlayerID: playerID}, nil)
+			mockLockoutRepository.On("Delete", ctx, playerID).Return(nil)
+
+			Expect(lockoutService.Unlock(ctx, playerID)).To(Succeed())
+			mockLockoutRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should return not found if the account is not tracked", func() {
+			mockLockoutRepository.On("Get", ctx, playerID, false).Return(nil, models.NewNotFoundError("not found"))
+
+			err := lockoutService.Unlock(ctx, playerID)
+			Expect(models.IsNotFoundError(err)).To(BeTrue())
+		})
+	})
+})
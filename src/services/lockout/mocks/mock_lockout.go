@@ -0,0 +1,49 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLockoutService struct {
+	mock.Mock
+}
+
+func (m *MockLockoutService) Check(ctx context.Context, playerID uuid.UUID) error {
+	args := m.Called(ctx, playerID)
+	return args.Error(0)
+}
+
+func (m *MockLockoutService) RecordFailure(ctx context.Context, login string, playerID *uuid.UUID, ip string) error {
+	args := m.Called(ctx, login, playerID, ip)
+	return args.Error(0)
+}
+
+func (m *MockLockoutService) RecordSuccess(ctx context.Context, login string, playerID uuid.UUID, ip string) error {
+	args := m.Called(ctx, login, playerID, ip)
+	return args.Error(0)
+}
+
+func (m *MockLockoutService) GetLockout(ctx context.Context, playerID uuid.UUID) (*domain.Lockout, error) {
+	args := m.Called(ctx, playerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Lockout), args.Error(1)
+}
+
+func (m *MockLockoutService) GetLockouts(ctx context.Context) ([]*domain.Lockout, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Lockout), args.Error(1)
+}
+
+func (m *MockLockoutService) Unlock(ctx context.Context, playerID uuid.UUID) error {
+	args := m.Called(ctx, playerID)
+	return args.Error(0)
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -12,7 +13,9 @@ import (
 type LoggerService interface {
 	Info(string, ...Field)
 	Debug(string, ...Field)
+	Warn(string, ...Field)
 	Error(string, ...Field)
+	With(...Field) LoggerService
 	Sync() error
 }
 
@@ -24,6 +27,28 @@ func NewLoggerService(appMode config.AppMode, logLevel config.LogLevel) (LoggerS
 	return logger, nil
 }
 
+func NewNopLoggerService() LoggerService {
+	return &loggerService{worker: zap.NewNop()}
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying l.
+func NewContext(ctx context.Context, l LoggerService) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the request-scoped logger stored in ctx, or a no-op
+// logger when there is none.
+func FromContext(ctx context.Context) LoggerService {
+	if l, ok := ctx.Value(contextKey{}).(LoggerService); ok {
+		return l
+	}
+	return nopLogger
+}
+
+var nopLogger = NewNopLoggerService()
+
 type Field struct {
 	zapField zap.Field
 }
@@ -44,6 +69,10 @@ func Duration(key string, value time.Duration) Field {
 	return Field{zapField: zap.Duration(key, value)}
 }
 
+func Err(err error) Field {
+	return Field{zapField: zap.Error(err)}
+}
+
 type loggerService struct {
 	worker *zap.Logger
 }
@@ -53,13 +82,21 @@ func (l *loggerService) Info(msg string, fields ...Field) {
 }
 
 func (l *loggerService) Debug(msg string, fields ...Field) {
-	l.worker.Info(msg, unwrap(fields)...)
+	l.worker.Debug(msg, unwrap(fields)...)
+}
+
+func (l *loggerService) Warn(msg string, fields ...Field) {
+	l.worker.Warn(msg, unwrap(fields)...)
 }
 
 func (l *loggerService) Error(msg string, fields ...Field) {
 	l.worker.Error(msg, unwrap(fields)...)
 }
 
+func (l *loggerService) With(fields ...Field) LoggerService {
+	return &loggerService{worker: l.worker.With(unwrap(fields)...)}
+}
+
 func (l *loggerService) Sync() error {
 	return l.worker.Sync()
 }
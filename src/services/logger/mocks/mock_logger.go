@@ -15,9 +15,19 @@ func (m *MockLoggerService) Info(msg string, fields ...logger.Field) {
 func (m *MockLoggerService) Debug(msg string, fields ...logger.Field) {
 	m.Called(msg, fields)
 }
+func (m *MockLoggerService) Warn(msg string, fields ...logger.Field) {
+	m.Called(msg, fields)
+}
 func (m *MockLoggerService) Error(msg string, fields ...logger.Field) {
 	m.Called(msg, fields)
 }
+func (m *MockLoggerService) With(fields ...logger.Field) logger.LoggerService {
+	args := m.Called(fields)
+	if l, ok := args.Get(0).(logger.LoggerService); ok {
+		return l
+	}
+	return m
+}
 
 func (m *MockLoggerService) Sync() error {
 	args := m.Called()
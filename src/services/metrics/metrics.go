@@ -0,0 +1,171 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	Namespace          string = "tictactoe"
+	UnmatchedRouteName string = "unmatched"
+	// ActiveRoomsTimeout bounds the count of the rooms on a scrape.
+	ActiveRoomsTimeout time.Duration = 5 * time.Second
+)
+
+type MetricsService interface {
+	ObserveRequest(method string, route string, status int, duration time.Duration)
+	GameStarted()
+	GameCompleted(draw bool)
+	GameVoided()
+	MoveMade()
+	LoginFailed()
+	Handler() http.Handler
+}
+
+func NewMetricsService(db *sql.DB) MetricsService {
+	m := &metricsServiceImpl{
+		registry: prometheus.NewRegistry(),
+		requestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of HTTP requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		gamesStarted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "game",
+			Name:      "games_started_total",
+			Help:      "Number of games started.",
+		}),
+		gamesCompleted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "game",
+			Name:      "games_completed_total",
+			Help:      "Number of games completed by result (win or draw).",
+		}, []string{"result"}),
+		gamesVoided: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "game",
+			Name:      "games_voided_total",
+			Help:      "Number of games in progress voided by moderators.",
+		}),
+		moves: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "game",
+			Name:      "moves_total",
+			Help:      "Number of accepted moves. Use rate() for moves per second.",
+		}),
+		loginFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "auth",
+			Name:      "login_failures_total",
+			Help:      "Number of failed login attempts.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requestsTotal,
+		m.requestDuration,
+		m.gamesStarted,
+		m.gamesCompleted,
+		m.gamesVoided,
+		m.moves,
+		m.loginFailures,
+	)
+
+	if db != nil {
+		m.registry.MustRegister(
+			collectors.NewDBStatsCollector(db, Namespace),
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: "game",
+				Name:      "active_rooms",
+				Help:      "Number of rooms in the database.",
+			}, activeRooms(db)),
+		)
+	}
+
+	return m
+}
+
+type metricsServiceImpl struct {
+	registry        *prometheus.Registry
+	requestsTotal   *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	gamesStarted    prometheus.Counter
+	gamesCompleted  *prometheus.CounterVec
+	gamesVoided     prometheus.Counter
+	moves           prometheus.Counter
+	loginFailures   prometheus.Counter
+}
+
+func (m *metricsServiceImpl) ObserveRequest(method string, route string, status int, duration time.Duration) {
+	if len(route) == 0 {
+		route = UnmatchedRouteName
+	}
+	statusStr := strconv.Itoa(status)
+	m.requestsTotal.WithLabelValues(method, route, statusStr).Inc()
+	m.requestDuration.WithLabelValues(method, route, statusStr).Observe(duration.Seconds())
+}
+
+func (m *metricsServiceImpl) GameStarted() {
+	m.gamesStarted.Inc()
+}
+
+func (m *metricsServiceImpl) GameCompleted(draw bool) {
+	if draw {
+		m.gamesCompleted.WithLabelValues("draw").Inc()
+	} else {
+		m.gamesCompleted.WithLabelValues("win").Inc()
+	}
+}
+
+func (m *metricsServiceImpl) GameVoided() {
+	m.gamesVoided.Inc()
+}
+
+func (m *metricsServiceImpl) MoveMade() {
+	m.moves.Inc()
+}
+
+func (m *metricsServiceImpl) LoginFailed() {
+	m.loginFailures.Inc()
+}
+
+func (m *metricsServiceImpl) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// activeRooms counts the rooms in the database rather than in the process,
+// so that every instance reports the same number. A failed count is reported
+// as NaN.
+func activeRooms(db *sql.DB) func() float64 {
+	return func() float64 {
+		ctx, cancel := context.WithTimeout(context.Background(), ActiveRoomsTimeout)
+		defer cancel()
+
+		var count int
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM rooms").Scan(&count); err != nil {
+			return math.NaN()
+		}
+
+		return float64(count)
+	}
+}
@@ -0,0 +1,44 @@
+package mocks
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMetricsService struct {
+	mock.Mock
+}
+
+func (m *MockMetricsService) ObserveRequest(method string, route string, status int, duration time.Duration) {
+	m.Called(method, route, status, duration)
+}
+
+func (m *MockMetricsService) GameStarted() {
+	m.Called()
+}
+
+func (m *MockMetricsService) GameCompleted(draw bool) {
+	m.Called(draw)
+}
+
+func (m *MockMetricsService) GameVoided() {
+	m.Called()
+}
+
+func (m *MockMetricsService) MoveMade() {
+	m.Called()
+}
+
+func (m *MockMetricsService) LoginFailed() {
+	m.Called()
+}
+
+func (m *MockMetricsService) Handler() http.Handler {
+	args := m.Called()
+	if handler, ok := args.Get(0).(http.Handler); ok {
+		return handler
+	}
+	return nil
+}
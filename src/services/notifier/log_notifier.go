@@ -0,0 +1,26 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/plamen-v/tic-tac-toe/src/services/logger"
+)
+
+// NewLogNotifier returns a notifier that writes messages to the log instead
+// of sending them. Messages may carry secrets such as reset tokens, so it is
+// meant for development only and is rejected in production.
+func NewLogNotifier(logger logger.LoggerService) Notifier {
+	return &logNotifierImpl{logger: logger}
+}
+
+type logNotifierImpl struct {
+	logger logger.LoggerService
+}
+
+func (n *logNotifierImpl) Notify(ctx context.Context, message *Message) error {
+	n.logger.Info("notification",
+		logger.String("to", message.To),
+		logger.String("subject", message.Subject),
+		logger.String("body", message.Body))
+	return nil
+}
@@ -0,0 +1,32 @@
+package notifier_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe/src/services/logger"
+	"github.com/plamen-v/tic-tac-toe/src/services/logger/mocks"
+	"github.com/plamen-v/tic-tac-toe/src/services/notifier"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("LogNotifier", func() {
+	It("should log the whole message", func() {
+		var fields []logger.Field
+		mockLogger := &mocks.MockLoggerService{}
+		mockLogger.On("Info", "notification", tmock.Anything).
+			Run(func(args tmock.Arguments) { fields = args.Get(1).([]logger.Field) })
+
+		err := notifier.NewLogNotifier(mockLogger).Notify(context.Background(), &notifier.Message{
+			To:      "player@example.com",
+			Subject: "Password reset",
+			Body:    "Use https://example.com/reset?token=s3cr3t to reset.",
+		})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fields).To(ContainElements(
+			logger.String("to", "player@example.com"),
+			logger.String("body", "Use https://example.com/reset?token=s3cr3t to reset.")))
+	})
+})
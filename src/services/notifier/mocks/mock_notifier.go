@@ -0,0 +1,17 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/plamen-v/tic-tac-toe/src/services/notifier"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, message *notifier.Message) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
@@ -0,0 +1,33 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/plamen-v/tic-tac-toe/src/config"
+	"github.com/plamen-v/tic-tac-toe/src/services/logger"
+)
+
+// Message is a plain text message to a single recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers messages to players out of band, for example password
+// reset links.
+type Notifier interface {
+	Notify(context.Context, *Message) error
+}
+
+func NewNotifier(configuration config.NotifierConfiguration, logger logger.LoggerService) (Notifier, error) {
+	switch configuration.Type {
+	case config.LogNotifierType:
+		return NewLogNotifier(logger), nil
+	case config.SMTPNotifierType:
+		return NewSMTPNotifier(configuration.SMTP), nil
+	default:
+		return nil, fmt.Errorf("unknown notifier type '%s'", configuration.Type)
+	}
+}
@@ -0,0 +1,58 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/config"
+)
+
+// NewSMTPNotifier returns a notifier that sends messages as plain text mail.
+func NewSMTPNotifier(configuration config.SMTPConfiguration) Notifier {
+	return &smtpNotifierImpl{configuration: configuration}
+}
+
+type smtpNotifierImpl struct {
+	configuration config.SMTPConfiguration
+}
+
+func (n *smtpNotifierImpl) Notify(ctx context.Context, message *Message) error {
+	if strings.ContainsAny(message.To, "\r\n") || strings.ContainsAny(message.Subject, "\r\n") {
+		return models.NewValidationError("message headers must not contain line breaks")
+	}
+
+	var auth smtp.Auth
+	if len(n.configuration.Username) > 0 {
+		auth = smtp.PlainAuth("", n.configuration.Username, n.configuration.Password, n.configuration.Host)
+	}
+
+	addr := net.JoinHostPort(n.configuration.Host, strconv.Itoa(n.configuration.Port))
+	if err := smtp.SendMail(addr, auth, n.configuration.From, []string{message.To}, n.format(message)); err != nil {
+		return models.NewGenericError(fmt.Sprintf("sending mail failed: %s", err.Error()))
+	}
+
+	return nil
+}
+
+func (n *smtpNotifierImpl) format(message *Message) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", n.configuration.From)
+	fmt.Fprintf(&b, "To: %s\r\n", message.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(message.Body, "\r\n", "\n"), "\n", "\r\n"))
+	b.WriteString("\r\n")
+
+	return b.Bytes()
+}
@@ -0,0 +1,151 @@
+package notifier_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/config"
+	"github.com/plamen-v/tic-tac-toe/src/services/notifier"
+)
+
+// smtpStub is a minimal SMTP server accepting a single message.
+type smtpStub struct {
+	listener   net.Listener
+	from       string
+	recipients []string
+	data       string
+	done       chan struct{}
+}
+
+func newSMTPStub() *smtpStub {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred())
+
+	stub := &smtpStub{listener: listener, done: make(chan struct{})}
+	go stub.serve()
+	return stub
+}
+
+func (s *smtpStub) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStub) serve() {
+	defer close(s.done)
+
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	_ = text.PrintfLine("220 localhost ESMTP stub")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch command {
+		case "EHLO", "HELO":
+			_ = text.PrintfLine("250 localhost")
+		case "MAIL":
+			s.from = strings.Trim(strings.TrimPrefix(line[len("MAIL "):], "FROM:"), "<>")
+			_ = text.PrintfLine("250 OK")
+		case "RCPT":
+			s.recipients = append(s.recipients, strings.Trim(strings.TrimPrefix(line[len("RCPT "):], "TO:"), "<>"))
+			_ = text.PrintfLine("250 OK")
+		case "DATA":
+			_ = text.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.data = string(data)
+			_ = text.PrintfLine("250 OK")
+		case "QUIT":
+			_ = text.PrintfLine("221 Bye")
+			return
+		default:
+			_ = text.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+var _ = Describe("SMTPNotifier", func() {
+	var (
+		ctx  context.Context
+		stub *smtpStub
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		stub = newSMTPStub()
+	})
+
+	AfterEach(func() {
+		stub.listener.Close()
+	})
+
+	It("should send the message", func() {
+		smtpNotifier := notifier.NewSMTPNotifier(config.SMTPConfiguration{
+			Host: "127.0.0.1",
+			Port: stub.port(),
+			From: "noreply@example.com",
+		})
+
+		err := smtpNotifier.Notify(ctx, &notifier.Message{
+			To:      "player@example.com",
+			Subject: "Reset your password",
+			Body:    "Open the link.\n.\nThanks",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(stub.done).Should(BeClosed())
+
+		Expect(stub.from).To(Equal("noreply@example.com"))
+		Expect(stub.recipients).To(Equal([]string{"player@example.com"}))
+
+		message, err := textproto.NewReader(bufio.NewReader(strings.NewReader(stub.data))).ReadMIMEHeader()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(message.Get("Subject")).To(Equal("Reset your password"))
+		Expect(message.Get("To")).To(Equal("player@example.com"))
+		Expect(stub.data).To(HaveSuffix("\n\nOpen the link.\n.\nThanks\n"))
+	})
+
+	It("should reject line breaks in headers", func() {
+		smtpNotifier := notifier.NewSMTPNotifier(config.SMTPConfiguration{
+			Host: "127.0.0.1",
+			Port: stub.port(),
+			From: "noreply@example.com",
+		})
+
+		err := smtpNotifier.Notify(ctx, &notifier.Message{
+			To:      "player@example.com\r\nBcc: other@example.com",
+			Subject: "Reset your password",
+		})
+		Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+	})
+
+	It("should return an error when the server is not reachable", func() {
+		port := stub.port()
+		stub.listener.Close()
+		Eventually(stub.done).Should(BeClosed())
+
+		smtpNotifier := notifier.NewSMTPNotifier(config.SMTPConfiguration{
+			Host: "127.0.0.1",
+			Port: port,
+			From: "noreply@example.com",
+		})
+
+		err := smtpNotifier.Notify(ctx, &notifier.Message{To: "player@example.com", Subject: "Subject"})
+		Expect(err).To(BeAssignableToTypeOf(&models.GenericError{}))
+	})
+})
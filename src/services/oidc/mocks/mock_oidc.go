@@ -0,0 +1,37 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/services/oidc"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOIDCService struct {
+	mock.Mock
+}
+
+func (m *MockOIDCService) Providers() []string {
+	args := m.Called()
+	if providers, ok := args.Get(0).([]string); ok {
+		return providers
+	}
+	return nil
+}
+
+func (m *MockOIDCService) Begin(ctx context.Context, provider string) (string, *oidc.Session, error) {
+	args := m.Called(ctx, provider)
+	if session, ok := args.Get(1).(*oidc.Session); ok {
+		return args.String(0), session, nil
+	}
+	return "", nil, args.Error(2)
+}
+
+func (m *MockOIDCService) Complete(ctx context.Context, provider string, session *oidc.Session, state string, code string) (*models.Player, error) {
+	args := m.Called(ctx, provider, session, state, code)
+	if player, ok := args.Get(0).(*models.Player); ok {
+		return player, nil
+	}
+	return nil, args.Error(1)
+}
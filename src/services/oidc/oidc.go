@@ -0,0 +1,294 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	goidc "github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/config"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/services/logger"
+	"golang.org/x/oauth2"
+)
+
+const (
+	SessionCookieName   string = "oidc_session"
+	InvalidStateMessage string = "oidc state is invalid"
+	NicknameMaxLength   int    = 30
+	DefaultNickname     string = "player"
+	nicknameAttempts    int    = 5
+)
+
+var nicknameDisallowed = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// Session is the per-login state kept by the client between Begin and
+// Complete, usually in a short lived cookie.
+type Session struct {
+	State    string
+	Nonce    string
+	Verifier string
+}
+
+func (s *Session) Encode() string {
+	return strings.Join([]string{s.State, s.Nonce, s.Verifier}, ".")
+}
+
+func DecodeSession(value string) (*Session, error) {
+	parts := strings.Split(value, ".")
+	if len(parts) != 3 || len(parts[0]) == 0 || len(parts[1]) == 0 || len(parts[2]) == 0 {
+		return nil, models.NewAuthorizationError(InvalidStateMessage)
+	}
+
+	return &Session{State: parts[0], Nonce: parts[1], Verifier: parts[2]}, nil
+}
+
+// OIDCService implements the OpenID Connect authorization code flow with
+// PKCE. Begin returns the provider URL to send the player to; Complete
+// exchanges the code from the callback, verifies the ID token and returns the
+// player linked to the external identity, creating one on first login.
+type OIDCService interface {
+	Providers() []string
+	Begin(context.Context, string) (string, *Session, error)
+	Complete(context.Context, string, *Session, string, string) (*models.Player, error)
+}
+
+func NewOIDCService(configuration config.OIDCConfiguration,
+	db *sql.DB,
+	playerRepositoryFactory func(q repository.Querier) repository.PlayerRepository,
+	identityRepositoryFactory func(q repository.Querier) repository.IdentityRepository) OIDCService {
+	return &oidcServiceImpl{
+		configuration:             configuration,
+		db:                        db,
+		playerRepositoryFactory:   playerRepositoryFactory,
+		identityRepositoryFactory: identityRepositoryFactory,
+		clients:                   map[string]*client{},
+	}
+}
+
+type oidcServiceImpl struct {
+	configuration             config.OIDCConfiguration
+	db                        *sql.DB
+	playerRepositoryFactory   func(q repository.Querier) repository.PlayerRepository
+	identityRepositoryFactory func(q repository.Querier) repository.IdentityRepository
+
+	mu      sync.Mutex
+	clients map[string]*client
+}
+
+type client struct {
+	oauth2   oauth2.Config
+	verifier *goidc.IDTokenVerifier
+}
+
+type claims struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+}
+
+func (s *oidcServiceImpl) Providers() []string {
+	providers := make([]string, 0, len(s.configuration.Providers))
+	for _, provider := range s.configuration.Providers {
+		providers = append(providers, provider.Name)
+	}
+
+	return providers
+}
+
+func (s *oidcServiceImpl) Begin(ctx context.Context, provider string) (string, *Session, error) {
+	c, err := s.client(ctx, provider)
+	if err != nil {
+		return "", nil, err
+	}
+
+	session := &Session{
+		State:    randomString(),
+		Nonce:    randomString(),
+		Verifier: oauth2.GenerateVerifier(),
+	}
+
+	url := c.oauth2.AuthCodeURL(session.State,
+		goidc.Nonce(session.Nonce),
+		oauth2.S256ChallengeOption(session.Verifier))
+
+	return url, session, nil
+}
+
+func (s *oidcServiceImpl) Complete(ctx context.Context, provider string, session *Session, state string, code string) (*models.Player, error) {
+	if session == nil || len(state) == 0 || state != session.State {
+		return nil, models.NewAuthorizationError(InvalidStateMessage)
+	}
+
+	if len(code) == 0 {
+		return nil, models.NewValidationError("authorization code is required")
+	}
+
+	c, err := s.client(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := c.oauth2.Exchange(ctx, code, oauth2.VerifierOption(session.Verifier))
+	if err != nil {
+		return nil, models.NewAuthorizationErrorf("code exchange failed: %s", err.Error())
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, models.NewAuthorizationError("id token is missing")
+	}
+
+	idToken, err := c.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, models.NewAuthorizationError(err.Error())
+	}
+
+	if idToken.Nonce != session.Nonce {
+		return nil, models.NewAuthorizationError("id token nonce is invalid")
+	}
+
+	var externalClaims claims
+	if err = idToken.Claims(&externalClaims); err != nil {
+		return nil, models.NewAuthorizationError(err.Error())
+	}
+
+	return s.link(ctx, provider, idToken.Issuer, &externalClaims)
+}
+
+// link returns the player of the external identity, creating the player and
+// the identity when the identity is seen for the first time.
+func (s *oidcServiceImpl) link(ctx context.Context, provider string, issuer string, externalClaims *claims) (*models.Player, error) {
+	return repository.WithTransactionT(ctx, s.db, func(tx *sql.Tx) (*models.Player, error) {
+		playerRepository := s.playerRepositoryFactory(tx)
+		identityRepository := s.identityRepositoryFactory(tx)
+
+		identity, err := identityRepository.Get(ctx, issuer, externalClaims.Subject)
+		if err == nil {
+			return playerRepository.Get(ctx, identity.PlayerID)
+		}
+		if !models.IsNotFoundError(err) {
+			return nil, err
+		}
+
+		nickname, err := s.nickname(ctx, playerRepository, externalClaims)
+		if err != nil {
+			return nil, err
+		}
+
+		player := &models.Player{
+			Login:    fmt.Sprintf("%s:%s", provider, externalClaims.Subject),
+			Nickname: nickname,
+		}
+		if player.ID, err = playerRepository.Create(ctx, player); err != nil {
+			return nil, err
+		}
+
+		identity = &domain.Identity{
+			Issuer:   issuer,
+			Subject:  externalClaims.Subject,
+			PlayerID: player.ID,
+		}
+		if externalClaims.EmailVerified {
+			identity.Email = externalClaims.Email
+		}
+		if err = identityRepository.Create(ctx, identity); err != nil {
+			return nil, err
+		}
+
+		logger.FromContext(ctx).Info("player created from external identity",
+			logger.String("provider", provider),
+			logger.String("playerId", player.ID.String()))
+
+		return player, nil
+	})
+}
+
+// nickname derives a free nickname from the profile claims, adding a random
+// suffix when the preferred one is taken.
+func (s *oidcServiceImpl) nickname(ctx context.Context, playerRepository repository.PlayerRepository, externalClaims *claims) (string, error) {
+	base := DefaultNickname
+	for _, candidate := range []string{externalClaims.PreferredUsername, externalClaims.Name, strings.Split(externalClaims.Email, "@")[0]} {
+		if candidate = nicknameDisallowed.ReplaceAllString(candidate, ""); len(candidate) > 0 {
+			base = candidate
+			break
+		}
+	}
+
+	nickname := truncate(base, NicknameMaxLength)
+	for range nicknameAttempts {
+		exists, err := playerRepository.NicknameExists(ctx, nickname)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return nickname, nil
+		}
+
+		suffix := "-" + strings.ReplaceAll(uuid.Must(uuid.NewV4()).String(), "-", "")[:6]
+		nickname = truncate(base, NicknameMaxLength-len(suffix)) + suffix
+	}
+
+	return "", models.NewGenericError("no free nickname found")
+}
+
+func (s *oidcServiceImpl) client(ctx context.Context, provider string) (*client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.clients[provider]; ok {
+		return c, nil
+	}
+
+	for _, configuration := range s.configuration.Providers {
+		if configuration.Name != provider {
+			continue
+		}
+
+		// Discovery happens on first use so that an unreachable provider
+		// does not prevent the application from starting.
+		p, err := goidc.NewProvider(ctx, configuration.Issuer)
+		if err != nil {
+			return nil, models.NewGenericError(fmt.Sprintf("oidc provider '%s' discovery failed: %s", provider, err.Error()))
+		}
+
+		c := &client{
+			oauth2: oauth2.Config{
+				ClientID:     configuration.ClientID,
+				ClientSecret: configuration.ClientSecret,
+				RedirectURL:  configuration.RedirectURL,
+				Endpoint:     p.Endpoint(),
+				Scopes:       configuration.Scopes,
+			},
+			verifier: p.Verifier(&goidc.Config{ClientID: configuration.ClientID}),
+		}
+		s.clients[provider] = c
+
+		return c, nil
+	}
+
+	return nil, models.NewNotFoundErrorf("oidc provider '%s' not exist", provider)
+}
+
+func randomString() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func truncate(s string, length int) string {
+	if len(s) > length {
+		return s[:length]
+	}
+	return s
+}
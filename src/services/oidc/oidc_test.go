@@ -0,0 +1,210 @@
+package oidc_test
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"strings"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofrs/uuid"
+	"github.com/golang-jwt/jwt/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/config"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/repository/mocks"
+	"github.com/plamen-v/tic-tac-toe/src/services/oidc"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("OIDC", func() {
+	var (
+		db                     *sql.DB
+		mock                   sqlmock.Sqlmock
+		ctx                    context.Context
+		provider               *mockProvider
+		mockPlayerRepository   *mocks.MockPlayerRepository
+		mockIdentityRepository *mocks.MockIdentityRepository
+		oidcService            oidc.OIDCService
+		playerID               uuid.UUID
+		err                    error
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		db, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		provider = newMockProvider()
+		mockPlayerRepository = new(mocks.MockPlayerRepository)
+		mockIdentityRepository = new(mocks.MockIdentityRepository)
+		playerID = uuid.Must(uuid.NewV4())
+
+		configuration := config.OIDCConfiguration{
+			Providers: []config.OIDCProviderConfiguration{{
+				Name:        "corp",
+				Issuer:      provider.Issuer(),
+				ClientID:    mockClientID,
+				RedirectURL: "http://localhost/api/oidc/corp/callback",
+			}},
+		}
+		configuration.SetDefaults()
+
+		oidcService = oidc.NewOIDCService(
+			configuration,
+			db,
+			func(q repository.Querier) repository.PlayerRepository {
+				return mockPlayerRepository
+			},
+			func(q repository.Querier) repository.IdentityRepository {
+				return mockIdentityRepository
+			},
+		)
+	})
+
+	AfterEach(func() {
+		provider.Close()
+		err = mock.ExpectationsWereMet()
+		Expect(err).ToNot(HaveOccurred())
+		db.Close()
+	})
+
+	Context("Begin", func() {
+		It("should return the authorization url with state, nonce and PKCE challenge", func() {
+			authURL, session, err := oidcService.Begin(ctx, "corp")
+			Expect(err).ToNot(HaveOccurred())
+
+			u, err := url.Parse(authURL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(u.Path).To(Equal("/authorize"))
+			query := u.Query()
+			Expect(query.Get("client_id")).To(Equal(mockClientID))
+			Expect(query.Get("redirect_uri")).To(Equal("http://localhost/api/oidc/corp/callback"))
+			Expect(query.Get("scope")).To(Equal("openid profile email"))
+			Expect(query.Get("state")).To(Equal(session.State))
+			Expect(query.Get("nonce")).To(Equal(session.Nonce))
+			Expect(query.Get("code_challenge_method")).To(Equal("S256"))
+			Expect(query.Get("code_challenge")).ToNot(BeEmpty())
+		})
+
+		It("should reject unknown providers", func() {
+			_, _, err := oidcService.Begin(ctx, "other")
+			Expect(models.IsNotFoundError(err)).To(BeTrue())
+		})
+	})
+
+	Context("Complete", func() {
+		var session *oidc.Session
+		var state string
+
+		authorize := func(claims jwt.MapClaims) {
+			var authURL string
+			authURL, session, err = oidcService.Begin(ctx, "corp")
+			Expect(err).ToNot(HaveOccurred())
+			state = provider.Authorize(authURL, "code", claims)
+		}
+
+		It("should create a player on first login", func() {
+			authorize(jwt.MapClaims{"sub": "user-1", "preferred_username": "jane.doe", "email": "jane@example.com", "email_verified": true})
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			mockIdentityRepository.On("Get", ctx, provider.Issuer(), "user-1").Return(nil, models.NewNotFoundError("not found"))
+			mockPlayerRepository.On("NicknameExists", ctx, "jane.doe").Return(false, nil)
+			mockPlayerRepository.On("Create", ctx, tmock.MatchedBy(func(p *models.Player) bool {
+				return p.Login == "corp:user-1" && p.Nickname == "jane.doe" && p.Password == ""
+			})).Return(playerID, nil)
+			mockIdentityRepository.On("Create", ctx, &domain.Identity{
+				Issuer:   provider.Issuer(),
+				Subject:  "user-1",
+				PlayerID: playerID,
+				Email:    "jane@example.com",
+			}).Return(nil)
+
+			player, err := oidcService.Complete(ctx, "corp", session, state, "code")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(player.ID).To(Equal(playerID))
+			mockPlayerRepository.AssertExpectations(GinkgoT())
+			mockIdentityRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should return the linked player on later logins", func() {
+			authorize(jwt.MapClaims{"sub": "user-1"})
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			mockIdentityRepository.On("Get", ctx, provider.Issuer(), "user-1").Return(&domain.Identity{PlayerID: playerID}, nil)
+			mockPlayerRepository.On("Get", ctx, playerID).Return(&models.Player{ID: playerID, Nickname: "jane.doe"}, nil)
+
+			player, err := oidcService.Complete(ctx, "corp", session, state, "code")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(player.Nickname).To(Equal("jane.doe"))
+			mockPlayerRepository.AssertNotCalled(GinkgoT(), "Create", tmock.Anything, tmock.Anything)
+		})
+
+		It("should pick another nickname when the preferred one is taken", func() {
+			authorize(jwt.MapClaims{"sub": "user-2", "name": "Jane Doe", "email": "jane@example.com"})
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			mockIdentityRepository.On("Get", ctx, provider.Issuer(), "user-2").Return(nil, models.NewNotFoundError("not found"))
+			mockPlayerRepository.On("NicknameExists", ctx, "JaneDoe").Return(true, nil).Once()
+			mockPlayerRepository.On("NicknameExists", ctx, tmock.Anything).Return(false, nil).Once()
+			mockPlayerRepository.On("Create", ctx, tmock.MatchedBy(func(p *models.Player) bool {
+				return strings.HasPrefix(p.Nickname, "JaneDoe-") && len(p.Nickname) <= oidc.NicknameMaxLength
+			})).Return(playerID, nil)
+			mockIdentityRepository.On("Create", ctx, tmock.MatchedBy(func(i *domain.Identity) bool {
+				return i.Email == ""
+			})).Return(nil)
+
+			_, err := oidcService.Complete(ctx, "corp", session, state, "code")
+
+			Expect(err).ToNot(HaveOccurred())
+			mockPlayerRepository.AssertExpectations(GinkgoT())
+			mockIdentityRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should reject a state that does not match the session", func() {
+			authorize(jwt.MapClaims{"sub": "user-1"})
+
+			_, err := oidcService.Complete(ctx, "corp", session, "forged", "code")
+
+			Expect(err).To(BeAssignableToTypeOf(&models.AuthorizationError{}))
+		})
+
+		It("should reject an ID token with another nonce", func() {
+			authorize(jwt.MapClaims{"sub": "user-1"})
+			session.Nonce = "other"
+
+			_, err := oidcService.Complete(ctx, "corp", session, state, "code")
+
+			Expect(err).To(BeAssignableToTypeOf(&models.AuthorizationError{}))
+		})
+
+		It("should reject a code exchanged without the PKCE verifier", func() {
+			authorize(jwt.MapClaims{"sub": "user-1"})
+			session.Verifier = "other"
+
+			_, err := oidcService.Complete(ctx, "corp", session, state, "code")
+
+			Expect(err).To(BeAssignableToTypeOf(&models.AuthorizationError{}))
+		})
+	})
+
+	Context("Session", func() {
+		It("should round-trip through its encoding", func() {
+			session := &oidc.Session{State: "state", Nonce: "nonce", Verifier: "verifier"}
+
+			decoded, err := oidc.DecodeSession(session.Encode())
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded).To(Equal(session))
+		})
+
+		It("should reject malformed values", func() {
+			_, err := oidc.DecodeSession("state.nonce")
+			Expect(err).To(BeAssignableToTypeOf(&models.AuthorizationError{}))
+		})
+	})
+})
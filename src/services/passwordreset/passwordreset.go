@@ -0,0 +1,168 @@
+package passwordreset
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/config"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/services/logger"
+	"github.com/plamen-v/tic-tac-toe/src/services/notifier"
+	"github.com/plamen-v/tic-tac-toe/src/services/profile"
+)
+
+const (
+	InvalidTokenMessage string = "password reset token is invalid or expired"
+	MessageSubject      string = "Reset your password"
+	tokenSize           int    = 32
+)
+
+// PasswordResetService lets players who forgot their password set a new one.
+// RequestReset sends a single-use token to the email address of the player;
+// it reports success for unknown logins too so that it cannot be used to
+// find out which accounts exist. ConfirmReset sets the new password and
+// invalidates all outstanding tokens of the player.
+type PasswordResetService interface {
+	RequestReset(context.Context, string) error
+	ConfirmReset(context.Context, string, string) error
+}
+
+func NewPasswordResetService(configuration config.PasswordResetConfiguration,
+	db *sql.DB,
+	notifier notifier.Notifier,
+	playerRepositoryFactory func(q repository.Querier) repository.PlayerRepository,
+	passwordResetRepositoryFactory func(q repository.Querier) repository.PasswordResetRepository) PasswordResetService {
+	return &passwordResetServiceImpl{
+		configuration:                  configuration,
+		db:                             db,
+		notifier:                       notifier,
+		playerRepositoryFactory:        playerRepositoryFactory,
+		passwordResetRepositoryFactory: passwordResetRepositoryFactory,
+	}
+}
+
+type passwordResetServiceImpl struct {
+	configuration                  config.PasswordResetConfiguration
+	db                             *sql.DB
+	notifier                       notifier.Notifier
+	playerRepositoryFactory        func(q repository.Querier) repository.PlayerRepository
+	passwordResetRepositoryFactory func(q repository.Querier) repository.PasswordResetRepository
+}
+
+func (s *passwordResetServiceImpl) RequestReset(ctx context.Context, login string) error {
+	if len(login) == 0 {
+		return models.NewValidationError("login is required")
+	}
+
+	playerRepository := s.playerRepositoryFactory(s.db)
+	player, err := playerRepository.GetByLogin(ctx, login)
+	if err != nil {
+		if models.IsNotFoundError(err) {
+			logger.FromContext(ctx).Info("password reset requested for unknown login")
+			return nil
+		}
+		return err
+	}
+
+	email, err := playerRepository.GetEmail(ctx, player.ID)
+	if err != nil {
+		return err
+	}
+	if len(email) == 0 {
+		logger.FromContext(ctx).Info("password reset requested for player without email",
+			logger.String("playerId", player.ID.String()))
+		return nil
+	}
+
+	token := generateToken()
+	err = s.passwordResetRepositoryFactory(s.db).Create(ctx, &domain.PasswordResetToken{
+		TokenHash: hashToken(token),
+		PlayerID:  player.ID,
+		ExpiresAt: time.Now().Add(s.configuration.TokenTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	// A failed delivery is only logged; reporting it would tell the caller
+	// that the account exists.
+	if err = s.notifier.Notify(ctx, s.message(email, player.Nickname, token)); err != nil {
+		logger.FromContext(ctx).Error("password reset notification failed",
+			logger.String("playerId", player.ID.String()),
+			logger.Err(err))
+		return nil
+	}
+
+	logger.FromContext(ctx).Info("password reset requested", logger.String("playerId", player.ID.String()))
+	return nil
+}
+
+func (s *passwordResetServiceImpl) ConfirmReset(ctx context.Context, token string, newPassword string) error {
+	if len(token) == 0 {
+		return models.NewAuthorizationError(InvalidTokenMessage)
+	}
+
+	passwordHash, err := profile.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	return repository.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		passwordResetRepository := s.passwordResetRepositoryFactory(tx)
+
+		playerID, err := passwordResetRepository.Consume(ctx, hashToken(token))
+		if err != nil {
+			if models.IsNotFoundError(err) {
+				return models.NewAuthorizationError(InvalidTokenMessage)
+			}
+			return err
+		}
+
+		if err = s.playerRepositoryFactory(tx).UpdatePassword(ctx, playerID, passwordHash); err != nil {
+			return err
+		}
+
+		if err = passwordResetRepository.DeleteByPlayer(ctx, playerID); err != nil {
+			return err
+		}
+
+		logger.FromContext(ctx).Info("password reset", logger.String("playerId", playerID.String()))
+		return nil
+	})
+}
+
+func (s *passwordResetServiceImpl) message(email string, nickname string, token string) *notifier.Message {
+	link := token
+	if len(s.configuration.URL) > 0 {
+		link = s.configuration.URL + token
+	}
+
+	return &notifier.Message{
+		To:      email,
+		Subject: MessageSubject,
+		Body: fmt.Sprintf("Hello %s,\n\n"+
+			"a password reset was requested for your account. Use the following to set a new password:\n\n"+
+			"%s\n\n"+
+			"It expires in %s and can be used once. If you did not request the reset, ignore this message.\n",
+			nickname, link, s.configuration.TokenTTL),
+	}
+}
+
+func generateToken() string {
+	b := make([]byte, tokenSize)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
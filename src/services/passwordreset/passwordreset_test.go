@@ -0,0 +1,163 @@
+package passwordreset_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofrs/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/config"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/repository/mocks"
+	"github.com/plamen-v/tic-tac-toe/src/services/notifier"
+	notifiermocks "github.com/plamen-v/tic-tac-toe/src/services/notifier/mocks"
+	"github.com/plamen-v/tic-tac-toe/src/services/passwordreset"
+	tmock "github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var _ = Describe("PasswordReset", func() {
+	var (
+		db                          *sql.DB
+		mock                        sqlmock.Sqlmock
+		ctx                         context.Context
+		mockPlayerRepository        *mocks.MockPlayerRepository
+		mockPasswordResetRepository *mocks.MockPasswordResetRepository
+		mockNotifier                *notifiermocks.MockNotifier
+		passwordResetService        passwordreset.PasswordResetService
+		player                      *models.Player
+		err                         error
+	)
+
+	hash := func(token string) string {
+		sum := sha256.Sum256([]byte(token))
+		return hex.EncodeToString(sum[:])
+	}
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		db, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		mockPlayerRepository = new(mocks.MockPlayerRepository)
+		mockPasswordResetRepository = new(mocks.MockPasswordResetRepository)
+		mockNotifier = new(notifiermocks.MockNotifier)
+		player = &models.Player{ID: uuid.Must(uuid.NewV4()), Login: "player", Nickname: "nick"}
+		passwordResetService = passwordreset.NewPasswordResetService(
+			config.PasswordResetConfiguration{TokenTTL: 30 * time.Minute, URL: "https://example.com/reset?token="},
+			db,
+			mockNotifier,
+			func(db repository.Querier) repository.PlayerRepository {
+				return mockPlayerRepository
+			},
+			func(db repository.Querier) repository.PasswordResetRepository {
+				return mockPasswordResetRepository
+			},
+		)
+	})
+
+	AfterEach(func() {
+		err = mock.ExpectationsWereMet()
+		Expect(err).ToNot(HaveOccurred())
+		db.Close()
+	})
+
+	Context("RequestReset", func() {
+		It("should store the hash of the token and send the token", func() {
+			var stored *domain.PasswordResetToken
+			var sent *notifier.Message
+			mockPlayerRepository.On("GetByLogin", ctx, "player").Return(player, nil)
+			mockPlayerRepository.On("GetEmail", ctx, player.ID).Return("player@example.com", nil)
+			mockPasswordResetRepository.On("Create", ctx, tmock.Anything).
+				Run(func(args tmock.Arguments) { stored = args.Get(1).(*domain.PasswordResetToken) }).
+				Return(nil)
+			mockNotifier.On("Notify", ctx, tmock.Anything).
+				Run(func(args tmock.Arguments) { sent = args.Get(1).(*notifier.Message) }).
+				Return(nil)
+
+			Expect(passwordResetService.RequestReset(ctx, "player")).To(Succeed())
+
+			Expect(sent.To).To(Equal("player@example.com"))
+			Expect(sent.Subject).To(Equal(passwordreset.MessageSubject))
+			token := regexp.MustCompile(`https://example\.com/reset\?token=([A-Za-z0-9_-]+)`).FindStringSubmatch(sent.Body)
+			Expect(token).To(HaveLen(2))
+			Expect(stored.TokenHash).To(Equal(hash(token[1])))
+			Expect(stored.PlayerID).To(Equal(player.ID))
+			Expect(stored.ExpiresAt).To(BeTemporally("~", time.Now().Add(30*time.Minute), time.Minute))
+		})
+
+		It("should report success for unknown logins", func() {
+			mockPlayerRepository.On("GetByLogin", ctx, "unknown").Return(nil, models.NewNotFoundError("player not exist"))
+
+			Expect(passwordResetService.RequestReset(ctx, "unknown")).To(Succeed())
+			mockNotifier.AssertNotCalled(GinkgoT(), "Notify", tmock.Anything, tmock.Anything)
+		})
+
+		It("should report success for players without email", func() {
+			mockPlayerRepository.On("GetByLogin", ctx, "player").Return(player, nil)
+			mockPlayerRepository.On("GetEmail", ctx, player.ID).Return("", nil)
+
+			Expect(passwordResetService.RequestReset(ctx, "player")).To(Succeed())
+			mockPasswordResetRepository.AssertNotCalled(GinkgoT(), "Create", tmock.Anything, tmock.Anything)
+		})
+
+		It("should not report failed deliveries", func() {
+			mockPlayerRepository.On("GetByLogin", ctx, "player").Return(player, nil)
+			mockPlayerRepository.On("GetEmail", ctx, player.ID).Return("player@example.com", nil)
+			mockPasswordResetRepository.On("Create", ctx, tmock.Anything).Return(nil)
+			mockNotifier.On("Notify", ctx, tmock.Anything).Return(errors.New("connection refused"))
+
+			Expect(passwordResetService.RequestReset(ctx, "player")).To(Succeed())
+		})
+
+		It("should require a login", func() {
+			err := passwordResetService.RequestReset(ctx, "")
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+		})
+	})
+
+	Context("ConfirmReset", func() {
+		It("should set the new password and invalidate the tokens", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			mockPasswordResetRepository.On("Consume", ctx, hash("token")).Return(player.ID, nil)
+			mockPlayerRepository.On("UpdatePassword", ctx, player.ID, tmock.MatchedBy(func(passwordHash string) bool {
+				return bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte("new-password")) == nil
+			})).Return(nil)
+			mockPasswordResetRepository.On("DeleteByPlayer", ctx, player.ID).Return(nil)
+
+			Expect(passwordResetService.ConfirmReset(ctx, "token", "new-password")).To(Succeed())
+			mockPlayerRepository.AssertExpectations(GinkgoT())
+			mockPasswordResetRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should reject unknown, used or expired tokens", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+			mockPasswordResetRepository.On("Consume", ctx, hash("token")).Return(uuid.Nil, models.NewNotFoundError("password reset token not exist"))
+
+			err := passwordResetService.ConfirmReset(ctx, "token", "new-password")
+			Expect(err).To(BeAssignableToTypeOf(&models.AuthorizationError{}))
+			mockPlayerRepository.AssertNotCalled(GinkgoT(), "UpdatePassword", tmock.Anything, tmock.Anything, tmock.Anything)
+		})
+
+		It("should reject a short password before using the token", func() {
+			err := passwordResetService.ConfirmReset(ctx, "token", "short")
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+			mockPasswordResetRepository.AssertNotCalled(GinkgoT(), "Consume", tmock.Anything, tmock.Anything)
+		})
+
+		It("should reject an empty token", func() {
+			err := passwordResetService.ConfirmReset(ctx, "", "new-password")
+			Expect(err).To(BeAssignableToTypeOf(&models.AuthorizationError{}))
+		})
+	})
+})
@@ -0,0 +1,26 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPresenceService struct {
+	mock.Mock
+}
+
+func (m *MockPresenceService) Touch(ctx context.Context, playerID uuid.UUID) error {
+	args := m.Called(ctx, playerID)
+	return args.Error(0)
+}
+
+func (m *MockPresenceService) GetPresence(ctx context.Context, playerIDs ...uuid.UUID) (map[uuid.UUID]*domain.Presence, error) {
+	args := m.Called(ctx, playerIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]*domain.Presence), args.Error(1)
+}
@@ -0,0 +1,135 @@
+package presence
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe/src/config"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+)
+
+// PresenceService tracks who is online. Touch records activity of a player,
+// from an authenticated request or any other live connection; the status is
+// derived from the time of the last activity and the room of the player.
+type PresenceService interface {
+	Touch(context.Context, uuid.UUID) error
+	GetPresence(context.Context, ...uuid.UUID) (map[uuid.UUID]*domain.Presence, error)
+}
+
+func NewPresenceService(configuration config.PresenceConfiguration,
+	db *sql.DB,
+	now func() time.Time,
+	presenceRepositoryFactory func(q repository.Querier) repository.PresenceRepository) PresenceService {
+	return &presenceServiceImpl{
+		configuration:             configuration,
+		db:                        db,
+		now:                       now,
+		presenceRepositoryFactory: presenceRepositoryFactory,
+		touched:                   map[uuid.UUID]time.Time{},
+		lastSweep:                 now(),
+	}
+}
+
+type presenceServiceImpl struct {
+	configuration             config.PresenceConfiguration
+	db                        *sql.DB
+	now                       func() time.Time
+	presenceRepositoryFactory func(q repository.Querier) repository.PresenceRepository
+
+	mu        sync.Mutex
+	touched   map[uuid.UUID]time.Time
+	lastSweep time.Time
+}
+
+// Touch writes the activity at most once per TouchInterval for each player,
+// so that it can run on every request.
+func (s *presenceServiceImpl) Touch(ctx context.Context, playerID uuid.UUID) error {
+	now := s.now()
+	if !s.shouldTouch(playerID, now) {
+		return nil
+	}
+
+	if err := s.presenceRepositoryFactory(s.db).Touch(ctx, playerID, now); err != nil {
+		s.mu.Lock()
+		delete(s.touched, playerID)
+		s.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+func (s *presenceServiceImpl) GetPresence(ctx context.Context, playerIDs ...uuid.UUID) (map[uuid.UUID]*domain.Presence, error) {
+	result := make(map[uuid.UUID]*domain.Presence, len(playerIDs))
+	if len(playerIDs) == 0 {
+		return result, nil
+	}
+
+	activities, err := s.presenceRepositoryFactory(s.db).GetActivity(ctx, playerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	for _, activity := range activities {
+		result[activity.PlayerID] = &domain.Presence{
+			Status:     s.status(activity, now),
+			LastSeenAt: activity.LastSeenAt,
+		}
+	}
+
+	return result, nil
+}
+
+// status ranks a game in progress over being away, so that a player thinking
+// over a move still shows as playing until they are offline.
+func (s *presenceServiceImpl) status(activity *domain.PlayerActivity, now time.Time) domain.PresenceStatus {
+	if activity.LastSeenAt == nil {
+		return domain.PresenceOffline
+	}
+
+	idle := now.Sub(*activity.LastSeenAt)
+	switch {
+	case idle >= s.configuration.OfflineAfter:
+		return domain.PresenceOffline
+	case activity.InGame:
+		return domain.PresenceInGame
+	case idle >= s.configuration.AwayAfter:
+		return domain.PresenceAway
+	case activity.InRoom:
+		return domain.PresenceInLobby
+	default:
+		return domain.PresenceOnline
+	}
+}
+
+func (s *presenceServiceImpl) shouldTouch(playerID uuid.UUID, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	if touched, ok := s.touched[playerID]; ok && now.Sub(touched) < s.configuration.TouchInterval {
+		return false
+	}
+	s.touched[playerID] = now
+
+	return true
+}
+
+func (s *presenceServiceImpl) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.configuration.TouchInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for playerID, touched := range s.touched {
+		if now.Sub(touched) >= s.configuration.TouchInterval {
+			delete(s.touched, playerID)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package presence_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofrs/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe/src/config"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/repository/mocks"
+	"github.com/plamen-v/tic-tac-toe/src/services/presence"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("Presence", func() {
+	var (
+		db                     *sql.DB
+		ctx                    context.Context
+		now                    time.Time
+		mockPresenceRepository *mocks.MockPresenceRepository
+		presenceService        presence.PresenceService
+		playerID               uuid.UUID
+		err                    error
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		db, _, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		mockPresenceRepository = new(mocks.MockPresenceRepository)
+		playerID = uuid.Must(uuid.NewV4())
+		presenceService = presence.NewPresenceService(
+			config.PresenceConfiguration{AwayAfter: 2 * time.Minute, OfflineAfter: 5 * time.Minute, TouchInterval: 30 * time.Second},
+			db,
+			func() time.Time { return now },
+			func(db repository.Querier) repository.PresenceRepository {
+				return mockPresenceRepository
+			},
+		)
+	})
+
+	AfterEach(func() {
+		db.Close()
+	})
+
+	Context("Touch", func() {
+		It("should write the activity once per interval", func() {
+			mockPresenceRepository.On("Touch", ctx, playerID, now).Return(nil).Once()
+
+			Expect(presenceService.Touch(ctx, playerID)).To(Succeed())
+			now = now.Add(10 * time.Second)
+			Expect(presenceService.Touch(ctx, playerID)).To(Succeed())
+
+			mockPresenceRepository.AssertNumberOfCalls(GinkgoT(), "Touch", 1)
+		})
+
+		It("should write the activity again after the interval", func() {
+			first := now
+			second := now.Add(30 * time.Second)
+			mockPresenceRepository.On("Touch", ctx, playerID, first).Return(nil).Once()
+			mockPresenceRepository.On("Touch", ctx, playerID, second).Return(nil).Once()
+
+			Expect(presenceService.Touch(ctx, playerID)).To(Succeed())
+			now = second
+			Expect(presenceService.Touch(ctx, playerID)).To(Succeed())
+
+			mockPresenceRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should retry a failed write", func() {
+			mockPresenceRepository.On("Touch", ctx, playerID, now).Return(errors.New("db error")).Once()
+			mockPresenceRepository.On("Touch", ctx, playerID, now).Return(nil).Once()
+
+			Expect(presenceService.Touch(ctx, playerID)).ToNot(Succeed())
+			Expect(presenceService.Touch(ctx, playerID)).To(Succeed())
+
+			mockPresenceRepository.AssertNumberOfCalls(GinkgoT(), "Touch", 2)
+		})
+	})
+
+	Context("GetPresence", func() {
+		seen := func(ago time.Duration) *time.Time {
+			t := now.Add(-ago)
+			return &t
+		}
+
+		DescribeTable("should derive the status",
+			func(lastSeen time.Duration, never bool, inRoom bool, inGame bool, expected domain.PresenceStatus) {
+				activity := &domain.PlayerActivity{PlayerID: playerID, InRoom: inRoom, InGame: inGame}
+				if !never {
+					activity.LastSeenAt = seen(lastSeen)
+				}
+				mockPresenceRepository.On("GetActivity", ctx, []uuid.UUID{playerID}).
+					Return([]*domain.PlayerActivity{activity}, nil)
+
+				result, err := presenceService.GetPresence(ctx, playerID)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(HaveKey(playerID))
+				Expect(result[playerID].Status).To(Equal(expected))
+			},
+			Entry("active outside of rooms", 10*time.Second, false, false, false, domain.PresenceOnline),
+			Entry("active in a room", 10*time.Second, false, true, false, domain.PresenceInLobby),
+			Entry("active in a game", 10*time.Second, false, true, true, domain.PresenceInGame),
+			Entry("idle outside of games", 3*time.Minute, false, true, false, domain.PresenceAway),
+			Entry("idle in a game", 3*time.Minute, false, true, true, domain.PresenceInGame),
+			Entry("inactive", 5*time.Minute, false, true, true, domain.PresenceOffline),
+			Entry("never seen", time.Duration(0), true, false, false, domain.PresenceOffline),
+		)
+
+		It("should not query without players", func() {
+			result, err := presenceService.GetPresence(ctx)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(BeEmpty())
+			mockPresenceRepository.AssertNotCalled(GinkgoT(), "GetActivity", tmock.Anything, tmock.Anything)
+		})
+	})
+})
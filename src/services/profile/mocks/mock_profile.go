@@ -0,0 +1,51 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProfileService struct {
+	mock.Mock
+}
+
+func (m *MockProfileService) GetProfile(ctx context.Context, playerID uuid.UUID) (*domain.Profile, error) {
+	args := m.Called(ctx, playerID)
+
+	profile, ok := args.Get(0).(*domain.Profile)
+	if profile == nil || !ok {
+		return nil, args.Error(1)
+	}
+
+	return profile, args.Error(1)
+}
+
+func (m *MockProfileService) GetOwnProfile(ctx context.Context, playerID uuid.UUID) (*domain.OwnProfile, error) {
+	args := m.Called(ctx, playerID)
+
+	profile, ok := args.Get(0).(*domain.OwnProfile)
+	if profile == nil || !ok {
+		return nil, args.Error(1)
+	}
+
+	return profile, args.Error(1)
+}
+
+func (m *MockProfileService) UpdateProfile(ctx context.Context, playerID uuid.UUID, request *domain.UpdateProfileRequest) (*domain.OwnProfile, error) {
+	args := m.Called(ctx, playerID, request)
+
+	profile, ok := args.Get(0).(*domain.OwnProfile)
+	if profile == nil || !ok {
+		return nil, args.Error(1)
+	}
+
+	return profile, args.Error(1)
+}
+
+func (m *MockProfileService) DeleteAccount(ctx context.Context, playerID uuid.UUID, password string) error {
+	args := m.Called(ctx, playerID, password)
+	return args.Error(0)
+}
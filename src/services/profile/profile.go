@@ -0,0 +1,269 @@
+package profile
+
+import (
+	"context"
+	"database/sql"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/services/logger"
+	"github.com/plamen-v/tic-tac-toe/src/services/presence"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	RecentGamesLimit  int = 10
+	NicknameMaxLength int = 30
+	PasswordMinLength int = 8
+	// PasswordMaxLength is the longest input bcrypt accepts.
+	PasswordMaxLength int = 72
+)
+
+var (
+	InvalidNicknameErrorMessage string = "nickname must be between 1 and 30 characters"
+	InvalidPasswordErrorMessage string = "password must be between 8 and 72 characters"
+	InvalidEmailErrorMessage    string = "email is invalid"
+	WrongPasswordErrorMessage   string = "current password is invalid"
+	NoPasswordErrorMessage      string = "account has no password, it signs in with an external provider"
+	PlayerInRoomErrorMessage    string = "leave the room before deleting the account"
+	EmptyProfileUpdateMessage   string = "nothing to update"
+	deletedNicknamePrefix       string = "deleted-"
+	deletedNicknameSuffixLength int    = 22
+)
+
+// ProfileService serves the public profiles of players and lets players
+// manage their own account. Deleting an account anonymises the player
+// instead of removing it so that the history of its opponents stays intact.
+type ProfileService interface {
+	GetProfile(context.Context, uuid.UUID) (*domain.Profile, error)
+	GetOwnProfile(context.Context, uuid.UUID) (*domain.OwnProfile, error)
+	UpdateProfile(context.Context, uuid.UUID, *domain.UpdateProfileRequest) (*domain.OwnProfile, error)
+	DeleteAccount(context.Context, uuid.UUID, string) error
+}
+
+func NewProfileService(db *sql.DB,
+	presenceService presence.PresenceService,
+	playerRepositoryFactory func(q repository.Querier) repository.PlayerRepository,
+	gameRepositoryFactory func(q repository.Querier) repository.GameRepository,
+	roomRepositoryFactory func(q repository.Querier) repository.RoomRepository,
+	achievementRepositoryFactory func(q repository.Querier) repository.AchievementRepository) ProfileService {
+	return &profileServiceImpl{
+		db:                           db,
+		presenceService:              presenceService,
+		playerRepositoryFactory:      playerRepositoryFactory,
+		gameRepositoryFactory:        gameRepositoryFactory,
+		roomRepositoryFactory:        roomRepositoryFactory,
+		achievementRepositoryFactory: achievementRepositoryFactory,
+	}
+}
+
+type profileServiceImpl struct {
+	db                           *sql.DB
+	presenceService              presence.PresenceService
+	playerRepositoryFactory      func(q repository.Querier) repository.PlayerRepository
+	gameRepositoryFactory        func(q repository.Querier) repository.GameRepository
+	roomRepositoryFactory        func(q repository.Querier) repository.RoomRepository
+	achievementRepositoryFactory func(q repository.Querier) repository.AchievementRepository
+}
+
+func (s *profileServiceImpl) GetProfile(ctx context.Context, playerID uuid.UUID) (*domain.Profile, error) {
+	return s.profile(ctx, s.db, playerID)
+}
+
+func (s *profileServiceImpl) GetOwnProfile(ctx context.Context, playerID uuid.UUID) (*domain.OwnProfile, error) {
+	return s.ownProfile(ctx, s.db, playerID)
+}
+
+func (s *profileServiceImpl) UpdateProfile(ctx context.Context, playerID uuid.UUID, request *domain.UpdateProfileRequest) (*domain.OwnProfile, error) {
+	if request == nil || (request.Nickname == nil && request.Email == nil && request.NewPassword == nil) {
+		return nil, models.NewValidationError(EmptyProfileUpdateMessage)
+	}
+
+	var nickname string
+	if request.Nickname != nil {
+		nickname = strings.TrimSpace(*request.Nickname)
+		if length := utf8.RuneCountInString(nickname); length == 0 || length > NicknameMaxLength {
+			return nil, models.NewValidationError(InvalidNicknameErrorMessage)
+		}
+	}
+
+	var email string
+	if request.Email != nil {
+		email = strings.TrimSpace(*request.Email)
+		if len(email) > 0 && !ValidEmail(email) {
+			return nil, models.NewValidationError(InvalidEmailErrorMessage)
+		}
+	}
+
+	var passwordHash string
+	if request.NewPassword != nil {
+		var err error
+		if passwordHash, err = HashPassword(*request.NewPassword); err != nil {
+			return nil, err
+		}
+	}
+
+	return repository.WithTransactionT(ctx, s.db, func(tx *sql.Tx) (*domain.OwnProfile, error) {
+		playerRepository := s.playerRepositoryFactory(tx)
+
+		// The email receives the password reset links, so changing it takes
+		// the current password like changing the password does.
+		if request.NewPassword != nil || request.Email != nil {
+			player, err := playerRepository.Get(ctx, playerID)
+			if err != nil {
+				return nil, err
+			}
+
+			if err = checkPassword(player, request.CurrentPassword); err != nil {
+				return nil, err
+			}
+		}
+
+		if request.NewPassword != nil {
+			if err := playerRepository.UpdatePassword(ctx, playerID, passwordHash); err != nil {
+				return nil, err
+			}
+		}
+
+		if request.Nickname != nil {
+			if err := playerRepository.UpdateNickname(ctx, playerID, nickname); err != nil {
+				return nil, err
+			}
+		}
+
+		if request.Email != nil {
+			if err := playerRepository.UpdateEmail(ctx, playerID, email); err != nil {
+				return nil, err
+			}
+		}
+
+		return s.ownProfile(ctx, tx, playerID)
+	})
+}
+
+// DeleteAccount anonymises the player. Players with a password have to
+// confirm with it; players signing in with an external provider have none.
+func (s *profileServiceImpl) DeleteAccount(ctx context.Context, playerID uuid.UUID, password string) error {
+	err := repository.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		playerRepository := s.playerRepositoryFactory(tx)
+
+		player, err := playerRepository.Get(ctx, playerID)
+		if err != nil {
+			return err
+		}
+
+		if len(player.Password) > 0 {
+			if err = checkPassword(player, password); err != nil {
+				return err
+			}
+		}
+
+		_, err = s.roomRepositoryFactory(tx).GetByPlayerID(ctx, playerID)
+		if err == nil {
+			return models.NewValidationError(PlayerInRoomErrorMessage)
+		}
+		if !models.IsNotFoundError(err) {
+			return err
+		}
+
+		return playerRepository.Anonymize(ctx, playerID, deletedNickname(playerID))
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("player account deleted", logger.String("playerId", playerID.String()))
+	return nil
+}
+
+func (s *profileServiceImpl) profile(ctx context.Context, q repository.Querier, playerID uuid.UUID) (*domain.Profile, error) {
+	profile, err := s.playerRepositoryFactory(q).GetProfile(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile.RecentGames, err = s.gameRepositoryFactory(q).GetRecentByPlayer(ctx, playerID, RecentGamesLimit); err != nil {
+		return nil, err
+	}
+
+	if profile.Achievements, err = s.achievementRepositoryFactory(q).GetByPlayer(ctx, playerID); err != nil {
+		return nil, err
+	}
+
+	presences, err := s.presenceService.GetPresence(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	profile.Presence = presences[playerID]
+
+	return profile, nil
+}
+
+func (s *profileServiceImpl) ownProfile(ctx context.Context, q repository.Querier, playerID uuid.UUID) (*domain.OwnProfile, error) {
+	profile, err := s.profile(ctx, q, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	playerRepository := s.playerRepositoryFactory(q)
+	account, err := playerRepository.GetAccount(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	email, err := playerRepository.GetEmail(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.OwnProfile{
+		Profile: *profile,
+		Login:   account.Login,
+		Email:   email,
+		Role:    account.Role,
+	}, nil
+}
+
+// HashPassword checks the length of a new password and returns its bcrypt
+// hash.
+func HashPassword(password string) (string, error) {
+	if length := len(password); length < PasswordMinLength || length > PasswordMaxLength {
+		return "", models.NewValidationError(InvalidPasswordErrorMessage)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", models.NewGenericError(err.Error())
+	}
+
+	return string(hash), nil
+}
+
+// ValidEmail accepts a bare address such as "player@example.com".
+func ValidEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email
+}
+
+func checkPassword(player *models.Player, password string) error {
+	if len(player.Password) == 0 {
+		return models.NewValidationError(NoPasswordErrorMessage)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(player.Password), []byte(password)); err != nil {
+		return models.NewAuthorizationError(WrongPasswordErrorMessage)
+	}
+
+	return nil
+}
+
+// deletedNickname returns the placeholder nickname of a deleted player. It is
+// derived from the id so that it is unique.
+func deletedNickname(playerID uuid.UUID) string {
+	return deletedNicknamePrefix + strings.ReplaceAll(playerID.String(), "-", "")[:deletedNicknameSuffixLength]
+}
@@ -0,0 +1,270 @@
+package profile_test
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofrs/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/repository/mocks"
+	presencemocks "github.com/plamen-v/tic-tac-toe/src/services/presence/mocks"
+	"github.com/plamen-v/tic-tac-toe/src/services/profile"
+	tmock "github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var _ = Describe("Profile", func() {
+	var (
+		db                        *sql.DB
+		mock                      sqlmock.Sqlmock
+		ctx                       context.Context
+		mockRoomRepository        *mocks.MockRoomRepository
+		mockGameRepository        *mocks.MockGameRepository
+		mockPlayerRepository      *mocks.MockPlayerRepository
+		mockAchievementRepository *mocks.MockAchievementRepository
+		mockPresenceService       *presencemocks.MockPresenceService
+		profileService            profile.ProfileService
+		playerID                  uuid.UUID
+		player                    *models.Player
+		err                       error
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		db, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		mockRoomRepository = new(mocks.MockRoomRepository)
+		mockGameRepository = new(mocks.MockGameRepository)
+		mockPlayerRepository = new(mocks.MockPlayerRepository)
+		mockAchievementRepository = new(mocks.MockAchievementRepository)
+		mockPresenceService = new(presencemocks.MockPresenceService)
+		playerID = uuid.Must(uuid.NewV4())
+		mockPresenceService.On("GetPresence", ctx, []uuid.UUID{playerID}).
+			Return(map[uuid.UUID]*domain.Presence{playerID: {Status: domain.PresenceOnline}}, nil)
+		hash, err := bcrypt.GenerateFromPassword([]byte("secret-password"), bcrypt.MinCost)
+		Expect(err).ToNot(HaveOccurred())
+		player = &models.Player{ID: playerID, Login: "player", Nickname: "nick", Password: string(hash)}
+		profileService = profile.NewProfileService(
+			db,
+			mockPresenceService,
+			func(db repository.Querier) repository.PlayerRepository {
+				return mockPlayerRepository
+			},
+			func(db repository.Querier) repository.GameRepository {
+				return mockGameRepository
+			},
+			func(db repository.Querier) repository.RoomRepository {
+				return mockRoomRepository
+			},
+			func(db repository.Querier) repository.AchievementRepository {
+				return mockAchievementRepository
+			},
+		)
+	})
+
+	AfterEach(func() {
+		err = mock.ExpectationsWereMet()
+		Expect(err).ToNot(HaveOccurred())
+		db.Close()
+	})
+
+	expectOwnProfile := func() {
+		mockPlayerRepository.On("GetProfile", ctx, playerID).Return(&domain.Profile{ID: playerID, Nickname: "nick", Rating: domain.DefaultRating}, nil)
+		mockGameRepository.On("GetRecentByPlayer", ctx, playerID, profile.RecentGamesLimit).Return([]*domain.GameSummary{}, nil)
+		mockAchievementRepository.On("GetByPlayer", ctx, playerID).Return([]*domain.Achievement{}, nil)
+		mockPlayerRepository.On("GetAccount", ctx, playerID).Return(&domain.Account{Player: *player, Role: domain.RolePlayer}, nil)
+		mockPlayerRepository.On("GetEmail", ctx, playerID).Return("player@example.com", nil)
+	}
+
+	Context("GetProfile", func() {
+		It("should return the profile with the recent games, the achievements and the presence", func() {
+			games := []*domain.GameSummary{{GameID: uuid.Must(uuid.NewV4()), Result: domain.GameResultWin, RatingChange: 16}}
+			achievements := []*domain.Achievement{{PlayerID: playerID, Code: domain.AchievementFirstWin}}
+			mockPlayerRepository.On("GetProfile", ctx, playerID).Return(&domain.Profile{ID: playerID, Nickname: "nick", Streak: domain.Streak{Current: 1, Longest: 1}}, nil)
+			mockGameRepository.On("GetRecentByPlayer", ctx, playerID, profile.RecentGamesLimit).Return(games, nil)
+			mockAchievementRepository.On("GetByPlayer", ctx, playerID).Return(achievements, nil)
+
+			result, err := profileService.GetProfile(ctx, playerID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Nickname).To(Equal("nick"))
+			Expect(result.RecentGames).To(Equal(games))
+			Expect(result.Achievements).To(Equal(achievements))
+			Expect(result.Streak.Longest).To(Equal(1))
+			Expect(result.Presence.Status).To(Equal(domain.PresenceOnline))
+		})
+
+		It("should return the error of the repository", func() {
+			mockPlayerRepository.On("GetProfile", ctx, playerID).Return(nil, models.NewNotFoundError("player not exist"))
+
+			_, err := profileService.GetProfile(ctx, playerID)
+			Expect(models.IsNotFoundError(err)).To(BeTrue())
+		})
+	})
+
+	Context("GetOwnProfile", func() {
+		It("should add the login and the role", func() {
+			expectOwnProfile()
+
+			result, err := profileService.GetOwnProfile(ctx, playerID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Login).To(Equal("player"))
+			Expect(result.Email).To(Equal("player@example.com"))
+			Expect(result.Role).To(Equal(domain.RolePlayer))
+			Expect(result.Rating).To(Equal(domain.DefaultRating))
+		})
+	})
+
+	Context("UpdateProfile", func() {
+		It("should change the nickname", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			nickname := "  new-nick "
+			mockPlayerRepository.On("UpdateNickname", ctx, playerID, "new-nick").Return(nil)
+			expectOwnProfile()
+
+			_, err := profileService.UpdateProfile(ctx, playerID, &domain.UpdateProfileRequest{Nickname: &nickname})
+			Expect(err).ToNot(HaveOccurred())
+			mockPlayerRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should return the error of a taken nickname", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+			nickname := "taken"
+			mockPlayerRepository.On("UpdateNickname", ctx, playerID, nickname).Return(models.NewValidationError("nickname 'taken' is already taken"))
+
+			_, err := profileService.UpdateProfile(ctx, playerID, &domain.UpdateProfileRequest{Nickname: &nickname})
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+		})
+
+		It("should reject an invalid nickname", func() {
+			nickname := "   "
+
+			_, err := profileService.UpdateProfile(ctx, playerID, &domain.UpdateProfileRequest{Nickname: &nickname})
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+			Expect(err.Error()).To(ContainSubstring(profile.InvalidNicknameErrorMessage))
+		})
+
+		It("should change the email", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			email := "new@example.com"
+			mockPlayerRepository.On("Get", ctx, playerID).Return(player, nil)
+			mockPlayerRepository.On("UpdateEmail", ctx, playerID, email).Return(nil)
+			expectOwnProfile()
+
+			_, err := profileService.UpdateProfile(ctx, playerID, &domain.UpdateProfileRequest{Email: &email, CurrentPassword: "secret-password"})
+			Expect(err).ToNot(HaveOccurred())
+			mockPlayerRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should reject an email change without the current password", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+			email := "attacker@example.com"
+			mockPlayerRepository.On("Get", ctx, playerID).Return(player, nil)
+
+			_, err := profileService.UpdateProfile(ctx, playerID, &domain.UpdateProfileRequest{Email: &email})
+			Expect(err).To(BeAssignableToTypeOf(&models.AuthorizationError{}))
+			mockPlayerRepository.AssertNotCalled(GinkgoT(), "UpdateEmail", tmock.Anything, tmock.Anything, tmock.Anything)
+		})
+
+		It("should reject an invalid email", func() {
+			email := "Player <player@example.com>"
+
+			_, err := profileService.UpdateProfile(ctx, playerID, &domain.UpdateProfileRequest{Email: &email})
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+			Expect(err.Error()).To(ContainSubstring(profile.InvalidEmailErrorMessage))
+		})
+
+		It("should reject an empty update", func() {
+			_, err := profileService.UpdateProfile(ctx, playerID, &domain.UpdateProfileRequest{})
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+		})
+
+		It("should change the password when the current one matches", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			newPassword := "another-password"
+			mockPlayerRepository.On("Get", ctx, playerID).Return(player, nil)
+			mockPlayerRepository.On("UpdatePassword", ctx, playerID, tmock.MatchedBy(func(hash string) bool {
+				return bcrypt.CompareHashAndPassword([]byte(hash), []byte(newPassword)) == nil
+			})).Return(nil)
+			expectOwnProfile()
+
+			_, err := profileService.UpdateProfile(ctx, playerID, &domain.UpdateProfileRequest{CurrentPassword: "secret-password", NewPassword: &newPassword})
+			Expect(err).ToNot(HaveOccurred())
+			mockPlayerRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should reject a wrong current password", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+			newPassword := "another-password"
+			mockPlayerRepository.On("Get", ctx, playerID).Return(player, nil)
+
+			_, err := profileService.UpdateProfile(ctx, playerID, &domain.UpdateProfileRequest{CurrentPassword: "wrong", NewPassword: &newPassword})
+			Expect(err).To(BeAssignableToTypeOf(&models.AuthorizationError{}))
+			mockPlayerRepository.AssertNotCalled(GinkgoT(), "UpdatePassword", tmock.Anything, tmock.Anything, tmock.Anything)
+		})
+
+		It("should reject a short new password", func() {
+			newPassword := "short"
+
+			_, err := profileService.UpdateProfile(ctx, playerID, &domain.UpdateProfileRequest{CurrentPassword: "secret-password", NewPassword: &newPassword})
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+		})
+	})
+
+	Context("DeleteAccount", func() {
+		It("should anonymise the player", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			mockPlayerRepository.On("Get", ctx, playerID).Return(player, nil)
+			mockRoomRepository.On("GetByPlayerID", ctx, playerID).Return(nil, models.NewNotFoundError("not in a room"))
+			mockPlayerRepository.On("Anonymize", ctx, playerID, tmock.MatchedBy(func(nickname string) bool {
+				return len(nickname) <= profile.NicknameMaxLength && nickname != player.Nickname
+			})).Return(nil)
+
+			Expect(profileService.DeleteAccount(ctx, playerID, "secret-password")).To(Succeed())
+			mockPlayerRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should not require a password for players without one", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			player.Password = ""
+			mockPlayerRepository.On("Get", ctx, playerID).Return(player, nil)
+			mockRoomRepository.On("GetByPlayerID", ctx, playerID).Return(nil, models.NewNotFoundError("not in a room"))
+			mockPlayerRepository.On("Anonymize", ctx, playerID, tmock.Anything).Return(nil)
+
+			Expect(profileService.DeleteAccount(ctx, playerID, "")).To(Succeed())
+		})
+
+		It("should reject a wrong password", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+			mockPlayerRepository.On("Get", ctx, playerID).Return(player, nil)
+
+			err := profileService.DeleteAccount(ctx, playerID, "wrong")
+			Expect(err).To(BeAssignableToTypeOf(&models.AuthorizationError{}))
+			mockPlayerRepository.AssertNotCalled(GinkgoT(), "Anonymize", tmock.Anything, tmock.Anything, tmock.Anything)
+		})
+
+		It("should reject players in a room", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+			mockPlayerRepository.On("Get", ctx, playerID).Return(player, nil)
+			mockRoomRepository.On("GetByPlayerID", ctx, playerID).Return(&models.Room{}, nil)
+
+			err := profileService.DeleteAccount(ctx, playerID, "secret-password")
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+			Expect(err.Error()).To(ContainSubstring(profile.PlayerInRoomErrorMessage))
+		})
+	})
+})
@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const SweepInterval time.Duration = time.Minute
+
+type bucket struct {
+	policy  Policy
+	tokens  float64
+	updated time.Time
+}
+
+func (b *bucket) full(now time.Time) bool {
+	return b.tokens+now.Sub(b.updated).Seconds()*b.policy.Rate >= float64(b.policy.Burst)
+}
+
+// NewMemoryStore returns a Store local to this process. Buckets that have
+// refilled completely are dropped periodically to bound memory.
+func NewMemoryStore(now func() time.Time) Store {
+	return &memoryStore{
+		now:       now,
+		buckets:   map[string]*bucket{},
+		lastSweep: now(),
+	}
+}
+
+type memoryStore struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func (s *memoryStore) Take(ctx context.Context, key string, policy Policy) (*Decision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{policy: policy, tokens: float64(policy.Burst), updated: now}
+		s.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(policy.Burst), b.tokens+elapsed*policy.Rate)
+		b.updated = now
+	}
+
+	decision := &Decision{Limit: policy.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		decision.Allowed = true
+	} else if policy.Rate > 0 {
+		decision.RetryAfter = time.Duration((1 - b.tokens) / policy.Rate * float64(time.Second))
+	}
+	decision.Remaining = int(b.tokens)
+
+	return decision, nil
+}
+
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < SweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if b.full(now) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package ratelimit_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/plamen-v/tic-tac-toe/src/config"
+	"github.com/plamen-v/tic-tac-toe/src/services/ratelimit"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MemoryStore", func() {
+	var (
+		ctx   context.Context
+		now   time.Time
+		store ratelimit.Store
+	)
+
+	policy := ratelimit.NewPolicy("test", config.RateLimitPolicyConfiguration{Requests: config.RateLimitRequests(1), Period: time.Second, Burst: 2})
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		store = ratelimit.NewMemoryStore(func() time.Time { return now })
+	})
+
+	It("should allow requests up to the burst", func() {
+		for i := 1; i >= 0; i-- {
+			decision, err := store.Take(ctx, "key", policy)
+			Expect(err).To(BeNil())
+			Expect(decision.Allowed).To(BeTrue())
+			Expect(decision.Remaining).To(Equal(i))
+		}
+	})
+
+	It("should reject requests over the burst with a retry delay", func() {
+		_, _ = store.Take(ctx, "key", policy)
+		_, _ = store.Take(ctx, "key", policy)
+
+		decision, err := store.Take(ctx, "key", policy)
+		Expect(err).To(BeNil())
+		Expect(decision.Allowed).To(BeFalse())
+		Expect(decision.RetryAfter).To(Equal(time.Second))
+	})
+
+	It("should refill tokens over time", func() {
+		_, _ = store.Take(ctx, "key", policy)
+		_, _ = store.Take(ctx, "key", policy)
+
+		now = now.Add(time.Second)
+		decision, err := store.Take(ctx, "key", policy)
+		Expect(err).To(BeNil())
+		Expect(decision.Allowed).To(BeTrue())
+	})
+
+	It("should keep separate buckets per key", func() {
+		_, _ = store.Take(ctx, "key", policy)
+		_, _ = store.Take(ctx, "key", policy)
+
+		decision, err := store.Take(ctx, "other", policy)
+		Expect(err).To(BeNil())
+		Expect(decision.Allowed).To(BeTrue())
+	})
+
+	It("should start a fresh bucket after a swept key", func() {
+		_, _ = store.Take(ctx, "key", policy)
+		_, _ = store.Take(ctx, "key", policy)
+
+		now = now.Add(ratelimit.SweepInterval)
+		decision, err := store.Take(ctx, "key", policy)
+		Expect(err).To(BeNil())
+		Expect(decision.Allowed).To(BeTrue())
+		Expect(decision.Remaining).To(Equal(1))
+	})
+})
+
+var _ = Describe("RateLimitService", func() {
+	policy := ratelimit.NewPolicy("test", config.RateLimitPolicyConfiguration{Requests: config.RateLimitRequests(1), Period: time.Minute, Burst: 1})
+
+	It("should allow every request when disabled", func() {
+		service, err := ratelimit.NewRateLimitService(config.RateLimitConfiguration{Store: config.NoneRateLimitStore})
+		Expect(err).To(BeNil())
+
+		for range 3 {
+			decision, err := service.Allow(context.Background(), policy, "key")
+			Expect(err).To(BeNil())
+			Expect(decision.Allowed).To(BeTrue())
+		}
+	})
+
+	It("should enforce the policy with the memory store", func() {
+		service, err := ratelimit.NewRateLimitService(config.RateLimitConfiguration{Store: config.MemoryRateLimitStore})
+		Expect(err).To(BeNil())
+
+		decision, _ := service.Allow(context.Background(), policy, "key")
+		Expect(decision.Allowed).To(BeTrue())
+		decision, _ = service.Allow(context.Background(), policy, "key")
+		Expect(decision.Allowed).To(BeFalse())
+	})
+
+	It("should allow every request under a policy of no requests", func() {
+		service, err := ratelimit.NewRateLimitService(config.RateLimitConfiguration{Store: config.MemoryRateLimitStore})
+		Expect(err).To(BeNil())
+		rateLimit := config.RateLimitPolicyConfiguration{Requests: config.RateLimitRequests(0)}
+		rateLimit.SetDefaults(config.DefaultLoginRateLimit)
+		disabled := ratelimit.NewPolicy("test", rateLimit)
+
+		for range 3 {
+			decision, err := service.Allow(context.Background(), disabled, "key")
+			Expect(err).To(BeNil())
+			Expect(decision.Allowed).To(BeTrue())
+		}
+	})
+
+	It("should reject an unknown store", func() {
+		_, err := ratelimit.NewRateLimitService(config.RateLimitConfiguration{Store: "redis"})
+		Expect(err).NotTo(BeNil())
+	})
+})
@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/plamen-v/tic-tac-toe/src/services/ratelimit"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRateLimitService struct {
+	mock.Mock
+}
+
+func (m *MockRateLimitService) Allow(ctx context.Context, policy ratelimit.Policy, key string) (*ratelimit.Decision, error) {
+	args := m.Called(ctx, policy, key)
+	var decision *ratelimit.Decision
+	if args.Get(0) != nil {
+		decision = args.Get(0).(*ratelimit.Decision)
+	}
+	return decision, args.Error(1)
+}
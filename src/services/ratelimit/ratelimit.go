@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/plamen-v/tic-tac-toe/src/config"
+)
+
+const (
+	LoginPolicyName string = "login"
+	MovePolicyName  string = "move"
+	APIPolicyName   string = "api"
+)
+
+// Policy is a token bucket: Burst tokens at most, refilled at Rate tokens
+// per second. Every request takes one token. A disabled policy lets every
+// request through.
+type Policy struct {
+	Name     string
+	Rate     float64
+	Burst    int
+	Disabled bool
+}
+
+func NewPolicy(name string, configuration config.RateLimitPolicyConfiguration) Policy {
+	if configuration.Requests == nil || *configuration.Requests == 0 {
+		return Policy{Name: name, Disabled: true}
+	}
+
+	return Policy{
+		Name:  name,
+		Rate:  float64(*configuration.Requests) / configuration.Period.Seconds(),
+		Burst: configuration.Burst,
+	}
+}
+
+type Decision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets. Implementations must apply the take atomically so
+// that several application instances can share one store.
+type Store interface {
+	Take(ctx context.Context, key string, policy Policy) (*Decision, error)
+}
+
+type RateLimitService interface {
+	Allow(ctx context.Context, policy Policy, key string) (*Decision, error)
+}
+
+func NewRateLimitService(configuration config.RateLimitConfiguration) (RateLimitService, error) {
+	switch configuration.Store {
+	case config.NoneRateLimitStore:
+		return &rateLimitServiceImpl{}, nil
+	case config.MemoryRateLimitStore:
+		return &rateLimitServiceImpl{store: NewMemoryStore(time.Now)}, nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store '%s'", configuration.Store)
+	}
+}
+
+func NewRateLimitServiceWithStore(store Store) RateLimitService {
+	return &rateLimitServiceImpl{store: store}
+}
+
+type rateLimitServiceImpl struct {
+	store Store
+}
+
+func (s *rateLimitServiceImpl) Allow(ctx context.Context, policy Policy, key string) (*Decision, error) {
+	if s.store == nil || policy.Disabled {
+		return &Decision{Allowed: true, Limit: policy.Burst, Remaining: policy.Burst}, nil
+	}
+
+	return s.store.Take(ctx, policy.Name+":"+key, policy)
+}
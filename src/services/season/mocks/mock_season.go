@@ -0,0 +1,79 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSeasonService struct {
+	mock.Mock
+}
+
+func (m *MockSeasonService) GetSeasons(ctx context.Context) ([]*domain.Season, error) {
+	args := m.Called(ctx)
+
+	seasons, ok := args.Get(0).([]*domain.Season)
+	if seasons == nil || !ok {
+		return nil, args.Error(1)
+	}
+
+	return seasons, args.Error(1)
+}
+
+func (m *MockSeasonService) GetCurrentSeason(ctx context.Context) (*domain.Season, error) {
+	args := m.Called(ctx)
+
+	season, ok := args.Get(0).(*domain.Season)
+	if season == nil || !ok {
+		return nil, args.Error(1)
+	}
+
+	return season, args.Error(1)
+}
+
+func (m *MockSeasonService) GetStandings(ctx context.Context, seasonID uuid.UUID, page int, pageSize int) (*domain.StandingsResponse, error) {
+	args := m.Called(ctx, seasonID, page, pageSize)
+
+	response, ok := args.Get(0).(*domain.StandingsResponse)
+	if response == nil || !ok {
+		return nil, args.Error(1)
+	}
+
+	return response, args.Error(1)
+}
+
+func (m *MockSeasonService) CreateSeason(ctx context.Context, request *domain.CreateSeasonRequest) (*domain.Season, error) {
+	args := m.Called(ctx, request)
+
+	season, ok := args.Get(0).(*domain.Season)
+	if season == nil || !ok {
+		return nil, args.Error(1)
+	}
+
+	return season, args.Error(1)
+}
+
+func (m *MockSeasonService) ArchiveSeason(ctx context.Context, seasonID uuid.UUID) (*domain.Season, error) {
+	args := m.Called(ctx, seasonID)
+
+	season, ok := args.Get(0).(*domain.Season)
+	if season == nil || !ok {
+		return nil, args.Error(1)
+	}
+
+	return season, args.Error(1)
+}
+
+func (m *MockSeasonService) GetLeaderboard(ctx context.Context, period domain.LeaderboardPeriod, page int, pageSize int) (*domain.LeaderboardResponse, error) {
+	args := m.Called(ctx, period, page, pageSize)
+
+	response, ok := args.Get(0).(*domain.LeaderboardResponse)
+	if response == nil || !ok {
+		return nil, args.Error(1)
+	}
+
+	return response, args.Error(1)
+}
@@ -0,0 +1,206 @@
+package season
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/services/logger"
+)
+
+const (
+	DefaultPageSize     int = 10
+	SeasonNameMaxLength int = 50
+)
+
+var (
+	InvalidSeasonNameErrorMessage     string = "season name must be between 1 and 50 characters"
+	InvalidSeasonDatesErrorMessage    string = "season must end after it starts"
+	InvalidLeaderboardErrorMessage    string = "leaderboard period must be daily, weekly or monthly"
+	SeasonNotEndedErrorMessage        string = "season has not ended yet"
+	SeasonAlreadyArchivedErrorMessage string = "season is already archived"
+)
+
+// SeasonService manages seasons and serves their standings and the
+// leaderboards of the running day, week and month. Season stats are recorded
+// by the game engine when games complete.
+type SeasonService interface {
+	GetSeasons(context.Context) ([]*domain.Season, error)
+	GetCurrentSeason(context.Context) (*domain.Season, error)
+	GetStandings(context.Context, uuid.UUID, int, int) (*domain.StandingsResponse, error)
+	CreateSeason(context.Context, *domain.CreateSeasonRequest) (*domain.Season, error)
+	ArchiveSeason(context.Context, uuid.UUID) (*domain.Season, error)
+	GetLeaderboard(context.Context, domain.LeaderboardPeriod, int, int) (*domain.LeaderboardResponse, error)
+}
+
+func NewSeasonService(db *sql.DB,
+	now func() time.Time,
+	seasonRepositoryFactory func(q repository.Querier) repository.SeasonRepository,
+	gameRepositoryFactory func(q repository.Querier) repository.GameRepository) SeasonService {
+	return &seasonServiceImpl{
+		db:                      db,
+		now:                     now,
+		seasonRepositoryFactory: seasonRepositoryFactory,
+		gameRepositoryFactory:   gameRepositoryFactory,
+	}
+}
+
+type seasonServiceImpl struct {
+	db                      *sql.DB
+	now                     func() time.Time
+	seasonRepositoryFactory func(q repository.Querier) repository.SeasonRepository
+	gameRepositoryFactory   func(q repository.Querier) repository.GameRepository
+}
+
+func (s *seasonServiceImpl) GetSeasons(ctx context.Context) ([]*domain.Season, error) {
+	return s.seasonRepositoryFactory(s.db).GetList(ctx)
+}
+
+func (s *seasonServiceImpl) GetCurrentSeason(ctx context.Context) (*domain.Season, error) {
+	return s.seasonRepositoryFactory(s.db).GetCurrent(ctx, s.now())
+}
+
+func (s *seasonServiceImpl) GetStandings(ctx context.Context, seasonID uuid.UUID, page int, pageSize int) (*domain.StandingsResponse, error) {
+	if pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+
+	seasonRepository := s.seasonRepositoryFactory(s.db)
+	season, err := seasonRepository.Get(ctx, seasonID)
+	if err != nil {
+		return nil, err
+	}
+
+	standings, pageSize, page, totalCnt, err := seasonRepository.GetStandings(ctx, season, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.StandingsResponse{
+		Season:    season,
+		Standings: standings,
+		PageInfo: models.PageInfo{
+			Page:     page,
+			PageSize: pageSize,
+			TotalCnt: totalCnt,
+		},
+	}, nil
+}
+
+func (s *seasonServiceImpl) CreateSeason(ctx context.Context, request *domain.CreateSeasonRequest) (*domain.Season, error) {
+	if request == nil {
+		return nil, models.NewValidationError(InvalidSeasonNameErrorMessage)
+	}
+
+	name := strings.TrimSpace(request.Name)
+	if length := utf8.RuneCountInString(name); length < 1 || length > SeasonNameMaxLength {
+		return nil, models.NewValidationError(InvalidSeasonNameErrorMessage)
+	}
+
+	if !request.EndsAt.After(request.StartsAt) {
+		return nil, models.NewValidationError(InvalidSeasonDatesErrorMessage)
+	}
+
+	season := &domain.Season{
+		Name:     name,
+		StartsAt: request.StartsAt.UTC(),
+		EndsAt:   request.EndsAt.UTC(),
+	}
+
+	id, err := s.seasonRepositoryFactory(s.db).Create(ctx, season)
+	if err != nil {
+		return nil, err
+	}
+	season.ID = id
+
+	logger.FromContext(ctx).Info("season created",
+		logger.String("season_id", id.String()),
+		logger.String("name", name))
+
+	return season, nil
+}
+
+// ArchiveSeason stores the final standings of a season that has ended.
+func (s *seasonServiceImpl) ArchiveSeason(ctx context.Context, seasonID uuid.UUID) (*domain.Season, error) {
+	season, err := repository.WithTransactionT(ctx, s.db, func(tx *sql.Tx) (*domain.Season, error) {
+		seasonRepository := s.seasonRepositoryFactory(tx)
+		season, err := seasonRepository.Get(ctx, seasonID)
+		if err != nil {
+			return nil, err
+		}
+
+		if season.ArchivedAt != nil {
+			return nil, models.NewValidationError(SeasonAlreadyArchivedErrorMessage)
+		}
+
+		if s.now().Before(season.EndsAt) {
+			return nil, models.NewValidationError(SeasonNotEndedErrorMessage)
+		}
+
+		if err = seasonRepository.Archive(ctx, seasonID); err != nil {
+			return nil, err
+		}
+
+		return seasonRepository.Get(ctx, seasonID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("season archived",
+		logger.String("season_id", seasonID.String()))
+
+	return season, nil
+}
+
+// GetLeaderboard ranks the players by the games completed in the running UTC
+// day, ISO week or calendar month.
+func (s *seasonServiceImpl) GetLeaderboard(ctx context.Context, period domain.LeaderboardPeriod, page int, pageSize int) (*domain.LeaderboardResponse, error) {
+	from, err := s.periodStart(period)
+	if err != nil {
+		return nil, err
+	}
+
+	if pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+
+	standings, pageSize, page, totalCnt, err := s.gameRepositoryFactory(s.db).GetLeaderboard(ctx, from, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.LeaderboardResponse{
+		Period:    period,
+		From:      from,
+		Standings: standings,
+		PageInfo: models.PageInfo{
+			Page:     page,
+			PageSize: pageSize,
+			TotalCnt: totalCnt,
+		},
+	}, nil
+}
+
+func (s *seasonServiceImpl) periodStart(period domain.LeaderboardPeriod) (time.Time, error) {
+	now := s.now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch period {
+	case domain.LeaderboardDaily:
+		return today, nil
+	case domain.LeaderboardWeekly:
+		// ISO weeks start on Monday.
+		return today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7)), nil
+	case domain.LeaderboardMonthly:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	default:
+		return time.Time{}, models.NewValidationError(InvalidLeaderboardErrorMessage)
+	}
+}
@@ -0,0 +1,210 @@
+package season_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofrs/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/repository/mocks"
+	"github.com/plamen-v/tic-tac-toe/src/services/season"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("Season", func() {
+	var (
+		db                   *sql.DB
+		mock                 sqlmock.Sqlmock
+		ctx                  context.Context
+		now                  time.Time
+		mockSeasonRepository *mocks.MockSeasonRepository
+		mockGameRepository   *mocks.MockGameRepository
+		seasonService        season.SeasonService
+		seasonID             uuid.UUID
+		err                  error
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		db, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		// Thursday
+		now = time.Date(2024, time.May, 16, 15, 30, 0, 0, time.UTC)
+		mockSeasonRepository = new(mocks.MockSeasonRepository)
+		mockGameRepository = new(mocks.MockGameRepository)
+		seasonID = uuid.Must(uuid.NewV4())
+		seasonService = season.NewSeasonService(
+			db,
+			func() time.Time { return now },
+			func(db repository.Querier) repository.SeasonRepository {
+				return mockSeasonRepository
+			},
+			func(db repository.Querier) repository.GameRepository {
+				return mockGameRepository
+			},
+		)
+	})
+
+	AfterEach(func() {
+		err = mock.ExpectationsWereMet()
+		Expect(err).ToNot(HaveOccurred())
+		db.Close()
+	})
+
+	Context("GetStandings", func() {
+		It("should return the standings of the season", func() {
+			s := &domain.Season{ID: seasonID, Name: "Spring"}
+			standings := []*domain.Standing{{Rank: 1, Rating: 1250}}
+			mockSeasonRepository.On("Get", ctx, seasonID).Return(s, nil)
+			mockSeasonRepository.On("GetStandings", ctx, s, 2, season.DefaultPageSize).
+				Return(standings, season.DefaultPageSize, 2, 11, nil)
+
+			response, err := seasonService.GetStandings(ctx, seasonID, 2, 0)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Season).To(Equal(s))
+			Expect(response.Standings).To(Equal(standings))
+			Expect(response.PageInfo).To(Equal(models.PageInfo{Page: 2, PageSize: season.DefaultPageSize, TotalCnt: 11}))
+		})
+
+		It("should return error if the season does not exist", func() {
+			mockSeasonRepository.On("Get", ctx, seasonID).Return(nil, models.NewNotFoundError("season not exist"))
+
+			_, err := seasonService.GetStandings(ctx, seasonID, 1, 10)
+
+			Expect(models.IsNotFoundError(err)).To(BeTrue())
+			mockSeasonRepository.AssertNotCalled(GinkgoT(), "GetStandings", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything)
+		})
+	})
+
+	Context("CreateSeason", func() {
+		It("should create the season", func() {
+			request := &domain.CreateSeasonRequest{
+				Name:     " Spring ",
+				StartsAt: now,
+				EndsAt:   now.AddDate(0, 3, 0),
+			}
+			mockSeasonRepository.On("Create", ctx, tmock.Anything).Return(seasonID, nil)
+
+			s, err := seasonService.CreateSeason(ctx, request)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.ID).To(Equal(seasonID))
+			Expect(s.Name).To(Equal("Spring"))
+		})
+
+		It("should return error if the season ends before it starts", func() {
+			request := &domain.CreateSeasonRequest{
+				Name:     "Spring",
+				StartsAt: now,
+				EndsAt:   now,
+			}
+
+			_, err := seasonService.CreateSeason(ctx, request)
+
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+			Expect(err.Error()).To(ContainSubstring(season.InvalidSeasonDatesErrorMessage))
+			mockSeasonRepository.AssertNotCalled(GinkgoT(), "Create", tmock.Anything, tmock.Anything)
+		})
+
+		It("should return error if the name is empty", func() {
+			request := &domain.CreateSeasonRequest{
+				Name:     "  ",
+				StartsAt: now,
+				EndsAt:   now.AddDate(0, 3, 0),
+			}
+
+			_, err := seasonService.CreateSeason(ctx, request)
+
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+			Expect(err.Error()).To(ContainSubstring(season.InvalidSeasonNameErrorMessage))
+		})
+	})
+
+	Context("ArchiveSeason", func() {
+		It("should archive a season that has ended", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			archivedAt := now
+			s := &domain.Season{ID: seasonID, EndsAt: now.Add(-time.Hour)}
+			archived := &domain.Season{ID: seasonID, EndsAt: s.EndsAt, ArchivedAt: &archivedAt}
+			mockSeasonRepository.On("Get", ctx, seasonID).Return(s, nil).Once()
+			mockSeasonRepository.On("Archive", ctx, seasonID).Return(nil)
+			mockSeasonRepository.On("Get", ctx, seasonID).Return(archived, nil).Once()
+
+			result, err := seasonService.ArchiveSeason(ctx, seasonID)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(archived))
+		})
+
+		It("should return error if the season has not ended", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+			s := &domain.Season{ID: seasonID, EndsAt: now.Add(time.Hour)}
+			mockSeasonRepository.On("Get", ctx, seasonID).Return(s, nil)
+
+			_, err := seasonService.ArchiveSeason(ctx, seasonID)
+
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+			Expect(err.Error()).To(ContainSubstring(season.SeasonNotEndedErrorMessage))
+			mockSeasonRepository.AssertNotCalled(GinkgoT(), "Archive", tmock.Anything, tmock.Anything)
+		})
+
+		It("should return error if the season is already archived", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+			archivedAt := now.Add(-time.Minute)
+			s := &domain.Season{ID: seasonID, EndsAt: now.Add(-time.Hour), ArchivedAt: &archivedAt}
+			mockSeasonRepository.On("Get", ctx, seasonID).Return(s, nil)
+
+			_, err := seasonService.ArchiveSeason(ctx, seasonID)
+
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+			Expect(err.Error()).To(ContainSubstring(season.SeasonAlreadyArchivedErrorMessage))
+		})
+	})
+
+	Context("GetLeaderboard", func() {
+		DescribeTable("should read the games completed since the start of the period",
+			func(period domain.LeaderboardPeriod, from time.Time) {
+				mockGameRepository.On("GetLeaderboard", ctx, from, 1, 10).
+					Return([]*domain.Standing{}, 10, 0, 0, nil)
+
+				response, err := seasonService.GetLeaderboard(ctx, period, 1, 10)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(response.Period).To(Equal(period))
+				Expect(response.From).To(Equal(from))
+			},
+			Entry("daily", domain.LeaderboardDaily, time.Date(2024, time.May, 16, 0, 0, 0, 0, time.UTC)),
+			Entry("weekly", domain.LeaderboardWeekly, time.Date(2024, time.May, 13, 0, 0, 0, 0, time.UTC)),
+			Entry("monthly", domain.LeaderboardMonthly, time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)),
+		)
+
+		It("should start the week on Monday when today is Sunday", func() {
+			now = time.Date(2024, time.May, 19, 23, 0, 0, 0, time.UTC)
+			from := time.Date(2024, time.May, 13, 0, 0, 0, 0, time.UTC)
+			mockGameRepository.On("GetLeaderboard", ctx, from, 1, 10).
+				Return([]*domain.Standing{}, 10, 0, 0, nil)
+
+			response, err := seasonService.GetLeaderboard(ctx, domain.LeaderboardWeekly, 1, 10)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.From).To(Equal(from))
+		})
+
+		It("should return error for an unknown period", func() {
+			_, err := seasonService.GetLeaderboard(ctx, domain.LeaderboardPeriod("yearly"), 1, 10)
+
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+			mockGameRepository.AssertNotCalled(GinkgoT(), "GetLeaderboard", tmock.Anything, tmock.Anything, tmock.Anything, tmock.Anything)
+		})
+	})
+})
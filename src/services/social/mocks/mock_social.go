@@ -0,0 +1,62 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSocialService struct {
+	mock.Mock
+}
+
+func (m *MockSocialService) GetFriends(ctx context.Context, playerID uuid.UUID) ([]*domain.Friend, error) {
+	args := m.Called(ctx, playerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Friend), args.Error(1)
+}
+
+func (m *MockSocialService) RequestFriend(ctx context.Context, playerID uuid.UUID, otherID uuid.UUID) error {
+	args := m.Called(ctx, playerID, otherID)
+	return args.Error(0)
+}
+
+func (m *MockSocialService) AcceptFriend(ctx context.Context, playerID uuid.UUID, requesterID uuid.UUID) error {
+	args := m.Called(ctx, playerID, requesterID)
+	return args.Error(0)
+}
+
+func (m *MockSocialService) RemoveFriend(ctx context.Context, playerID uuid.UUID, otherID uuid.UUID) error {
+	args := m.Called(ctx, playerID, otherID)
+	return args.Error(0)
+}
+
+func (m *MockSocialService) GetBlocked(ctx context.Context, playerID uuid.UUID) ([]*domain.BlockedPlayer, error) {
+	args := m.Called(ctx, playerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.BlockedPlayer), args.Error(1)
+}
+
+func (m *MockSocialService) Block(ctx context.Context, playerID uuid.UUID, otherID uuid.UUID) error {
+	args := m.Called(ctx, playerID, otherID)
+	return args.Error(0)
+}
+
+func (m *MockSocialService) Unblock(ctx context.Context, playerID uuid.UUID, otherID uuid.UUID) error {
+	args := m.Called(ctx, playerID, otherID)
+	return args.Error(0)
+}
+
+func (m *MockSocialService) Invite(ctx context.Context, playerID uuid.UUID, inviteeID uuid.UUID, title string, description string) (uuid.UUID, error) {
+	args := m.Called(ctx, playerID, inviteeID, title, description)
+	if args.Get(0) == nil {
+		return uuid.Nil, args.Error(1)
+	}
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
@@ -0,0 +1,230 @@
+package social
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/apperrors"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/services/engine"
+	"github.com/plamen-v/tic-tac-toe/src/services/logger"
+	"github.com/plamen-v/tic-tac-toe/src/services/notifier"
+	"github.com/plamen-v/tic-tac-toe/src/services/presence"
+)
+
+var (
+	SelfErrorMessage           string = "player can not do this with himself"
+	AlreadyFriendsErrorMessage string = "players are already friends"
+	RequestAlreadySentMessage  string = "friend request is already sent"
+	PlayerBlockedErrorMessage  string = "player is blocked"
+	InvitationSubject          string = "You are invited to a game"
+)
+
+// SocialService manages the friends and the blocked players of a player and
+// sends game invitations. Blocking works both ways: neither of the players
+// can send the other friend requests or invitations or join the rooms of the
+// other, and a friendship between them is removed.
+type SocialService interface {
+	GetFriends(context.Context, uuid.UUID) ([]*domain.Friend, error)
+	RequestFriend(context.Context, uuid.UUID, uuid.UUID) error
+	AcceptFriend(context.Context, uuid.UUID, uuid.UUID) error
+	RemoveFriend(context.Context, uuid.UUID, uuid.UUID) error
+	GetBlocked(context.Context, uuid.UUID) ([]*domain.BlockedPlayer, error)
+	Block(context.Context, uuid.UUID, uuid.UUID) error
+	Unblock(context.Context, uuid.UUID, uuid.UUID) error
+	Invite(context.Context, uuid.UUID, uuid.UUID, string, string) (uuid.UUID, error)
+}
+
+func NewSocialService(db *sql.DB,
+	gameEngineService engine.GameEngineService,
+	presenceService presence.PresenceService,
+	notifier notifier.Notifier,
+	playerRepositoryFactory func(q repository.Querier) repository.PlayerRepository,
+	friendshipRepositoryFactory func(q repository.Querier) repository.FriendshipRepository,
+	blockRepositoryFactory func(q repository.Querier) repository.BlockRepository) SocialService {
+	return &socialServiceImpl{
+		db:                          db,
+		gameEngineService:           gameEngineService,
+		presenceService:             presenceService,
+		notifier:                    notifier,
+		playerRepositoryFactory:     playerRepositoryFactory,
+		friendshipRepositoryFactory: friendshipRepositoryFactory,
+		blockRepositoryFactory:      blockRepositoryFactory,
+	}
+}
+
+type socialServiceImpl struct {
+	db                          *sql.DB
+	gameEngineService           engine.GameEngineService
+	presenceService             presence.PresenceService
+	notifier                    notifier.Notifier
+	playerRepositoryFactory     func(q repository.Querier) repository.PlayerRepository
+	friendshipRepositoryFactory func(q repository.Querier) repository.FriendshipRepository
+	blockRepositoryFactory      func(q repository.Querier) repository.BlockRepository
+}
+
+// GetFriends returns the friends and the pending requests of a player. Only
+// accepted friends see the presence of each other.
+func (s *socialServiceImpl) GetFriends(ctx context.Context, playerID uuid.UUID) ([]*domain.Friend, error) {
+	friends, err := s.friendshipRepositoryFactory(s.db).GetByPlayer(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	friendIDs := make([]uuid.UUID, 0, len(friends))
+	for _, friend := range friends {
+		if friend.Status == domain.FriendStatusAccepted {
+			friendIDs = append(friendIDs, friend.ID)
+		}
+	}
+
+	presences, err := s.presenceService.GetPresence(ctx, friendIDs...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, friend := range friends {
+		friend.Presence = presences[friend.ID]
+	}
+
+	return friends, nil
+}
+
+// RequestFriend sends a friend request. A pending request in the other
+// direction is accepted instead.
+func (s *socialServiceImpl) RequestFriend(ctx context.Context, playerID uuid.UUID, otherID uuid.UUID) error {
+	if playerID == otherID {
+		return models.NewValidationError(SelfErrorMessage)
+	}
+
+	return repository.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.validateOther(ctx, tx, playerID, otherID); err != nil {
+			return err
+		}
+
+		friendshipRepository := s.friendshipRepositoryFactory(tx)
+		friendship, err := friendshipRepository.Get(ctx, playerID, otherID)
+		if err != nil {
+			if models.IsNotFoundError(err) {
+				return friendshipRepository.Create(ctx, playerID, otherID)
+			}
+			return err
+		}
+
+		if friendship.Accepted {
+			return models.NewValidationError(AlreadyFriendsErrorMessage)
+		}
+
+		if friendship.RequesterID == playerID {
+			return models.NewValidationError(RequestAlreadySentMessage)
+		}
+
+		return friendshipRepository.Accept(ctx, otherID, playerID)
+	})
+}
+
+func (s *socialServiceImpl) AcceptFriend(ctx context.Context, playerID uuid.UUID, requesterID uuid.UUID) error {
+	return s.friendshipRepositoryFactory(s.db).Accept(ctx, requesterID, playerID)
+}
+
+// RemoveFriend ends a friendship, withdraws a sent request or declines a
+// received one.
+func (s *socialServiceImpl) RemoveFriend(ctx context.Context, playerID uuid.UUID, otherID uuid.UUID) error {
+	return s.friendshipRepositoryFactory(s.db).Delete(ctx, playerID, otherID)
+}
+
+func (s *socialServiceImpl) GetBlocked(ctx context.Context, playerID uuid.UUID) ([]*domain.BlockedPlayer, error) {
+	return s.blockRepositoryFactory(s.db).GetByPlayer(ctx, playerID)
+}
+
+func (s *socialServiceImpl) Block(ctx context.Context, playerID uuid.UUID, otherID uuid.UUID) error {
+	if playerID == otherID {
+		return models.NewValidationError(SelfErrorMessage)
+	}
+
+	err := repository.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		if _, err := s.playerRepositoryFactory(tx).GetProfile(ctx, otherID); err != nil {
+			return err
+		}
+
+		if err := s.blockRepositoryFactory(tx).Create(ctx, playerID, otherID); err != nil {
+			return err
+		}
+
+		err := s.friendshipRepositoryFactory(tx).Delete(ctx, playerID, otherID)
+		if err != nil && !models.IsNotFoundError(err) {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("player blocked", logger.String("target_player_id", otherID.String()))
+	return nil
+}
+
+func (s *socialServiceImpl) Unblock(ctx context.Context, playerID uuid.UUID, otherID uuid.UUID) error {
+	return s.blockRepositoryFactory(s.db).Delete(ctx, playerID, otherID)
+}
+
+// Invite creates a private room for the invited player and lets them know by
+// email when they have an address. A failed notification does not fail the
+// invitation; it is listed among the invitations of the player either way.
+func (s *socialServiceImpl) Invite(ctx context.Context, playerID uuid.UUID, inviteeID uuid.UUID, title string, description string) (uuid.UUID, error) {
+	roomID, err := s.gameEngineService.InvitePlayer(ctx, playerID, inviteeID, title, description)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err = s.notifyInvitee(ctx, playerID, inviteeID, title); err != nil {
+		logger.FromContext(ctx).Error("invitation notification failed",
+			logger.String("room_id", roomID.String()),
+			logger.Err(err))
+	}
+
+	return roomID, nil
+}
+
+func (s *socialServiceImpl) notifyInvitee(ctx context.Context, playerID uuid.UUID, inviteeID uuid.UUID, title string) error {
+	playerRepository := s.playerRepositoryFactory(s.db)
+	email, err := playerRepository.GetEmail(ctx, inviteeID)
+	if err != nil || len(email) == 0 {
+		return err
+	}
+
+	host, err := playerRepository.Get(ctx, playerID)
+	if err != nil {
+		return err
+	}
+
+	return s.notifier.Notify(ctx, &notifier.Message{
+		To:      email,
+		Subject: InvitationSubject,
+		Body:    fmt.Sprintf("%s invited you to a game in the room \"%s\". Open your invitations to join or decline.\n", host.Nickname, title),
+	})
+}
+
+// validateOther checks that the other player exists and that neither of the
+// players blocked the other.
+func (s *socialServiceImpl) validateOther(ctx context.Context, q repository.Querier, playerID uuid.UUID, otherID uuid.UUID) error {
+	if _, err := s.playerRepositoryFactory(q).GetProfile(ctx, otherID); err != nil {
+		return err
+	}
+
+	blocked, err := s.blockRepositoryFactory(q).Exists(ctx, playerID, otherID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return apperrors.NewForbiddenError(PlayerBlockedErrorMessage)
+	}
+
+	return nil
+}
@@ -0,0 +1,258 @@
+package social_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofrs/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/apperrors"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/repository/mocks"
+	enginemocks "github.com/plamen-v/tic-tac-toe/src/services/engine/mocks"
+	"github.com/plamen-v/tic-tac-toe/src/services/notifier"
+	notifiermocks "github.com/plamen-v/tic-tac-toe/src/services/notifier/mocks"
+	presencemocks "github.com/plamen-v/tic-tac-toe/src/services/presence/mocks"
+	"github.com/plamen-v/tic-tac-toe/src/services/social"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("Social", func() {
+	var (
+		db                       *sql.DB
+		mock                     sqlmock.Sqlmock
+		ctx                      context.Context
+		mockGameEngineService    *enginemocks.MockGameEngineService
+		mockPresenceService      *presencemocks.MockPresenceService
+		mockNotifier             *notifiermocks.MockNotifier
+		mockPlayerRepository     *mocks.MockPlayerRepository
+		mockFriendshipRepository *mocks.MockFriendshipRepository
+		mockBlockRepository      *mocks.MockBlockRepository
+		socialService            social.SocialService
+		playerID                 uuid.UUID
+		otherID                  uuid.UUID
+		err                      error
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		db, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		mockGameEngineService = new(enginemocks.MockGameEngineService)
+		mockPresenceService = new(presencemocks.MockPresenceService)
+		mockNotifier = new(notifiermocks.MockNotifier)
+		mockPlayerRepository = new(mocks.MockPlayerRepository)
+		mockFriendshipRepository = new(mocks.MockFriendshipRepository)
+		mockBlockRepository = new(mocks.MockBlockRepository)
+		playerID = uuid.Must(uuid.NewV4())
+		otherID = uuid.Must(uuid.NewV4())
+		socialService = social.NewSocialService(
+			db,
+			mockGameEngineService,
+			mockPresenceService,
+			mockNotifier,
+			func(db repository.Querier) repository.PlayerRepository {
+				return mockPlayerRepository
+			},
+			func(db repository.Querier) repository.FriendshipRepository {
+				return mockFriendshipRepository
+			},
+			func(db repository.Querier) repository.BlockRepository {
+				return mockBlockRepository
+			},
+		)
+	})
+
+	AfterEach(func() {
+		err = mock.ExpectationsWereMet()
+		Expect(err).ToNot(HaveOccurred())
+		db.Close()
+	})
+
+	Context("GetFriends", func() {
+		It("should return the presence of accepted friends only", func() {
+			pendingID := uuid.Must(uuid.NewV4())
+			mockFriendshipRepository.On("GetByPlayer", ctx, playerID).Return([]*domain.Friend{
+				{ID: otherID, Status: domain.FriendStatusAccepted},
+				{ID: pendingID, Status: domain.FriendStatusIncoming},
+			}, nil)
+			mockPresenceService.On("GetPresence", ctx, []uuid.UUID{otherID}).
+				Return(map[uuid.UUID]*domain.Presence{otherID: {Status: domain.PresenceInGame}}, nil)
+
+			friends, err := socialService.GetFriends(ctx, playerID)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(friends).To(HaveLen(2))
+			Expect(friends[0].Presence.Status).To(Equal(domain.PresenceInGame))
+			Expect(friends[1].Presence).To(BeNil())
+		})
+	})
+
+	Context("RequestFriend", func() {
+		It("should create a friend request", func() {
+			mock.ExpectBegin()
+			mockPlayerRepository.On("GetProfile", ctx, otherID).Return(&domain.Profile{ID: otherID}, nil)
+			mockBlockRepository.On("Exists", ctx, playerID, otherID).Return(false, nil)
+			mockFriendshipRepository.On("Get", ctx, playerID, otherID).Return(nil, models.NewNotFoundError("not found"))
+			mockFriendshipRepository.On("Create", ctx, playerID, otherID).Return(nil)
+			mock.ExpectCommit()
+
+			err = socialService.RequestFriend(ctx, playerID, otherID)
+
+			Expect(err).ToNot(HaveOccurred())
+			mockFriendshipRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should accept a request sent by the other player", func() {
+			mock.ExpectBegin()
+			mockPlayerRepository.On("GetProfile", ctx, otherID).Return(&domain.Profile{ID: otherID}, nil)
+			mockBlockRepository.On("Exists", ctx, playerID, otherID).Return(false, nil)
+			mockFriendshipRepository.On("Get", ctx, playerID, otherID).
+				Return(&domain.Friendship{RequesterID: otherID, AddresseeID: playerID}, nil)
+			mockFriendshipRepository.On("Accept", ctx, otherID, playerID).Return(nil)
+			mock.ExpectCommit()
+
+			err = socialService.RequestFriend(ctx, playerID, otherID)
+
+			Expect(err).ToNot(HaveOccurred())
+			mockFriendshipRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should reject a repeated request", func() {
+			mock.ExpectBegin()
+			mockPlayerRepository.On("GetProfile", ctx, otherID).Return(&domain.Profile{ID: otherID}, nil)
+			mockBlockRepository.On("Exists", ctx, playerID, otherID).Return(false, nil)
+			mockFriendshipRepository.On("Get", ctx, playerID, otherID).
+				Return(&domain.Friendship{RequesterID: playerID, AddresseeID: otherID}, nil)
+			mock.ExpectRollback()
+
+			err = socialService.RequestFriend(ctx, playerID, otherID)
+
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+			Expect(err.Error()).To(Equal(social.RequestAlreadySentMessage))
+		})
+
+		It("should reject a request between blocked players", func() {
+			mock.ExpectBegin()
+			mockPlayerRepository.On("GetProfile", ctx, otherID).Return(&domain.Profile{ID: otherID}, nil)
+			mockBlockRepository.On("Exists", ctx, playerID, otherID).Return(true, nil)
+			mock.ExpectRollback()
+
+			err = socialService.RequestFriend(ctx, playerID, otherID)
+
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(&apperrors.ForbiddenError{}))
+			mockFriendshipRepository.AssertNotCalled(GinkgoT(), "Create", tmock.Anything, tmock.Anything, tmock.Anything)
+		})
+
+		It("should reject a request to himself", func() {
+			err = socialService.RequestFriend(ctx, playerID, playerID)
+
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+		})
+	})
+
+	Context("Block", func() {
+		It("should block the player and remove the friendship", func() {
+			mock.ExpectBegin()
+			mockPlayerRepository.On("GetProfile", ctx, otherID).Return(&domain.Profile{ID: otherID}, nil)
+			mockBlockRepository.On("Create", ctx, playerID, otherID).Return(nil)
+			mockFriendshipRepository.On("Delete", ctx, playerID, otherID).Return(nil)
+			mock.ExpectCommit()
+
+			err = socialService.Block(ctx, playerID, otherID)
+
+			Expect(err).ToNot(HaveOccurred())
+			mockBlockRepository.AssertExpectations(GinkgoT())
+			mockFriendshipRepository.AssertExpectations(GinkgoT())
+		})
+
+		It("should block a player who is not a friend", func() {
+			mock.ExpectBegin()
+			mockPlayerRepository.On("GetProfile", ctx, otherID).Return(&domain.Profile{ID: otherID}, nil)
+			mockBlockRepository.On("Create", ctx, playerID, otherID).Return(nil)
+			mockFriendshipRepository.On("Delete", ctx, playerID, otherID).Return(models.NewNotFoundError("not found"))
+			mock.ExpectCommit()
+
+			err = socialService.Block(ctx, playerID, otherID)
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should return error when the player does not exist", func() {
+			mock.ExpectBegin()
+			mockPlayerRepository.On("GetProfile", ctx, otherID).Return(nil, models.NewNotFoundError("not found"))
+			mock.ExpectRollback()
+
+			err = socialService.Block(ctx, playerID, otherID)
+
+			Expect(err).To(HaveOccurred())
+			Expect(models.IsNotFoundError(err)).To(BeTrue())
+		})
+	})
+
+	Context("Invite", func() {
+		var roomID uuid.UUID
+
+		BeforeEach(func() {
+			roomID = uuid.Must(uuid.NewV4())
+		})
+
+		It("should create the room and notify the invited player", func() {
+			var sent *notifier.Message
+			mockGameEngineService.On("InvitePlayer", ctx, playerID, otherID, "title", "description").Return(roomID, nil)
+			mockPlayerRepository.On("GetEmail", ctx, otherID).Return("other@example.com", nil)
+			mockPlayerRepository.On("Get", ctx, playerID).Return(&models.Player{ID: playerID, Nickname: "host"}, nil)
+			mockNotifier.On("Notify", ctx, tmock.Anything).
+				Run(func(args tmock.Arguments) { sent = args.Get(1).(*notifier.Message) }).
+				Return(nil)
+
+			result, err := socialService.Invite(ctx, playerID, otherID, "title", "description")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(roomID))
+			Expect(sent.To).To(Equal("other@example.com"))
+			Expect(sent.Body).To(ContainSubstring("host"))
+		})
+
+		It("should not notify a player without email", func() {
+			mockGameEngineService.On("InvitePlayer", ctx, playerID, otherID, "title", "").Return(roomID, nil)
+			mockPlayerRepository.On("GetEmail", ctx, otherID).Return("", nil)
+
+			result, err := socialService.Invite(ctx, playerID, otherID, "title", "")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(roomID))
+			mockNotifier.AssertNotCalled(GinkgoT(), "Notify", tmock.Anything, tmock.Anything)
+		})
+
+		It("should not fail when the notification fails", func() {
+			mockGameEngineService.On("InvitePlayer", ctx, playerID, otherID, "title", "").Return(roomID, nil)
+			mockPlayerRepository.On("GetEmail", ctx, otherID).Return("other@example.com", nil)
+			mockPlayerRepository.On("Get", ctx, playerID).Return(&models.Player{ID: playerID, Nickname: "host"}, nil)
+			mockNotifier.On("Notify", ctx, tmock.Anything).Return(errors.New("smtp error"))
+
+			result, err := socialService.Invite(ctx, playerID, otherID, "title", "")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(roomID))
+		})
+
+		It("should return the error of the engine", func() {
+			mockGameEngineService.On("InvitePlayer", ctx, playerID, otherID, "title", "").
+				Return(nil, apperrors.NewForbiddenError("player is blocked"))
+
+			_, err := socialService.Invite(ctx, playerID, otherID, "title", "")
+
+			Expect(err).To(HaveOccurred())
+			mockNotifier.AssertNotCalled(GinkgoT(), "Notify", tmock.Anything, tmock.Anything)
+		})
+	})
+})
@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/plamen-v/tic-tac-toe/src/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const InstrumentationName string = "github.com/plamen-v/tic-tac-toe"
+
+type TracingService interface {
+	Shutdown(context.Context) error
+}
+
+// NewTracingService installs the global tracer provider and the W3C trace
+// context propagator. With the "none" exporter spans are still created, so
+// incoming trace context is propagated, but nothing is exported.
+func NewTracingService(appName string, configuration config.TracingConfiguration) (TracingService, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(*configuration.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(appName))),
+	}
+
+	exporter, err := newExporter(configuration)
+	if err != nil {
+		return nil, err
+	}
+	if exporter != nil {
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+
+	return &tracingServiceImpl{provider: provider}, nil
+}
+
+type tracingServiceImpl struct {
+	provider *sdktrace.TracerProvider
+}
+
+func (t *tracingServiceImpl) Shutdown(ctx context.Context) error {
+	return t.provider.Shutdown(ctx)
+}
+
+func newExporter(configuration config.TracingConfiguration) (sdktrace.SpanExporter, error) {
+	switch configuration.Exporter {
+	case config.NoneTracingExporter:
+		return nil, nil
+	case config.StdoutTracingExporter:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case config.OTLPTracingExporter:
+		options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(configuration.Endpoint)}
+		if configuration.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(context.Background(), options...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter '%s'", configuration.Exporter)
+	}
+}
+
+// Tracer returns the named tracer from the global provider.
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer(InstrumentationName + "/" + name)
+}
+
+// End records err on span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
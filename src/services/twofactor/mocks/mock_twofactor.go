@@ -0,0 +1,60 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTwoFactorService struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorService) Status(ctx context.Context, playerID uuid.UUID) (*domain.TwoFactorStatus, error) {
+	args := m.Called(ctx, playerID)
+	if status, ok := args.Get(0).(*domain.TwoFactorStatus); ok {
+		return status, nil
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTwoFactorService) Enabled(ctx context.Context, playerID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, playerID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTwoFactorService) Enroll(ctx context.Context, playerID uuid.UUID) (*domain.TwoFactorEnrollment, error) {
+	args := m.Called(ctx, playerID)
+	if enrollment, ok := args.Get(0).(*domain.TwoFactorEnrollment); ok {
+		return enrollment, nil
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTwoFactorService) Confirm(ctx context.Context, playerID uuid.UUID, code string) ([]string, error) {
+	args := m.Called(ctx, playerID, code)
+	if codes, ok := args.Get(0).([]string); ok {
+		return codes, nil
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTwoFactorService) Disable(ctx context.Context, playerID uuid.UUID, code string) error {
+	args := m.Called(ctx, playerID, code)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorService) RegenerateRecoveryCodes(ctx context.Context, playerID uuid.UUID, code string) ([]string, error) {
+	args := m.Called(ctx, playerID, code)
+	if codes, ok := args.Get(0).([]string); ok {
+		return codes, nil
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTwoFactorService) Verify(ctx context.Context, playerID uuid.UUID, code string) error {
+	args := m.Called(ctx, playerID, code)
+	return args.Error(0)
+}
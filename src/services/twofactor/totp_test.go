@@ -0,0 +1,78 @@
+package twofactor_test
+
+import (
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe/src/services/twofactor"
+)
+
+var _ = Describe("TOTP", func() {
+	// The SHA1 secret of the RFC 6238 test vectors, base32 encoded.
+	const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	DescribeTable("should match the RFC 6238 test vectors",
+		func(unix int64, expected string) {
+			code, err := twofactor.Code(rfcSecret, twofactor.Step(time.Unix(unix, 0)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(expected))
+		},
+		Entry("at 59", int64(59), "287082"),
+		Entry("at 1111111109", int64(1111111109), "081804"),
+		Entry("at 1234567890", int64(1234567890), "005924"),
+		Entry("at 2000000000", int64(2000000000), "279037"),
+	)
+
+	It("should accept codes of adjacent steps", func() {
+		now := time.Unix(1111111109, 0)
+		previous, err := twofactor.Code(rfcSecret, twofactor.Step(now)-1)
+		Expect(err).ToNot(HaveOccurred())
+
+		step, ok := twofactor.Match(rfcSecret, previous, now, 0)
+
+		Expect(ok).To(BeTrue())
+		Expect(step).To(Equal(twofactor.Step(now) - 1))
+	})
+
+	It("should reject codes outside of the skew", func() {
+		now := time.Unix(1111111109, 0)
+		old, err := twofactor.Code(rfcSecret, twofactor.Step(now)-2)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, ok := twofactor.Match(rfcSecret, old, now, 0)
+
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should reject codes of already used steps", func() {
+		now := time.Unix(1111111109, 0)
+		code, err := twofactor.Code(rfcSecret, twofactor.Step(now))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, ok := twofactor.Match(rfcSecret, code, now, twofactor.Step(now))
+
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should build a provisioning uri", func() {
+		u, err := url.Parse(twofactor.ProvisioningURI("Tic Tac Toe", "player_1", rfcSecret))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(u.Scheme).To(Equal("otpauth"))
+		Expect(u.Host).To(Equal("totp"))
+		Expect(u.Path).To(Equal("/Tic Tac Toe:player_1"))
+		Expect(u.Query().Get("secret")).To(Equal(rfcSecret))
+		Expect(u.Query().Get("issuer")).To(Equal("Tic Tac Toe"))
+		Expect(u.Query().Get("digits")).To(Equal("6"))
+	})
+
+	It("should generate distinct decodable secrets", func() {
+		secret := twofactor.GenerateSecret()
+
+		Expect(secret).ToNot(Equal(twofactor.GenerateSecret()))
+		_, err := twofactor.Code(secret, 1)
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
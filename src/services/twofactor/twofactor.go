@@ -0,0 +1,261 @@
+package twofactor
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/config"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+)
+
+const (
+	InvalidCodeMessage      string = "two-factor code is invalid"
+	AlreadyEnabledMessage   string = "two-factor authentication is already enabled"
+	NotEnabledMessage       string = "two-factor authentication is not enabled"
+	recoveryCodeSize        int    = 10
+	recoveryCodeGroupLength int    = 5
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TwoFactorService manages optional TOTP two-factor authentication. An
+// enrolment started with Enroll becomes effective once Confirm accepts a code
+// from the authenticator app; from then on Verify accepts either a current
+// code or one of the single-use recovery codes.
+type TwoFactorService interface {
+	Status(context.Context, uuid.UUID) (*domain.TwoFactorStatus, error)
+	Enabled(context.Context, uuid.UUID) (bool, error)
+	Enroll(context.Context, uuid.UUID) (*domain.TwoFactorEnrollment, error)
+	Confirm(context.Context, uuid.UUID, string) ([]string, error)
+	Disable(context.Context, uuid.UUID, string) error
+	RegenerateRecoveryCodes(context.Context, uuid.UUID, string) ([]string, error)
+	Verify(context.Context, uuid.UUID, string) error
+}
+
+func NewTwoFactorService(configuration config.TwoFactorConfiguration,
+	db *sql.DB,
+	playerRepositoryFactory func(q repository.Querier) repository.PlayerRepository,
+	twoFactorRepositoryFactory func(q repository.Querier) repository.TwoFactorRepository) TwoFactorService {
+	return &twoFactorServiceImpl{
+		configuration:              configuration,
+		db:                         db,
+		playerRepositoryFactory:    playerRepositoryFactory,
+		twoFactorRepositoryFactory: twoFactorRepositoryFactory,
+	}
+}
+
+type twoFactorServiceImpl struct {
+	configuration              config.TwoFactorConfiguration
+	db                         *sql.DB
+	playerRepositoryFactory    func(q repository.Querier) repository.PlayerRepository
+	twoFactorRepositoryFactory func(q repository.Querier) repository.TwoFactorRepository
+}
+
+func (s *twoFactorServiceImpl) Status(ctx context.Context, playerID uuid.UUID) (*domain.TwoFactorStatus, error) {
+	twoFactorRepository := s.twoFactorRepositoryFactory(s.db)
+	twoFactor, err := twoFactorRepository.Get(ctx, playerID, false)
+	if err != nil {
+		if models.IsNotFoundError(err) {
+			return &domain.TwoFactorStatus{}, nil
+		}
+		return nil, err
+	}
+
+	status := &domain.TwoFactorStatus{
+		Enabled: twoFactor.Enabled,
+		Pending: !twoFactor.Enabled,
+	}
+	if twoFactor.Enabled {
+		if status.RecoveryCodesLeft, err = twoFactorRepository.CountRecoveryCodes(ctx, playerID); err != nil {
+			return nil, err
+		}
+	}
+
+	return status, nil
+}
+
+func (s *twoFactorServiceImpl) Enabled(ctx context.Context, playerID uuid.UUID) (bool, error) {
+	twoFactor, err := s.twoFactorRepositoryFactory(s.db).Get(ctx, playerID, false)
+	if err != nil {
+		if models.IsNotFoundError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return twoFactor.Enabled, nil
+}
+
+// Enroll starts a new enrolment, replacing a pending one. The secret is
+// returned only here.
+func (s *twoFactorServiceImpl) Enroll(ctx context.Context, playerID uuid.UUID) (*domain.TwoFactorEnrollment, error) {
+	return repository.WithTransactionT(ctx, s.db, func(tx *sql.Tx) (*domain.TwoFactorEnrollment, error) {
+		twoFactorRepository := s.twoFactorRepositoryFactory(tx)
+
+		twoFactor, err := twoFactorRepository.Get(ctx, playerID, true)
+		if err != nil && !models.IsNotFoundError(err) {
+			return nil, err
+		}
+		if twoFactor != nil && twoFactor.Enabled {
+			return nil, models.NewValidationError(AlreadyEnabledMessage)
+		}
+
+		player, err := s.playerRepositoryFactory(tx).Get(ctx, playerID)
+		if err != nil {
+			return nil, err
+		}
+
+		twoFactor = &domain.TwoFactor{
+			PlayerID: playerID,
+			Secret:   GenerateSecret(),
+		}
+		if err = twoFactorRepository.Save(ctx, twoFactor); err != nil {
+			return nil, err
+		}
+
+		return &domain.TwoFactorEnrollment{
+			Secret:          twoFactor.Secret,
+			ProvisioningURI: ProvisioningURI(s.configuration.Issuer, player.Login, twoFactor.Secret),
+		}, nil
+	})
+}
+
+// Confirm enables a pending enrolment and returns the initial recovery codes.
+func (s *twoFactorServiceImpl) Confirm(ctx context.Context, playerID uuid.UUID, code string) ([]string, error) {
+	return repository.WithTransactionT(ctx, s.db, func(tx *sql.Tx) ([]string, error) {
+		twoFactorRepository := s.twoFactorRepositoryFactory(tx)
+
+		twoFactor, err := twoFactorRepository.Get(ctx, playerID, true)
+		if err != nil {
+			if models.IsNotFoundError(err) {
+				return nil, models.NewValidationError("two-factor enrolment has not been started")
+			}
+			return nil, err
+		}
+		if twoFactor.Enabled {
+			return nil, models.NewValidationError(AlreadyEnabledMessage)
+		}
+
+		now := time.Now()
+		step, ok := Match(twoFactor.Secret, normalize(code), now, twoFactor.LastUsedStep)
+		if !ok {
+			return nil, models.NewValidationError(InvalidCodeMessage)
+		}
+
+		twoFactor.Enabled = true
+		twoFactor.LastUsedStep = step
+		twoFactor.ConfirmedAt = &now
+		if err = twoFactorRepository.Save(ctx, twoFactor); err != nil {
+			return nil, err
+		}
+
+		return s.replaceRecoveryCodes(ctx, twoFactorRepository, playerID)
+	})
+}
+
+func (s *twoFactorServiceImpl) Disable(ctx context.Context, playerID uuid.UUID, code string) error {
+	return repository.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		twoFactorRepository := s.twoFactorRepositoryFactory(tx)
+
+		if err := s.verify(ctx, twoFactorRepository, playerID, code); err != nil {
+			return err
+		}
+
+		return twoFactorRepository.Delete(ctx, playerID)
+	})
+}
+
+func (s *twoFactorServiceImpl) RegenerateRecoveryCodes(ctx context.Context, playerID uuid.UUID, code string) ([]string, error) {
+	return repository.WithTransactionT(ctx, s.db, func(tx *sql.Tx) ([]string, error) {
+		twoFactorRepository := s.twoFactorRepositoryFactory(tx)
+
+		if err := s.verify(ctx, twoFactorRepository, playerID, code); err != nil {
+			return nil, err
+		}
+
+		return s.replaceRecoveryCodes(ctx, twoFactorRepository, playerID)
+	})
+}
+
+func (s *twoFactorServiceImpl) Verify(ctx context.Context, playerID uuid.UUID, code string) error {
+	return repository.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		return s.verify(ctx, s.twoFactorRepositoryFactory(tx), playerID, code)
+	})
+}
+
+// verify accepts a TOTP code of a step not used before or an unused recovery
+// code. The enrolment row is locked so that concurrent requests cannot use
+// the same code twice.
+func (s *twoFactorServiceImpl) verify(ctx context.Context, twoFactorRepository repository.TwoFactorRepository, playerID uuid.UUID, code string) error {
+	twoFactor, err := twoFactorRepository.Get(ctx, playerID, true)
+	if err != nil {
+		if models.IsNotFoundError(err) {
+			return models.NewValidationError(NotEnabledMessage)
+		}
+		return err
+	}
+	if !twoFactor.Enabled {
+		return models.NewValidationError(NotEnabledMessage)
+	}
+
+	code = normalize(code)
+	if len(code) == Digits {
+		if step, ok := Match(twoFactor.Secret, code, time.Now(), twoFactor.LastUsedStep); ok {
+			twoFactor.LastUsedStep = step
+			return twoFactorRepository.Save(ctx, twoFactor)
+		}
+	} else if len(code) > 0 {
+		used, err := twoFactorRepository.UseRecoveryCode(ctx, playerID, hashRecoveryCode(code))
+		if err != nil {
+			return err
+		}
+		if used {
+			return nil
+		}
+	}
+
+	return models.NewAuthorizationError(InvalidCodeMessage)
+}
+
+func (s *twoFactorServiceImpl) replaceRecoveryCodes(ctx context.Context, twoFactorRepository repository.TwoFactorRepository, playerID uuid.UUID) ([]string, error) {
+	codes := make([]string, s.configuration.RecoveryCodes)
+	hashes := make([]string, s.configuration.RecoveryCodes)
+	for i := range codes {
+		codes[i] = generateRecoveryCode()
+		hashes[i] = hashRecoveryCode(normalize(codes[i]))
+	}
+
+	if err := twoFactorRepository.ReplaceRecoveryCodes(ctx, playerID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// generateRecoveryCode returns a code such as "k4x2p-9qmzt".
+func generateRecoveryCode() string {
+	b := make([]byte, recoveryCodeSize)
+	_, _ = rand.Read(b)
+	code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:2*recoveryCodeGroupLength]
+	return code[:recoveryCodeGroupLength] + "-" + code[recoveryCodeGroupLength:]
+}
+
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalize drops the separators players tend to type and lower-cases the
+// code.
+func normalize(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
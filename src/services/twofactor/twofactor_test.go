@@ -0,0 +1,215 @@
+package twofactor_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofrs/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/plamen-v/tic-tac-toe-models/models"
+	"github.com/plamen-v/tic-tac-toe/src/config"
+	"github.com/plamen-v/tic-tac-toe/src/domain"
+	"github.com/plamen-v/tic-tac-toe/src/repository"
+	"github.com/plamen-v/tic-tac-toe/src/repository/mocks"
+	"github.com/plamen-v/tic-tac-toe/src/services/twofactor"
+	tmock "github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("TwoFactor", func() {
+	var (
+		db                      *sql.DB
+		mock                    sqlmock.Sqlmock
+		ctx                     context.Context
+		mockPlayerRepository    *mocks.MockPlayerRepository
+		mockTwoFactorRepository *mocks.MockTwoFactorRepository
+		twoFactorService        twofactor.TwoFactorService
+		playerID                uuid.UUID
+		secret                  string
+		err                     error
+	)
+
+	currentCode := func() string {
+		code, err := twofactor.Code(secret, twofactor.Step(time.Now()))
+		Expect(err).ToNot(HaveOccurred())
+		return code
+	}
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		db, mock, err = sqlmock.New()
+		Expect(err).ToNot(HaveOccurred())
+		mockPlayerRepository = new(mocks.MockPlayerRepository)
+		mockTwoFactorRepository = new(mocks.MockTwoFactorRepository)
+		playerID = uuid.Must(uuid.NewV4())
+		secret = twofactor.GenerateSecret()
+		twoFactorService = twofactor.NewTwoFactorService(
+			config.TwoFactorConfiguration{Issuer: "Tic-Tac-Toe", RecoveryCodes: 3},
+			db,
+			func(q repository.Querier) repository.PlayerRepository {
+				return mockPlayerRepository
+			},
+			func(q repository.Querier) repository.TwoFactorRepository {
+				return mockTwoFactorRepository
+			},
+		)
+	})
+
+	AfterEach(func() {
+		err = mock.ExpectationsWereMet()
+		Expect(err).ToNot(HaveOccurred())
+		db.Close()
+	})
+
+	Context("Enroll", func() {
+		It("should store a pending enrolment and return the provisioning uri", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			mockTwoFactorRepository.On("Get", ctx, playerID, true).Return(nil, models.NewNotFoundError("not found"))
+			mockPlayerRepository.On("Get", ctx, playerID).Return(&models.Player{ID: playerID, Login: "player_1"}, nil)
+			mockTwoFactorRepository.On("Save", ctx, tmock.MatchedBy(func(tf *domain.TwoFactor) bool {
+				return tf.PlayerID == playerID && !tf.Enabled && len(tf.Secret) > 0
+			})).Return(nil)
+
+			enrollment, err := twoFactorService.Enroll(ctx, playerID)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(enrollment.ProvisioningURI).To(HavePrefix("otpauth://totp/Tic-Tac-Toe:player_1?"))
+			Expect(enrollment.ProvisioningURI).To(ContainSubstring("secret=" + enrollment.Secret))
+		})
+
+		It("should reject players that are already enrolled", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+			mockTwoFactorRepository.On("Get", ctx, playerID, true).Return(&domain.TwoFactor{PlayerID: playerID, Enabled: true}, nil)
+
+			_, err := twoFactorService.Enroll(ctx, playerID)
+
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+		})
+	})
+
+	Context("Confirm", func() {
+		It("should enable the enrolment and return recovery codes", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			mockTwoFactorRepository.On("Get", ctx, playerID, true).Return(&domain.TwoFactor{PlayerID: playerID, Secret: secret}, nil)
+			mockTwoFactorRepository.On("Save", ctx, tmock.MatchedBy(func(tf *domain.TwoFactor) bool {
+				return tf.Enabled && tf.ConfirmedAt != nil && tf.LastUsedStep > 0
+			})).Return(nil)
+			var hashes []string
+			mockTwoFactorRepository.On("ReplaceRecoveryCodes", ctx, playerID, tmock.Anything).Run(func(args tmock.Arguments) {
+				hashes = args.Get(2).([]string)
+			}).Return(nil)
+
+			codes, err := twoFactorService.Confirm(ctx, playerID, currentCode())
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(codes).To(HaveLen(3))
+			Expect(hashes).To(HaveLen(3))
+			Expect(codes[0]).To(MatchRegexp(`^[a-z2-7]{5}-[a-z2-7]{5}$`))
+			sum := sha256.Sum256([]byte(strings.ReplaceAll(codes[0], "-", "")))
+			Expect(hashes[0]).To(Equal(hex.EncodeToString(sum[:])))
+		})
+
+		It("should reject wrong codes", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+			mockTwoFactorRepository.On("Get", ctx, playerID, true).Return(&domain.TwoFactor{PlayerID: playerID, Secret: secret}, nil)
+
+			_, err := twoFactorService.Confirm(ctx, playerID, "abcdef")
+
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+			mockTwoFactorRepository.AssertNotCalled(GinkgoT(), "Save", tmock.Anything, tmock.Anything)
+		})
+	})
+
+	Context("Verify", func() {
+		It("should accept the current code once", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			twoFactor := &domain.TwoFactor{PlayerID: playerID, Secret: secret, Enabled: true}
+			mockTwoFactorRepository.On("Get", ctx, playerID, true).Return(twoFactor, nil)
+			mockTwoFactorRepository.On("Save", ctx, twoFactor).Return(nil)
+
+			code := currentCode()
+			Expect(twoFactorService.Verify(ctx, playerID, code)).To(Succeed())
+			Expect(twoFactor.LastUsedStep).To(BeNumerically(">=", twofactor.Step(time.Now())-twofactor.Skew))
+
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+			err := twoFactorService.Verify(ctx, playerID, code)
+			Expect(err).To(BeAssignableToTypeOf(&models.AuthorizationError{}))
+		})
+
+		It("should accept an unused recovery code", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			mockTwoFactorRepository.On("Get", ctx, playerID, true).Return(&domain.TwoFactor{PlayerID: playerID, Secret: secret, Enabled: true}, nil)
+			sum := sha256.Sum256([]byte("abcdefghij"))
+			mockTwoFactorRepository.On("UseRecoveryCode", ctx, playerID, hex.EncodeToString(sum[:])).Return(true, nil)
+
+			Expect(twoFactorService.Verify(ctx, playerID, "ABCDE-FGHIJ")).To(Succeed())
+		})
+
+		It("should reject used recovery codes", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+			mockTwoFactorRepository.On("Get", ctx, playerID, true).Return(&domain.TwoFactor{PlayerID: playerID, Secret: secret, Enabled: true}, nil)
+			mockTwoFactorRepository.On("UseRecoveryCode", ctx, playerID, tmock.Anything).Return(false, nil)
+
+			err := twoFactorService.Verify(ctx, playerID, "abcde-fghij")
+
+			Expect(err).To(BeAssignableToTypeOf(&models.AuthorizationError{}))
+		})
+
+		It("should reject players without two-factor authentication", func() {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+			mockTwoFactorRepository.On("Get", ctx, playerID, true).Return(&domain.TwoFactor{PlayerID: playerID, Secret: secret}, nil)
+
+			err := twoFactorService.Verify(ctx, playerID, currentCode())
+
+			Expect(err).To(BeAssignableToTypeOf(&models.ValidationError{}))
+		})
+	})
+
+	Context("Disable", func() {
+		It("should delete the enrolment after a valid code", func() {
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+			mockTwoFactorRepository.On("Get", ctx, playerID, true).Return(&domain.TwoFactor{PlayerID: playerID, Secret: secret, Enabled: true}, nil)
+			mockTwoFactorRepository.On("Save", ctx, tmock.Anything).Return(nil)
+			mockTwoFactorRepository.On("Delete", ctx, playerID).Return(nil)
+
+			Expect(twoFactorService.Disable(ctx, playerID, currentCode())).To(Succeed())
+			mockTwoFactorRepository.AssertExpectations(GinkgoT())
+		})
+	})
+
+	Context("Status", func() {
+		It("should report the remaining recovery codes", func() {
+			mockTwoFactorRepository.On("Get", ctx, playerID, false).Return(&domain.TwoFactor{PlayerID: playerID, Enabled: true}, nil)
+			mockTwoFactorRepository.On("CountRecoveryCodes", ctx, playerID).Return(7, nil)
+
+			status, err := twoFactorService.Status(ctx, playerID)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status).To(Equal(&domain.TwoFactorStatus{Enabled: true, RecoveryCodesLeft: 7}))
+		})
+
+		It("should report players without enrolment as disabled", func() {
+			mockTwoFactorRepository.On("Get", ctx, playerID, false).Return(nil, models.NewNotFoundError("not found"))
+
+			status, err := twoFactorService.Status(ctx, playerID)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(status).To(Equal(&domain.TwoFactorStatus{}))
+		})
+	})
+})